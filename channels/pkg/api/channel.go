@@ -41,6 +41,8 @@ type AddonSpec struct {
 	Namespace *string `json:"namespace,omitempty"`
 
 	// Selector is a label query over pods that should match the Replicas count.
+	// When channels is run with a health timeout, the Deployments, DaemonSets and Pods matching the selector
+	// must become ready after an update, or the previously installed manifest is re-applied.
 	Selector map[string]string `json:"selector"`
 
 	// Manifest is the URL to the manifest that should be applied
@@ -152,7 +152,7 @@ func (a *Addon) GetManifestFullUrl() (*url.URL, error) {
 	return manifestURL, nil
 }
 
-func (a *Addon) EnsureUpdated(ctx context.Context, k8sClient kubernetes.Interface, cmClient certmanager.Interface, pruner *Pruner, existingVersion *ChannelVersion) (*AddonUpdate, error) {
+func (a *Addon) EnsureUpdated(ctx context.Context, k8sClient kubernetes.Interface, cmClient certmanager.Interface, applier *Applier, pruner *Pruner, healthChecker *HealthChecker, existingVersion *ChannelVersion) (*AddonUpdate, error) {
 	required, err := a.GetRequiredUpdates(ctx, k8sClient, cmClient, existingVersion)
 	if err != nil {
 		return nil, err
@@ -162,22 +162,41 @@ func (a *Addon) EnsureUpdated(ctx context.Context, k8sClient kubernetes.Interfac
 	}
 
 	if required.NewVersion != nil {
-		manifestURL, err := a.GetManifestFullUrl()
+		manifestURL, data, err := a.readManifest()
 		if err != nil {
 			return nil, err
 		}
 		klog.Infof("Applying update from %q", manifestURL)
 
-		// We copy the manifest to a temp file because it is likely e.g. an s3 URL, which kubectl can't read
-		data, err := vfs.Context.ReadFile(manifestURL.String())
+		channel := a.buildChannel()
+
+		previous, err := channel.GetInstalledManifest(ctx, k8sClient)
 		if err != nil {
-			return nil, fmt.Errorf("error reading manifest: %w", err)
+			return nil, err
+		}
+		if previous == nil && required.ExistingVersion != nil {
+			// The addon was installed before we recorded manifests, so roll back to the live objects instead
+			snapshot, err := applier.Snapshot(ctx, data)
+			if err != nil {
+				return nil, fmt.Errorf("error recording live objects of %q before update: %w", a.Name, err)
+			}
+			previous = &InstalledManifest{Version: required.ExistingVersion, Manifest: snapshot}
 		}
 
-		if err := Apply(data); err != nil {
+		results, err := applier.Apply(ctx, data)
+		for _, result := range results {
+			if result.Error == nil {
+				klog.V(2).Infof("applied %s", result)
+			}
+		}
+		if err != nil {
 			return nil, fmt.Errorf("error applying update from %q: %w", manifestURL, err)
 		}
 
+		if err := healthChecker.WaitForReady(ctx, a.GetNamespace(), a.Spec.Selector); err != nil {
+			return nil, a.rollback(ctx, applier, previous, fmt.Errorf("update from %q failed health check: %w", manifestURL, err))
+		}
+
 		if err := pruner.Prune(ctx, data, a.Spec.Prune); err != nil {
 			return nil, fmt.Errorf("error pruning manifest from %q: %w", manifestURL, err)
 		}
@@ -186,11 +205,14 @@ func (a *Addon) EnsureUpdated(ctx context.Context, k8sClient kubernetes.Interfac
 			return nil, fmt.Errorf("error adding needs-update label: %v", err)
 		}
 
-		channel := a.buildChannel()
 		err = channel.SetInstalledVersion(ctx, k8sClient, a.ChannelVersion())
 		if err != nil {
 			return nil, fmt.Errorf("error applying annotation to record addon installation: %v", err)
 		}
+
+		if err := channel.SetInstalledManifest(ctx, k8sClient, a.ChannelVersion(), data); err != nil {
+			return nil, err
+		}
 	}
 	if required.InstallPKI {
 		err := a.installPKI(ctx, k8sClient, cmClient)
@@ -201,6 +223,68 @@ func (a *Addon) EnsureUpdated(ctx context.Context, k8sClient kubernetes.Interfac
 	return required, nil
 }
 
+// rollback re-applies the previously installed manifest after a failed update, returning an error that wraps cause.
+// The installed version annotation is left unchanged, so the update will be retried on the next run.
+func (a *Addon) rollback(ctx context.Context, applier *Applier, previous *InstalledManifest, cause error) error {
+	if previous == nil {
+		klog.Warningf("no previously installed manifest recorded for %q; unable to roll back", a.Name)
+		return cause
+	}
+
+	klog.Warningf("rolling back %q to %v: %v", a.Name, previous.Version, cause)
+	if _, err := applier.Apply(ctx, previous.Manifest); err != nil {
+		return fmt.Errorf("%v; additionally failed to roll back to %v: %w", cause, previous.Version, err)
+	}
+	return fmt.Errorf("%w; rolled back to %v", cause, previous.Version)
+}
+
+// readManifest returns the location and contents of the addon manifest.
+func (a *Addon) readManifest() (*url.URL, []byte, error) {
+	manifestURL, err := a.GetManifestFullUrl()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	data, err := vfs.Context.ReadFile(manifestURL.String())
+	if err != nil {
+		return nil, nil, fmt.Errorf("error reading manifest: %w", err)
+	}
+	return manifestURL, data, nil
+}
+
+// AddonDiff holds the changes that applying an addon would make to the cluster.
+type AddonDiff struct {
+	Name string
+	// Objects is the diff for each object in the manifest.
+	Objects []*ObjectDiff
+	// Prune is the list of objects that would be pruned.
+	Prune []*PruneTarget
+}
+
+// Diff computes the changes that applying the addon manifest would make, without changing the cluster.
+func (a *Addon) Diff(ctx context.Context, applier *Applier, pruner *Pruner) (*AddonDiff, error) {
+	manifestURL, data, err := a.readManifest()
+	if err != nil {
+		return nil, err
+	}
+
+	objects, err := applier.Diff(ctx, data)
+	if err != nil {
+		return nil, fmt.Errorf("error computing diff for %q: %w", manifestURL, err)
+	}
+
+	prune, err := pruner.FindPruneTargets(ctx, data, a.Spec.Prune)
+	if err != nil {
+		return nil, fmt.Errorf("error computing objects to prune for %q: %w", manifestURL, err)
+	}
+
+	return &AddonDiff{
+		Name:    a.Name,
+		Objects: objects,
+		Prune:   prune,
+	}, nil
+}
+
 func (a *Addon) AddNeedsUpdateLabel(ctx context.Context, k8sClient kubernetes.Interface, required *AddonUpdate) error {
 	if required.ExistingVersion != nil {
 		if a.Spec.NeedsRollingUpdate != "" {
@@ -18,14 +18,21 @@ package channels
 
 import (
 	"context"
+	"net/url"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/blang/semver/v4"
 	fakecertmanager "github.com/cert-manager/cert-manager/pkg/client/clientset/versioned/fake"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
 	fakekubernetes "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
 	"k8s.io/kops/channels/pkg/api"
 	"k8s.io/kops/upup/pkg/fi"
 )
@@ -363,3 +370,66 @@ func Test_InstallPKI(t *testing.T) {
 		t.Errorf("unexpected error: %v", err)
 	}
 }
+
+func Test_EnsureUpdated_RecordsManifest(t *testing.T) {
+	ctx := context.Background()
+
+	dir := t.TempDir()
+	manifest := []byte(testManifest)
+	if err := os.WriteFile(filepath.Join(dir, "test.yaml"), manifest, 0o644); err != nil {
+		t.Fatalf("error writing manifest: %v", err)
+	}
+	channelLocation, err := url.Parse("file://" + dir + "/")
+	if err != nil {
+		t.Fatalf("error parsing channel location: %v", err)
+	}
+
+	kubeSystem := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "kube-system",
+		},
+	}
+	fakek8s := fakekubernetes.NewSimpleClientset(kubeSystem)
+
+	dynamicClient := fakedynamic.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		crdGVR: "CustomResourceDefinitionList",
+	}, newEstablishedCRD("widgets.example.com"))
+	dynamicClient.PrependReactor("patch", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		obj := &unstructured.Unstructured{}
+		if err := obj.UnmarshalJSON(action.(k8stesting.PatchAction).GetPatch()); err != nil {
+			return true, nil, err
+		}
+		return true, obj, nil
+	})
+	applier := &Applier{
+		Client:     dynamicClient,
+		RESTMapper: newTestRESTMapper(),
+	}
+	pruner := &Pruner{
+		Client:     dynamicClient,
+		RESTMapper: newTestRESTMapper(),
+	}
+
+	// An addon without a selector is not health checked, but must still be recorded so that a later update can roll back
+	addon := &Addon{
+		Name:            "test",
+		ChannelName:     "test",
+		ChannelLocation: *channelLocation,
+		Spec: &api.AddonSpec{
+			Name:         fi.String("test"),
+			Manifest:     fi.String("test.yaml"),
+			ManifestHash: "abc",
+		},
+	}
+	if _, err := addon.EnsureUpdated(ctx, fakek8s, fakecertmanager.NewSimpleClientset(), applier, pruner, nil, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	installed, err := addon.buildChannel().GetInstalledManifest(ctx, fakek8s)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if installed == nil || installed.Version.ManifestHash != "abc" || string(installed.Manifest) != testManifest {
+		t.Errorf("unexpected installed manifest: %v", installed)
+	}
+}
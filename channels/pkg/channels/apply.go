@@ -17,53 +17,230 @@ limitations under the License.
 package channels
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
-	"os"
-	"os/exec"
-	"path"
-	"strings"
+	"time"
 
+	"go.uber.org/multierr"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/klog/v2"
+	"k8s.io/kops/pkg/kubemanifest"
 )
 
-// Apply calls kubectl apply to apply the manifest.
-// We will likely in future change this to create things directly (or more likely embed this logic into kubectl itself)
-func Apply(data []byte) error {
-	// We copy the manifest to a temp file because it is likely e.g. an s3 URL, which kubectl can't read
-	tmpDir, err := os.MkdirTemp("", "channel")
+// FieldManager is the field manager we use when applying objects.
+const FieldManager = "kops"
+
+var crdGVR = schema.GroupVersionResource{Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions"}
+
+// Applier applies manifests using server-side apply.
+type Applier struct {
+	Client     dynamic.Interface
+	RESTMapper meta.RESTMapper
+
+	// CRDEstablishedTimeout is how long we wait for applied CRDs to become established; defaults to one minute.
+	CRDEstablishedTimeout time.Duration
+}
+
+// ApplyResult holds the outcome of applying a single object.
+type ApplyResult struct {
+	GroupVersionKind schema.GroupVersionKind
+	Namespace        string
+	Name             string
+
+	// Error is non-nil if the object could not be applied.
+	Error error
+}
+
+func (r *ApplyResult) String() string {
+	key := r.Name
+	if r.Namespace != "" {
+		key = r.Namespace + "/" + r.Name
+	}
+	return r.GroupVersionKind.Kind + "." + r.GroupVersionKind.Group + " " + key
+}
+
+// Apply applies each object in the manifest using server-side apply.
+// CustomResourceDefinitions are applied first, and we wait for them to be established before applying the other objects.
+// A result is returned for every object that we attempted to apply; the error is non-nil if any object failed.
+func (a *Applier) Apply(ctx context.Context, data []byte) ([]*ApplyResult, error) {
+	objects, err := kubemanifest.LoadObjectsFrom(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse objects: %w", err)
+	}
+
+	var crds, others []*unstructured.Unstructured
+	for _, object := range objects {
+		u := object.ToUnstructured()
+		gvk := u.GroupVersionKind()
+		if gvk.Group == crdGVR.Group && gvk.Kind == "CustomResourceDefinition" {
+			crds = append(crds, u)
+		} else {
+			others = append(others, u)
+		}
+	}
+
+	var results []*ApplyResult
+	var merr error
+
+	for _, crd := range crds {
+		result := a.applyObject(ctx, crd)
+		if result.Error == nil {
+			result.Error = a.waitForCRDEstablished(ctx, crd.GetName())
+		}
+		results = append(results, result)
+		merr = multierr.Append(merr, result.Error)
+	}
+
+	if len(crds) != 0 {
+		// The new types are not known to a discovery-based mapper until it is reset
+		if resettable, ok := a.RESTMapper.(meta.ResettableRESTMapper); ok {
+			resettable.Reset()
+		}
+	}
+
+	for _, object := range others {
+		result := a.applyObject(ctx, object)
+		results = append(results, result)
+		merr = multierr.Append(merr, result.Error)
+	}
+
+	return results, merr
+}
+
+// applyObject applies a single object, returning the result.
+func (a *Applier) applyObject(ctx context.Context, object *unstructured.Unstructured) *ApplyResult {
+	result := &ApplyResult{
+		GroupVersionKind: object.GroupVersionKind(),
+		Namespace:        object.GetNamespace(),
+		Name:             object.GetName(),
+	}
+
+	resource, err := a.resourceFor(object)
+	if err != nil {
+		result.Error = err
+		return result
+	}
+	result.Namespace = object.GetNamespace()
+
+	data, err := json.Marshal(object)
+	if err != nil {
+		result.Error = fmt.Errorf("failed to marshal %s: %w", result, err)
+		return result
+	}
+
+	klog.V(2).Infof("applying %s", result)
+
+	force := true
+	if _, err := resource.Patch(ctx, object.GetName(), types.ApplyPatchType, data, metav1.PatchOptions{
+		FieldManager: FieldManager,
+		Force:        &force,
+	}); err != nil {
+		result.Error = fmt.Errorf("failed to apply %s: %w", result, err)
+		return result
+	}
+
+	return result
+}
+
+// Snapshot returns a manifest of the live objects that applying the manifest would change, so that they can be restored later.
+// Objects that do not exist yet are omitted, and the fields set by the server are removed.
+func (a *Applier) Snapshot(ctx context.Context, data []byte) ([]byte, error) {
+	objects, err := kubemanifest.LoadObjectsFrom(data)
 	if err != nil {
-		return fmt.Errorf("error creating temp dir: %v", err)
+		return nil, fmt.Errorf("failed to parse objects: %w", err)
 	}
 
-	defer func() {
-		if err := os.RemoveAll(tmpDir); err != nil {
-			klog.Warningf("error deleting temp dir %q: %v", tmpDir, err)
+	var snapshot kubemanifest.ObjectList
+	for _, object := range objects {
+		desired := object.ToUnstructured()
+		resource, err := a.resourceFor(desired)
+		if err != nil {
+			// The kind is not known yet, so there are no live objects of it
+			continue
+		}
+		live, err := resource.Get(ctx, desired.GetName(), metav1.GetOptions{})
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to get %s %s/%s: %w", desired.GetKind(), desired.GetNamespace(), desired.GetName(), err)
 		}
-	}()
 
-	localManifestFile := path.Join(tmpDir, "manifest.yaml")
-	if err := os.WriteFile(localManifestFile, data, 0o600); err != nil {
-		return fmt.Errorf("error writing temp file: %v", err)
+		live = withoutServerFields(live)
+		live.SetUID("")
+		live.SetCreationTimestamp(metav1.Time{})
+		live.SetSelfLink("")
+		unstructured.RemoveNestedField(live.Object, "status")
+		snapshot = append(snapshot, kubemanifest.NewObject(live.Object))
 	}
 
-	_, err = execKubectl("apply", "-f", localManifestFile, "--server-side", "--force-conflicts", "--field-manager=kops")
-	return err
+	return snapshot.ToYAML()
 }
 
-func execKubectl(args ...string) (string, error) {
-	kubectlPath := "kubectl" // Assume in PATH
-	cmd := exec.Command(kubectlPath, args...)
-	env := os.Environ()
-	cmd.Env = env
+// resourceFor returns the dynamic client for the object's resource.
+// Namespaced objects without a namespace are placed in the default namespace, matching kubectl.
+func (a *Applier) resourceFor(object *unstructured.Unstructured) (dynamic.ResourceInterface, error) {
+	gvk := object.GroupVersionKind()
+	if gvk.Kind == "" || gvk.Version == "" {
+		return nil, fmt.Errorf("object %q does not specify apiVersion and kind", object.GetName())
+	}
+	if object.GetName() == "" {
+		return nil, fmt.Errorf("object of kind %s does not specify a name", gvk.Kind)
+	}
 
-	human := strings.Join(cmd.Args, " ")
-	klog.V(2).Infof("Running command: %s", human)
-	output, err := cmd.CombinedOutput()
+	restMapping, err := a.RESTMapper.RESTMapping(gvk.GroupKind(), gvk.Version)
 	if err != nil {
-		klog.Infof("error running %s", human)
-		klog.Info(string(output))
-		return string(output), fmt.Errorf("error running kubectl: %v", err)
+		return nil, fmt.Errorf("unable to find resource for %s: %w", gvk, err)
+	}
+
+	if restMapping.Scope.Name() != meta.RESTScopeNameNamespace {
+		return a.Client.Resource(restMapping.Resource), nil
+	}
+
+	if object.GetNamespace() == "" {
+		object.SetNamespace(metav1.NamespaceDefault)
+	}
+	return a.Client.Resource(restMapping.Resource).Namespace(object.GetNamespace()), nil
+}
+
+// waitForCRDEstablished waits for the named CRD to report the Established condition.
+func (a *Applier) waitForCRDEstablished(ctx context.Context, name string) error {
+	timeout := a.CRDEstablishedTimeout
+	if timeout == 0 {
+		timeout = time.Minute
 	}
 
-	return string(output), err
+	err := wait.PollImmediateWithContext(ctx, time.Second, timeout, func(ctx context.Context) (bool, error) {
+		crd, err := a.Client.Resource(crdGVR).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			klog.V(2).Infof("error getting CRD %q: %v", name, err)
+			return false, nil
+		}
+		conditions, _, err := unstructured.NestedSlice(crd.Object, "status", "conditions")
+		if err != nil {
+			return false, fmt.Errorf("error reading conditions of CRD %q: %w", name, err)
+		}
+		for _, c := range conditions {
+			condition, ok := c.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if condition["type"] == "Established" && condition["status"] == "True" {
+				return true, nil
+			}
+		}
+		return false, nil
+	})
+	if err != nil {
+		return fmt.Errorf("error waiting for CRD %q to be established: %w", name, err)
+	}
+	return nil
 }
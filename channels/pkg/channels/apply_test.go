@@ -0,0 +1,214 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package channels
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/kops/pkg/kubemanifest"
+)
+
+const testManifest = `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+  namespace: kube-system
+---
+apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: widget
+---
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: widgets.example.com
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: role
+`
+
+func newTestRESTMapper() meta.RESTMapper {
+	mapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{
+		{Version: "v1"},
+		{Group: "example.com", Version: "v1"},
+		{Group: "apiextensions.k8s.io", Version: "v1"},
+		{Group: "rbac.authorization.k8s.io", Version: "v1"},
+	})
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, meta.RESTScopeNamespace)
+	mapper.Add(schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Widget"}, meta.RESTScopeNamespace)
+	mapper.Add(schema.GroupVersionKind{Group: "apiextensions.k8s.io", Version: "v1", Kind: "CustomResourceDefinition"}, meta.RESTScopeRoot)
+	mapper.Add(schema.GroupVersionKind{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "ClusterRole"}, meta.RESTScopeRoot)
+	return mapper
+}
+
+func newEstablishedCRD(name string) *unstructured.Unstructured {
+	crd := &unstructured.Unstructured{}
+	crd.SetAPIVersion("apiextensions.k8s.io/v1")
+	crd.SetKind("CustomResourceDefinition")
+	crd.SetName(name)
+	unstructured.SetNestedSlice(crd.Object, []interface{}{
+		map[string]interface{}{"type": "Established", "status": "True"},
+	}, "status", "conditions")
+	return crd
+}
+
+func Test_Apply(t *testing.T) {
+	ctx := context.Background()
+
+	client := fakedynamic.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		crdGVR: "CustomResourceDefinitionList",
+	}, newEstablishedCRD("widgets.example.com"))
+
+	var applied []string
+	client.PrependReactor("patch", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		patch := action.(k8stesting.PatchAction)
+		if patch.GetPatchType() != types.ApplyPatchType {
+			return true, nil, fmt.Errorf("unexpected patch type %q", patch.GetPatchType())
+		}
+		if patch.GetResource().Resource == "clusterroles" {
+			return true, nil, fmt.Errorf("forbidden")
+		}
+		applied = append(applied, patch.GetResource().Resource+" "+patch.GetNamespace()+"/"+patch.GetName())
+
+		obj := &unstructured.Unstructured{}
+		if err := obj.UnmarshalJSON(patch.GetPatch()); err != nil {
+			return true, nil, err
+		}
+		return true, obj, nil
+	})
+
+	applier := &Applier{
+		Client:                client,
+		RESTMapper:            newTestRESTMapper(),
+		CRDEstablishedTimeout: time.Second,
+	}
+
+	results, err := applier.Apply(ctx, []byte(testManifest))
+	if err == nil {
+		t.Errorf("expected error applying ClusterRole")
+	}
+
+	expectedApplied := []string{
+		"customresourcedefinitions /widgets.example.com",
+		"configmaps kube-system/config",
+		"widgets default/widget",
+	}
+	if !reflect.DeepEqual(applied, expectedApplied) {
+		t.Errorf("unexpected applied objects: expected %v, got %v", expectedApplied, applied)
+	}
+
+	if len(results) != 4 {
+		t.Fatalf("expected 4 results, got %d", len(results))
+	}
+	for _, result := range results {
+		failed := result.GroupVersionKind.Kind == "ClusterRole"
+		if failed != (result.Error != nil) {
+			t.Errorf("unexpected error for %s: %v", result, result.Error)
+		}
+	}
+}
+
+func Test_Apply_CRDNotEstablished(t *testing.T) {
+	ctx := context.Background()
+
+	client := fakedynamic.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		crdGVR: "CustomResourceDefinitionList",
+	})
+	client.PrependReactor("patch", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		obj := &unstructured.Unstructured{}
+		if err := obj.UnmarshalJSON(action.(k8stesting.PatchAction).GetPatch()); err != nil {
+			return true, nil, err
+		}
+		return true, obj, nil
+	})
+
+	applier := &Applier{
+		Client:                client,
+		RESTMapper:            newTestRESTMapper(),
+		CRDEstablishedTimeout: 10 * time.Millisecond,
+	}
+
+	results, err := applier.Apply(ctx, []byte(testManifest))
+	if err == nil {
+		t.Fatalf("expected error waiting for CRD")
+	}
+	if results[0].GroupVersionKind.Kind != "CustomResourceDefinition" || results[0].Error == nil {
+		t.Errorf("expected CRD result to report an error, got %s: %v", results[0], results[0].Error)
+	}
+}
+
+func Test_Snapshot(t *testing.T) {
+	ctx := context.Background()
+
+	live := &unstructured.Unstructured{}
+	live.SetAPIVersion("v1")
+	live.SetKind("ConfigMap")
+	live.SetNamespace("kube-system")
+	live.SetName("config")
+	live.SetUID("0123")
+	live.SetResourceVersion("123")
+	live.SetManagedFields([]metav1.ManagedFieldsEntry{{Manager: FieldManager, Operation: metav1.ManagedFieldsOperationApply}})
+	live.Object["data"] = map[string]interface{}{"a": "1"}
+
+	client := fakedynamic.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		{Version: "v1", Resource: "configmaps"}:                    "ConfigMapList",
+		{Group: "example.com", Version: "v1", Resource: "widgets"}: "WidgetList",
+		crdGVR: "CustomResourceDefinitionList",
+		{Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "clusterroles"}: "ClusterRoleList",
+	}, live)
+
+	applier := &Applier{
+		Client:     client,
+		RESTMapper: newTestRESTMapper(),
+	}
+
+	snapshot, err := applier.Snapshot(ctx, []byte(testManifest))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	objects, err := kubemanifest.LoadObjectsFrom(snapshot)
+	if err != nil {
+		t.Fatalf("error parsing snapshot: %v", err)
+	}
+	if len(objects) != 1 {
+		t.Fatalf("expected only the existing ConfigMap in the snapshot, got %d objects:\n%s", len(objects), snapshot)
+	}
+	u := objects[0].ToUnstructured()
+	if u.GetName() != "config" || u.GetUID() != "" || u.GetResourceVersion() != "" || u.GetManagedFields() != nil {
+		t.Errorf("expected server fields to be removed from the snapshot:\n%s", snapshot)
+	}
+	if data, _, _ := unstructured.NestedStringMap(u.Object, "data"); data["a"] != "1" {
+		t.Errorf("expected the live data in the snapshot:\n%s", snapshot)
+	}
+}
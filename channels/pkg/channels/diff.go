@@ -0,0 +1,153 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package channels
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/kops/pkg/diff"
+	"k8s.io/kops/pkg/kubemanifest"
+	"sigs.k8s.io/yaml"
+)
+
+// ObjectDiff describes the difference between an object in a manifest and the live object in the cluster.
+type ObjectDiff struct {
+	GroupVersionKind schema.GroupVersionKind
+	Namespace        string
+	Name             string
+
+	// New is true if the object does not yet exist in the cluster.
+	New bool
+	// Diff is the textual diff from the live object to the object in the manifest; empty if there are no changes.
+	Diff string
+}
+
+func (d *ObjectDiff) String() string {
+	key := d.Name
+	if d.Namespace != "" {
+		key = d.Namespace + "/" + d.Name
+	}
+	return d.GroupVersionKind.Kind + "." + d.GroupVersionKind.Group + " " + key
+}
+
+// Diff compares each object in the manifest against the live object in the cluster.
+// Each existing object is applied with a server-side dry run, using the same field manager as Apply,
+// and the result is compared against the live object, so that defaulted fields do not show up as changes.
+func (a *Applier) Diff(ctx context.Context, data []byte) ([]*ObjectDiff, error) {
+	objects, err := kubemanifest.LoadObjectsFrom(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse objects: %w", err)
+	}
+
+	var diffs []*ObjectDiff
+	for _, object := range objects {
+		desired := object.ToUnstructured()
+		d, err := a.diffObject(ctx, desired)
+		if err != nil {
+			return nil, err
+		}
+		diffs = append(diffs, d)
+	}
+	return diffs, nil
+}
+
+func (a *Applier) diffObject(ctx context.Context, desired *unstructured.Unstructured) (*ObjectDiff, error) {
+	d := &ObjectDiff{
+		GroupVersionKind: desired.GroupVersionKind(),
+		Namespace:        desired.GetNamespace(),
+		Name:             desired.GetName(),
+	}
+
+	resource, resourceErr := a.resourceFor(desired)
+	d.Namespace = desired.GetNamespace()
+
+	desiredYAML, err := yaml.Marshal(desired.Object)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s: %w", d, err)
+	}
+
+	if resourceErr != nil {
+		if a.isNewKind(d.GroupVersionKind) {
+			// The kind is expected to be created by a CRD in the same manifest
+			d.New = true
+			d.Diff = diff.FormatDiff("", string(desiredYAML))
+			return d, nil
+		}
+		return nil, resourceErr
+	}
+
+	live, err := resource.Get(ctx, desired.GetName(), metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			d.New = true
+			d.Diff = diff.FormatDiff("", string(desiredYAML))
+			return d, nil
+		}
+		return nil, fmt.Errorf("failed to get %s: %w", d, err)
+	}
+
+	data, err := json.Marshal(desired)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s: %w", d, err)
+	}
+	force := true
+	applied, err := resource.Patch(ctx, desired.GetName(), types.ApplyPatchType, data, metav1.PatchOptions{
+		FieldManager: FieldManager,
+		Force:        &force,
+		DryRun:       []string{metav1.DryRunAll},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply %s with a dry run: %w", d, err)
+	}
+
+	liveYAML, err := yaml.Marshal(withoutServerFields(live).Object)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal live %s: %w", d, err)
+	}
+	appliedYAML, err := yaml.Marshal(withoutServerFields(applied).Object)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal applied %s: %w", d, err)
+	}
+
+	if string(liveYAML) != string(appliedYAML) {
+		d.Diff = diff.FormatDiff(string(liveYAML), string(appliedYAML))
+	}
+	return d, nil
+}
+
+// isNewKind returns true if the kind is not known to the RESTMapper, which is the case for kinds defined by CRDs that are not yet installed.
+func (a *Applier) isNewKind(gvk schema.GroupVersionKind) bool {
+	_, err := a.RESTMapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	return err != nil
+}
+
+// withoutServerFields returns a copy of the object without the metadata that the server updates on every write,
+// which would otherwise show up as changes.
+func withoutServerFields(u *unstructured.Unstructured) *unstructured.Unstructured {
+	u = u.DeepCopy()
+	u.SetManagedFields(nil)
+	u.SetResourceVersion("")
+	u.SetGeneration(0)
+	return u
+}
@@ -0,0 +1,228 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package channels
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/kops/channels/pkg/api"
+)
+
+func newConfigMap(namespace, name string, data map[string]interface{}) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetAPIVersion("v1")
+	u.SetKind("ConfigMap")
+	u.SetNamespace(namespace)
+	u.SetName(name)
+	u.SetLabels(map[string]string{"app": "test"})
+	u.Object["data"] = data
+	return u
+}
+
+// newWidget returns a Widget with a list of containers, standing in for a workload whose list items are defaulted by the server.
+func newWidget(namespace, name string, containers ...map[string]interface{}) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetAPIVersion("example.com/v1")
+	u.SetKind("Widget")
+	u.SetNamespace(namespace)
+	u.SetName(name)
+	var list []interface{}
+	for _, c := range containers {
+		list = append(list, c)
+	}
+	unstructured.SetNestedSlice(u.Object, list, "spec", "containers")
+	return u
+}
+
+// applyWithDefaults simulates a server-side dry-run apply, defaulting the imagePullPolicy of Widget containers.
+func applyWithDefaults(action k8stesting.Action) (bool, runtime.Object, error) {
+	patch := action.(k8stesting.PatchAction)
+	if patch.GetPatchType() != types.ApplyPatchType {
+		return true, nil, fmt.Errorf("unexpected patch type %q", patch.GetPatchType())
+	}
+	obj := &unstructured.Unstructured{}
+	if err := obj.UnmarshalJSON(patch.GetPatch()); err != nil {
+		return true, nil, err
+	}
+	containers, _, _ := unstructured.NestedSlice(obj.Object, "spec", "containers")
+	for _, c := range containers {
+		container := c.(map[string]interface{})
+		if _, found := container["imagePullPolicy"]; !found {
+			container["imagePullPolicy"] = "IfNotPresent"
+		}
+	}
+	if containers != nil {
+		unstructured.SetNestedSlice(obj.Object, containers, "spec", "containers")
+	}
+	obj.SetResourceVersion("124")
+	return true, obj, nil
+}
+
+func Test_Diff(t *testing.T) {
+	ctx := context.Background()
+
+	unchanged := newConfigMap("kube-system", "unchanged", map[string]interface{}{"a": "1"})
+	unchanged.SetResourceVersion("123")
+	unchanged.SetManagedFields([]metav1.ManagedFieldsEntry{{Manager: FieldManager, Operation: metav1.ManagedFieldsOperationApply}})
+	changed := newConfigMap("kube-system", "changed", map[string]interface{}{"a": "1"})
+	defaulted := newWidget("kube-system", "defaulted", map[string]interface{}{"name": "app", "image": "app:1", "imagePullPolicy": "IfNotPresent"})
+
+	client := fakedynamic.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		{Version: "v1", Resource: "configmaps"}:                    "ConfigMapList",
+		{Group: "example.com", Version: "v1", Resource: "widgets"}: "WidgetList",
+	}, unchanged, changed, defaulted)
+
+	var patched int
+	client.PrependReactor("patch", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		patched++
+		return applyWithDefaults(action)
+	})
+
+	applier := &Applier{
+		Client:     client,
+		RESTMapper: newTestRESTMapper(),
+	}
+
+	manifest := `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: unchanged
+  namespace: kube-system
+  labels:
+    app: test
+data:
+  a: "1"
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: changed
+  namespace: kube-system
+  labels:
+    app: test
+data:
+  a: "2"
+---
+apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: defaulted
+  namespace: kube-system
+spec:
+  containers:
+  - name: app
+    image: app:1
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: created
+  namespace: kube-system
+---
+apiVersion: unknown.example.com/v1
+kind: Gadget
+metadata:
+  name: gadget
+`
+
+	diffs, err := applier.Diff(ctx, []byte(manifest))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(diffs) != 5 {
+		t.Fatalf("expected 5 diffs, got %d", len(diffs))
+	}
+
+	if diffs[0].New || diffs[0].Diff != "" {
+		t.Errorf("expected no changes to %s, got %q", diffs[0], diffs[0].Diff)
+	}
+	if diffs[1].New || !strings.Contains(diffs[1].Diff, "-   a: \"1\"") || !strings.Contains(diffs[1].Diff, "+   a: \"2\"") {
+		t.Errorf("unexpected diff for %s: %q", diffs[1], diffs[1].Diff)
+	}
+	if diffs[2].New || diffs[2].Diff != "" {
+		t.Errorf("expected defaulted fields of %s not to show as changes, got %q", diffs[2], diffs[2].Diff)
+	}
+	if !diffs[3].New {
+		t.Errorf("expected %s to be new", diffs[3])
+	}
+	if !diffs[4].New {
+		t.Errorf("expected %s of unknown kind to be new", diffs[4])
+	}
+
+	// Only the existing objects are applied with a dry run
+	if patched != 3 {
+		t.Errorf("expected 3 dry-run applies, got %d", patched)
+	}
+}
+
+func Test_FindPruneTargets(t *testing.T) {
+	ctx := context.Background()
+
+	client := fakedynamic.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		{Version: "v1", Resource: "configmaps"}: "ConfigMapList",
+	},
+		newConfigMap("kube-system", "keep", nil),
+		newConfigMap("kube-system", "remove", nil),
+		newConfigMap("default", "other-namespace", nil),
+	)
+
+	pruner := &Pruner{
+		Client:     client,
+		RESTMapper: newTestRESTMapper(),
+	}
+
+	manifest := `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: keep
+  namespace: kube-system
+`
+	spec := &api.PruneSpec{
+		Kinds: []api.PruneKindSpec{
+			{Kind: "ConfigMap", Namespaces: []string{"kube-system"}, LabelSelector: "app=test"},
+		},
+	}
+
+	targets, err := pruner.FindPruneTargets(ctx, []byte(manifest), spec)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(targets) != 1 || targets[0].Namespace != "kube-system" || targets[0].Name != "remove" {
+		t.Errorf("unexpected prune targets: %v", targets)
+	}
+
+	// FindPruneTargets must not delete anything
+	list, err := client.Resource(targets[0].GroupVersionResource).Namespace("kube-system").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("error listing objects: %v", err)
+	}
+	if len(list.Items) != 2 {
+		t.Errorf("expected 2 objects to remain, got %d", len(list.Items))
+	}
+}
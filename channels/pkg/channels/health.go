@@ -0,0 +1,133 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package channels
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+	"k8s.io/kops/pkg/readiness"
+)
+
+// DefaultHealthTimeout is how long we wait by default for the workloads of an updated addon to become ready.
+const DefaultHealthTimeout = 5 * time.Minute
+
+// HealthChecker waits for the workloads of an addon to become ready.
+type HealthChecker struct {
+	Client kubernetes.Interface
+
+	// Timeout is how long we wait for the workloads to become ready; if zero, health checking is disabled.
+	Timeout time.Duration
+	// Interval is how often we check the workloads; defaults to five seconds.
+	Interval time.Duration
+}
+
+// WaitForReady waits until all Deployments, DaemonSets and Pods in the namespace matching the selector are ready.
+// It returns an error describing the last unready workload if they do not become ready within the timeout.
+func (h *HealthChecker) WaitForReady(ctx context.Context, namespace string, selector map[string]string) error {
+	if h == nil || h.Timeout == 0 || len(selector) == 0 {
+		return nil
+	}
+
+	interval := h.Interval
+	if interval == 0 {
+		interval = 5 * time.Second
+	}
+
+	listOptions := metav1.ListOptions{LabelSelector: labels.SelectorFromSet(selector).String()}
+
+	var lastProblem string
+	err := wait.PollImmediateWithContext(ctx, interval, h.Timeout, func(ctx context.Context) (bool, error) {
+		problem, err := h.findProblem(ctx, namespace, listOptions)
+		if err != nil {
+			klog.Warningf("error checking readiness: %v", err)
+			lastProblem = err.Error()
+			return false, nil
+		}
+		if problem != "" {
+			klog.V(2).Infof("waiting for addon to become ready: %s", problem)
+			lastProblem = problem
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil {
+		if lastProblem != "" {
+			return fmt.Errorf("addon did not become ready within %v: %s", h.Timeout, lastProblem)
+		}
+		return fmt.Errorf("addon did not become ready within %v: %w", h.Timeout, err)
+	}
+	return nil
+}
+
+// findProblem returns a description of the first unready workload, or an empty string if all workloads are ready.
+func (h *HealthChecker) findProblem(ctx context.Context, namespace string, listOptions metav1.ListOptions) (string, error) {
+	deployments, err := h.Client.AppsV1().Deployments(namespace).List(ctx, listOptions)
+	if err != nil {
+		return "", fmt.Errorf("error listing deployments: %w", err)
+	}
+	for i := range deployments.Items {
+		if problem := readiness.DeploymentProblem(&deployments.Items[i]); problem != "" {
+			return problem, nil
+		}
+	}
+
+	daemonSets, err := h.Client.AppsV1().DaemonSets(namespace).List(ctx, listOptions)
+	if err != nil {
+		return "", fmt.Errorf("error listing daemonsets: %w", err)
+	}
+	for _, ds := range daemonSets.Items {
+		desired := ds.Status.DesiredNumberScheduled
+		if ds.Status.ObservedGeneration < ds.Generation {
+			return fmt.Sprintf("daemonset %s/%s has not observed the latest generation", ds.Namespace, ds.Name), nil
+		}
+		if ds.Status.UpdatedNumberScheduled < desired || ds.Status.NumberAvailable < desired {
+			return fmt.Sprintf("daemonset %s/%s has %d/%d updated and %d/%d available pods", ds.Namespace, ds.Name, ds.Status.UpdatedNumberScheduled, desired, ds.Status.NumberAvailable, desired), nil
+		}
+	}
+
+	pods, err := h.Client.CoreV1().Pods(namespace).List(ctx, listOptions)
+	if err != nil {
+		return "", fmt.Errorf("error listing pods: %w", err)
+	}
+	for _, pod := range pods.Items {
+		if pod.Status.Phase == v1.PodSucceeded || pod.DeletionTimestamp != nil {
+			continue
+		}
+		if !isPodReady(&pod) {
+			return fmt.Sprintf("pod %s/%s is not ready (phase %s)", pod.Namespace, pod.Name, pod.Status.Phase), nil
+		}
+	}
+
+	return "", nil
+}
+
+func isPodReady(pod *v1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == v1.PodReady {
+			return condition.Status == v1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -0,0 +1,143 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package channels
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakekubernetes "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/kops/upup/pkg/fi"
+)
+
+func Test_HealthChecker(t *testing.T) {
+	labels := map[string]string{"k8s-addon": "test"}
+	objectMeta := metav1.ObjectMeta{Name: "test", Namespace: "kube-system", Labels: labels, Generation: 2}
+
+	readyDeployment := &appsv1.Deployment{
+		ObjectMeta: objectMeta,
+		Spec:       appsv1.DeploymentSpec{Replicas: fi.Int32(2)},
+		Status:     appsv1.DeploymentStatus{ObservedGeneration: 2, UpdatedReplicas: 2, AvailableReplicas: 2},
+	}
+	unavailableDeployment := &appsv1.Deployment{
+		ObjectMeta: objectMeta,
+		Spec:       appsv1.DeploymentSpec{Replicas: fi.Int32(2)},
+		Status:     appsv1.DeploymentStatus{ObservedGeneration: 2, UpdatedReplicas: 2, AvailableReplicas: 1},
+	}
+	staleDaemonSet := &appsv1.DaemonSet{
+		ObjectMeta: objectMeta,
+		Status:     appsv1.DaemonSetStatus{ObservedGeneration: 1, DesiredNumberScheduled: 3, UpdatedNumberScheduled: 3, NumberAvailable: 3},
+	}
+	readyPod := &corev1.Pod{
+		ObjectMeta: objectMeta,
+		Status: corev1.PodStatus{
+			Phase:      corev1.PodRunning,
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+		},
+	}
+	unreadyPod := &corev1.Pod{
+		ObjectMeta: objectMeta,
+		Status: corev1.PodStatus{
+			Phase:      corev1.PodPending,
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionFalse}},
+		},
+	}
+	unselectedPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "kube-system"},
+		Status:     corev1.PodStatus{Phase: corev1.PodPending},
+	}
+
+	grid := []struct {
+		Name     string
+		Objects  []runtime.Object
+		Expected string
+	}{
+		{
+			Name:    "ready",
+			Objects: []runtime.Object{readyDeployment, readyPod, unselectedPod},
+		},
+		{
+			Name:     "unavailable deployment",
+			Objects:  []runtime.Object{unavailableDeployment},
+			Expected: "deployment kube-system/test has 2/2 updated and 1/2 available replicas",
+		},
+		{
+			Name:     "stale daemonset",
+			Objects:  []runtime.Object{staleDaemonSet},
+			Expected: "daemonset kube-system/test has not observed the latest generation",
+		},
+		{
+			Name:     "unready pod",
+			Objects:  []runtime.Object{unreadyPod},
+			Expected: "pod kube-system/test is not ready (phase Pending)",
+		},
+	}
+	for _, g := range grid {
+		t.Run(g.Name, func(t *testing.T) {
+			h := &HealthChecker{
+				Client:   fakekubernetes.NewSimpleClientset(g.Objects...),
+				Timeout:  20 * time.Millisecond,
+				Interval: 5 * time.Millisecond,
+			}
+			err := h.WaitForReady(context.Background(), "kube-system", labels)
+			if g.Expected == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), g.Expected) {
+				t.Errorf("expected error containing %q, got %v", g.Expected, err)
+			}
+		})
+	}
+}
+
+func Test_InstalledManifest(t *testing.T) {
+	ctx := context.Background()
+	k8sClient := fakekubernetes.NewSimpleClientset()
+	channel := &Channel{Namespace: "kube-system", Name: "test.addons.k8s.io"}
+
+	installed, err := channel.GetInstalledManifest(ctx, k8sClient)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if installed != nil {
+		t.Fatalf("expected no installed manifest, got %v", installed)
+	}
+
+	for _, hash := range []string{"abc", "def"} {
+		version := &ChannelVersion{Channel: fi.String("test"), ManifestHash: hash, SystemGeneration: CurrentSystemGeneration}
+		if err := channel.SetInstalledManifest(ctx, k8sClient, version, []byte("manifest-"+hash)); err != nil {
+			t.Fatalf("unexpected error recording manifest: %v", err)
+		}
+	}
+
+	installed, err = channel.GetInstalledManifest(ctx, k8sClient)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if installed == nil || installed.Version.ManifestHash != "def" || string(installed.Manifest) != "manifest-def" {
+		t.Errorf("unexpected installed manifest: %v", installed)
+	}
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package channels
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// historyConfigMapPrefix is the prefix of the name of the ConfigMap holding the last installed manifest of an addon.
+	historyConfigMapPrefix = "addon-history."
+	// historyManifestKey is the key holding the gzipped manifest in the history ConfigMap.
+	historyManifestKey = "manifest.gz"
+	// historyVersionKey is the key holding the encoded ChannelVersion in the history ConfigMap.
+	historyVersionKey = "version"
+)
+
+// InstalledManifest is a manifest that was previously installed successfully.
+type InstalledManifest struct {
+	Version  *ChannelVersion
+	Manifest []byte
+}
+
+func (c *Channel) historyConfigMapName() string {
+	return historyConfigMapPrefix + c.Name
+}
+
+// GetInstalledManifest returns the manifest that was last installed successfully, or nil if none was recorded.
+func (c *Channel) GetInstalledManifest(ctx context.Context, k8sClient kubernetes.Interface) (*InstalledManifest, error) {
+	configMap, err := k8sClient.CoreV1().ConfigMaps(c.Namespace).Get(ctx, c.historyConfigMapName(), metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error querying addon history %s/%s: %w", c.Namespace, c.historyConfigMapName(), err)
+	}
+
+	version, err := ParseChannelVersion(configMap.Data[historyVersionKey])
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := gzip.NewReader(bytes.NewReader(configMap.BinaryData[historyManifestKey]))
+	if err != nil {
+		return nil, fmt.Errorf("error decompressing manifest in addon history: %w", err)
+	}
+	manifest, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("error decompressing manifest in addon history: %w", err)
+	}
+
+	return &InstalledManifest{
+		Version:  version,
+		Manifest: manifest,
+	}, nil
+}
+
+// SetInstalledManifest records the manifest that was installed successfully, so that we can roll back to it if a later update fails.
+func (c *Channel) SetInstalledManifest(ctx context.Context, k8sClient kubernetes.Interface, version *ChannelVersion, manifest []byte) error {
+	encodedVersion, err := version.Encode()
+	if err != nil {
+		return err
+	}
+
+	var compressed bytes.Buffer
+	w := gzip.NewWriter(&compressed)
+	if _, err := w.Write(manifest); err != nil {
+		return fmt.Errorf("error compressing manifest: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("error compressing manifest: %w", err)
+	}
+
+	configMap := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      c.historyConfigMapName(),
+			Namespace: c.Namespace,
+			Labels: map[string]string{
+				"addon.kops.k8s.io/name": c.Name,
+			},
+		},
+		Data: map[string]string{
+			historyVersionKey: encodedVersion,
+		},
+		BinaryData: map[string][]byte{
+			historyManifestKey: compressed.Bytes(),
+		},
+	}
+
+	configMaps := k8sClient.CoreV1().ConfigMaps(c.Namespace)
+	_, err = configMaps.Update(ctx, configMap, metav1.UpdateOptions{})
+	if errors.IsNotFound(err) {
+		_, err = configMaps.Create(ctx, configMap, metav1.CreateOptions{})
+	}
+	if err != nil {
+		return fmt.Errorf("error recording addon history %s/%s: %w", c.Namespace, c.historyConfigMapName(), err)
+	}
+	return nil
+}
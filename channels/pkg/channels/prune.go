@@ -20,11 +20,11 @@ import (
 	"context"
 	"fmt"
 
+	"k8s.io/apimachinery/pkg/api/meta"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic"
-	"k8s.io/client-go/restmapper"
 	"k8s.io/klog/v2"
 	"k8s.io/kops/channels/pkg/api"
 	"k8s.io/kops/pkg/kubemanifest"
@@ -32,31 +32,72 @@ import (
 
 type Pruner struct {
 	Client     dynamic.Interface
-	RESTMapper *restmapper.DeferredDiscoveryRESTMapper
+	RESTMapper meta.RESTMapper
+}
+
+// PruneTarget identifies an object that would be deleted by Prune.
+type PruneTarget struct {
+	GroupVersionResource schema.GroupVersionResource
+	Namespace            string
+	Name                 string
+}
+
+func (t *PruneTarget) String() string {
+	key := t.Name
+	if t.Namespace != "" {
+		key = t.Namespace + "/" + t.Name
+	}
+	return t.GroupVersionResource.String() + " " + key
 }
 
 // Prune prunes objects not in the manifest, according to PruneSpec.
 func (p *Pruner) Prune(ctx context.Context, manifest []byte, spec *api.PruneSpec) error {
-	klog.Infof("Prune spec: %v", spec)
+	targets, err := p.FindPruneTargets(ctx, manifest, spec)
+	if err != nil {
+		return err
+	}
+
+	for _, target := range targets {
+		klog.Infof("pruning %s", target)
+
+		var resource dynamic.ResourceInterface
+		if target.Namespace != "" {
+			resource = p.Client.Resource(target.GroupVersionResource).Namespace(target.Namespace)
+		} else {
+			resource = p.Client.Resource(target.GroupVersionResource)
+		}
+
+		var opts v1.DeleteOptions
+		if err := resource.Delete(ctx, target.Name, opts); err != nil {
+			return fmt.Errorf("failed to delete %s: %w", target, err)
+		}
+	}
+
+	return nil
+}
+
+// FindPruneTargets returns the objects that Prune would delete, without deleting them.
+func (p *Pruner) FindPruneTargets(ctx context.Context, manifest []byte, spec *api.PruneSpec) ([]*PruneTarget, error) {
+	klog.V(2).Infof("Prune spec: %v", spec)
 
 	if spec == nil {
-		return nil
+		return nil, nil
 	}
 
 	objects, err := kubemanifest.LoadObjectsFrom(manifest)
 	if err != nil {
-		return fmt.Errorf("failed to parse objects: %w", err)
+		return nil, fmt.Errorf("failed to parse objects: %w", err)
 	}
 
 	objectsByKind := make(map[schema.GroupKind][]*kubemanifest.Object)
 	for _, object := range objects {
 		gv, err := schema.ParseGroupVersion(object.APIVersion())
 		if err != nil || gv.Version == "" {
-			return fmt.Errorf("failed to parse apiVersion %q", object.APIVersion())
+			return nil, fmt.Errorf("failed to parse apiVersion %q", object.APIVersion())
 		}
 		kind := object.Kind()
 		if kind == "" {
-			return fmt.Errorf("failed to find kind in object")
+			return nil, fmt.Errorf("failed to find kind in object")
 		}
 
 		gvk := gv.WithKind(kind)
@@ -64,23 +105,26 @@ func (p *Pruner) Prune(ctx context.Context, manifest []byte, spec *api.PruneSpec
 		objectsByKind[gk] = append(objectsByKind[gk], object)
 	}
 
+	var targets []*PruneTarget
 	for i := range spec.Kinds {
 		pruneKind := &spec.Kinds[i]
 		gk := schema.GroupKind{Group: pruneKind.Group, Kind: pruneKind.Kind}
-		if err := p.pruneObjectsOfKind(ctx, gk, pruneKind, objectsByKind[gk]); err != nil {
-			return fmt.Errorf("failed to prune objects of kind %s: %w", gk, err)
+		kindTargets, err := p.findPruneTargetsOfKind(ctx, gk, pruneKind, objectsByKind[gk])
+		if err != nil {
+			return nil, fmt.Errorf("failed to prune objects of kind %s: %w", gk, err)
 		}
+		targets = append(targets, kindTargets...)
 	}
 
-	return nil
+	return targets, nil
 }
 
-func (p *Pruner) pruneObjectsOfKind(ctx context.Context, gk schema.GroupKind, spec *api.PruneKindSpec, keepObjects []*kubemanifest.Object) error {
-	klog.Infof("pruning objects of kind: %v", gk)
+func (p *Pruner) findPruneTargetsOfKind(ctx context.Context, gk schema.GroupKind, spec *api.PruneKindSpec, keepObjects []*kubemanifest.Object) ([]*PruneTarget, error) {
+	klog.V(2).Infof("finding objects of kind %v that are not in the manifest", gk)
 
 	restMapping, err := p.RESTMapper.RESTMapping(gk)
 	if err != nil {
-		return fmt.Errorf("unable to find resource for %s: %w", gk, err)
+		return nil, fmt.Errorf("unable to find resource for %s: %w", gk, err)
 	}
 
 	gvr := restMapping.Resource
@@ -89,38 +133,36 @@ func (p *Pruner) pruneObjectsOfKind(ctx context.Context, gk schema.GroupKind, sp
 	listOptions.LabelSelector = spec.LabelSelector
 	listOptions.FieldSelector = spec.FieldSelector
 
+	var targets []*PruneTarget
 	baseResource := p.Client.Resource(gvr)
 	if len(spec.Namespaces) == 0 {
 		objects, err := baseResource.List(ctx, listOptions)
 		if err != nil {
-			return fmt.Errorf("error listing objects: %w", err)
-		}
-		if err := p.pruneObjects(ctx, gvr, objects, keepObjects); err != nil {
-			return err
+			return nil, fmt.Errorf("error listing objects: %w", err)
 		}
+		targets = append(targets, findPruneTargets(gvr, objects, keepObjects)...)
 	} else {
 		for _, namespace := range spec.Namespaces {
 			resource := baseResource.Namespace(namespace)
 			actualObjects, err := resource.List(ctx, listOptions)
 			if err != nil {
-				return fmt.Errorf("error listing objects in namespace %s: %w", namespace, err)
-			}
-			if err := p.pruneObjects(ctx, gvr, actualObjects, keepObjects); err != nil {
-				return err
+				return nil, fmt.Errorf("error listing objects in namespace %s: %w", namespace, err)
 			}
+			targets = append(targets, findPruneTargets(gvr, actualObjects, keepObjects)...)
 		}
 	}
 
-	return nil
+	return targets, nil
 }
 
-func (p *Pruner) pruneObjects(ctx context.Context, gvr schema.GroupVersionResource, actualObjects *unstructured.UnstructuredList, keepObjects []*kubemanifest.Object) error {
+func findPruneTargets(gvr schema.GroupVersionResource, actualObjects *unstructured.UnstructuredList, keepObjects []*kubemanifest.Object) []*PruneTarget {
 	keepMap := make(map[string]*kubemanifest.Object)
 	for _, keepObject := range keepObjects {
 		key := keepObject.GetNamespace() + "/" + keepObject.GetName()
 		keepMap[key] = keepObject
 	}
 
+	var targets []*PruneTarget
 	for _, actualObject := range actualObjects.Items {
 		name := actualObject.GetName()
 		namespace := actualObject.GetNamespace()
@@ -130,20 +172,12 @@ func (p *Pruner) pruneObjects(ctx context.Context, gvr schema.GroupVersionResour
 			continue
 		}
 
-		klog.Infof("pruning %s %s", gvr, key)
-
-		var resource dynamic.ResourceInterface
-		if namespace != "" {
-			resource = p.Client.Resource(gvr).Namespace(namespace)
-		} else {
-			resource = p.Client.Resource(gvr)
-		}
-
-		var opts v1.DeleteOptions
-		if err := resource.Delete(ctx, name, opts); err != nil {
-			return fmt.Errorf("failed to delete %s: %w", key, err)
-		}
+		targets = append(targets, &PruneTarget{
+			GroupVersionResource: gvr,
+			Namespace:            namespace,
+			Name:                 name,
+		})
 	}
 
-	return nil
+	return targets
 }
@@ -23,6 +23,7 @@ import (
 	"net/url"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/blang/semver/v4"
 	"github.com/cert-manager/cert-manager/pkg/client/clientset/versioned"
@@ -39,8 +40,12 @@ import (
 )
 
 type ApplyChannelOptions struct {
-	Yes   bool
-	Files []string
+	Yes    bool
+	DryRun bool
+	Files  []string
+
+	// HealthTimeout is how long to wait for the selected workloads of an updated addon to become ready before rolling back.
+	HealthTimeout time.Duration
 }
 
 func NewCmdApplyChannel(f Factory, out io.Writer) *cobra.Command {
@@ -56,7 +61,9 @@ func NewCmdApplyChannel(f Factory, out io.Writer) *cobra.Command {
 	}
 
 	cmd.Flags().BoolVar(&options.Yes, "yes", false, "Apply update")
+	cmd.Flags().BoolVar(&options.DryRun, "dry-run", false, "Print the changes that would be made to each object, without applying them")
 	cmd.Flags().StringSliceVarP(&options.Files, "filename", "f", []string{}, "Apply from a local file")
+	cmd.Flags().DurationVar(&options.HealthTimeout, "health-timeout", channels.DefaultHealthTimeout, "Wait up to this long for the workloads matching an addon's selector to become ready, rolling back to the previous manifest if they do not; 0 disables health checking")
 
 	return cmd
 }
@@ -107,10 +114,14 @@ func RunApplyChannel(ctx context.Context, f Factory, out io.Writer, options *App
 	}
 	menu.MergeAddons(filesMenu)
 
-	return applyMenu(ctx, menu, k8sClient, cmClient, dynamicClient, restMapper, options.Yes)
+	if options.Yes && options.DryRun {
+		return fmt.Errorf("cannot use both --yes and --dry-run")
+	}
+
+	return applyMenu(ctx, menu, k8sClient, cmClient, dynamicClient, restMapper, options)
 }
 
-func applyMenu(ctx context.Context, menu *channels.AddonMenu, k8sClient kubernetes.Interface, cmClient versioned.Interface, dynamicClient dynamic.Interface, restMapper *restmapper.DeferredDiscoveryRESTMapper, apply bool) error {
+func applyMenu(ctx context.Context, menu *channels.AddonMenu, k8sClient kubernetes.Interface, cmClient versioned.Interface, dynamicClient dynamic.Interface, restMapper *restmapper.DeferredDiscoveryRESTMapper, options *ApplyChannelOptions) error {
 	// channelVersions is the list of installed addons in the cluster.
 	// It is keyed by <namespace>:<addon name>.
 	channelVersions, err := getChannelVersions(ctx, k8sClient)
@@ -159,9 +170,9 @@ func applyMenu(ctx context.Context, menu *channels.AddonMenu, k8sClient kubernet
 		}
 	}
 
-	if !apply {
-		fmt.Printf("\nMust specify --yes to update\n")
-		return nil
+	applier := &channels.Applier{
+		Client:     dynamicClient,
+		RESTMapper: restMapper,
 	}
 
 	pruner := &channels.Pruner{
@@ -169,10 +180,24 @@ func applyMenu(ctx context.Context, menu *channels.AddonMenu, k8sClient kubernet
 		RESTMapper: restMapper,
 	}
 
+	if options.DryRun {
+		return printDiffs(ctx, os.Stdout, updates, needUpdates, applier, pruner)
+	}
+
+	if !options.Yes {
+		fmt.Printf("\nMust specify --yes to update\n")
+		return nil
+	}
+
+	healthChecker := &channels.HealthChecker{
+		Client:  k8sClient,
+		Timeout: options.HealthTimeout,
+	}
+
 	var merr error
 
 	for _, needUpdate := range needUpdates {
-		update, err := needUpdate.EnsureUpdated(ctx, k8sClient, cmClient, pruner, channelVersions[needUpdate.GetNamespace()+":"+needUpdate.Name])
+		update, err := needUpdate.EnsureUpdated(ctx, k8sClient, cmClient, applier, pruner, healthChecker, channelVersions[needUpdate.GetNamespace()+":"+needUpdate.Name])
 		if err != nil {
 			merr = multierr.Append(merr, fmt.Errorf("updating %q: %w", needUpdate.Name, err))
 		} else if update != nil {
@@ -183,6 +208,47 @@ func applyMenu(ctx context.Context, menu *channels.AddonMenu, k8sClient kubernet
 	return merr
 }
 
+// printDiffs prints the changes that each addon update would make to the objects in the cluster.
+func printDiffs(ctx context.Context, out io.Writer, updates []*channels.AddonUpdate, needUpdates []*channels.Addon, applier *channels.Applier, pruner *channels.Pruner) error {
+	for i, addon := range needUpdates {
+		if updates[i].NewVersion == nil {
+			continue
+		}
+
+		addonDiff, err := addon.Diff(ctx, applier, pruner)
+		if err != nil {
+			return fmt.Errorf("computing diff for %q: %w", addon.Name, err)
+		}
+
+		fmt.Fprintf(out, "\nAddon %q:\n", addonDiff.Name)
+		changed := false
+		for _, objectDiff := range addonDiff.Objects {
+			switch {
+			case objectDiff.New:
+				fmt.Fprintf(out, "  Create %s\n", objectDiff)
+			case objectDiff.Diff != "":
+				fmt.Fprintf(out, "  Update %s\n", objectDiff)
+			default:
+				continue
+			}
+			changed = true
+			for _, line := range strings.Split(strings.TrimSuffix(objectDiff.Diff, "\n"), "\n") {
+				fmt.Fprintf(out, "    %s\n", line)
+			}
+		}
+		for _, target := range addonDiff.Prune {
+			changed = true
+			fmt.Fprintf(out, "  Prune %s\n", target)
+		}
+		if !changed {
+			fmt.Fprintf(out, "  No object changes\n")
+		}
+	}
+
+	fmt.Fprintf(out, "\nDry run: no changes were applied\n")
+	return nil
+}
+
 func getUpdates(ctx context.Context, menu *channels.AddonMenu, k8sClient kubernetes.Interface, cmClient versioned.Interface, channelVersions map[string]*channels.ChannelVersion) ([]*channels.AddonUpdate, []*channels.Addon, error) {
 	var updates []*channels.AddonUpdate
 	var needUpdates []*channels.Addon
@@ -32,6 +32,7 @@ import (
 	"k8s.io/kops/cmd/kops-controller/pkg/config"
 	"k8s.io/kops/cmd/kops-controller/pkg/server"
 	"k8s.io/kops/pkg/bootstrap"
+	"k8s.io/kops/pkg/bootstrap/oidc"
 	"k8s.io/kops/pkg/nodeidentity"
 	nodeidentityaws "k8s.io/kops/pkg/nodeidentity/aws"
 	nodeidentityazure "k8s.io/kops/pkg/nodeidentity/azure"
@@ -61,7 +62,7 @@ func main() {
 
 	// Disable metrics by default (avoid port conflicts, also risky because we are host network)
 	metricsAddress := ":0"
-	// flag.StringVar(&metricsAddr, "metrics-addr", metricsAddress, "The address the metric endpoint binds to.")
+	flag.StringVar(&metricsAddress, "metrics-addr", metricsAddress, "The address the metric endpoint binds to.")
 
 	configPath := "/etc/kubernetes/kops-controller/config.yaml"
 	flag.StringVar(&configPath, "conf", configPath, "Location of yaml configuration file")
@@ -123,7 +124,27 @@ func main() {
 			klog.Fatalf("server cloud provider config not provided")
 		}
 
-		srv, err := server.NewServer(&opt, verifier)
+		var adminVerifiers []bootstrap.Verifier
+		if adminCredentials := opt.Server.AdminCredentials; adminCredentials != nil {
+			if adminCredentials.AWS != nil {
+				adminVerifier, err := awsup.NewAWSUserVerifier(adminCredentials.AWS)
+				if err != nil {
+					setupLog.Error(err, "unable to create admin credentials verifier")
+					os.Exit(1)
+				}
+				adminVerifiers = append(adminVerifiers, adminVerifier)
+			}
+			if adminCredentials.OIDC != nil {
+				adminVerifier, err := oidc.NewVerifier(adminCredentials.OIDC)
+				if err != nil {
+					setupLog.Error(err, "unable to create admin credentials verifier")
+					os.Exit(1)
+				}
+				adminVerifiers = append(adminVerifiers, adminVerifier)
+			}
+		}
+
+		srv, err := server.NewServer(&opt, verifier, adminVerifiers)
 		if err != nil {
 			setupLog.Error(err, "unable to create server")
 			os.Exit(1)
@@ -17,6 +17,10 @@ limitations under the License.
 package config
 
 import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kops/pkg/bootstrap/oidc"
 	"k8s.io/kops/upup/pkg/fi/cloudup/awsup"
 	gcetpm "k8s.io/kops/upup/pkg/fi/cloudup/gce/tpm"
 )
@@ -58,6 +62,41 @@ type ServerOptions struct {
 
 	// UseInstanceIDForNodeName uses the instance ID instead of the hostname for the node name.
 	UseInstanceIDForNodeName bool `json:"useInstanceIDForNodeName,omitempty"`
+
+	// AdminCredentials configures the issuing of admin credentials to users.
+	AdminCredentials *AdminCredentialsOptions `json:"adminCredentials,omitempty"`
+
+	// RateLimit limits how often each node or user may request credentials.
+	// Bootstrap requests that only fetch the node configuration are not counted.
+	RateLimit *RateLimitOptions `json:"rateLimit,omitempty"`
+}
+
+const (
+	// DefaultRateLimitRequests is the number of requests each node or user may make in each DefaultRateLimitPeriod,
+	// when no rate limit is configured.
+	DefaultRateLimitRequests = 10
+	// DefaultRateLimitPeriod is the period in which requests are counted, when no rate limit is configured.
+	DefaultRateLimitPeriod = time.Hour
+)
+
+// RateLimitOptions limits how often each node or user may request credentials.
+type RateLimitOptions struct {
+	// Requests is the number of requests each node or user may make in each Period.
+	// Rate limiting is disabled if it is 0.
+	Requests int `json:"requests"`
+	// Period is the period in which the requests are counted.
+	Period metav1.Duration `json:"period"`
+}
+
+// AdminCredentialsOptions configures the issuing of admin credentials to users.
+type AdminCredentialsOptions struct {
+	// MaxValidity is the longest lifetime of an issued credential.
+	MaxValidity metav1.Duration `json:"maxValidity"`
+
+	// AWS authenticates users by their AWS identity.
+	AWS *awsup.AWSUserVerifierOptions `json:"aws,omitempty"`
+	// OIDC authenticates users by their OIDC ID tokens.
+	OIDC *oidc.VerifierOptions `json:"oidc,omitempty"`
 }
 
 type ServerProviderOptions struct {
@@ -0,0 +1,153 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"k8s.io/klog/v2"
+	"k8s.io/kops/pkg/apis/nodeup"
+	"k8s.io/kops/pkg/bootstrap"
+	"k8s.io/kops/pkg/pki"
+	"k8s.io/kops/pkg/rbac"
+	"k8s.io/kops/upup/pkg/fi"
+)
+
+// adminCredentials issues a short-lived cluster admin certificate to an authenticated user.
+func (s *Server) adminCredentials(w http.ResponseWriter, r *http.Request) {
+	audit := newAuditRecord("admin-credentials", r)
+	defer audit.finish()
+	w = audit.wrap(w)
+
+	if r.Body == nil {
+		klog.Infof("admin-credentials %s no body", r.RemoteAddr)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		klog.Infof("admin-credentials %s read err: %v", r.RemoteAddr, err)
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(fmt.Sprintf("admin-credentials %s failed to read body: %v", r.RemoteAddr, err)))
+		return
+	}
+
+	id, err := s.verifyAdmin(r, body)
+	if err != nil {
+		klog.Infof("admin-credentials %s verify err: %v", r.RemoteAddr, err)
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(fmt.Sprintf("failed to verify token: %v", err)))
+		return
+	}
+	audit.identify(id)
+
+	if !s.checkRateLimit(w, r, "admin-credentials", "user/"+id.User) {
+		return
+	}
+
+	req := &nodeup.AdminCredentialsRequest{}
+	if err := json.Unmarshal(body, req); err != nil {
+		klog.Infof("admin-credentials %s decode err: %v", r.RemoteAddr, err)
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(fmt.Sprintf("failed to decode: %v", err)))
+		return
+	}
+
+	if req.APIVersion != nodeup.BootstrapAPIVersion {
+		klog.Infof("admin-credentials %s wrong APIVersion", r.RemoteAddr)
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte("unexpected APIVersion"))
+		return
+	}
+
+	validity := adminCredentialValidity(time.Duration(req.ValiditySeconds)*time.Second, s.opt.Server.AdminCredentials.MaxValidity.Duration)
+	cert, err := s.issueAdminCert(req.PublicKey, id, validity)
+	if err != nil {
+		klog.Infof("admin-credentials %s issue err: %v", r.RemoteAddr, err)
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(fmt.Sprintf("failed to issue certificate: %v", err)))
+		return
+	}
+	audit.certs = append(audit.certs, "admin")
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(&nodeup.AdminCredentialsResponse{Certificate: cert})
+	klog.Infof("admin-credentials %s issued to %q for %v", r.RemoteAddr, id.User, validity)
+}
+
+// verifyAdmin authenticates the user with each of the configured methods in turn.
+func (s *Server) verifyAdmin(r *http.Request, body []byte) (*bootstrap.VerifyResult, error) {
+	token := r.Header.Get("Authorization")
+
+	var errs []string
+	for _, verifier := range s.adminVerifiers {
+		id, err := verifier.VerifyToken(r.Context(), token, body, false)
+		if err == nil {
+			if id.User == "" {
+				return nil, fmt.Errorf("verifier did not identify a user")
+			}
+			return id, nil
+		}
+		errs = append(errs, err.Error())
+	}
+	return nil, fmt.Errorf("%s", strings.Join(errs, "; "))
+}
+
+// adminCredentialValidity returns the lifetime of an issued credential, limiting the requested lifetime to the maximum.
+func adminCredentialValidity(requested time.Duration, max time.Duration) time.Duration {
+	if requested <= 0 || requested > max {
+		return max
+	}
+	return requested
+}
+
+func (s *Server) issueAdminCert(pubKey string, id *bootstrap.VerifyResult, validity time.Duration) (string, error) {
+	block, _ := pem.Decode([]byte(pubKey))
+	if block == nil || block.Type != "RSA PUBLIC KEY" {
+		return "", fmt.Errorf("unexpected public key")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return "", fmt.Errorf("parsing key: %v", err)
+	}
+
+	issueReq := &pki.IssueCertRequest{
+		Signer: fi.CertificateIDCA,
+		Type:   "client",
+		Subject: pkix.Name{
+			CommonName:   id.User,
+			Organization: []string{rbac.SystemPrivilegedGroup},
+		},
+		PublicKey: key,
+		Validity:  validity,
+	}
+	cert, _, _, err := pki.IssueCert(issueReq, s.keystore)
+	if err != nil {
+		return "", fmt.Errorf("issuing certificate: %v", err)
+	}
+
+	return cert.AsString()
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"net/http"
+	"time"
+
+	"k8s.io/klog/v2"
+	"k8s.io/kops/pkg/bootstrap"
+)
+
+// Outcomes of requests for credentials, as reported in the audit log and metrics.
+const (
+	outcomeSuccess     = "success"
+	outcomeInvalid     = "invalid"
+	outcomeDenied      = "denied"
+	outcomeRateLimited = "rate_limited"
+	outcomeError       = "error"
+)
+
+// auditRecord is the audit record of a request for credentials.
+// It is logged, and the metrics of the request are recorded, once the request is handled.
+type auditRecord struct {
+	endpoint   string
+	remoteAddr string
+	start      time.Time
+
+	// nodeName, instanceGroup and user are the verified identity of the requester.
+	nodeName      string
+	instanceGroup string
+	user          string
+
+	// certs are the names of the certificates issued to the requester.
+	certs []string
+
+	// status is the HTTP status of the response, or 0 if no response was written.
+	status int
+}
+
+func newAuditRecord(endpoint string, r *http.Request) *auditRecord {
+	return &auditRecord{
+		endpoint:   endpoint,
+		remoteAddr: r.RemoteAddr,
+		start:      time.Now(),
+	}
+}
+
+// identify records the verified identity of the requester.
+func (a *auditRecord) identify(id *bootstrap.VerifyResult) {
+	a.nodeName = id.NodeName
+	a.instanceGroup = id.InstanceGroupName
+	a.user = id.User
+}
+
+// wrap returns a ResponseWriter that records the status of the response.
+func (a *auditRecord) wrap(w http.ResponseWriter) http.ResponseWriter {
+	return &statusRecorder{ResponseWriter: w, record: a}
+}
+
+// outcome classifies the request by the status of the response.
+func (a *auditRecord) outcome() string {
+	switch a.status {
+	case http.StatusOK:
+		return outcomeSuccess
+	case http.StatusBadRequest:
+		return outcomeInvalid
+	case http.StatusForbidden:
+		return outcomeDenied
+	case http.StatusTooManyRequests:
+		return outcomeRateLimited
+	default:
+		// Includes requests that panicked before writing a response.
+		return outcomeError
+	}
+}
+
+// finish logs the audit record and records the metrics of the request.
+func (a *auditRecord) finish() {
+	outcome := a.outcome()
+	duration := time.Since(a.start)
+
+	klog.InfoS("kops-controller audit",
+		"endpoint", a.endpoint,
+		"remoteAddr", a.remoteAddr,
+		"node", a.nodeName,
+		"instanceGroup", a.instanceGroup,
+		"user", a.user,
+		"certs", a.certs,
+		"outcome", outcome,
+		"status", a.status,
+		"duration", duration)
+
+	requestsTotal.WithLabelValues(a.endpoint, outcome).Inc()
+	requestDuration.WithLabelValues(a.endpoint, outcome).Observe(duration.Seconds())
+	if outcome == outcomeSuccess {
+		for _, name := range a.certs {
+			certificatesIssued.WithLabelValues(a.endpoint, name).Inc()
+		}
+	}
+}
+
+// statusRecorder is a ResponseWriter that records the status of the response in an auditRecord.
+type statusRecorder struct {
+	http.ResponseWriter
+	record *auditRecord
+}
+
+func (w *statusRecorder) WriteHeader(status int) {
+	if w.record.status == 0 {
+		w.record.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusRecorder) Write(b []byte) (int, error) {
+	if w.record.status == 0 {
+		w.record.status = http.StatusOK
+	}
+	return w.ResponseWriter.Write(b)
+}
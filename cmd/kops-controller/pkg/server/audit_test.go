@@ -0,0 +1,56 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAuditRecordOutcome(t *testing.T) {
+	grid := []struct {
+		handler  func(w http.ResponseWriter)
+		expected string
+	}{
+		{
+			handler:  func(w http.ResponseWriter) { _, _ = w.Write([]byte("{}")) },
+			expected: outcomeSuccess,
+		},
+		{
+			handler:  func(w http.ResponseWriter) { w.WriteHeader(http.StatusForbidden) },
+			expected: outcomeDenied,
+		},
+		{
+			handler:  func(w http.ResponseWriter) { w.WriteHeader(http.StatusTooManyRequests) },
+			expected: outcomeRateLimited,
+		},
+		{
+			handler:  func(w http.ResponseWriter) {},
+			expected: outcomeError,
+		},
+	}
+
+	for _, g := range grid {
+		r := httptest.NewRequest("POST", "/bootstrap", nil)
+		audit := newAuditRecord("bootstrap", r)
+		g.handler(audit.wrap(httptest.NewRecorder()))
+		if actual := audit.outcome(); actual != g.expected {
+			t.Errorf("unexpected outcome, actual=%q, expected=%q", actual, g.expected)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"fmt"
+	"os"
+	"path"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/klog/v2"
+	"k8s.io/kops/pkg/pki"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// servingCertificateName is the name under which the expiry of our TLS serving certificate is reported.
+const servingCertificateName = "kops-controller"
+
+var (
+	certificateExpirationDesc = prometheus.NewDesc(
+		"kops_controller_certificate_expiration_timestamp_seconds",
+		"The time at which a certificate used by kops-controller expires, in seconds since the Unix epoch.",
+		[]string{"name"},
+		nil,
+	)
+
+	requestsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kops_controller_requests_total",
+			Help: "The number of requests for credentials handled by kops-controller, by endpoint and outcome.",
+		},
+		[]string{"endpoint", "outcome"},
+	)
+
+	requestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "kops_controller_request_duration_seconds",
+			Help:    "The time taken by kops-controller to handle requests for credentials, by endpoint and outcome.",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"endpoint", "outcome"},
+	)
+
+	certificatesIssued = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kops_controller_certificates_issued_total",
+			Help: "The number of certificates issued by kops-controller, by endpoint and certificate name.",
+		},
+		[]string{"endpoint", "name"},
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(requestsTotal, requestDuration, certificatesIssued)
+}
+
+// certificateCollector exports the expiry of the signing CAs and of the serving certificate.
+// The certificates are read when the metrics are scraped, so that rotated certificates are reported.
+// The metrics are served by the controller manager when it is configured with a metrics address.
+type certificateCollector struct {
+	server *Server
+}
+
+var _ prometheus.Collector = &certificateCollector{}
+
+// Describe implements prometheus.Collector
+func (c *certificateCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- certificateExpirationDesc
+}
+
+// Collect implements prometheus.Collector
+func (c *certificateCollector) Collect(ch chan<- prometheus.Metric) {
+	opt := c.server.opt.Server
+
+	paths := make(map[string]string)
+	for _, name := range opt.SigningCAs {
+		paths[name] = path.Join(opt.CABasePath, name+".crt")
+	}
+	paths[servingCertificateName] = opt.ServerCertificatePath
+
+	for name, p := range paths {
+		certificate, err := readCertificateFile(p)
+		if err != nil {
+			klog.Warningf("unable to report expiry of %q certificate: %v", name, err)
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(certificateExpirationDesc, prometheus.GaugeValue, float64(certificate.Certificate.NotAfter.Unix()), name)
+	}
+}
+
+func readCertificateFile(p string) (*pki.Certificate, error) {
+	b, err := os.ReadFile(p)
+	if err != nil {
+		return nil, fmt.Errorf("reading %q: %v", p, err)
+	}
+	certificate, err := pki.ParsePEMCertificate(b)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %q: %v", p, err)
+	}
+	return certificate, nil
+}
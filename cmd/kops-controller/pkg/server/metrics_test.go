@@ -0,0 +1,96 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"crypto/x509/pkix"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/kops/cmd/kops-controller/pkg/config"
+	"k8s.io/kops/pkg/pki"
+)
+
+func writeTestCertificate(t *testing.T, p string, validity time.Duration) time.Time {
+	certificate, _, _, err := pki.IssueCert(&pki.IssueCertRequest{
+		Type:     "ca",
+		Subject:  pkix.Name{CommonName: filepath.Base(p)},
+		Validity: validity,
+	}, nil)
+	if err != nil {
+		t.Fatalf("issuing certificate: %v", err)
+	}
+	if err := certificate.WriteToFile(p, 0o644); err != nil {
+		t.Fatalf("writing certificate: %v", err)
+	}
+	return certificate.Certificate.NotAfter
+}
+
+func gatherCertificateExpiration(t *testing.T, registry *prometheus.Registry) map[string]float64 {
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("gathering metrics: %v", err)
+	}
+	values := make(map[string]float64)
+	for _, family := range families {
+		if family.GetName() != "kops_controller_certificate_expiration_timestamp_seconds" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "name" {
+					values[label.GetValue()] = metric.GetGauge().GetValue()
+				}
+			}
+		}
+	}
+	return values
+}
+
+func TestCertificateCollector(t *testing.T) {
+	dir := t.TempDir()
+	opt := &config.Options{
+		Server: &config.ServerOptions{
+			CABasePath:            dir,
+			SigningCAs:            []string{"kubernetes-ca"},
+			ServerCertificatePath: filepath.Join(dir, "kops-controller.crt"),
+		},
+	}
+
+	caExpiry := writeTestCertificate(t, filepath.Join(dir, "kubernetes-ca.crt"), 365*24*time.Hour)
+	servingExpiry := writeTestCertificate(t, opt.Server.ServerCertificatePath, 30*24*time.Hour)
+
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(&certificateCollector{server: &Server{opt: opt}})
+
+	values := gatherCertificateExpiration(t, registry)
+	if values["kubernetes-ca"] != float64(caExpiry.Unix()) {
+		t.Errorf("unexpected kubernetes-ca expiry, actual=%v, expected=%v", values["kubernetes-ca"], caExpiry.Unix())
+	}
+	if values[servingCertificateName] != float64(servingExpiry.Unix()) {
+		t.Errorf("unexpected serving certificate expiry, actual=%v, expected=%v", values[servingCertificateName], servingExpiry.Unix())
+	}
+
+	// A rotated certificate is reported at the next scrape
+	servingExpiry = writeTestCertificate(t, opt.Server.ServerCertificatePath, 60*24*time.Hour)
+	values = gatherCertificateExpiration(t, registry)
+	if values[servingCertificateName] != float64(servingExpiry.Unix()) {
+		t.Errorf("expected the rotated serving certificate to be reported, actual=%v, expected=%v", values[servingCertificateName], servingExpiry.Unix())
+	}
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+	"k8s.io/klog/v2"
+	"k8s.io/kops/cmd/kops-controller/pkg/config"
+)
+
+// rateLimiter limits the requests of each node or user, so that a compromised instance cannot obtain
+// certificates in a loop. A nil rateLimiter allows every request.
+type rateLimiter struct {
+	limit  rate.Limit
+	burst  int
+	period time.Duration
+
+	mutex     sync.Mutex
+	limiters  map[string]*identityLimiter
+	lastPrune time.Time
+}
+
+type identityLimiter struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// newRateLimiter builds the rate limiter for the given options, applying the defaults if there are none.
+func newRateLimiter(opt *config.RateLimitOptions) *rateLimiter {
+	requests, period := config.DefaultRateLimitRequests, config.DefaultRateLimitPeriod
+	if opt != nil {
+		requests, period = opt.Requests, opt.Period.Duration
+	}
+	if requests <= 0 || period <= 0 {
+		return nil
+	}
+
+	return &rateLimiter{
+		limit:    rate.Limit(float64(requests) / period.Seconds()),
+		burst:    requests,
+		period:   period,
+		limiters: make(map[string]*identityLimiter),
+	}
+}
+
+// allow returns true if the identity may make a request at the given time,
+// otherwise it returns how long the identity should wait before retrying.
+func (l *rateLimiter) allow(identity string, now time.Time) (bool, time.Duration) {
+	if l == nil {
+		return true, 0
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	// An identity that was idle for a whole period is allowed a full burst again, so we can forget it.
+	if now.Sub(l.lastPrune) > l.period {
+		for k, v := range l.limiters {
+			if now.Sub(v.lastSeen) > l.period {
+				delete(l.limiters, k)
+			}
+		}
+		l.lastPrune = now
+	}
+
+	il := l.limiters[identity]
+	if il == nil {
+		il = &identityLimiter{limiter: rate.NewLimiter(l.limit, l.burst)}
+		l.limiters[identity] = il
+	}
+	il.lastSeen = now
+
+	reservation := il.limiter.ReserveN(now, 1)
+	if !reservation.OK() {
+		return false, l.period
+	}
+	if delay := reservation.DelayFrom(now); delay > 0 {
+		reservation.CancelAt(now)
+		return false, delay
+	}
+	return true, 0
+}
+
+// checkRateLimit responds with 429 Too Many Requests and returns false if the identity has made too many requests.
+func (s *Server) checkRateLimit(w http.ResponseWriter, r *http.Request, endpoint string, identity string) bool {
+	allowed, wait := s.rateLimiter.allow(identity, time.Now())
+	if allowed {
+		return true
+	}
+
+	klog.Infof("%s %s rate limited %q for %v", endpoint, r.RemoteAddr, identity, wait)
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+	w.WriteHeader(http.StatusTooManyRequests)
+	_, _ = w.Write([]byte("too many requests"))
+	return false
+}
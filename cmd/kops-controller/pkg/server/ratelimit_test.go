@@ -0,0 +1,72 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kops/cmd/kops-controller/pkg/config"
+)
+
+func TestRateLimiter(t *testing.T) {
+	l := newRateLimiter(&config.RateLimitOptions{
+		Requests: 2,
+		Period:   metav1.Duration{Duration: time.Minute},
+	})
+	now := time.Unix(1600000000, 0)
+
+	for i := 0; i < 2; i++ {
+		if allowed, _ := l.allow("node/a", now); !allowed {
+			t.Fatalf("expected request %d to be allowed", i)
+		}
+	}
+	allowed, wait := l.allow("node/a", now)
+	if allowed {
+		t.Fatalf("expected the third request to be rate limited")
+	}
+	if wait != 30*time.Second {
+		t.Errorf("unexpected wait, actual=%v, expected=%v", wait, 30*time.Second)
+	}
+
+	if allowed, _ := l.allow("node/b", now); !allowed {
+		t.Errorf("expected another identity to be allowed")
+	}
+	if allowed, _ := l.allow("node/a", now.Add(30*time.Second)); !allowed {
+		t.Errorf("expected a request to be allowed once a token is refilled")
+	}
+
+	l.allow("node/b", now.Add(3*time.Minute))
+	if _, found := l.limiters["node/a"]; found {
+		t.Errorf("expected idle identities to be forgotten")
+	}
+}
+
+func TestRateLimiterDisabled(t *testing.T) {
+	if l := newRateLimiter(&config.RateLimitOptions{}); l != nil {
+		t.Fatalf("expected rate limiting to be disabled")
+	}
+	var l *rateLimiter
+	if allowed, _ := l.allow("node/a", time.Now()); !allowed {
+		t.Errorf("expected a disabled rate limiter to allow requests")
+	}
+
+	if l := newRateLimiter(nil); l == nil || l.burst != config.DefaultRateLimitRequests {
+		t.Errorf("expected the default rate limit, got %v", l)
+	}
+}
@@ -39,6 +39,7 @@ import (
 	"k8s.io/kops/pkg/rbac"
 	"k8s.io/kops/upup/pkg/fi"
 	"k8s.io/kops/util/pkg/vfs"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
 )
 
 type Server struct {
@@ -49,11 +50,17 @@ type Server struct {
 	verifier   bootstrap.Verifier
 	keystore   pki.Keystore
 
+	// adminVerifiers authenticate users requesting admin credentials.
+	adminVerifiers []bootstrap.Verifier
+
+	// rateLimiter limits the requests of each node or user.
+	rateLimiter *rateLimiter
+
 	// configBase is the base of the configuration storage.
 	configBase vfs.Path
 }
 
-func NewServer(opt *config.Options, verifier bootstrap.Verifier) (*Server, error) {
+func NewServer(opt *config.Options, verifier bootstrap.Verifier, adminVerifiers []bootstrap.Verifier) (*Server, error) {
 	server := &http.Server{
 		Addr: opt.Server.Listen,
 		TLSConfig: &tls.Config{
@@ -67,6 +74,9 @@ func NewServer(opt *config.Options, verifier bootstrap.Verifier) (*Server, error
 		certNames: sets.NewString(opt.Server.CertNames...),
 		server:    server,
 		verifier:  verifier,
+
+		adminVerifiers: adminVerifiers,
+		rateLimiter:    newRateLimiter(opt.Server.RateLimit),
 	}
 
 	configBase, err := vfs.Context.BuildVfsPath(opt.ConfigBase)
@@ -77,8 +87,15 @@ func NewServer(opt *config.Options, verifier bootstrap.Verifier) (*Server, error
 
 	r := http.NewServeMux()
 	r.Handle("/bootstrap", http.HandlerFunc(s.bootstrap))
+	if opt.Server.AdminCredentials != nil && len(adminVerifiers) != 0 {
+		r.Handle("/admin-credentials", http.HandlerFunc(s.adminCredentials))
+	}
 	server.Handler = recovery(r)
 
+	if err := metrics.Registry.Register(&certificateCollector{server: s}); err != nil {
+		return nil, fmt.Errorf("registering certificate metrics: %v", err)
+	}
+
 	return s, nil
 }
 
@@ -109,6 +126,10 @@ func (s *Server) Start(ctx context.Context) error {
 }
 
 func (s *Server) bootstrap(w http.ResponseWriter, r *http.Request) {
+	audit := newAuditRecord("bootstrap", r)
+	defer audit.finish()
+	w = audit.wrap(w)
+
 	if r.Body == nil {
 		klog.Infof("bootstrap %s no body", r.RemoteAddr)
 		w.WriteHeader(http.StatusBadRequest)
@@ -132,6 +153,7 @@ func (s *Server) bootstrap(w http.ResponseWriter, r *http.Request) {
 		_, _ = w.Write([]byte(fmt.Sprintf("failed to verify token: %v", err)))
 		return
 	}
+	audit.identify(id)
 
 	req := &nodeup.BootstrapRequest{}
 	if err := json.Unmarshal(body, req); err != nil {
@@ -148,6 +170,12 @@ func (s *Server) bootstrap(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Only requests for certificates count against the rate limit, as nodes without access to the
+	// state store periodically request their configuration to reconcile it.
+	if len(req.Certs) > 0 && !s.checkRateLimit(w, r, "bootstrap", "node/"+id.NodeName) {
+		return
+	}
+
 	resp := &nodeup.BootstrapResponse{
 		Certs: map[string]string{},
 	}
@@ -180,6 +208,7 @@ func (s *Server) bootstrap(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 		resp.Certs[name] = cert
+		audit.certs = append(audit.certs, name)
 	}
 
 	w.Header().Set("Content-Type", "application/json")
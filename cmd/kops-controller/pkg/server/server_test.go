@@ -0,0 +1,96 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kops/cmd/kops-controller/pkg/config"
+	"k8s.io/kops/pkg/apis/nodeup"
+	"k8s.io/kops/pkg/bootstrap"
+)
+
+type fakeVerifier struct{}
+
+func (fakeVerifier) VerifyToken(ctx context.Context, token string, body []byte, useInstanceIDForNodeName bool) (*bootstrap.VerifyResult, error) {
+	return &bootstrap.VerifyResult{NodeName: "node-a"}, nil
+}
+
+func TestBootstrapRateLimit(t *testing.T) {
+	s := &Server{
+		opt:      &config.Options{Server: &config.ServerOptions{}},
+		verifier: fakeVerifier{},
+		rateLimiter: newRateLimiter(&config.RateLimitOptions{
+			Requests: 1,
+			Period:   metav1.Duration{Duration: time.Hour},
+		}),
+	}
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("error generating key: %v", err)
+	}
+	pubKey, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatalf("error marshaling public key: %v", err)
+	}
+	certs := map[string]string{
+		"kubelet": string(pem.EncodeToMemory(&pem.Block{Type: "RSA PUBLIC KEY", Bytes: pubKey})),
+	}
+
+	bootstrapStatus := func(certs map[string]string) int {
+		body, err := json.Marshal(&nodeup.BootstrapRequest{
+			APIVersion: nodeup.BootstrapAPIVersion,
+			Certs:      certs,
+		})
+		if err != nil {
+			t.Fatalf("error encoding request: %v", err)
+		}
+		w := httptest.NewRecorder()
+		s.bootstrap(w, httptest.NewRequest(http.MethodPost, "/bootstrap", bytes.NewReader(body)))
+		return w.Code
+	}
+
+	// Requests that issue no certificates, such as reconciling the node configuration, are not counted
+	for i := 0; i < 3; i++ {
+		if status := bootstrapStatus(nil); status != http.StatusOK {
+			t.Fatalf("unexpected status for request %d without certificates: %d", i, status)
+		}
+	}
+
+	// The certificate name is not enabled, so the request fails after being counted
+	if status := bootstrapStatus(certs); status == http.StatusTooManyRequests {
+		t.Fatalf("expected the first request for certificates to be allowed")
+	}
+	if status := bootstrapStatus(certs); status != http.StatusTooManyRequests {
+		t.Errorf("expected the second request for certificates to be rate limited, got status %d", status)
+	}
+	if status := bootstrapStatus(nil); status != http.StatusOK {
+		t.Errorf("expected a request without certificates to be allowed once rate limited, got status %d", status)
+	}
+}
@@ -209,7 +209,7 @@ func NewCmdCreateCluster(f *util.Factory, out io.Writer) *cobra.Command {
 	}
 
 	cmd.Flags().BoolVarP(&options.Yes, "yes", "y", options.Yes, "Specify --yes to immediately create the cluster")
-	cmd.Flags().StringVar(&options.Target, "target", options.Target, fmt.Sprintf("Valid targets: %s, %s, %s. Set this flag to %s if you want kOps to generate terraform", cloudup.TargetDirect, cloudup.TargetTerraform, cloudup.TargetCloudformation, cloudup.TargetTerraform))
+	cmd.Flags().StringVar(&options.Target, "target", options.Target, fmt.Sprintf("Valid targets: %s, %s, %s, %s. Set this flag to %s if you want kOps to generate terraform", cloudup.TargetDirect, cloudup.TargetTerraform, cloudup.TargetTerraformJSON, cloudup.TargetCloudformation, cloudup.TargetTerraform))
 	cmd.RegisterFlagCompletionFunc("target", completeCreateClusterTarget(options))
 
 	// Configuration / state location
@@ -479,7 +479,7 @@ func RunCreateCluster(ctx context.Context, f *util.Factory, out io.Writer, c *Cr
 	// TODO: Reuse rootCommand stateStore logic?
 
 	if c.OutDir == "" {
-		if c.Target == cloudup.TargetTerraform {
+		if c.Target == cloudup.TargetTerraform || c.Target == cloudup.TargetTerraformJSON {
 			c.OutDir = "out/terraform"
 		} else if c.Target == cloudup.TargetCloudformation {
 			c.OutDir = "out/cloudformation"
@@ -1002,7 +1002,7 @@ func completeCreateClusterTarget(options *CreateClusterOptions) func(cmd *cobra.
 		}
 		for _, cp := range cloudup.TerraformCloudProviders {
 			if options.CloudProvider == string(cp) {
-				completions = append(completions, cloudup.TargetTerraform)
+				completions = append(completions, cloudup.TargetTerraform, cloudup.TargetTerraformJSON)
 			}
 		}
 		if options.CloudProvider == string(api.CloudProviderAWS) {
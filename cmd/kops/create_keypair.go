@@ -21,6 +21,7 @@ import (
 	"crypto/x509/pkix"
 	"fmt"
 	"io"
+	"math/big"
 	"os"
 	"strings"
 	"time"
@@ -81,6 +82,9 @@ type CreateKeypairOptions struct {
 	PrivateKeyPath string
 	CertPath       string
 	Primary        bool
+
+	// serial is the serial number, and so the ID, of the generated keypair; a new one is built if it is not set.
+	serial *big.Int
 }
 
 func rotatableKeysetFilter(name string, _ *fi.Keyset) bool {
@@ -164,7 +168,8 @@ func RunCreateKeypair(ctx context.Context, f *util.Factory, out io.Writer, optio
 	}
 
 	if options.Keyset != "all" {
-		return createKeypair(out, options, options.Keyset, keyStore)
+		_, err := createKeypair(out, options, options.Keyset, keyStore)
+		return err
 	}
 
 	keysets, err := keyStore.ListKeysets()
@@ -174,7 +179,7 @@ func RunCreateKeypair(ctx context.Context, f *util.Factory, out io.Writer, optio
 
 	for name := range keysets {
 		if rotatableKeysetFilter(name, nil) {
-			if err := createKeypair(out, options, name, keyStore); err != nil {
+			if _, err := createKeypair(out, options, name, keyStore); err != nil {
 				return fmt.Errorf("creating keypair for %s: %v", name, err)
 			}
 		}
@@ -183,19 +188,20 @@ func RunCreateKeypair(ctx context.Context, f *util.Factory, out io.Writer, optio
 	return nil
 }
 
-func createKeypair(out io.Writer, options *CreateKeypairOptions, name string, keyStore fi.CAStore) error {
+// createKeypair adds a keypair to the named keyset, returning the added item
+func createKeypair(out io.Writer, options *CreateKeypairOptions, name string, keyStore fi.CAStore) (*fi.KeysetItem, error) {
 	var err error
 	var privateKey *pki.PrivateKey
 	if options.PrivateKeyPath != "" {
 		options.PrivateKeyPath = utils.ExpandPath(options.PrivateKeyPath)
 		privateKeyBytes, err := os.ReadFile(options.PrivateKeyPath)
 		if err != nil {
-			return fmt.Errorf("error reading user provided private key %q: %v", options.PrivateKeyPath, err)
+			return nil, fmt.Errorf("error reading user provided private key %q: %v", options.PrivateKeyPath, err)
 		}
 
 		privateKey, err = pki.ParsePEMPrivateKey(privateKeyBytes)
 		if err != nil {
-			return fmt.Errorf("error loading private key %q: %v", privateKeyBytes, err)
+			return nil, fmt.Errorf("error loading private key %q: %v", privateKeyBytes, err)
 		}
 	}
 
@@ -204,11 +210,14 @@ func createKeypair(out io.Writer, options *CreateKeypairOptions, name string, ke
 		if privateKey == nil {
 			privateKey, err = pki.GeneratePrivateKey()
 			if err != nil {
-				return fmt.Errorf("error generating private key: %v", err)
+				return nil, fmt.Errorf("error generating private key: %v", err)
 			}
 		}
 
-		serial := pki.BuildPKISerial(time.Now().UnixNano())
+		serial := options.serial
+		if serial == nil {
+			serial = pki.BuildPKISerial(time.Now().UnixNano())
+		}
 		req := pki.IssueCertRequest{
 			Type:       "ca",
 			Subject:    pkix.Name{CommonName: name, SerialNumber: serial.String()},
@@ -217,18 +226,18 @@ func createKeypair(out io.Writer, options *CreateKeypairOptions, name string, ke
 		}
 		cert, _, _, err = pki.IssueCert(&req, nil)
 		if err != nil {
-			return fmt.Errorf("error issuing certificate: %v", err)
+			return nil, fmt.Errorf("error issuing certificate: %v", err)
 		}
 	} else {
 		options.CertPath = utils.ExpandPath(options.CertPath)
 		certBytes, err := os.ReadFile(options.CertPath)
 		if err != nil {
-			return fmt.Errorf("error reading user provided cert %q: %v", options.CertPath, err)
+			return nil, fmt.Errorf("error reading user provided cert %q: %v", options.CertPath, err)
 		}
 
 		cert, err = pki.ParsePEMCertificate(certBytes)
 		if err != nil {
-			return fmt.Errorf("error loading certificate %q: %v", options.CertPath, err)
+			return nil, fmt.Errorf("error loading certificate %q: %v", options.CertPath, err)
 		}
 	}
 
@@ -237,24 +246,24 @@ func createKeypair(out io.Writer, options *CreateKeypairOptions, name string, ke
 	if os.IsNotExist(err) || (err == nil && keyset == nil) {
 		if options.Primary {
 			if keyset, err = fi.NewKeyset(cert, privateKey); err != nil {
-				return err
+				return nil, err
 			}
 		} else {
-			return fmt.Errorf("the first keypair added to a keyset must be primary")
+			return nil, fmt.Errorf("the first keypair added to a keyset must be primary")
 		}
 		item = keyset.Primary
 	} else if err != nil {
-		return fmt.Errorf("reading existing keyset: %v", err)
+		return nil, fmt.Errorf("reading existing keyset: %v", err)
 	} else {
 		item, err = keyset.AddItem(cert, privateKey, options.Primary)
 	}
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	err = keyStore.StoreKeyset(name, keyset)
 	if err != nil {
-		return fmt.Errorf("error storing user provided keys %q %q: %v", options.CertPath, options.PrivateKeyPath, err)
+		return nil, fmt.Errorf("error storing user provided keys %q %q: %v", options.CertPath, options.PrivateKeyPath, err)
 	}
 
 	if options.CertPath != "" {
@@ -264,7 +273,7 @@ func createKeypair(out io.Writer, options *CreateKeypairOptions, name string, ke
 		fmt.Fprintf(out, "using user provided private key: %v\n", options.PrivateKeyPath)
 	}
 	fmt.Fprintf(out, "Created %s %s\n", name, item.Id)
-	return nil
+	return item, nil
 }
 
 func completeKeyset(cluster *kopsapi.Cluster, clientSet simple.Clientset, args []string, filter func(name string, keyset *fi.Keyset) bool) (keyset *fi.Keyset, keyStore fi.CAStore, completions []string, directive cobra.ShellCompDirective) {
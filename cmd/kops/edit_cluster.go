@@ -27,6 +27,7 @@ import (
 	"strings"
 
 	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/kops/cmd/kops/util"
 	api "k8s.io/kops/pkg/apis/kops"
 	"k8s.io/kops/pkg/apis/kops/validation"
@@ -236,6 +237,26 @@ func RunEditCluster(ctx context.Context, f *util.Factory, out io.Writer, options
 		}
 
 		failure, err := updateCluster(ctx, clientset, oldCluster, newCluster, instanceGroups)
+		if apierrors.IsConflict(err) {
+			// Someone else changed the cluster while we were editing it; reopen the latest version
+			latest, err := GetCluster(ctx, f, options.ClusterName)
+			if err != nil {
+				return preservedFile(err, file, out)
+			}
+			if err := latest.FillDefaults(); err != nil {
+				return preservedFile(err, file, out)
+			}
+			raw, err = kopscodecs.ToVersionedYaml(latest)
+			if err != nil {
+				return preservedFile(err, file, out)
+			}
+			oldCluster = latest
+
+			results = editResults{}
+			results.header.addConflict(file)
+			containsError = false
+			continue
+		}
 		if err != nil {
 			return preservedFile(err, file, out)
 		}
@@ -299,6 +320,13 @@ func (h *editHeader) addError(err string) {
 	h.errors = append(h.errors, err)
 }
 
+// addConflict reports that the object was modified while it was being edited.
+// The edits are kept in the given file, so they can be reapplied to the latest version.
+func (h *editHeader) addConflict(file string) {
+	h.addError("The object has been modified since you started editing it, so your changes were not saved.")
+	h.addError(fmt.Sprintf("The latest version is shown below; your changes have been kept in %q so you can reapply them.", file))
+}
+
 func (h *editHeader) addExtraFields(line string) {
 	h.extraFields = append(h.extraFields, line)
 }
@@ -26,6 +26,7 @@ import (
 	"strings"
 
 	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/klog/v2"
 	"k8s.io/kops/cmd/kops/util"
@@ -264,6 +265,23 @@ func RunEditInstanceGroup(ctx context.Context, f *util.Factory, out io.Writer, o
 		}
 
 		failure, err := updateInstanceGroup(ctx, clientset, channel, cluster, oldGroup, newGroup)
+		if apierrors.IsConflict(err) {
+			// Someone else changed the InstanceGroup while we were editing it; reopen the latest version
+			latest, err := clientset.InstanceGroupsFor(cluster).Get(ctx, groupName, metav1.GetOptions{})
+			if err != nil {
+				return preservedFile(fmt.Errorf("error reading InstanceGroup %q: %v", groupName, err), file, out)
+			}
+			raw, err = kopscodecs.ToVersionedYaml(latest)
+			if err != nil {
+				return preservedFile(err, file, out)
+			}
+			oldGroup = latest
+
+			results = editResults{}
+			results.header.addConflict(file)
+			containsError = false
+			continue
+		}
 		if err != nil {
 			return preservedFile(err, file, out)
 		}
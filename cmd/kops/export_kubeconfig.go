@@ -20,6 +20,11 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -27,9 +32,18 @@ import (
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/kops/cmd/kops/util"
 	kopsapi "k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/apis/nodeup"
+	"k8s.io/kops/pkg/bootstrap"
+	"k8s.io/kops/pkg/bootstrap/oidc"
+	"k8s.io/kops/pkg/client/simple"
 	"k8s.io/kops/pkg/commands/commandutils"
 	"k8s.io/kops/pkg/kubeconfig"
+	"k8s.io/kops/pkg/wellknownports"
+	"k8s.io/kops/upup/pkg/fi"
 	"k8s.io/kops/upup/pkg/fi/cloudup"
+	"k8s.io/kops/upup/pkg/fi/cloudup/awsup"
+	"k8s.io/kops/upup/pkg/fi/utils"
+	"k8s.io/kops/util/pkg/vfs"
 	"k8s.io/kubectl/pkg/util/i18n"
 	"k8s.io/kubectl/pkg/util/templates"
 )
@@ -49,6 +63,10 @@ var (
 
 	# export using the internal DNS name, bypassing the cloud load balancer
 	kops export kubeconfig k8s-cluster.example.com --internal
+
+	# export a short-lived cluster admin user credential issued by kops-controller,
+	# authenticating with an OIDC ID token
+	kops export kubeconfig k8s-cluster.example.com --admin=1h --admin-issuer=kops-controller --oidc-token-file=id-token
 	`))
 
 	exportKubeconfigShort = i18n.T(`Export kubeconfig.`)
@@ -64,10 +82,24 @@ type ExportKubeconfigOptions struct {
 
 	// UseKopsAuthenticationPlugin controls whether we should use the kOps auth helper instead of a static credential
 	UseKopsAuthenticationPlugin bool
+
+	// adminIssuer is what issues the admin credential: the CA private key in the state store, or kops-controller
+	adminIssuer string
+	// oidcTokenFile is a file holding an OIDC ID token to authenticate to kops-controller with
+	oidcTokenFile string
+	// kopsControllerURL overrides the URL of kops-controller
+	kopsControllerURL string
 }
 
+const (
+	adminIssuerStateStore     = "state-store"
+	adminIssuerKopsController = "kops-controller"
+)
+
 func NewCmdExportKubeconfig(f *util.Factory, out io.Writer) *cobra.Command {
-	options := &ExportKubeconfigOptions{}
+	options := &ExportKubeconfigOptions{
+		adminIssuer: adminIssuerStateStore,
+	}
 
 	cmd := &cobra.Command{
 		Use:     "kubeconfig [CLUSTER | --all]",
@@ -79,6 +111,18 @@ func NewCmdExportKubeconfig(f *util.Factory, out io.Writer) *cobra.Command {
 			if options.admin != 0 && options.user != "" {
 				return fmt.Errorf("cannot use both --admin and --user")
 			}
+			switch options.adminIssuer {
+			case adminIssuerStateStore:
+				if options.oidcTokenFile != "" || options.kopsControllerURL != "" {
+					return fmt.Errorf("--oidc-token-file and --kops-controller-url require --admin-issuer=%s", adminIssuerKopsController)
+				}
+			case adminIssuerKopsController:
+				if options.admin == 0 {
+					return fmt.Errorf("--admin-issuer=%s requires --admin", adminIssuerKopsController)
+				}
+			default:
+				return fmt.Errorf("unknown --admin-issuer %q, expected %q or %q", options.adminIssuer, adminIssuerStateStore, adminIssuerKopsController)
+			}
 			if options.all {
 				if len(args) != 0 {
 					return fmt.Errorf("cannot use both --all flag and positional arguments")
@@ -102,6 +146,12 @@ func NewCmdExportKubeconfig(f *util.Factory, out io.Writer) *cobra.Command {
 	cmd.RegisterFlagCompletionFunc("user", completeKubecfgUser)
 	cmd.Flags().BoolVar(&options.internal, "internal", options.internal, "Use the cluster's internal DNS name")
 	cmd.Flags().BoolVar(&options.UseKopsAuthenticationPlugin, "auth-plugin", options.UseKopsAuthenticationPlugin, "Use the kOps authentication plugin")
+	cmd.Flags().StringVar(&options.adminIssuer, "admin-issuer", options.adminIssuer, "What issues the admin credential: \"state-store\" signs it with the CA private key from the state store, \"kops-controller\" requests it from kops-controller")
+	cmd.RegisterFlagCompletionFunc("admin-issuer", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{adminIssuerStateStore, adminIssuerKopsController}, cobra.ShellCompDirectiveNoFileComp
+	})
+	cmd.Flags().StringVar(&options.oidcTokenFile, "oidc-token-file", options.oidcTokenFile, "File containing an OIDC ID token to authenticate to kops-controller with, instead of the cloud identity")
+	cmd.Flags().StringVar(&options.kopsControllerURL, "kops-controller-url", options.kopsControllerURL, "URL of kops-controller, if it is not reachable at its internal DNS name")
 
 	return cmd
 }
@@ -130,9 +180,18 @@ func RunExportKubeconfig(ctx context.Context, f *util.Factory, out io.Writer, op
 	}
 
 	for _, cluster := range clusterList {
-		keyStore, err := clientset.KeyStore(cluster)
-		if err != nil {
-			return err
+		var signer kubeconfig.Signer
+		if options.adminIssuer == adminIssuerKopsController {
+			signer, err = buildKopsControllerSigner(ctx, clientset, cluster, options)
+			if err != nil {
+				return err
+			}
+		} else {
+			keyStore, err := clientset.KeyStore(cluster)
+			if err != nil {
+				return err
+			}
+			signer = kubeconfig.NewKeystoreSigner(keyStore)
 		}
 
 		secretStore, err := clientset.SecretStore(cluster)
@@ -146,7 +205,7 @@ func RunExportKubeconfig(ctx context.Context, f *util.Factory, out io.Writer, op
 		}
 		conf, err := kubeconfig.BuildKubecfg(
 			cluster,
-			keyStore,
+			signer,
 			secretStore,
 			cloud,
 			options.admin,
@@ -166,6 +225,97 @@ func RunExportKubeconfig(ctx context.Context, f *util.Factory, out io.Writer, op
 	return nil
 }
 
+// buildKopsControllerSigner builds a signer that requests the admin credential from kops-controller.
+// The CA certificates are read from the configuration of a control plane instance group,
+// so the CA private key is not read from the state store.
+func buildKopsControllerSigner(ctx context.Context, clientset simple.Clientset, cluster *kopsapi.Cluster, options *ExportKubeconfigOptions) (*kubeconfig.KopsControllerSigner, error) {
+	if cluster.Spec.KopsController == nil || cluster.Spec.KopsController.AdminCredentials == nil {
+		return nil, fmt.Errorf("kops-controller of cluster %q does not issue admin credentials; set spec.kopsController.adminCredentials", cluster.ObjectMeta.Name)
+	}
+
+	cas, err := readClusterCACertificates(ctx, clientset, cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	var authenticator bootstrap.Authenticator
+	if options.oidcTokenFile != "" {
+		token, err := os.ReadFile(options.oidcTokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading OIDC ID token: %v", err)
+		}
+		authenticator = oidc.NewTokenAuthenticator(strings.TrimSpace(string(token)))
+	} else {
+		switch cluster.Spec.GetCloudProvider() {
+		case kopsapi.CloudProviderAWS:
+			region, err := awsup.FindRegion(cluster)
+			if err != nil {
+				return nil, err
+			}
+			authenticator, err = awsup.NewAWSAuthenticator(region)
+			if err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("authenticating to kops-controller with the cloud identity is not supported on %s; use --oidc-token-file", cluster.Spec.GetCloudProvider())
+		}
+	}
+
+	serverName := "kops-controller.internal." + cluster.ObjectMeta.Name
+	baseURL := url.URL{
+		Scheme: "https",
+		Host:   net.JoinHostPort(serverName, strconv.Itoa(wellknownports.KopsControllerPort)),
+		Path:   "/",
+	}
+	if options.kopsControllerURL != "" {
+		u, err := url.Parse(options.kopsControllerURL)
+		if err != nil {
+			return nil, fmt.Errorf("parsing --kops-controller-url: %v", err)
+		}
+		baseURL = *u
+	}
+
+	return &kubeconfig.KopsControllerSigner{
+		Authenticator: authenticator,
+		CAs:           cas,
+		BaseURL:       baseURL,
+		ServerName:    serverName,
+	}, nil
+}
+
+// readClusterCACertificates reads the certificates of the cluster CA from the configuration of a control plane instance group.
+func readClusterCACertificates(ctx context.Context, clientset simple.Clientset, cluster *kopsapi.Cluster) ([]byte, error) {
+	configBase, err := vfs.Context.BuildVfsPath(cluster.Spec.ConfigBase)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing config base %q: %v", cluster.Spec.ConfigBase, err)
+	}
+
+	igList, err := clientset.InstanceGroupsFor(cluster).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for i := range igList.Items {
+		ig := &igList.Items[i]
+		if !ig.IsMaster() {
+			continue
+		}
+
+		p := configBase.Join("igconfig", strings.ToLower(string(ig.Spec.Role)), ig.Name, "nodeupconfig.yaml")
+		b, err := p.ReadFile()
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s: %v", p, err)
+		}
+		nodeupConfig := &nodeup.Config{}
+		if err := utils.YamlUnmarshal(b, nodeupConfig); err != nil {
+			return nil, fmt.Errorf("error parsing %s: %v", p, err)
+		}
+		if cas := nodeupConfig.CAs[fi.CertificateIDCA]; cas != "" {
+			return []byte(cas), nil
+		}
+	}
+	return nil, fmt.Errorf("cannot find CA certificate in the configuration of the control plane instance groups; run kops update cluster")
+}
+
 func buildPathOptions(options *ExportKubeconfigOptions) *clientcmd.PathOptions {
 	pathOptions := clientcmd.NewDefaultPathOptions()
 
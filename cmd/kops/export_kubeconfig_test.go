@@ -0,0 +1,72 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/client/simple/vfsclientset"
+	"k8s.io/kops/pkg/testutils"
+	"k8s.io/kops/util/pkg/vfs"
+)
+
+func TestReadClusterCACertificates(t *testing.T) {
+	ctx := context.Background()
+	vfs.Context.ResetMemfsContext(true)
+	basePath, err := vfs.Context.BuildVfsPath("memfs://unittest-bucket")
+	if err != nil {
+		t.Fatalf("error building path: %v", err)
+	}
+	clientset := vfsclientset.NewVFSClientset(basePath)
+	cluster := testutils.BuildMinimalCluster("test.k8s.local")
+	cluster.Spec.ConfigBase = "memfs://unittest-bucket/test.k8s.local"
+	if _, err := clientset.CreateCluster(ctx, cluster); err != nil {
+		t.Fatalf("error creating cluster: %v", err)
+	}
+	for _, ig := range []kops.InstanceGroup{
+		testutils.BuildMinimalNodeInstanceGroup("nodes", "subnet-us-test-1a"),
+		testutils.BuildMinimalMasterInstanceGroup("subnet-us-test-1a"),
+	} {
+		ig.Spec.Image = "ubuntu/images/hvm-ssd/ubuntu-focal-20.04-amd64-server-20220404"
+		if _, err := clientset.InstanceGroupsFor(cluster).Create(ctx, &ig, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("error creating instance group: %v", err)
+		}
+	}
+
+	if _, err := readClusterCACertificates(ctx, clientset, cluster); err == nil {
+		t.Fatalf("expected error reading CA certificates before the cluster is updated")
+	}
+
+	nodeupConfig := "CAs:\n  kubernetes-ca: |\n    -----BEGIN CERTIFICATE-----\n    MIIB\n    -----END CERTIFICATE-----\n"
+	p := basePath.Join("test.k8s.local", "igconfig", "master", "master-subnet-us-test-1a", "nodeupconfig.yaml")
+	if err := p.WriteFile(bytes.NewReader([]byte(nodeupConfig)), nil); err != nil {
+		t.Fatalf("error writing nodeup config: %v", err)
+	}
+
+	cas, err := readClusterCACertificates(ctx, clientset, cluster)
+	if err != nil {
+		t.Fatalf("error reading CA certificates: %v", err)
+	}
+	expected := "-----BEGIN CERTIFICATE-----\nMIIB\n-----END CERTIFICATE-----\n"
+	if string(cas) != expected {
+		t.Errorf("expected %q, got %q", expected, string(cas))
+	}
+}
@@ -21,18 +21,26 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"net/url"
 
+	"k8s.io/kops/pkg/apis/kops"
+	kopsutil "k8s.io/kops/pkg/apis/kops/util"
+	"k8s.io/kops/pkg/apis/nodeup"
 	"k8s.io/kops/pkg/assets"
 	"k8s.io/kops/pkg/commands/commandutils"
+	"k8s.io/kops/pkg/ospackages"
 	"k8s.io/kops/pkg/pretty"
 	"k8s.io/kubectl/pkg/util/i18n"
 	"k8s.io/kubectl/pkg/util/templates"
 	"sigs.k8s.io/yaml"
 
+	"k8s.io/kops/util/pkg/architectures"
+	"k8s.io/kops/util/pkg/distributions"
 	"k8s.io/kops/util/pkg/tables"
 
 	"github.com/spf13/cobra"
 	"k8s.io/kops/cmd/kops/util"
+	"k8s.io/kops/upup/pkg/fi"
 	"k8s.io/kops/upup/pkg/fi/cloudup"
 )
 
@@ -42,7 +50,11 @@ var (
 	(original) and download (local repository) locations.
 
 	When invoked with the ` + pretty.Bash("--copy") + ` flag, will copy each asset from the
-	canonical to the download location.`))
+	canonical to the download location.
+
+	When invoked with the ` + pretty.Bash("--bundle-os-packages") + ` flag, will also resolve the OS packages
+	that nodeup installs, along with their dependencies, for each distribution given with
+	` + pretty.Bash("--os-distribution") + `, and bundle them in the file repository.`))
 
 	getAssetsExample = templates.Examples(i18n.T(`
 	# Display all assets.
@@ -50,6 +62,9 @@ var (
 
 	# Copy assets to the local repositories configured in the cluster spec.
 	kops get assets --copy 
+
+	# Also bundle the OS packages for Ubuntu 22.04 and Rocky Linux 9 in the file repository.
+	kops get assets --copy --bundle-os-packages --os-distribution jammy --os-distribution rocky9
 	`))
 
 	getAssetsShort = i18n.T(`Display assets for cluster.`)
@@ -58,6 +73,10 @@ var (
 type GetAssetsOptions struct {
 	*GetOptions
 	Copy bool
+	// BundleOSPackages resolves the OS packages installed by nodeup into bundles in the file repository
+	BundleOSPackages bool
+	// OSDistributions are the ids of the distributions to bundle OS packages for
+	OSDistributions []string
 }
 
 type Image struct {
@@ -96,11 +115,29 @@ func NewCmdGetAssets(f *util.Factory, out io.Writer, getOptions *GetOptions) *co
 	}
 
 	cmd.Flags().BoolVar(&options.Copy, "copy", options.Copy, "copy assets to local repository")
+	cmd.Flags().BoolVar(&options.BundleOSPackages, "bundle-os-packages", options.BundleOSPackages, "bundle the OS packages installed by nodeup, and their dependencies, in the file repository")
+	cmd.Flags().StringSliceVar(&options.OSDistributions, "os-distribution", options.OSDistributions, "distribution to bundle OS packages for, e.g. jammy or rocky9")
 
 	return cmd
 }
 
 func RunGetAssets(ctx context.Context, f *util.Factory, out io.Writer, options *GetAssetsOptions) error {
+	var distros []distributions.Distribution
+	if options.BundleOSPackages {
+		if len(options.OSDistributions) == 0 {
+			return fmt.Errorf("--os-distribution is required with --bundle-os-packages")
+		}
+		for _, id := range options.OSDistributions {
+			d, err := distributions.FindDistributionByID(id)
+			if err != nil {
+				return err
+			}
+			distros = append(distros, d)
+		}
+	} else if len(options.OSDistributions) != 0 {
+		return fmt.Errorf("--os-distribution can only be used with --bundle-os-packages")
+	}
+
 	updateClusterResults, err := RunUpdateCluster(ctx, f, out, &UpdateClusterOptions{
 		Target:      cloudup.TargetDryRun,
 		GetAssets:   true,
@@ -140,11 +177,45 @@ func RunGetAssets(ctx context.Context, f *util.Factory, out io.Writer, options *
 		}
 	}
 
+	fileAssets := updateClusterResults.FileAssets
+	var bundles map[string]*ospackages.Bundle
+	if options.BundleOSPackages {
+		var bundleAssets []*assets.FileAsset
+		bundles, bundleAssets, err = bundleOSPackages(updateClusterResults.Cluster, updateClusterResults.InstanceGroups, distros)
+		if err != nil {
+			return err
+		}
+		for _, fileAsset := range bundleAssets {
+			result.Files = append(result.Files, &File{
+				Canonical: fileAsset.CanonicalURL.String(),
+				Download:  fileAsset.DownloadURL.String(),
+				SHA:       fileAsset.SHAValue,
+			})
+		}
+		fileAssets = append(fileAssets, bundleAssets...)
+	}
+
 	if options.Copy {
-		err := assets.Copy(updateClusterResults.ImageAssets, updateClusterResults.FileAssets, updateClusterResults.Cluster)
+		err := assets.Copy(updateClusterResults.ImageAssets, fileAssets, updateClusterResults.Cluster)
 		if err != nil {
 			return err
 		}
+
+		// The indexes are written last, so that nodes only find bundles whose files are all present
+		hashes := make(map[string]string)
+		for bundleURL, bundle := range bundles {
+			hash, err := assets.WriteOSPackageBundle(updateClusterResults.Cluster, bundle, bundleURL)
+			if err != nil {
+				return err
+			}
+			hashes[bundle.Key()] = hash
+		}
+		if len(bundles) != 0 {
+			base := ospackages.BaseURL(fi.StringValue(updateClusterResults.Cluster.Spec.Assets.FileRepository))
+			if err := assets.WriteOSPackageManifest(updateClusterResults.Cluster, base, hashes); err != nil {
+				return err
+			}
+		}
 	}
 
 	switch options.Output {
@@ -176,6 +247,114 @@ func RunGetAssets(ctx context.Context, f *util.Factory, out io.Writer, options *
 	return nil
 }
 
+// bundleOSPackages resolves the OS packages that nodeup may install for the given distributions, for every
+// supported architecture, returning the bundles by their location and the package files to copy into them
+func bundleOSPackages(cluster *kops.Cluster, instanceGroups []*kops.InstanceGroup, distros []distributions.Distribution) (map[string]*ospackages.Bundle, []*assets.FileAsset, error) {
+	fileRepository := fi.StringValue(cluster.Spec.Assets.FileRepository)
+	if fileRepository == "" {
+		return nil, nil, fmt.Errorf("spec.assets.fileRepository must be set to bundle OS packages")
+	}
+	base := ospackages.BaseURL(fileRepository)
+
+	resolver := ospackages.NewResolver()
+	bundles := make(map[string]*ospackages.Bundle)
+	var fileAssets []*assets.FileAsset
+	for _, d := range distros {
+		var names []string
+		var aptSources []string
+		for _, ig := range instanceGroups {
+			node, err := osPackageNode(cluster, ig, d)
+			if err != nil {
+				return nil, nil, err
+			}
+			names = append(names, node.All()...)
+			if node.NvidiaDriverPackage != "" && d.IsUbuntu() {
+				aptSources = ospackages.NvidiaSources
+			}
+			names = append(names, ig.Spec.Packages...)
+		}
+
+		for _, arch := range architectures.GetSupported() {
+			bundle, sources, err := resolver.Resolve(d, arch, names, aptSources)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			bundleURL := ospackages.BundleURL(base, d, arch)
+			bundles[bundleURL] = bundle
+			addFile := func(file string, sha256 string) error {
+				canonicalURL, err := url.Parse(sources[file])
+				if err != nil {
+					return fmt.Errorf("error parsing URL %q: %v", sources[file], err)
+				}
+				downloadURL, err := url.Parse(bundleURL + "/" + file)
+				if err != nil {
+					return fmt.Errorf("error parsing URL %q: %v", bundleURL+"/"+file, err)
+				}
+				fileAssets = append(fileAssets, &assets.FileAsset{
+					CanonicalURL: canonicalURL,
+					DownloadURL:  downloadURL,
+					SHAValue:     sha256,
+				})
+				return nil
+			}
+			for _, p := range bundle.Packages {
+				if err := addFile(p.File, p.SHA256); err != nil {
+					return nil, nil, err
+				}
+			}
+			// Nodes verify the packages against the signed metadata of their repositories
+			for _, repository := range bundle.Repositories {
+				for _, f := range append([]*ospackages.BundledFile{repository.Release}, repository.Indexes...) {
+					if err := addFile(f.File, f.SHA256); err != nil {
+						return nil, nil, err
+					}
+				}
+			}
+		}
+	}
+	return bundles, fileAssets, nil
+}
+
+// osPackageNode returns the settings that determine the OS packages nodeup installs on the nodes of an instance group
+// running the distribution. The NVIDIA packages are included whenever the NVIDIA runtime is enabled, as the GPU
+// of the instances is only known on the nodes.
+func osPackageNode(cluster *kops.Cluster, ig *kops.InstanceGroup, d distributions.Distribution) (*ospackages.Node, error) {
+	kubernetesVersion, err := kopsutil.ParseKubernetesVersion(cluster.Spec.KubernetesVersion)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse KubernetesVersion %q: %v", cluster.Spec.KubernetesVersion, err)
+	}
+	nodeupConfig, _ := nodeup.NewConfig(cluster, ig)
+
+	node := &ospackages.Node{
+		Distribution:     d,
+		KubernetesLT120:  !kopsutil.IsKubernetesGTE("1.20", *kubernetesVersion),
+		OnGCE:            cluster.Spec.GetCloudProvider() == kops.CloudProviderGCE,
+		ManagedNTP:       cluster.Spec.NTP.IsManaged(),
+		AutomaticUpdates: nodeupConfig.UpdatePolicy != kops.UpdatePolicyExternal,
+		Calico:           cluster.Spec.Networking != nil && cluster.Spec.Networking.Calico != nil,
+	}
+
+	var nvidiaEnabled bool
+	driverPackage := kops.NvidiaDefaultDriverPackage
+	for _, containerd := range []*kops.ContainerdConfig{cluster.Spec.Containerd, ig.Spec.Containerd} {
+		if containerd == nil || containerd.NvidiaGPU == nil {
+			continue
+		}
+		if containerd.NvidiaGPU.Enabled != nil {
+			nvidiaEnabled = *containerd.NvidiaGPU.Enabled
+		}
+		if containerd.NvidiaGPU.DriverPackage != "" {
+			driverPackage = containerd.NvidiaGPU.DriverPackage
+		}
+	}
+	if nvidiaEnabled {
+		node.NvidiaDriverPackage = driverPackage
+	}
+
+	return node, nil
+}
+
 func imageOutputTable(images []*Image, out io.Writer) error {
 	fmt.Println("")
 	t := &tables.Table{}
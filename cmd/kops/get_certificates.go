@@ -0,0 +1,548 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/expfmt"
+	"github.com/spf13/cobra"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+	"k8s.io/klog/v2"
+	"k8s.io/kops/cmd/kops/util"
+	"k8s.io/kops/pkg/commands/commandutils"
+	"k8s.io/kops/util/pkg/tables"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+	"sigs.k8s.io/yaml"
+)
+
+var (
+	getCertificatesLong = templates.LongDesc(i18n.T(`
+	Display the certificates of the keysets in the state store, with their expiry.
+
+	With --nodes, the certificates used in the cluster are also inspected, through the Kubernetes API:
+	the serving certificate of the API server, verified with the kubeconfig; the certificates the kubelet
+	on each node reports in its metrics; and the certificates of etcd-manager and kops-controller on each
+	control plane node, read by running a command in the etcd-manager pods.`))
+
+	getCertificatesExample = templates.Examples(i18n.T(`
+	# List the certificates of all keysets.
+	kops get certificates
+
+	# List the certificates, including those of the nodes, that expire within 30 days.
+	kops get certificates --expiring-within 30d --nodes`))
+
+	getCertificatesShort = i18n.T(`Get certificates and their expiry.`)
+)
+
+// dialTimeout is the time allowed to connect to the API server to read its certificate
+const dialTimeout = 5 * time.Second
+
+type GetCertificatesOptions struct {
+	*GetOptions
+	KeysetNames []string
+
+	// ExpiringWithin limits the output to certificates that expire within the duration, such as "30d" or "72h".
+	ExpiringWithin string
+
+	// Nodes also inspects the certificates of the API server, the kubelets, etcd-manager and kops-controller.
+	Nodes bool
+}
+
+func NewCmdGetCertificates(f *util.Factory, out io.Writer, getOptions *GetOptions) *cobra.Command {
+	options := &GetCertificatesOptions{
+		GetOptions: getOptions,
+	}
+	cmd := &cobra.Command{
+		Use:     "certificates [KEYSET]...",
+		Aliases: []string{"certificate", "certs", "cert"},
+		Short:   getCertificatesShort,
+		Long:    getCertificatesLong,
+		Example: getCertificatesExample,
+		Args: func(cmd *cobra.Command, args []string) error {
+			options.ClusterName = rootCommand.ClusterName(true)
+			if options.ClusterName == "" {
+				return fmt.Errorf("--name is required")
+			}
+
+			options.KeysetNames = args
+			return nil
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return completeGetKeypairs(f, &GetKeypairsOptions{GetOptions: options.GetOptions}, args, toComplete)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return RunGetCertificates(context.TODO(), f, out, options)
+		},
+	}
+
+	cmd.Flags().StringVar(&options.ExpiringWithin, "expiring-within", options.ExpiringWithin, "Only show certificates that expire within this duration, such as 30d or 72h")
+	cmd.Flags().BoolVar(&options.Nodes, "nodes", options.Nodes, "Also inspect the certificates of the API server, of the kubelets, and of etcd-manager and kops-controller on the control plane nodes")
+
+	return cmd
+}
+
+type certificateItem struct {
+	// Source is where the certificate was found: a keyset in the state store, the API server or a node.
+	Source    string    `json:"source"`
+	Name      string    `json:"name"`
+	ID        string    `json:"id,omitempty"`
+	Subject   string    `json:"subject"`
+	Issuer    string    `json:"issuer"`
+	IsCA      bool      `json:"isCA,omitempty"`
+	NotBefore time.Time `json:"notBefore"`
+	NotAfter  time.Time `json:"notAfter"`
+}
+
+const (
+	certificateSourceKeyset    = "keyset"
+	certificateSourceAPIServer = "apiserver"
+	certificateSourceNode      = "node"
+)
+
+func RunGetCertificates(ctx context.Context, f commandutils.Factory, out io.Writer, options *GetCertificatesOptions) error {
+	var within time.Duration
+	if options.ExpiringWithin != "" {
+		var err error
+		within, err = parseDurationWithDays(options.ExpiringWithin)
+		if err != nil {
+			return fmt.Errorf("invalid --expiring-within: %v", err)
+		}
+	}
+
+	clientset, err := f.Clientset()
+	if err != nil {
+		return err
+	}
+
+	cluster, err := clientset.GetCluster(ctx, options.ClusterName)
+	if err != nil {
+		return err
+	}
+
+	keyStore, err := clientset.KeyStore(cluster)
+	if err != nil {
+		return err
+	}
+
+	keypairs, err := listKeypairs(keyStore, options.KeysetNames, false)
+	if err != nil {
+		return err
+	}
+
+	var items []*certificateItem
+	for _, keypair := range keypairs {
+		items = append(items, &certificateItem{
+			Source:    certificateSourceKeyset,
+			Name:      keypair.Name,
+			ID:        keypair.ID,
+			Subject:   keypair.Subject,
+			Issuer:    keypair.Issuer,
+			IsCA:      keypair.IsCA,
+			NotBefore: keypair.NotBefore,
+			NotAfter:  keypair.NotAfter,
+		})
+	}
+
+	if options.Nodes {
+		nodeItems, err := listServingCertificates(ctx, cluster.ObjectMeta.Name)
+		if err != nil {
+			return err
+		}
+		items = append(items, nodeItems...)
+	}
+
+	if options.ExpiringWithin != "" {
+		items = filterExpiringCertificates(items, time.Now(), within)
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].NotAfter.Before(items[j].NotAfter)
+	})
+
+	switch options.Output {
+	case OutputTable:
+		if len(items) == 0 {
+			if options.ExpiringWithin != "" {
+				fmt.Fprintf(out, "No certificates expire within %s\n", options.ExpiringWithin)
+				return nil
+			}
+			return fmt.Errorf("no certificates found")
+		}
+
+		now := time.Now()
+		t := &tables.Table{}
+		t.AddColumn("SOURCE", func(i *certificateItem) string {
+			return i.Source
+		})
+		t.AddColumn("NAME", func(i *certificateItem) string {
+			return i.Name
+		})
+		t.AddColumn("ID", func(i *certificateItem) string {
+			return i.ID
+		})
+		t.AddColumn("SUBJECT", func(i *certificateItem) string {
+			return i.Subject
+		})
+		t.AddColumn("EXPIRES", func(i *certificateItem) string {
+			return i.NotAfter.Local().Format("2006-01-02")
+		})
+		t.AddColumn("REMAINING", func(i *certificateItem) string {
+			return formatRemaining(i.NotAfter.Sub(now))
+		})
+		return t.Render(items, out, "SOURCE", "NAME", "ID", "SUBJECT", "EXPIRES", "REMAINING")
+
+	case OutputYaml:
+		y, err := yaml.Marshal(items)
+		if err != nil {
+			return fmt.Errorf("unable to marshal YAML: %v", err)
+		}
+		if _, err := out.Write(y); err != nil {
+			return fmt.Errorf("error writing to output: %v", err)
+		}
+	case OutputJSON:
+		j, err := json.Marshal(items)
+		if err != nil {
+			return fmt.Errorf("unable to marshal JSON: %v", err)
+		}
+		if _, err := out.Write(j); err != nil {
+			return fmt.Errorf("error writing to output: %v", err)
+		}
+
+	default:
+		return fmt.Errorf("Unknown output format: %q", options.Output)
+	}
+
+	return nil
+}
+
+// listServingCertificates reads the certificates of the API server, of the kubelet on each node,
+// and of etcd-manager and kops-controller on each control plane node.
+// All of them are read through the Kubernetes API; certificates that cannot be read are reported as warnings.
+func listServingCertificates(ctx context.Context, contextName string) ([]*certificateItem, error) {
+	clientGetter := genericclioptions.NewConfigFlags(true)
+	clientGetter.Context = &contextName
+
+	config, err := clientGetter.ToRESTConfig()
+	if err != nil {
+		return nil, fmt.Errorf("cannot load kubecfg settings for %q: %v", contextName, err)
+	}
+	k8sClient, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("cannot build kubernetes api client for %q: %v", contextName, err)
+	}
+
+	var items []*certificateItem
+
+	cert, err := readAPIServerCertificate(config)
+	if err != nil {
+		klog.Warningf("cannot read the API server certificate: %v", err)
+	} else {
+		items = append(items, newServingCertificateItem(certificateSourceAPIServer, config.Host, cert))
+	}
+
+	nodes, err := k8sClient.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error listing nodes: %v", err)
+	}
+	for i := range nodes.Items {
+		nodeItems, err := readKubeletCertificates(ctx, k8sClient, &nodes.Items[i], time.Now())
+		if err != nil {
+			klog.Warningf("cannot read the kubelet certificates of node %q: %v", nodes.Items[i].Name, err)
+			continue
+		}
+		items = append(items, nodeItems...)
+	}
+
+	pods, err := k8sClient.CoreV1().Pods(metav1.NamespaceSystem).List(ctx, metav1.ListOptions{LabelSelector: etcdManagerSelector})
+	if err != nil {
+		return nil, fmt.Errorf("error listing etcd-manager pods: %v", err)
+	}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Status.Phase != v1.PodRunning {
+			klog.Warningf("cannot read the control plane certificates of node %q: pod %q is %s", pod.Spec.NodeName, pod.Name, pod.Status.Phase)
+			continue
+		}
+		podItems, err := readControlPlaneCertificates(config, k8sClient, pod)
+		if err != nil {
+			klog.Warningf("cannot read the control plane certificates of node %q: %v", pod.Spec.NodeName, err)
+			continue
+		}
+		items = append(items, podItems...)
+	}
+
+	return items, nil
+}
+
+// readAPIServerCertificate returns the certificate presented by the API server,
+// verified with the TLS settings of the kubeconfig.
+func readAPIServerCertificate(config *rest.Config) (*x509.Certificate, error) {
+	u, err := url.Parse(config.Host)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse API server address %q: %v", config.Host, err)
+	}
+	if u.Scheme != "https" {
+		return nil, fmt.Errorf("API server address %q does not use https", config.Host)
+	}
+	address := u.Host
+	if u.Port() == "" {
+		address = net.JoinHostPort(u.Hostname(), "443")
+	}
+
+	tlsConfig, err := rest.TLSConfigFor(config)
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{}
+	}
+
+	dialer := &net.Dialer{Timeout: dialTimeout}
+	conn, err := tls.DialWithDialer(dialer, "tcp", address, tlsConfig)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	certs := conn.ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("%s did not present a certificate", address)
+	}
+	return certs[0], nil
+}
+
+// kubeletCertificateMetrics maps the kubelet metrics reporting the time to live of its certificates to the names we report them under
+var kubeletCertificateMetrics = map[string]string{
+	"kubelet_certificate_manager_server_ttl_seconds": "kubelet-server",
+	"kubelet_certificate_manager_client_ttl_seconds": "kubelet-client",
+}
+
+// readKubeletCertificates reads the expiry of the kubelet certificates from the kubelet metrics, through the API server node proxy.
+// The kubelet only reports the certificates it manages: the serving certificate is reported when it is rotated by the kubelet.
+func readKubeletCertificates(ctx context.Context, k8sClient kubernetes.Interface, node *v1.Node, now time.Time) ([]*certificateItem, error) {
+	port := node.Status.DaemonEndpoints.KubeletEndpoint.Port
+	if port == 0 {
+		return nil, fmt.Errorf("cannot determine the kubelet port")
+	}
+
+	metrics, err := k8sClient.CoreV1().RESTClient().Get().
+		AbsPath("/api/v1/nodes", fmt.Sprintf("%s:%d", node.Name, port), "proxy/metrics").
+		Do(ctx).Raw()
+	if err != nil {
+		return nil, fmt.Errorf("error reading kubelet metrics: %v", err)
+	}
+
+	return parseKubeletCertificateMetrics(node.Name, metrics, now)
+}
+
+// parseKubeletCertificateMetrics returns the kubelet certificates whose time to live is reported in the metrics.
+// A certificate the kubelet does not use is reported with an infinite time to live, and is skipped.
+func parseKubeletCertificateMetrics(nodeName string, metrics []byte, now time.Time) ([]*certificateItem, error) {
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(bytes.NewReader(metrics))
+	if err != nil {
+		return nil, fmt.Errorf("error parsing kubelet metrics: %v", err)
+	}
+
+	var items []*certificateItem
+	for metricName, name := range kubeletCertificateMetrics {
+		family := families[metricName]
+		if family == nil || len(family.GetMetric()) == 0 {
+			klog.V(2).Infof("node %q does not report %s", nodeName, metricName)
+			continue
+		}
+		ttl := family.GetMetric()[0].GetGauge().GetValue()
+		if math.IsInf(ttl, 0) || math.IsNaN(ttl) {
+			continue
+		}
+		items = append(items, &certificateItem{
+			Source:   certificateSourceNode,
+			Name:     nodeName + ":" + name,
+			NotAfter: now.Add(time.Duration(ttl) * time.Second).UTC().Truncate(time.Second),
+		})
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Name < items[j].Name
+	})
+	return items, nil
+}
+
+// etcdManagerSelector selects the etcd-manager pods of the main etcd cluster, which run on every control plane node
+const etcdManagerSelector = "k8s-app=etcd-manager-main"
+
+// controlPlaneCertificatePaths are the host directories holding the certificates of etcd-manager and kops-controller.
+// etcd-manager keeps the certificates it issues to etcd on its volumes, mounted under /mnt.
+var controlPlaneCertificatePaths = []string{
+	"/mnt",
+	"/etc/kubernetes/pki",
+	"/etc/kubernetes/kops-controller/pki",
+}
+
+// readControlPlaneCertificates reads the leaf certificates of etcd-manager and kops-controller on the node of an etcd-manager pod.
+// The etcd-manager pod mounts the host filesystem at /rootfs; the certificates are read by running a command in it through the API server.
+func readControlPlaneCertificates(config *rest.Config, k8sClient kubernetes.Interface, pod *v1.Pod) ([]*certificateItem, error) {
+	var dirs []string
+	for _, p := range controlPlaneCertificatePaths {
+		dirs = append(dirs, "/rootfs"+p)
+	}
+	script := fmt.Sprintf(`for f in $(find %s -type f -name '*.crt' 2>/dev/null); do echo "%s${f#/rootfs}"; cat "$f"; done`, strings.Join(dirs, " "), certificateFileMarker)
+
+	req := k8sClient.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(pod.Namespace).
+		Name(pod.Name).
+		SubResource("exec").
+		VersionedParams(&v1.PodExecOptions{
+			Container: "etcd-manager",
+			Command:   []string{"/bin/sh", "-c", script},
+			Stdout:    true,
+			Stderr:    true,
+		}, scheme.ParameterCodec)
+
+	executor, err := remotecommand.NewSPDYExecutor(config, "POST", req.URL())
+	if err != nil {
+		return nil, err
+	}
+	var stdout, stderr bytes.Buffer
+	if err := executor.Stream(remotecommand.StreamOptions{Stdout: &stdout, Stderr: &stderr}); err != nil {
+		return nil, fmt.Errorf("error running command in pod %q: %v: %s", pod.Name, err, stderr.String())
+	}
+
+	return parseCertificateFiles(pod.Spec.NodeName, stdout.Bytes())
+}
+
+// certificateFileMarker prefixes the path of each certificate file in the output of the command listing them
+const certificateFileMarker = "# certificate file "
+
+// parseCertificateFiles parses the certificate files listed by readControlPlaneCertificates.
+// CA certificates are skipped: they are the keysets in the state store.
+func parseCertificateFiles(nodeName string, output []byte) ([]*certificateItem, error) {
+	var items []*certificateItem
+
+	var p string
+	var contents []byte
+	flush := func() error {
+		if p == "" {
+			return nil
+		}
+		rest := contents
+		for {
+			var block *pem.Block
+			block, rest = pem.Decode(rest)
+			if block == nil {
+				break
+			}
+			if block.Type != "CERTIFICATE" {
+				continue
+			}
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return fmt.Errorf("error parsing certificate %q: %v", p, err)
+			}
+			if cert.IsCA {
+				continue
+			}
+			items = append(items, newServingCertificateItem(certificateSourceNode, nodeName+":"+p, cert))
+		}
+		return nil
+	}
+
+	for _, line := range strings.SplitAfter(string(output), "\n") {
+		if strings.HasPrefix(line, certificateFileMarker) {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+			p = strings.TrimSpace(strings.TrimPrefix(line, certificateFileMarker))
+			contents = nil
+			continue
+		}
+		contents = append(contents, line...)
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+func newServingCertificateItem(source string, name string, cert *x509.Certificate) *certificateItem {
+	return &certificateItem{
+		Source:    source,
+		Name:      name,
+		Subject:   cert.Subject.String(),
+		Issuer:    cert.Issuer.String(),
+		IsCA:      cert.IsCA,
+		NotBefore: cert.NotBefore.UTC(),
+		NotAfter:  cert.NotAfter.UTC(),
+	}
+}
+
+// filterExpiringCertificates returns the certificates that expire within the duration after now, including those already expired
+func filterExpiringCertificates(items []*certificateItem, now time.Time, within time.Duration) []*certificateItem {
+	deadline := now.Add(within)
+	var expiring []*certificateItem
+	for _, item := range items {
+		if item.NotAfter.Before(deadline) {
+			expiring = append(expiring, item)
+		}
+	}
+	return expiring
+}
+
+// parseDurationWithDays parses a duration, additionally accepting a whole number of days such as "30d"
+func parseDurationWithDays(s string) (time.Duration, error) {
+	if days := strings.TrimSuffix(s, "d"); days != s {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(s)
+}
+
+// formatRemaining formats the time remaining until a certificate expires, in days if it is at least a day
+func formatRemaining(d time.Duration) string {
+	if d <= 0 {
+		return "expired"
+	}
+	if d >= 24*time.Hour {
+		return fmt.Sprintf("%dd", int(d/(24*time.Hour)))
+	}
+	return d.Round(time.Minute).String()
+}
@@ -0,0 +1,158 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"crypto/x509/pkix"
+	"fmt"
+	"testing"
+	"time"
+
+	"k8s.io/kops/pkg/pki"
+)
+
+func TestParseDurationWithDays(t *testing.T) {
+	grid := []struct {
+		input    string
+		expected time.Duration
+		err      bool
+	}{
+		{input: "30d", expected: 30 * 24 * time.Hour},
+		{input: "0d", expected: 0},
+		{input: "72h", expected: 72 * time.Hour},
+		{input: "90m", expected: 90 * time.Minute},
+		{input: "1.5d", err: true},
+		{input: "d", err: true},
+		{input: "30", err: true},
+	}
+	for _, g := range grid {
+		t.Run(g.input, func(t *testing.T) {
+			actual, err := parseDurationWithDays(g.input)
+			if g.err {
+				if err == nil {
+					t.Fatalf("expected error parsing %q, got %v", g.input, actual)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if actual != g.expected {
+				t.Errorf("expected %v, got %v", g.expected, actual)
+			}
+		})
+	}
+}
+
+func TestFilterExpiringCertificates(t *testing.T) {
+	now := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	items := []*certificateItem{
+		{Name: "expired", NotAfter: now.Add(-time.Hour)},
+		{Name: "soon", NotAfter: now.Add(10 * 24 * time.Hour)},
+		{Name: "later", NotAfter: now.Add(60 * 24 * time.Hour)},
+	}
+
+	expiring := filterExpiringCertificates(items, now, 30*24*time.Hour)
+	var names []string
+	for _, item := range expiring {
+		names = append(names, item.Name)
+	}
+	if len(names) != 2 || names[0] != "expired" || names[1] != "soon" {
+		t.Errorf("unexpected certificates expiring within 30d: %v", names)
+	}
+}
+
+func TestParseKubeletCertificateMetrics(t *testing.T) {
+	now := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	metrics := `# HELP kubelet_certificate_manager_client_ttl_seconds [ALPHA] Gauge of the TTL (time-to-live) of the Kubelet's client certificate.
+# TYPE kubelet_certificate_manager_client_ttl_seconds gauge
+kubelet_certificate_manager_client_ttl_seconds 86400
+# HELP kubelet_certificate_manager_server_ttl_seconds [ALPHA] Gauge of the shortest TTL (time-to-live) of the Kubelet's serving certificate.
+# TYPE kubelet_certificate_manager_server_ttl_seconds gauge
+kubelet_certificate_manager_server_ttl_seconds +Inf
+# HELP kubelet_running_pods [ALPHA] Number of pods that have a running pod sandbox
+# TYPE kubelet_running_pods gauge
+kubelet_running_pods 3
+`
+
+	items, err := parseKubeletCertificateMetrics("node-a", []byte(metrics), now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(items) != 1 {
+		t.Fatalf("expected the client certificate only, got %d certificates", len(items))
+	}
+	if items[0].Name != "node-a:kubelet-client" {
+		t.Errorf("unexpected name %q", items[0].Name)
+	}
+	if !items[0].NotAfter.Equal(now.Add(24 * time.Hour)) {
+		t.Errorf("unexpected expiry, actual=%v, expected=%v", items[0].NotAfter, now.Add(24*time.Hour))
+	}
+}
+
+func TestParseCertificateFiles(t *testing.T) {
+	ca, caKey, _, err := pki.IssueCert(&pki.IssueCertRequest{
+		Type:    "ca",
+		Subject: pkix.Name{CommonName: "etcd-manager-ca-main"},
+	}, nil)
+	if err != nil {
+		t.Fatalf("issuing CA: %v", err)
+	}
+	keystore := &testKeystore{certificate: ca, key: caKey}
+	leaf, _, _, err := pki.IssueCert(&pki.IssueCertRequest{
+		Signer:  "etcd-manager-ca-main",
+		Type:    "server",
+		Subject: pkix.Name{CommonName: "etcd-a"},
+	}, keystore)
+	if err != nil {
+		t.Fatalf("issuing certificate: %v", err)
+	}
+
+	caPEM, err := ca.AsString()
+	if err != nil {
+		t.Fatalf("encoding CA: %v", err)
+	}
+	leafPEM, err := leaf.AsString()
+	if err != nil {
+		t.Fatalf("encoding certificate: %v", err)
+	}
+	output := fmt.Sprintf("%s/etc/kubernetes/pki/etcd-manager-main/etcd-manager-ca.crt\n%s%s/mnt/master-vol-1/pki/me.crt\n%s",
+		certificateFileMarker, caPEM, certificateFileMarker, leafPEM)
+
+	items, err := parseCertificateFiles("node-a", []byte(output))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(items) != 1 {
+		t.Fatalf("expected the leaf certificate only, got %d certificates", len(items))
+	}
+	if items[0].Name != "node-a:/mnt/master-vol-1/pki/me.crt" {
+		t.Errorf("unexpected name %q", items[0].Name)
+	}
+	if items[0].Subject != "CN=etcd-a" || items[0].Issuer != "CN=etcd-manager-ca-main" {
+		t.Errorf("unexpected subject %q or issuer %q", items[0].Subject, items[0].Issuer)
+	}
+}
+
+type testKeystore struct {
+	certificate *pki.Certificate
+	key         *pki.PrivateKey
+}
+
+func (k *testKeystore) FindPrimaryKeypair(name string) (*pki.Certificate, *pki.PrivateKey, error) {
+	return k.certificate, k.key, nil
+}
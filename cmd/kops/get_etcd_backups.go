@@ -0,0 +1,230 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/kops/cmd/kops/util"
+	kopsapi "k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/client/simple"
+	"k8s.io/kops/pkg/commands/commandutils"
+	"k8s.io/kops/pkg/etcdbackup"
+	"k8s.io/kops/util/pkg/tables"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+	"sigs.k8s.io/yaml"
+)
+
+var (
+	getEtcdBackupsLong = templates.LongDesc(i18n.T(`
+	Display the backups that etcd-manager has taken of the etcd clusters.
+
+	The backups are read from the backup store of each etcd cluster, so the
+	cluster does not need to be running.`))
+
+	getEtcdBackupsExample = templates.Examples(i18n.T(`
+	# List the backups of all etcd clusters.
+	kops get etcd-backups --name k8s-cluster.example.com
+
+	# List the backups of the main etcd cluster.
+	kops get etcd-backups --name k8s-cluster.example.com --cluster main`))
+
+	getEtcdBackupsShort = i18n.T(`Get etcd backups.`)
+)
+
+type GetEtcdBackupsOptions struct {
+	*GetOptions
+
+	// EtcdCluster limits the output to the backups of the named etcd cluster.
+	EtcdCluster string
+}
+
+func NewCmdGetEtcdBackups(f *util.Factory, out io.Writer, getOptions *GetOptions) *cobra.Command {
+	options := &GetEtcdBackupsOptions{
+		GetOptions: getOptions,
+	}
+	cmd := &cobra.Command{
+		Use:     "etcd-backups",
+		Aliases: []string{"etcd-backup"},
+		Short:   getEtcdBackupsShort,
+		Long:    getEtcdBackupsLong,
+		Example: getEtcdBackupsExample,
+		Args: func(cmd *cobra.Command, args []string) error {
+			options.ClusterName = rootCommand.ClusterName(true)
+			if options.ClusterName == "" {
+				return fmt.Errorf("--name is required")
+			}
+			return cobra.NoArgs(cmd, args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return RunGetEtcdBackups(context.TODO(), f, out, options)
+		},
+	}
+
+	cmd.Flags().StringVar(&options.EtcdCluster, "cluster", options.EtcdCluster, "Name of the etcd cluster, such as main or events")
+	cmd.RegisterFlagCompletionFunc("cluster", completeEtcdClusterName(f))
+
+	return cmd
+}
+
+type etcdBackupItem struct {
+	EtcdCluster string    `json:"etcdCluster"`
+	Name        string    `json:"name"`
+	Timestamp   time.Time `json:"timestamp"`
+	EtcdVersion string    `json:"etcdVersion,omitempty"`
+	MemberCount int32     `json:"memberCount,omitempty"`
+}
+
+func RunGetEtcdBackups(ctx context.Context, f commandutils.Factory, out io.Writer, options *GetEtcdBackupsOptions) error {
+	clientset, err := f.Clientset()
+	if err != nil {
+		return err
+	}
+
+	cluster, err := clientset.GetCluster(ctx, options.ClusterName)
+	if err != nil {
+		return err
+	}
+	if cluster == nil {
+		return fmt.Errorf("cluster %q not found", options.ClusterName)
+	}
+	if options.EtcdCluster != "" && findEtcdCluster(cluster, options.EtcdCluster) == nil {
+		return fmt.Errorf("etcd cluster %q not found", options.EtcdCluster)
+	}
+
+	var items []*etcdBackupItem
+	for i := range cluster.Spec.EtcdClusters {
+		etcdCluster := &cluster.Spec.EtcdClusters[i]
+		if options.EtcdCluster != "" && etcdCluster.Name != options.EtcdCluster {
+			continue
+		}
+
+		store, err := etcdBackupStore(clientset, cluster, etcdCluster)
+		if err != nil {
+			return err
+		}
+		backups, err := store.ListBackups()
+		if err != nil {
+			return err
+		}
+		for _, backup := range backups {
+			item := &etcdBackupItem{
+				EtcdCluster: etcdCluster.Name,
+				Name:        backup.Name,
+				Timestamp:   backup.Time(),
+				EtcdVersion: backup.EtcdVersion,
+			}
+			if backup.ClusterSpec != nil {
+				item.MemberCount = backup.ClusterSpec.MemberCount
+			}
+			items = append(items, item)
+		}
+	}
+
+	switch options.Output {
+	case OutputTable:
+		if len(items) == 0 {
+			return fmt.Errorf("no etcd backups found")
+		}
+		t := &tables.Table{}
+		t.AddColumn("ETCD-CLUSTER", func(i *etcdBackupItem) string {
+			return i.EtcdCluster
+		})
+		t.AddColumn("NAME", func(i *etcdBackupItem) string {
+			return i.Name
+		})
+		t.AddColumn("TIMESTAMP", func(i *etcdBackupItem) string {
+			if i.Timestamp.Unix() == 0 {
+				return ""
+			}
+			return i.Timestamp.Local().Format(time.RFC3339)
+		})
+		t.AddColumn("ETCD-VERSION", func(i *etcdBackupItem) string {
+			return i.EtcdVersion
+		})
+		return t.Render(items, out, "ETCD-CLUSTER", "NAME", "TIMESTAMP", "ETCD-VERSION")
+
+	case OutputYaml:
+		y, err := yaml.Marshal(items)
+		if err != nil {
+			return fmt.Errorf("unable to marshal YAML: %v", err)
+		}
+		if _, err := out.Write(y); err != nil {
+			return fmt.Errorf("error writing to output: %v", err)
+		}
+	case OutputJSON:
+		j, err := json.Marshal(items)
+		if err != nil {
+			return fmt.Errorf("unable to marshal JSON: %v", err)
+		}
+		if _, err := out.Write(j); err != nil {
+			return fmt.Errorf("error writing to output: %v", err)
+		}
+
+	default:
+		return fmt.Errorf("Unknown output format: %q", options.Output)
+	}
+
+	return nil
+}
+
+// etcdBackupStore returns the backup store that etcd-manager uses for the etcd cluster
+func etcdBackupStore(clientset simple.Clientset, cluster *kopsapi.Cluster, etcdCluster *kopsapi.EtcdClusterSpec) (*etcdbackup.Store, error) {
+	configBase, err := clientset.ConfigBaseFor(cluster)
+	if err != nil {
+		return nil, err
+	}
+	p, err := etcdbackup.BackupStorePath(configBase, etcdCluster)
+	if err != nil {
+		return nil, err
+	}
+	return etcdbackup.NewStore(p), nil
+}
+
+// findEtcdCluster returns the etcd cluster with the given name, or nil if there is none
+func findEtcdCluster(cluster *kopsapi.Cluster, name string) *kopsapi.EtcdClusterSpec {
+	for i := range cluster.Spec.EtcdClusters {
+		if cluster.Spec.EtcdClusters[i].Name == name {
+			return &cluster.Spec.EtcdClusters[i]
+		}
+	}
+	return nil
+}
+
+func completeEtcdClusterName(f commandutils.Factory) func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		commandutils.ConfigureKlogForCompletion()
+		ctx := context.TODO()
+
+		cluster, _, completions, directive := GetClusterForCompletion(ctx, f, nil)
+		if cluster == nil {
+			return completions, directive
+		}
+
+		var names []string
+		for _, etcdCluster := range cluster.Spec.EtcdClusters {
+			names = append(names, etcdCluster.Name)
+		}
+		return names, cobra.ShellCompDirectiveNoFileComp
+	}
+}
@@ -0,0 +1,39 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"io"
+
+	"github.com/spf13/cobra"
+	"k8s.io/kops/cmd/kops/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+)
+
+var getHistoryShort = i18n.T(`Get the revision history of a resource.`)
+
+func NewCmdGetHistory(f *util.Factory, out io.Writer, getOptions *GetOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: getHistoryShort,
+	}
+
+	// create subcommands
+	cmd.AddCommand(NewCmdGetHistoryCluster(f, out, getOptions))
+
+	return cmd
+}
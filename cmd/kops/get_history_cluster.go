@@ -0,0 +1,199 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"k8s.io/kops/cmd/kops/util"
+	"k8s.io/kops/pkg/client/simple"
+	"k8s.io/kops/pkg/commands/commandutils"
+	"k8s.io/kops/pkg/diff"
+	"k8s.io/kops/pkg/history"
+	"k8s.io/kops/pkg/pretty"
+	"k8s.io/kops/util/pkg/tables"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+	"sigs.k8s.io/yaml"
+)
+
+var (
+	getHistoryClusterLong = pretty.LongDesc(i18n.T(`
+	Display the revisions of the cluster and instance group configuration recorded in the state store.
+
+	A revision is recorded each time the configuration is written. A previous revision can be restored with ` + pretty.Bash("kops rollback cluster") + `.`))
+
+	getHistoryClusterExample = templates.Examples(i18n.T(`
+	# List the revisions of a cluster's configuration.
+	kops get history cluster k8s-cluster.example.com
+
+	# Show the changes made by revision 3.
+	kops get history cluster k8s-cluster.example.com --diff 3
+
+	# Show the differences between revisions 2 and 5.
+	kops get history cluster k8s-cluster.example.com --diff 2,5`))
+
+	getHistoryClusterShort = i18n.T(`Get the revision history of a cluster.`)
+)
+
+type GetHistoryClusterOptions struct {
+	*GetOptions
+
+	// Diff holds the revisions to compare; a single revision is compared with the revision before it.
+	Diff []int
+}
+
+func NewCmdGetHistoryCluster(f *util.Factory, out io.Writer, getOptions *GetOptions) *cobra.Command {
+	options := &GetHistoryClusterOptions{
+		GetOptions: getOptions,
+	}
+
+	cmd := &cobra.Command{
+		Use:               "cluster [CLUSTER]",
+		Aliases:           []string{"clusters"},
+		Short:             getHistoryClusterShort,
+		Long:              getHistoryClusterLong,
+		Example:           getHistoryClusterExample,
+		Args:              rootCommand.clusterNameArgs(&options.ClusterName),
+		ValidArgsFunction: commandutils.CompleteClusterName(f, true, false),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return RunGetHistoryCluster(context.TODO(), f, out, options)
+		},
+	}
+
+	cmd.Flags().IntSliceVar(&options.Diff, "diff", options.Diff, "Show the differences between two revisions, or between a revision and the one before it")
+	cmd.RegisterFlagCompletionFunc("diff", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	})
+
+	return cmd
+}
+
+func RunGetHistoryCluster(ctx context.Context, f commandutils.Factory, out io.Writer, options *GetHistoryClusterOptions) error {
+	clientset, err := f.Clientset()
+	if err != nil {
+		return err
+	}
+
+	cluster, err := clientset.GetCluster(ctx, options.ClusterName)
+	if err != nil {
+		return err
+	}
+
+	historyClient := clientset.HistoryFor(cluster)
+
+	if len(options.Diff) != 0 {
+		if len(options.Diff) > 2 {
+			return fmt.Errorf("--diff takes one or two revisions")
+		}
+
+		to, err := getRevision(historyClient, options.Diff[len(options.Diff)-1])
+		if err != nil {
+			return err
+		}
+
+		from := &history.Revision{}
+		if len(options.Diff) == 2 {
+			from, err = getRevision(historyClient, options.Diff[0])
+			if err != nil {
+				return err
+			}
+		} else if to.Number > 1 {
+			from, err = getRevision(historyClient, to.Number-1)
+			if err != nil {
+				return err
+			}
+		}
+
+		fmt.Fprintf(out, "Revision %d -> %d\n", from.Number, to.Number)
+		if from.SameConfig(to) {
+			fmt.Fprintf(out, "No changes\n")
+			return nil
+		}
+		_, err = fmt.Fprint(out, diff.FormatDiff(from.Config(), to.Config()))
+		return err
+	}
+
+	revisions, err := historyClient.List()
+	if err != nil {
+		return err
+	}
+
+	if len(revisions) == 0 {
+		return fmt.Errorf("no revisions found")
+	}
+
+	switch options.Output {
+	case OutputTable:
+		t := &tables.Table{}
+		t.AddColumn("REVISION", func(r *history.Revision) string {
+			return strconv.Itoa(r.Number)
+		})
+		t.AddColumn("CREATED", func(r *history.Revision) string {
+			return r.Timestamp.Local().Format("2006-01-02 15:04:05")
+		})
+		t.AddColumn("USER", func(r *history.Revision) string {
+			return r.User
+		})
+		t.AddColumn("KOPS VERSION", func(r *history.Revision) string {
+			return r.KopsVersion
+		})
+		t.AddColumn("CHANGE", func(r *history.Revision) string {
+			return r.Change
+		})
+		return t.Render(revisions, out, "REVISION", "CREATED", "USER", "KOPS VERSION", "CHANGE")
+
+	case OutputYaml:
+		y, err := yaml.Marshal(revisions)
+		if err != nil {
+			return fmt.Errorf("unable to marshal YAML: %v", err)
+		}
+		if _, err := out.Write(y); err != nil {
+			return fmt.Errorf("error writing to output: %v", err)
+		}
+	case OutputJSON:
+		j, err := json.Marshal(revisions)
+		if err != nil {
+			return fmt.Errorf("unable to marshal JSON: %v", err)
+		}
+		if _, err := out.Write(j); err != nil {
+			return fmt.Errorf("error writing to output: %v", err)
+		}
+
+	default:
+		return fmt.Errorf("Unknown output format: %q", options.Output)
+	}
+
+	return nil
+}
+
+// getRevision returns the revision with the given number, or an error if it does not exist
+func getRevision(historyClient simple.HistoryClient, number int) (*history.Revision, error) {
+	revision, err := historyClient.Get(number)
+	if err != nil {
+		return nil, err
+	}
+	if revision == nil {
+		return nil, fmt.Errorf("revision %d not found", number)
+	}
+	return revision, nil
+}
@@ -0,0 +1,175 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8s.io/kops/cmd/kops/util"
+	"k8s.io/kops/pkg/commands/commandutils"
+	"k8s.io/kops/pkg/pretty"
+	"k8s.io/kops/pkg/rollout"
+	"k8s.io/kops/util/pkg/tables"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+	"sigs.k8s.io/yaml"
+)
+
+var (
+	getRolloutsLong = pretty.LongDesc(i18n.T(`
+	Display the rolling updates recorded in the state store for a cluster.
+
+	An incomplete rolling update can be continued with ` + pretty.Bash("kops rolling-update cluster --resume") + `.`))
+
+	getRolloutsExample = templates.Examples(i18n.T(`
+	# List the rolling updates of a cluster.
+	kops get rollouts --name k8s-cluster.example.com
+
+	# Show the full record of a rolling update.
+	kops get rollouts 20220301-120000-5f3a9c1e --name k8s-cluster.example.com -o yaml`))
+
+	getRolloutsShort = i18n.T(`Get the rolling updates of a cluster.`)
+)
+
+type GetRolloutsOptions struct {
+	*GetOptions
+	RolloutNames []string
+}
+
+func NewCmdGetRollouts(f *util.Factory, out io.Writer, getOptions *GetOptions) *cobra.Command {
+	options := &GetRolloutsOptions{
+		GetOptions: getOptions,
+	}
+	cmd := &cobra.Command{
+		Use:     "rollouts [NAME]...",
+		Aliases: []string{"rollout"},
+		Short:   getRolloutsShort,
+		Long:    getRolloutsLong,
+		Example: getRolloutsExample,
+		Args: func(cmd *cobra.Command, args []string) error {
+			options.ClusterName = rootCommand.ClusterName(true)
+			if options.ClusterName == "" {
+				return fmt.Errorf("--name is required")
+			}
+
+			options.RolloutNames = args
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return RunGetRollouts(context.TODO(), f, out, options)
+		},
+	}
+
+	return cmd
+}
+
+func RunGetRollouts(ctx context.Context, f commandutils.Factory, out io.Writer, options *GetRolloutsOptions) error {
+	clientset, err := f.Clientset()
+	if err != nil {
+		return err
+	}
+
+	cluster, err := clientset.GetCluster(ctx, options.ClusterName)
+	if err != nil {
+		return err
+	}
+
+	records, err := clientset.RolloutsFor(cluster).List()
+	if err != nil {
+		return err
+	}
+
+	if len(options.RolloutNames) != 0 {
+		var filtered []*rollout.Record
+		for _, name := range options.RolloutNames {
+			var found *rollout.Record
+			for _, record := range records {
+				if record.Name == name {
+					found = record
+					break
+				}
+			}
+			if found == nil {
+				return fmt.Errorf("rolling update %q not found", name)
+			}
+			filtered = append(filtered, found)
+		}
+		records = filtered
+	}
+
+	if len(records) == 0 {
+		return fmt.Errorf("no rolling updates found")
+	}
+
+	switch options.Output {
+	case OutputTable:
+		t := &tables.Table{}
+		t.AddColumn("NAME", func(r *rollout.Record) string {
+			return r.Name
+		})
+		t.AddColumn("STATUS", func(r *rollout.Record) string {
+			return string(r.Status)
+		})
+		t.AddColumn("PHASE", func(r *rollout.Record) string {
+			return string(r.Phase)
+		})
+		t.AddColumn("STARTED", func(r *rollout.Record) string {
+			return r.StartedAt.Local().Format("2006-01-02 15:04:05")
+		})
+		t.AddColumn("UPDATED", func(r *rollout.Record) string {
+			return r.UpdatedAt.Local().Format("2006-01-02 15:04:05")
+		})
+		t.AddColumn("COMPLETED", func(r *rollout.Record) string {
+			return strings.Join(r.CompletedGroups, ",")
+		})
+		t.AddColumn("REPLACED", func(r *rollout.Record) string {
+			return strconv.Itoa(len(r.ReplacedInstances))
+		})
+		t.AddColumn("ERROR", func(r *rollout.Record) string {
+			return r.Error
+		})
+		return t.Render(records, out, "NAME", "STATUS", "PHASE", "STARTED", "UPDATED", "COMPLETED", "REPLACED", "ERROR")
+
+	case OutputYaml:
+		y, err := yaml.Marshal(records)
+		if err != nil {
+			return fmt.Errorf("unable to marshal YAML: %v", err)
+		}
+		if _, err := out.Write(y); err != nil {
+			return fmt.Errorf("error writing to output: %v", err)
+		}
+	case OutputJSON:
+		j, err := json.Marshal(records)
+		if err != nil {
+			return fmt.Errorf("unable to marshal JSON: %v", err)
+		}
+		if _, err := out.Write(j); err != nil {
+			return fmt.Errorf("error writing to output: %v", err)
+		}
+
+	default:
+		return fmt.Errorf("Unknown output format: %q", options.Output)
+	}
+
+	return nil
+}
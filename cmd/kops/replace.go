@@ -145,6 +145,9 @@ func RunReplace(ctx context.Context, f *util.Factory, out io.Writer, c *ReplaceO
 					} else {
 						_, err = clientset.UpdateCluster(ctx, v, status)
 						if err != nil {
+							if errors.IsConflict(err) {
+								return fmt.Errorf("cluster %q has been modified since %q was written; apply your changes to the output of \"kops get cluster %s -o yaml\" and try again", clusterName, f, clusterName)
+							}
 							return fmt.Errorf("error replacing cluster: %v", err)
 						}
 					}
@@ -184,6 +187,9 @@ func RunReplace(ctx context.Context, f *util.Factory, out io.Writer, c *ReplaceO
 				default:
 					_, err = clientset.InstanceGroupsFor(cluster).Update(ctx, v, metav1.UpdateOptions{})
 					if err != nil {
+						if errors.IsConflict(err) {
+							return fmt.Errorf("instanceGroup %q has been modified since %q was written; apply your changes to the output of \"kops get instancegroup %s --name %s -o yaml\" and try again", igName, f, igName, clusterName)
+						}
 						return fmt.Errorf("error replacing instanceGroup: %v", err)
 					}
 				}
@@ -0,0 +1,204 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/kops/cmd/kops/util"
+	"k8s.io/kops/pkg/commands/commandutils"
+	"k8s.io/kops/pkg/etcdbackup"
+	"k8s.io/kops/pkg/pretty"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	restoreEtcdLong = pretty.LongDesc(i18n.T(`
+	Restore an etcd cluster from a backup taken by etcd-manager.
+
+	The restore is requested by writing a command to the backup store of the etcd cluster,
+	as ` + pretty.Bash("etcd-manager-ctl restore-backup") + ` does. etcd-manager performs the restore
+	when it next starts, so the etcd-manager containers on the control plane nodes must be
+	restarted, or the control plane nodes rolled, after the command is written.
+	etcd-manager removes the command once the restore has completed; use
+	` + pretty.Bash("--wait") + ` to wait for that.
+
+	The restore replaces the contents of the etcd cluster with the backup and cannot be undone.
+	Use ` + pretty.Bash("kops get etcd-backups") + ` to list the backups.`))
+
+	restoreEtcdExample = templates.Examples(i18n.T(`
+	# Preview the restore of a backup of the main etcd cluster.
+	kops restore etcd --name k8s-cluster.example.com --cluster main --backup 2022-06-01T10:00:00Z-000001
+
+	# Restore the backup, and wait up to 30 minutes for etcd-manager to complete it.
+	kops restore etcd --name k8s-cluster.example.com --cluster main --backup 2022-06-01T10:00:00Z-000001 --yes --wait 30m`))
+
+	restoreEtcdShort = i18n.T(`Restore an etcd cluster from a backup.`)
+)
+
+// restoreEtcdPollInterval is the interval at which the backup store is checked for completion of the restore
+var restoreEtcdPollInterval = 10 * time.Second
+
+type RestoreEtcdOptions struct {
+	ClusterName string
+
+	// EtcdCluster is the name of the etcd cluster to restore, such as main or events.
+	EtcdCluster string
+	// Backup is the name of the backup to restore.
+	Backup string
+
+	Yes bool
+
+	// Wait is the time to wait for etcd-manager to complete the restore; no wait if zero.
+	Wait time.Duration
+}
+
+// NewCmdRestoreEtcd returns a restore etcd command.
+func NewCmdRestoreEtcd(f *util.Factory, out io.Writer) *cobra.Command {
+	options := &RestoreEtcdOptions{}
+
+	cmd := &cobra.Command{
+		Use:               "etcd [CLUSTER]",
+		Short:             restoreEtcdShort,
+		Long:              restoreEtcdLong,
+		Example:           restoreEtcdExample,
+		Args:              rootCommand.clusterNameArgs(&options.ClusterName),
+		ValidArgsFunction: commandutils.CompleteClusterName(f, true, false),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return RunRestoreEtcd(context.TODO(), f, out, options)
+		},
+	}
+
+	cmd.Flags().StringVar(&options.EtcdCluster, "cluster", options.EtcdCluster, "Name of the etcd cluster to restore, such as main or events")
+	cmd.MarkFlagRequired("cluster")
+	cmd.RegisterFlagCompletionFunc("cluster", completeEtcdClusterName(f))
+	cmd.Flags().StringVar(&options.Backup, "backup", options.Backup, "Name of the backup to restore")
+	cmd.MarkFlagRequired("backup")
+	cmd.Flags().BoolVarP(&options.Yes, "yes", "y", options.Yes, "Request the restore without confirmation")
+	cmd.Flags().DurationVar(&options.Wait, "wait", options.Wait, "Amount of time to wait for etcd-manager to complete the restore")
+
+	return cmd
+}
+
+// RunRestoreEtcd asks etcd-manager to restore an etcd cluster from a backup.
+func RunRestoreEtcd(ctx context.Context, f commandutils.Factory, out io.Writer, options *RestoreEtcdOptions) error {
+	if options.EtcdCluster == "" {
+		return fmt.Errorf("--cluster is required")
+	}
+	if options.Backup == "" {
+		return fmt.Errorf("--backup is required")
+	}
+
+	clientset, err := f.Clientset()
+	if err != nil {
+		return err
+	}
+
+	cluster, err := clientset.GetCluster(ctx, options.ClusterName)
+	if err != nil {
+		return err
+	}
+	if cluster == nil {
+		return fmt.Errorf("cluster %q not found", options.ClusterName)
+	}
+
+	etcdCluster := findEtcdCluster(cluster, options.EtcdCluster)
+	if etcdCluster == nil {
+		return fmt.Errorf("etcd cluster %q not found", options.EtcdCluster)
+	}
+	store, err := etcdBackupStore(clientset, cluster, etcdCluster)
+	if err != nil {
+		return err
+	}
+
+	backup, err := store.LoadBackup(options.Backup)
+	if err != nil {
+		return err
+	}
+	if backup == nil {
+		return fmt.Errorf("backup %q not found in %s", options.Backup, store.Path())
+	}
+
+	commands, err := store.ListCommands()
+	if err != nil {
+		return err
+	}
+	for _, command := range commands {
+		if command.RestoreBackup != nil {
+			return fmt.Errorf("a restore of backup %q of etcd cluster %q is already pending (command %s)", command.RestoreBackup.Backup, etcdCluster.Name, command.Name)
+		}
+	}
+
+	// The cluster is recreated with the shape recorded in the backup, as etcd-manager-ctl does
+	spec := backup.ClusterSpec
+	if spec == nil {
+		spec = &etcdbackup.ClusterSpec{
+			MemberCount: int32(len(etcdCluster.Members)),
+			EtcdVersion: backup.EtcdVersion,
+		}
+	}
+
+	if !options.Yes {
+		fmt.Fprintf(out, "Will restore etcd cluster %q from backup %q", etcdCluster.Name, backup.Name)
+		if backup.Timestamp != 0 {
+			fmt.Fprintf(out, ", taken at %s", backup.Time().Format(time.RFC3339))
+		}
+		fmt.Fprintf(out, ".\n")
+		fmt.Fprintf(out, "The etcd cluster will be recreated with %d members running etcd %s.\n", spec.MemberCount, spec.EtcdVersion)
+		fmt.Fprintf(out, "Changes made to the cluster since the backup was taken will be lost.\n")
+		fmt.Fprintf(out, "\nMust specify --yes to restore\n")
+		return nil
+	}
+
+	command, err := store.AddRestoreCommand(backup, spec, time.Now())
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Requested restore of etcd cluster %q from backup %q.\n", etcdCluster.Name, backup.Name)
+	fmt.Fprintf(out, "Restart the etcd-manager-%s containers on the control plane nodes, or roll the control plane, for etcd-manager to perform the restore.\n", etcdCluster.Name)
+
+	if options.Wait == 0 {
+		return nil
+	}
+
+	fmt.Fprintf(out, "\nWaiting for etcd-manager to complete the restore...\n")
+	timeout := time.Now().Add(options.Wait)
+	for {
+		pending, err := store.IsPending(command)
+		if err != nil {
+			return err
+		}
+		if !pending {
+			fmt.Fprintf(out, "Restore of etcd cluster %q completed.\n", etcdCluster.Name)
+			return nil
+		}
+		if time.Now().After(timeout) {
+			return fmt.Errorf("wait time exceeded before the restore of etcd cluster %q completed", etcdCluster.Name)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(restoreEtcdPollInterval):
+		}
+	}
+}
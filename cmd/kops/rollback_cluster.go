@@ -0,0 +1,228 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kops/cmd/kops/util"
+	kopsapi "k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/commands/commandutils"
+	"k8s.io/kops/pkg/diff"
+	"k8s.io/kops/pkg/history"
+	"k8s.io/kops/pkg/kopscodecs"
+	"k8s.io/kops/pkg/pretty"
+	"k8s.io/kops/upup/pkg/fi/cloudup"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	rollbackClusterLong = pretty.LongDesc(i18n.T(`
+	Restore the cluster and instance group configuration recorded in a previous revision.
+
+	The revisions of a cluster are listed by ` + pretty.Bash("kops get history cluster") + `. Restoring a revision
+	records a single new revision; instance groups that were created after the revision are left in place.
+
+	kops rollback does not update the cloud resources; to apply the changes use ` + pretty.Bash("kops update cluster") + `.`))
+
+	rollbackClusterExample = templates.Examples(i18n.T(`
+	# Preview the changes to restore revision 3 of a cluster's configuration.
+	kops rollback cluster k8s-cluster.example.com --to-revision 3
+
+	# Restore revision 3 of a cluster's configuration.
+	kops rollback cluster k8s-cluster.example.com --to-revision 3 --yes
+	`))
+
+	rollbackClusterShort = i18n.T("Roll back a cluster's configuration to a previous revision.")
+)
+
+type RollbackClusterOptions struct {
+	ClusterName string
+	// ToRevision is the number of the revision to restore.
+	ToRevision int
+	Yes        bool
+}
+
+func NewCmdRollbackCluster(f *util.Factory, out io.Writer) *cobra.Command {
+	options := &RollbackClusterOptions{}
+
+	cmd := &cobra.Command{
+		Use:               "cluster [CLUSTER]",
+		Short:             rollbackClusterShort,
+		Long:              rollbackClusterLong,
+		Example:           rollbackClusterExample,
+		Args:              rootCommand.clusterNameArgs(&options.ClusterName),
+		ValidArgsFunction: commandutils.CompleteClusterName(f, true, false),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return RunRollbackCluster(context.TODO(), f, out, options)
+		},
+	}
+
+	cmd.Flags().IntVar(&options.ToRevision, "to-revision", 0, "Revision to restore")
+	cmd.MarkFlagRequired("to-revision")
+	cmd.RegisterFlagCompletionFunc("to-revision", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	})
+	cmd.Flags().BoolVarP(&options.Yes, "yes", "y", false, "Restore the revision")
+
+	return cmd
+}
+
+func RunRollbackCluster(ctx context.Context, f commandutils.Factory, out io.Writer, options *RollbackClusterOptions) error {
+	clientset, err := f.Clientset()
+	if err != nil {
+		return err
+	}
+
+	cluster, err := clientset.GetCluster(ctx, options.ClusterName)
+	if err != nil {
+		return err
+	}
+
+	historyClient := clientset.HistoryFor(cluster)
+	target, err := getRevision(historyClient, options.ToRevision)
+	if err != nil {
+		return err
+	}
+
+	revisions, err := historyClient.List()
+	if err != nil {
+		return err
+	}
+	latest := revisions[len(revisions)-1]
+	if latest.SameConfig(target) {
+		fmt.Fprintf(out, "The configuration of cluster %q is already the same as revision %d\n", cluster.Name, target.Number)
+		return nil
+	}
+
+	fmt.Fprintf(out, "Changes to restore revision %d (from revision %d):\n", target.Number, latest.Number)
+	fmt.Fprint(out, diff.FormatDiff(latest.Config(), target.Config()))
+
+	restored, instanceGroups, err := decodeRevision(target)
+	if err != nil {
+		return err
+	}
+	if restored.Name != cluster.Name {
+		return fmt.Errorf("revision %d is for cluster %q, not %q", target.Number, restored.Name, cluster.Name)
+	}
+
+	if !options.Yes {
+		fmt.Fprintf(out, "\nMust specify --yes to restore revision %d\n", target.Number)
+		return nil
+	}
+
+	// Retrieve the current status of the cluster.  This will eventually be part of the cluster object.
+	cloud, err := cloudup.BuildCloud(restored)
+	if err != nil {
+		return err
+	}
+	status, err := cloud.FindClusterStatus(restored)
+	if err != nil {
+		return err
+	}
+
+	// The writes are recorded together as a single revision once they are all done
+	writeCtx := history.WithoutRecording(ctx)
+
+	// Fail rather than overwrite changes made since we read the cluster
+	restored.ResourceVersion = cluster.ResourceVersion
+	if _, err := clientset.UpdateCluster(writeCtx, restored, status); err != nil {
+		return fmt.Errorf("error restoring cluster: %v", err)
+	}
+
+	igClient := clientset.InstanceGroupsFor(cluster)
+	current, err := igClient.List(writeCtx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("error listing instance groups: %v", err)
+	}
+	currentByName := make(map[string]*kopsapi.InstanceGroup)
+	for i := range current.Items {
+		currentByName[current.Items[i].Name] = &current.Items[i]
+	}
+
+	for _, ig := range instanceGroups {
+		existing := currentByName[ig.Name]
+		delete(currentByName, ig.Name)
+		if existing == nil {
+			if _, err := igClient.Create(writeCtx, ig, metav1.CreateOptions{}); err != nil {
+				return fmt.Errorf("error restoring instance group %q: %v", ig.Name, err)
+			}
+			continue
+		}
+		ig.ResourceVersion = existing.ResourceVersion
+		if _, err := igClient.Update(writeCtx, ig, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("error restoring instance group %q: %v", ig.Name, err)
+		}
+	}
+
+	if err := historyClient.Record(ctx, fmt.Sprintf("Rolled back to revision %d", target.Number)); err != nil {
+		return fmt.Errorf("error recording revision: %v", err)
+	}
+
+	var extra []string
+	for name := range currentByName {
+		extra = append(extra, name)
+	}
+	sort.Strings(extra)
+	for _, name := range extra {
+		fmt.Fprintf(out, "Instance group %q did not exist in revision %d; delete it with `kops delete instancegroup %s` if it is no longer needed\n", name, target.Number, name)
+	}
+
+	fmt.Fprintf(out, "\nRestored revision %d of the configuration.\n", target.Number)
+	fmt.Fprintf(out, "You can now apply these changes, using `kops update cluster %s`\n", cluster.Name)
+
+	return nil
+}
+
+// decodeRevision parses the cluster and instance groups recorded in a revision
+func decodeRevision(revision *history.Revision) (*kopsapi.Cluster, []*kopsapi.InstanceGroup, error) {
+	o, _, err := kopscodecs.Decode([]byte(revision.Cluster), nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error parsing cluster in revision %d: %v", revision.Number, err)
+	}
+	cluster, ok := o.(*kopsapi.Cluster)
+	if !ok {
+		return nil, nil, fmt.Errorf("unexpected object %T for cluster in revision %d", o, revision.Number)
+	}
+
+	var names []string
+	for name := range revision.InstanceGroups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var instanceGroups []*kopsapi.InstanceGroup
+	for _, name := range names {
+		o, _, err := kopscodecs.Decode([]byte(revision.InstanceGroups[name]), nil)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error parsing instance group %q in revision %d: %v", name, revision.Number, err)
+		}
+		ig, ok := o.(*kopsapi.InstanceGroup)
+		if !ok {
+			return nil, nil, fmt.Errorf("unexpected object %T for instance group %q in revision %d", o, name, revision.Number)
+		}
+		instanceGroups = append(instanceGroups, ig)
+	}
+
+	return cluster, instanceGroups, nil
+}
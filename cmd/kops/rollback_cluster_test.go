@@ -0,0 +1,107 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kops/cloudmock/aws/mockec2"
+	"k8s.io/kops/cmd/kops/util"
+	"k8s.io/kops/pkg/testutils"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup/awsup"
+	"k8s.io/kops/util/pkg/vfs"
+)
+
+func TestRollbackCluster(t *testing.T) {
+	ctx := context.Background()
+	vfs.Context.ResetMemfsContext(true)
+	cloud := awsup.InstallMockAWSCloud("us-mock-1", "abc")
+	cloud.MockEC2 = &mockec2.MockEC2{}
+
+	f := util.NewFactory(&util.FactoryOptions{RegistryPath: "memfs://unittest-bucket"})
+	clientset, err := f.Clientset()
+	if err != nil {
+		t.Fatalf("error building clientset: %v", err)
+	}
+
+	// Revisions 1 and 2
+	cluster, err := clientset.CreateCluster(ctx, testutils.BuildMinimalCluster("test.k8s.local"))
+	if err != nil {
+		t.Fatalf("error creating cluster: %v", err)
+	}
+	ig := testutils.BuildMinimalNodeInstanceGroup("nodes", "subnet-us-mock-1a")
+	ig.Spec.Image = "ubuntu/images/hvm-ssd/ubuntu-focal-20.04-amd64-server-20220404"
+	ig.Spec.MaxSize = fi.Int32(2)
+	igClient := clientset.InstanceGroupsFor(cluster)
+	if _, err := igClient.Create(ctx, &ig, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("error creating instance group: %v", err)
+	}
+
+	// Revisions 3 and 4
+	cluster.Spec.KubernetesVersion = "1.24.0"
+	if cluster, err = clientset.UpdateCluster(ctx, cluster, nil); err != nil {
+		t.Fatalf("error updating cluster: %v", err)
+	}
+	updated, err := igClient.Get(ctx, "nodes", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("error getting instance group: %v", err)
+	}
+	updated.Spec.MaxSize = fi.Int32(5)
+	if _, err := igClient.Update(ctx, updated, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("error updating instance group: %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := RunRollbackCluster(ctx, f, &out, &RollbackClusterOptions{ClusterName: cluster.Name, ToRevision: 2, Yes: true}); err != nil {
+		t.Fatalf("error rolling back cluster: %v\n%s", err, out.String())
+	}
+
+	restored, err := clientset.GetCluster(ctx, cluster.Name)
+	if err != nil {
+		t.Fatalf("error getting cluster: %v", err)
+	}
+	if restored.Spec.KubernetesVersion != "1.14.6" {
+		t.Errorf("expected kubernetesVersion 1.14.6 to be restored, got %q", restored.Spec.KubernetesVersion)
+	}
+	restoredIG, err := igClient.Get(ctx, "nodes", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("error getting instance group: %v", err)
+	}
+	if fi.Int32Value(restoredIG.Spec.MaxSize) != 2 {
+		t.Errorf("expected maxSize 2 to be restored, got %d", fi.Int32Value(restoredIG.Spec.MaxSize))
+	}
+
+	revisions, err := clientset.HistoryFor(cluster).List()
+	if err != nil {
+		t.Fatalf("error listing revisions: %v", err)
+	}
+	if len(revisions) != 5 {
+		t.Fatalf("expected the rollback to record a single revision, got %d revisions", len(revisions))
+	}
+	latest := revisions[4]
+	if latest.Number != 5 || latest.Change != "Rolled back to revision 2" {
+		t.Errorf("unexpected revision %d %q", latest.Number, latest.Change)
+	}
+	if latest.InstanceGroups["nodes"] == revisions[3].InstanceGroups["nodes"] {
+		t.Errorf("expected the restored instance group to be recorded")
+	}
+
+}
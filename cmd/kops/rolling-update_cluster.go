@@ -35,10 +35,12 @@ import (
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 	"k8s.io/kops/cmd/kops/util"
 	kopsapi "k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/client/simple"
 	"k8s.io/kops/pkg/cloudinstances"
 	"k8s.io/kops/pkg/commands/commandutils"
 	"k8s.io/kops/pkg/instancegroups"
 	"k8s.io/kops/pkg/pretty"
+	"k8s.io/kops/pkg/rollout"
 	"k8s.io/kops/pkg/validation"
 	"k8s.io/kops/upup/pkg/fi/cloudup"
 	"k8s.io/kops/util/pkg/tables"
@@ -86,6 +88,10 @@ var (
 		# Update only the "nodes-1a" instance group of the k8s-cluster.example.com kOps cluster.
 		kops rolling-update cluster k8s-cluster.example.com --yes \
 		  --instance-group nodes-1a
+
+		# Resume an interrupted rolling update of the k8s-cluster.example.com kOps cluster,
+		# skipping the instance groups it already completed.
+		kops rolling-update cluster k8s-cluster.example.com --yes --resume
 		`))
 
 	rollingupdateShort = i18n.T(`Rolling update a cluster.`)
@@ -140,6 +146,14 @@ type RollingUpdateOptions struct {
 	// InstanceGroupRoles is the list of roles we should rolling-update
 	// if not specified, all instance groups will be updated
 	InstanceGroupRoles []string
+
+	// Resume continues the most recent rolling update that did not complete,
+	// using its options and skipping the instance groups it already completed.
+	Resume bool
+
+	// Output is the output format: table prints the instance groups to update,
+	// json streams the events of the rolling update as one JSON object per line.
+	Output string
 }
 
 func (o *RollingUpdateOptions) InitDefaults() {
@@ -159,6 +173,8 @@ func (o *RollingUpdateOptions) InitDefaults() {
 	o.ValidateCount = 2
 
 	o.DrainTimeout = 15 * time.Minute
+
+	o.Output = OutputTable
 }
 
 func NewCmdRollingUpdateCluster(f *util.Factory, out io.Writer) *cobra.Command {
@@ -201,6 +217,12 @@ func NewCmdRollingUpdateCluster(f *util.Factory, out io.Writer) *cobra.Command {
 		return sets.NewString(allRoles...).Delete(options.InstanceGroupRoles...).List(), cobra.ShellCompDirectiveNoFileComp
 	})
 
+	cmd.Flags().BoolVar(&options.Resume, "resume", options.Resume, "Resume the most recent rolling update that did not complete")
+	cmd.Flags().StringVarP(&options.Output, "output", "o", options.Output, "Output format. One of: table, json. With json, the events of the rolling update are written as one JSON object per line")
+	cmd.RegisterFlagCompletionFunc("output", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{OutputTable, OutputJSON}, cobra.ShellCompDirectiveNoFileComp
+	})
+
 	cmd.Flags().BoolVar(&options.FailOnDrainError, "fail-on-drain-error", true, "Fail if draining a node fails")
 	cmd.Flags().BoolVar(&options.FailOnValidate, "fail-on-validate-error", true, "Fail if the cluster fails to validate")
 
@@ -218,6 +240,18 @@ func NewCmdRollingUpdateCluster(f *util.Factory, out io.Writer) *cobra.Command {
 }
 
 func RunRollingUpdateCluster(ctx context.Context, f *util.Factory, out io.Writer, options *RollingUpdateOptions) error {
+	switch options.Output {
+	case OutputTable, OutputJSON:
+	default:
+		return fmt.Errorf("unknown output format: %q", options.Output)
+	}
+
+	// With JSON output, stdout is reserved for the events; messages for humans go to stderr.
+	messages := out
+	if options.Output == OutputJSON {
+		messages = os.Stderr
+	}
+
 	clientset, err := f.Clientset()
 	if err != nil {
 		return err
@@ -228,6 +262,24 @@ func RunRollingUpdateCluster(ctx context.Context, f *util.Factory, out io.Writer
 		return err
 	}
 
+	rollouts := clientset.RolloutsFor(cluster)
+	var record *rollout.Record
+	if options.Resume {
+		record, err = findResumableRollout(rollouts)
+		if err != nil {
+			return err
+		}
+		if record == nil {
+			return fmt.Errorf("no incomplete rolling update found for cluster %q", cluster.ObjectMeta.Name)
+		}
+		fmt.Fprintf(messages, "Resuming rolling update %q started at %s; %d instance groups already completed.\n", record.Name, record.StartedAt.Format(time.RFC3339), len(record.CompletedGroups))
+
+		options.Force = record.Options.Force
+		options.CloudOnly = record.Options.CloudOnly
+		options.InstanceGroups = record.Options.InstanceGroups
+		options.InstanceGroupRoles = record.Options.InstanceGroupRoles
+	}
+
 	contextName := cluster.ObjectMeta.Name
 	clientGetter := genericclioptions.NewConfigFlags(true)
 	clientGetter.Context = &contextName
@@ -353,7 +405,7 @@ func RunRollingUpdateCluster(ctx context.Context, f *util.Factory, out io.Writer
 		return err
 	}
 
-	{
+	if options.Output == OutputTable {
 		t := &tables.Table{}
 		t.AddColumn("NAME", func(r *cloudinstances.CloudInstanceGroup) string {
 			return r.InstanceGroup.ObjectMeta.Name
@@ -413,15 +465,44 @@ func RunRollingUpdateCluster(ctx context.Context, f *util.Factory, out io.Writer
 	}
 
 	if !needUpdate && !options.Force {
-		fmt.Printf("\nNo rolling-update required.\n")
+		fmt.Fprintf(messages, "\nNo rolling-update required.\n")
+		if record != nil && options.Yes {
+			record.Status = rollout.StatusCompleted
+			record.Phase = rollout.PhaseCompleted
+			record.UpdatedAt = time.Now()
+			if err := rollouts.Write(record); err != nil {
+				return err
+			}
+		}
 		return nil
 	}
 
 	if !options.Yes {
-		fmt.Printf("\nMust specify --yes to rolling-update.\n")
+		fmt.Fprintf(messages, "\nMust specify --yes to rolling-update.\n")
 		return nil
 	}
 
+	if record == nil {
+		record = rollout.NewRecord(time.Now(), rollout.Options{
+			Force:              options.Force,
+			CloudOnly:          options.CloudOnly,
+			InstanceGroups:     options.InstanceGroups,
+			InstanceGroupRoles: options.InstanceGroupRoles,
+		})
+	} else {
+		record.Resumed++
+		record.Status = rollout.StatusInProgress
+		record.Error = ""
+	}
+	d.Rollout = record
+	d.Rollouts = rollouts
+
+	if options.Output == OutputJSON {
+		d.Events = rollout.NewJSONEventSink(out)
+		// The output of the drains would corrupt the stream of events
+		d.DrainOut = os.Stderr
+	}
+
 	var clusterValidator validation.ClusterValidator
 	if !options.CloudOnly {
 		clusterValidator, err = validation.NewClusterValidator(cluster, cloud, list, config.Host, k8sClient)
@@ -434,6 +515,22 @@ func RunRollingUpdateCluster(ctx context.Context, f *util.Factory, out io.Writer
 	return d.RollingUpdate(groups, list)
 }
 
+// findResumableRollout returns the most recent rolling update, if it did not complete.
+func findResumableRollout(rollouts simple.RolloutsClient) (*rollout.Record, error) {
+	records, err := rollouts.List()
+	if err != nil {
+		return nil, fmt.Errorf("error listing rolling updates: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+	latest := records[len(records)-1]
+	if latest.Status == rollout.StatusCompleted {
+		return nil, nil
+	}
+	return latest, nil
+}
+
 func completeInstanceGroup(f commandutils.Factory, selectedInstanceGroups *[]string, selectedInstanceGroupRoles *[]string) func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		commandutils.ConfigureKlogForCompletion()
@@ -0,0 +1,414 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/kops/cmd/kops/util"
+	kopsapi "k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/carotation"
+	"k8s.io/kops/pkg/client/simple"
+	"k8s.io/kops/pkg/commands/commandutils"
+	"k8s.io/kops/pkg/kubeconfig"
+	"k8s.io/kops/pkg/pki"
+	"k8s.io/kops/pkg/pretty"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	rotateCALong = pretty.LongDesc(i18n.T(`
+	Rotate the keypairs of a keyset, or of all rotatable keysets.
+
+	The rotation performs the procedure described in the documentation on rotating secrets:
+	it creates new keypairs, applies the cluster and performs a rolling update so that the new
+	keypairs are trusted, promotes them to primary, applies and rolls the cluster again, and
+	finally distrusts the previous keypairs and applies and rolls the cluster once more.
+	The kubeconfig is regenerated after each stage, and the cluster is validated after each
+	rolling update.
+
+	Progress is recorded in the state store. If the rotation is interrupted or fails,
+	running the command again resumes it from the phase that did not complete.
+
+	Clients of the Kubernetes API other than the exported kubeconfig must be given the new
+	` + pretty.Bash("certificate-authority-data") + ` before the keypairs are promoted; use
+	` + pretty.Bash("--pause-before-promote") + ` to stop the rotation at that point.`))
+
+	rotateCAExample = templates.Examples(i18n.T(`
+	# Preview the rotation of all rotatable keysets.
+	kops rotate ca --name k8s-cluster.example.com
+
+	# Rotate all rotatable keysets, exporting an admin credential after each stage.
+	kops rotate ca --name k8s-cluster.example.com --admin --yes
+
+	# Rotate only the kubernetes-ca keyset.
+	kops rotate ca --name k8s-cluster.example.com --keyset kubernetes-ca --yes
+	`))
+
+	rotateCAShort = i18n.T(`Rotate the cluster's certificate authorities.`)
+)
+
+type RotateCAOptions struct {
+	ClusterName string
+	Keyset      string
+	Yes         bool
+
+	// PauseBeforePromote stops the rotation once the new keypairs are trusted, so that clients can be given the new CA certificates.
+	PauseBeforePromote bool
+
+	// ValidationTimeout is the time to wait for the cluster to validate after each rolling update.
+	ValidationTimeout time.Duration
+
+	KubeConfigPath string
+	admin          time.Duration
+}
+
+func (o *RotateCAOptions) InitDefaults() {
+	o.Keyset = "all"
+	o.ValidationTimeout = 15 * time.Minute
+}
+
+// NewCmdRotateCA returns a rotate ca command.
+func NewCmdRotateCA(f *util.Factory, out io.Writer) *cobra.Command {
+	options := &RotateCAOptions{}
+	options.InitDefaults()
+
+	cmd := &cobra.Command{
+		Use:               "ca [CLUSTER]",
+		Short:             rotateCAShort,
+		Long:              rotateCALong,
+		Example:           rotateCAExample,
+		Args:              rootCommand.clusterNameArgs(&options.ClusterName),
+		ValidArgsFunction: commandutils.CompleteClusterName(f, true, false),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return RunRotateCA(context.TODO(), f, out, options)
+		},
+	}
+
+	cmd.Flags().StringVar(&options.Keyset, "keyset", options.Keyset, "Keyset to rotate, or \"all\" for all rotatable keysets")
+	cmd.RegisterFlagCompletionFunc("keyset", completeRotateCAKeyset(f))
+	cmd.Flags().BoolVarP(&options.Yes, "yes", "y", options.Yes, "Perform the rotation without confirmation")
+	cmd.Flags().BoolVar(&options.PauseBeforePromote, "pause-before-promote", options.PauseBeforePromote, "Stop before promoting the new keypairs, so that the new CA certificates can be distributed")
+	cmd.Flags().DurationVar(&options.ValidationTimeout, "validation-timeout", options.ValidationTimeout, "Maximum time to wait for the cluster to validate after each rolling update")
+	cmd.Flags().StringVar(&options.KubeConfigPath, "kubeconfig", options.KubeConfigPath, "Filename of the kubeconfig to regenerate")
+	cmd.Flags().DurationVar(&options.admin, "admin", options.admin, "Also export a cluster admin user credential with the specified lifetime when regenerating the kubeconfig")
+	cmd.Flags().Lookup("admin").NoOptDefVal = kubeconfig.DefaultKubecfgAdminLifetime.String()
+
+	return cmd
+}
+
+// RunRotateCA rotates the keypairs of a keyset, resuming the rotation in progress if there is one.
+func RunRotateCA(ctx context.Context, f *util.Factory, out io.Writer, options *RotateCAOptions) error {
+	if !rotatableKeysetFilter(options.Keyset, nil) {
+		return fmt.Errorf("rotating keyset %q is not supported", options.Keyset)
+	}
+
+	clientset, err := f.Clientset()
+	if err != nil {
+		return err
+	}
+
+	cluster, err := GetCluster(ctx, f, options.ClusterName)
+	if err != nil {
+		return err
+	}
+
+	rotations := clientset.CARotationsFor(cluster)
+	record, err := findResumableRotation(rotations)
+	if err != nil {
+		return err
+	}
+	if record != nil && record.Keyset != options.Keyset {
+		return fmt.Errorf("rotation %q of keyset %q is in progress; specify --keyset=%s to resume it", record.Name, record.Keyset, record.Keyset)
+	}
+
+	if !options.Yes {
+		if record != nil {
+			fmt.Fprintf(out, "Will resume rotation %q of keyset %q, started at %s.\n", record.Name, record.Keyset, record.StartedAt.Format(time.RFC3339))
+			if record.Error != "" {
+				fmt.Fprintf(out, "The rotation stopped with error: %s\n", record.Error)
+			}
+		} else {
+			fmt.Fprintf(out, "Will rotate keyset %q.\n", options.Keyset)
+		}
+		printRotationPhases(out, record)
+		fmt.Fprintf(out, "\nMust specify --yes to rotate\n")
+		return nil
+	}
+
+	if record != nil {
+		record.Resumed++
+		fmt.Fprintf(out, "Resuming rotation %q at phase %s.\n", record.Name, record.Phase)
+	} else {
+		record = carotation.NewRecord(time.Now(), options.Keyset)
+		fmt.Fprintf(out, "Starting rotation %q of keyset %q.\n", record.Name, record.Keyset)
+	}
+	if err := rotations.Write(record); err != nil {
+		return err
+	}
+
+	for record.Phase != carotation.PhaseCompleted {
+		if record.Phase == carotation.PhasePromoteKeypairs && options.PauseBeforePromote {
+			fmt.Fprintf(out, "\nThe new keypairs are trusted. Distribute the new certificate-authority-data to the clients of the cluster,\n")
+			fmt.Fprintf(out, "then run \"kops rotate ca\" without --pause-before-promote to continue the rotation.\n")
+			return nil
+		}
+
+		fmt.Fprintf(out, "\nPhase %s\n", record.Phase)
+		if err := runRotationPhase(ctx, f, out, clientset, cluster, options, record); err != nil {
+			record.Fail(time.Now(), err)
+			if writeErr := rotations.Write(record); writeErr != nil {
+				return fmt.Errorf("error recording failure of rotation (%v): %v", err, writeErr)
+			}
+			return fmt.Errorf("rotation failed in phase %s: %v; run \"kops rotate ca\" again to resume it", record.Phase, err)
+		}
+
+		if err := record.Advance(time.Now()); err != nil {
+			return err
+		}
+		if err := rotations.Write(record); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(out, "\nRotation %q of keyset %q completed.\n", record.Name, record.Keyset)
+	return nil
+}
+
+// runRotationPhase performs the current phase of the rotation
+func runRotationPhase(ctx context.Context, f *util.Factory, out io.Writer, clientset simple.Clientset, cluster *kopsapi.Cluster, options *RotateCAOptions, record *carotation.Record) error {
+	switch record.Phase {
+	case carotation.PhaseCreateKeypairs:
+		return createRotationKeypairs(out, clientset, cluster, record)
+
+	case carotation.PhasePromoteKeypairs:
+		keyStore, err := clientset.KeyStore(cluster)
+		if err != nil {
+			return err
+		}
+		for _, keyset := range record.Keysets {
+			if err := promoteKeypair(out, keyset.Name, keyset.NewKeypair, keyStore); err != nil {
+				return fmt.Errorf("promoting keypair for %s: %v", keyset.Name, err)
+			}
+		}
+		return nil
+
+	case carotation.PhaseDistrustKeypairs:
+		keyStore, err := clientset.KeyStore(cluster)
+		if err != nil {
+			return err
+		}
+		for _, keyset := range record.Keysets {
+			current, err := keyStore.FindKeyset(keyset.Name)
+			if err != nil {
+				return err
+			}
+			if current == nil || current.Primary == nil || current.Primary.Id != keyset.NewKeypair {
+				return fmt.Errorf("the primary keypair of keyset %q is no longer %s, the keypair created by the rotation", keyset.Name, keyset.NewKeypair)
+			}
+			if err := distrustKeypair(out, keyset.Name, nil, keyStore); err != nil {
+				return fmt.Errorf("distrusting keypair for %s: %v", keyset.Name, err)
+			}
+		}
+		return nil
+
+	case carotation.PhaseUpdateTrust, carotation.PhaseUpdatePromoted, carotation.PhaseUpdateDistrusted:
+		return applyAndRollForRotation(ctx, f, out, clientset, cluster, options, record)
+
+	case carotation.PhaseExportTrust, carotation.PhaseExportPromoted, carotation.PhaseExportDistrusted:
+		return RunExportKubeconfig(ctx, f, out, &ExportKubeconfigOptions{
+			ClusterName:    options.ClusterName,
+			KubeConfigPath: options.KubeConfigPath,
+			admin:          options.admin,
+		}, nil)
+
+	default:
+		return fmt.Errorf("unknown phase %q", record.Phase)
+	}
+}
+
+// createRotationKeypairs adds a new keypair to each keyset being rotated, recording the new keypairs as it goes
+// so that a resumed rotation does not create them again.
+func createRotationKeypairs(out io.Writer, clientset simple.Clientset, cluster *kopsapi.Cluster, record *carotation.Record) error {
+	keyStore, err := clientset.KeyStore(cluster)
+	if err != nil {
+		return err
+	}
+
+	names := []string{record.Keyset}
+	if record.Keyset == "all" {
+		keysets, err := keyStore.ListKeysets()
+		if err != nil {
+			return fmt.Errorf("listing keysets: %v", err)
+		}
+		names = nil
+		for name := range keysets {
+			if rotatableKeysetFilter(name, nil) {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+	}
+
+	rotations := clientset.CARotationsFor(cluster)
+	for _, name := range names {
+		keyset, err := keyStore.FindKeyset(name)
+		if err != nil {
+			return fmt.Errorf("reading keyset %s: %v", name, err)
+		}
+		if keyset == nil || keyset.Primary == nil {
+			return fmt.Errorf("keyset %q not found", name)
+		}
+
+		// The ID of the new keypair is recorded before the keypair is created,
+		// so that a resumed rotation creates the same keypair rather than another one.
+		recorded := record.FindKeyset(name)
+		if recorded == nil {
+			record.Keysets = append(record.Keysets, carotation.Keyset{
+				Name:            name,
+				PreviousPrimary: keyset.Primary.Id,
+				NewKeypair:      pki.BuildPKISerial(time.Now().UnixNano()).String(),
+			})
+			if err := rotations.Write(record); err != nil {
+				return err
+			}
+			recorded = record.FindKeyset(name)
+		}
+		if keyset.Items[recorded.NewKeypair] != nil {
+			continue
+		}
+
+		serial, ok := new(big.Int).SetString(recorded.NewKeypair, 10)
+		if !ok {
+			return fmt.Errorf("invalid keypair ID %q recorded for %s", recorded.NewKeypair, name)
+		}
+		if _, err := createKeypair(out, &CreateKeypairOptions{serial: serial}, name, keyStore); err != nil {
+			return fmt.Errorf("creating keypair for %s: %v", name, err)
+		}
+	}
+
+	return nil
+}
+
+// applyAndRollForRotation applies the cluster configuration, performs a rolling update and validates the cluster.
+// A rolling update left incomplete by an earlier attempt at the rotation is resumed.
+func applyAndRollForRotation(ctx context.Context, f *util.Factory, out io.Writer, clientset simple.Clientset, cluster *kopsapi.Cluster, options *RotateCAOptions, record *carotation.Record) error {
+	updateOptions := &UpdateClusterOptions{}
+	updateOptions.InitDefaults()
+	updateOptions.ClusterName = options.ClusterName
+	updateOptions.Yes = true
+	updateOptions.CreateKubecfg = false
+	if _, err := RunUpdateCluster(ctx, f, out, updateOptions); err != nil {
+		return fmt.Errorf("updating cluster: %v", err)
+	}
+
+	rollingUpdateOptions := &RollingUpdateOptions{}
+	rollingUpdateOptions.InitDefaults()
+	rollingUpdateOptions.ClusterName = options.ClusterName
+	rollingUpdateOptions.Yes = true
+	// Every node must restart onto the new trust bundle, even if its launch configuration did not change
+	rollingUpdateOptions.Force = true
+	rollingUpdateOptions.ValidationTimeout = options.ValidationTimeout
+
+	incomplete, err := findResumableRollout(clientset.RolloutsFor(cluster))
+	if err != nil {
+		return err
+	}
+	if incomplete != nil && incomplete.StartedAt.After(record.StartedAt) {
+		rollingUpdateOptions.Resume = true
+	}
+	if err := RunRollingUpdateCluster(ctx, f, out, rollingUpdateOptions); err != nil {
+		return fmt.Errorf("rolling update: %v", err)
+	}
+
+	validateOptions := &ValidateClusterOptions{}
+	validateOptions.InitDefaults()
+	validateOptions.ClusterName = options.ClusterName
+	validateOptions.wait = options.ValidationTimeout
+	validateOptions.kubeconfig = options.KubeConfigPath
+	if _, err := RunValidateCluster(ctx, f, out, validateOptions); err != nil {
+		return fmt.Errorf("validating cluster: %v", err)
+	}
+
+	return nil
+}
+
+// findResumableRotation returns the most recent rotation if it has not completed
+func findResumableRotation(rotations simple.CARotationsClient) (*carotation.Record, error) {
+	records, err := rotations.List()
+	if err != nil {
+		return nil, fmt.Errorf("error listing CA rotations: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+	latest := records[len(records)-1]
+	if latest.Status == carotation.StatusCompleted {
+		return nil, nil
+	}
+	return latest, nil
+}
+
+// printRotationPhases prints the phases of the rotation, marking the ones that have completed
+func printRotationPhases(out io.Writer, record *carotation.Record) {
+	if record != nil {
+		for _, keyset := range record.Keysets {
+			fmt.Fprintf(out, "  keyset %s: previous primary %s, new keypair %s\n", keyset.Name, keyset.PreviousPrimary, keyset.NewKeypair)
+		}
+	}
+
+	next := carotation.Phases[0]
+	if record != nil {
+		next = record.Phase
+	}
+
+	fmt.Fprintf(out, "\nPhases:\n")
+	status := "completed"
+	for _, phase := range carotation.Phases {
+		if phase == carotation.PhaseCompleted {
+			break
+		}
+		if phase == next {
+			fmt.Fprintf(out, "  %-20s %s\n", phase, "next")
+			status = "pending"
+			continue
+		}
+		fmt.Fprintf(out, "  %-20s %s\n", phase, status)
+	}
+}
+
+func completeRotateCAKeyset(f commandutils.Factory) func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		commandutils.ConfigureKlogForCompletion()
+		ctx := context.TODO()
+
+		cluster, clientSet, completions, directive := GetClusterForCompletion(ctx, f, args)
+		if cluster == nil {
+			return completions, directive
+		}
+
+		_, _, completions, directive = completeKeyset(cluster, clientSet, nil, rotatableKeysetFilter)
+		return completions, directive
+	}
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"k8s.io/kops/pkg/carotation"
+	"k8s.io/kops/pkg/client/simple/vfsclientset"
+	"k8s.io/kops/pkg/pki"
+	"k8s.io/kops/pkg/testutils"
+	"k8s.io/kops/util/pkg/vfs"
+)
+
+func TestRotateCAKeypairPhases(t *testing.T) {
+	ctx := context.Background()
+	vfs.Context.ResetMemfsContext(true)
+	basePath, err := vfs.Context.BuildVfsPath("memfs://unittest-bucket")
+	if err != nil {
+		t.Fatalf("error building path: %v", err)
+	}
+	clientset := vfsclientset.NewVFSClientset(basePath)
+	cluster := testutils.BuildMinimalCluster("test.k8s.local")
+	if _, err := clientset.CreateCluster(ctx, cluster); err != nil {
+		t.Fatalf("error creating cluster: %v", err)
+	}
+
+	keyStore, err := clientset.KeyStore(cluster)
+	if err != nil {
+		t.Fatalf("error building keystore: %v", err)
+	}
+	var out bytes.Buffer
+	previous := map[string]string{}
+	for _, name := range []string{"kubernetes-ca", "service-account", "kubelet"} {
+		item, err := createKeypair(&out, &CreateKeypairOptions{Primary: true}, name, keyStore)
+		if err != nil {
+			t.Fatalf("error creating keyset %s: %v", name, err)
+		}
+		previous[name] = item.Id
+	}
+
+	record := carotation.NewRecord(time.Now(), "all")
+	if err := createRotationKeypairs(&out, clientset, cluster, record); err != nil {
+		t.Fatalf("error creating keypairs: %v", err)
+	}
+	if len(record.Keysets) != 2 || record.Keysets[0].Name != "kubernetes-ca" || record.Keysets[1].Name != "service-account" {
+		t.Fatalf("expected the rotatable keysets to be recorded, got %v", record.Keysets)
+	}
+	for _, keyset := range record.Keysets {
+		if keyset.PreviousPrimary != previous[keyset.Name] || keyset.NewKeypair == "" || keyset.NewKeypair == keyset.PreviousPrimary {
+			t.Errorf("unexpected record of keyset %s: %+v", keyset.Name, keyset)
+		}
+	}
+
+	// Resuming the phase must not add further keypairs
+	if err := createRotationKeypairs(&out, clientset, cluster, record); err != nil {
+		t.Fatalf("error resuming creation of keypairs: %v", err)
+	}
+	keyset, err := keyStore.FindKeyset("kubernetes-ca")
+	if err != nil {
+		t.Fatalf("error reading keyset: %v", err)
+	}
+	if len(keyset.Items) != 2 || keyset.Primary.Id != previous["kubernetes-ca"] {
+		t.Fatalf("expected a single secondary keypair to be added, got %d items with primary %s", len(keyset.Items), keyset.Primary.Id)
+	}
+
+	record.Phase = carotation.PhasePromoteKeypairs
+	if err := runRotationPhase(ctx, nil, &out, clientset, cluster, &RotateCAOptions{}, record); err != nil {
+		t.Fatalf("error promoting keypairs: %v", err)
+	}
+	record.Phase = carotation.PhaseDistrustKeypairs
+	if err := runRotationPhase(ctx, nil, &out, clientset, cluster, &RotateCAOptions{}, record); err != nil {
+		t.Fatalf("error distrusting keypairs: %v", err)
+	}
+
+	// The keystore caches the kubernetes-ca keyset, so we read the results through a new one
+	keyStore, err = clientset.KeyStore(cluster)
+	if err != nil {
+		t.Fatalf("error building keystore: %v", err)
+	}
+	for _, rotated := range record.Keysets {
+		keyset, err := keyStore.FindKeyset(rotated.Name)
+		if err != nil {
+			t.Fatalf("error reading keyset: %v", err)
+		}
+		if keyset.Primary.Id != rotated.NewKeypair {
+			t.Errorf("expected keyset %s to have primary %s, got %s", rotated.Name, rotated.NewKeypair, keyset.Primary.Id)
+		}
+		if keyset.Items[rotated.PreviousPrimary].DistrustTimestamp == nil {
+			t.Errorf("expected previous primary of keyset %s to be distrusted", rotated.Name)
+		}
+	}
+}
+
+func TestRotateCACreatesRecordedKeypair(t *testing.T) {
+	ctx := context.Background()
+	vfs.Context.ResetMemfsContext(true)
+	basePath, err := vfs.Context.BuildVfsPath("memfs://unittest-bucket")
+	if err != nil {
+		t.Fatalf("error building path: %v", err)
+	}
+	clientset := vfsclientset.NewVFSClientset(basePath)
+	cluster := testutils.BuildMinimalCluster("test.k8s.local")
+	if _, err := clientset.CreateCluster(ctx, cluster); err != nil {
+		t.Fatalf("error creating cluster: %v", err)
+	}
+	keyStore, err := clientset.KeyStore(cluster)
+	if err != nil {
+		t.Fatalf("error building keystore: %v", err)
+	}
+	var out bytes.Buffer
+	item, err := createKeypair(&out, &CreateKeypairOptions{Primary: true}, "kubernetes-ca", keyStore)
+	if err != nil {
+		t.Fatalf("error creating keyset: %v", err)
+	}
+
+	// An earlier attempt recorded the new keypair, but failed before creating it
+	newKeypair := pki.BuildPKISerial(time.Now().UnixNano()).String()
+	record := carotation.NewRecord(time.Now(), "kubernetes-ca")
+	record.Keysets = []carotation.Keyset{{Name: "kubernetes-ca", PreviousPrimary: item.Id, NewKeypair: newKeypair}}
+	if err := createRotationKeypairs(&out, clientset, cluster, record); err != nil {
+		t.Fatalf("error creating keypairs: %v", err)
+	}
+
+	keyset, err := keyStore.FindKeyset("kubernetes-ca")
+	if err != nil {
+		t.Fatalf("error reading keyset: %v", err)
+	}
+	if len(keyset.Items) != 2 || keyset.Items[newKeypair] == nil {
+		t.Errorf("expected the recorded keypair %s to be created, got %d items", newKeypair, len(keyset.Items))
+	}
+	if len(record.Keysets) != 1 {
+		t.Errorf("expected the record to be unchanged, got %v", record.Keysets)
+	}
+}
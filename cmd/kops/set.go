@@ -0,0 +1,58 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"io"
+
+	"github.com/spf13/cobra"
+	"k8s.io/kops/cmd/kops/util"
+	"k8s.io/kops/pkg/pretty"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	setLong = pretty.LongDesc(i18n.T(`Set a configuration field.
+
+	kops set does not update the cloud resources; to apply the changes use ` + pretty.Bash("kops update cluster") + `.`))
+
+	setExample = templates.Examples(i18n.T(`
+	# Set cluster to run kubernetes version 1.25.3
+	kops set cluster k8s-cluster.example.com spec.kubernetesVersion=1.25.3
+
+	# Set the maximum size of the nodes instance group
+	kops set instancegroup --name k8s-cluster.example.com nodes spec.maxSize=10
+	`))
+
+	setShort = i18n.T(`Set fields on clusters and other resources.`)
+)
+
+func NewCmdSet(f *util.Factory, out io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "set",
+		Short:   setShort,
+		Long:    setLong,
+		Example: setExample,
+	}
+
+	// create subcommands
+	cmd.AddCommand(NewCmdSetCluster(f, out))
+	cmd.AddCommand(NewCmdSetInstancegroup(f, out))
+
+	return cmd
+}
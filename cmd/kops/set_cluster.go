@@ -0,0 +1,106 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8s.io/kops/cmd/kops/util"
+	"k8s.io/kops/pkg/commands"
+	"k8s.io/kops/pkg/commands/commandutils"
+	"k8s.io/kops/pkg/pretty"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	setClusterLong = pretty.LongDesc(i18n.T(`Set a cluster field value.
+
+	This command changes the desired cluster configuration in the registry.
+	The configuration is validated before it is written.
+
+	kops set does not update the cloud resources; to apply the changes use ` + pretty.Bash("kops update cluster") + `.`))
+
+	setClusterExample = templates.Examples(i18n.T(`
+	# Set cluster to run kubernetes version 1.25.3
+	kops set cluster k8s-cluster.example.com spec.kubernetesVersion=1.25.3
+
+	# Set several fields at once
+	kops set cluster k8s-cluster.example.com spec.kubelet.authorizationMode=Webhook spec.kubelet.authenticationTokenWebhook=true
+	`))
+
+	setClusterShort = i18n.T(`Set cluster fields.`)
+)
+
+// NewCmdSetCluster builds a cobra command for the kops set cluster command
+func NewCmdSetCluster(f *util.Factory, out io.Writer) *cobra.Command {
+	options := &commands.SetClusterOptions{}
+
+	cmd := &cobra.Command{
+		Use:     "cluster [CLUSTER] KEY=VALUE...",
+		Short:   setClusterShort,
+		Long:    setClusterLong,
+		Example: setClusterExample,
+		Args: func(cmd *cobra.Command, args []string) error {
+			// The cluster name is optional, and is the first argument if present
+			if len(args) > 0 && !strings.Contains(args[0], "=") {
+				if err := rootCommand.ProcessArgs(args[:1]); err != nil {
+					return err
+				}
+				args = args[1:]
+			}
+
+			options.ClusterName = rootCommand.ClusterName(true)
+			if options.ClusterName == "" {
+				return fmt.Errorf("--name is required")
+			}
+
+			if len(args) == 0 {
+				return fmt.Errorf("must specify at least one field to set")
+			}
+			for _, arg := range args {
+				if !strings.Contains(arg, "=") {
+					return fmt.Errorf("unhandled field %q: fields must be of the form KEY=VALUE", arg)
+				}
+			}
+			options.Fields = args
+
+			return nil
+		},
+		ValidArgsFunction: completeClusterThenFields(f),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return commands.RunSetCluster(context.TODO(), f, out, options)
+		},
+	}
+
+	return cmd
+}
+
+// completeClusterThenFields completes the optional cluster name, which is the first argument
+func completeClusterThenFields(f commandutils.Factory) func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	completeClusterName := commandutils.CompleteClusterName(f, true, false)
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 && !strings.Contains(toComplete, "=") && !isFieldPath(toComplete) {
+			return completeClusterName(cmd, args, toComplete)
+		}
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+}
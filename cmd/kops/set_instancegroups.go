@@ -0,0 +1,100 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8s.io/kops/cmd/kops/util"
+	"k8s.io/kops/pkg/commands"
+	"k8s.io/kops/pkg/pretty"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	setInstancegroupLong = pretty.LongDesc(i18n.T(`Set an instance group field value.
+
+	This command changes the desired instance group configuration in the registry.
+	The configuration is validated before it is written.
+
+	kops set does not update the cloud resources; to apply the changes use ` + pretty.Bash("kops update cluster") + `.`))
+
+	setInstancegroupExample = templates.Examples(i18n.T(`
+	# Set the maximum size of the nodes instance group
+	kops set instancegroup --name k8s-cluster.example.com nodes spec.maxSize=10
+	`))
+
+	setInstancegroupShort = i18n.T(`Set instancegroup fields.`)
+)
+
+// NewCmdSetInstancegroup builds a cobra command for the kops set instancegroup command
+func NewCmdSetInstancegroup(f *util.Factory, out io.Writer) *cobra.Command {
+	options := &commands.SetInstanceGroupOptions{}
+
+	cmd := &cobra.Command{
+		Use:     "instancegroup INSTANCE_GROUP KEY=VALUE...",
+		Aliases: []string{"instancegroups", "ig"},
+		Short:   setInstancegroupShort,
+		Long:    setInstancegroupLong,
+		Example: setInstancegroupExample,
+		Args: func(cmd *cobra.Command, args []string) error {
+			options.ClusterName = rootCommand.ClusterName(true)
+			if options.ClusterName == "" {
+				return fmt.Errorf("--name is required")
+			}
+
+			if len(args) == 0 {
+				return fmt.Errorf("must specify the name of the instance group to set")
+			}
+			options.InstanceGroupName = args[0]
+
+			if len(args) == 1 {
+				return fmt.Errorf("must specify at least one field to set")
+			}
+			for _, arg := range args[1:] {
+				if !strings.Contains(arg, "=") {
+					return fmt.Errorf("unhandled field %q: fields must be of the form KEY=VALUE", arg)
+				}
+			}
+			options.Fields = args[1:]
+
+			return nil
+		},
+		ValidArgsFunction: completeInstanceGroupThenFields(f),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return commands.RunSetInstancegroup(context.TODO(), f, out, options)
+		},
+	}
+
+	return cmd
+}
+
+// completeInstanceGroupThenFields completes the instance group name, which is the first argument
+func completeInstanceGroupThenFields(f *util.Factory) func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	completeInstanceGroupName := completeInstanceGroup(f, nil, nil)
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return completeInstanceGroupName(cmd, args, toComplete)
+		}
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+}
@@ -35,6 +35,9 @@ func NewCmdToolbox(f *util.Factory, out io.Writer) *cobra.Command {
 	cmd.AddCommand(NewCmdToolboxDump(f, out))
 	cmd.AddCommand(NewCmdToolboxTemplate(f, out))
 	cmd.AddCommand(NewCmdToolboxInstanceSelector(f, out))
+	cmd.AddCommand(NewCmdToolboxMigrateState(f, out))
+	cmd.AddCommand(NewCmdToolboxPruneEtcdBackups(f, out))
+	cmd.AddCommand(NewCmdToolboxReencryptState(f, out))
 
 	return cmd
 }
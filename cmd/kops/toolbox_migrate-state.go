@@ -0,0 +1,206 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8s.io/kops/cmd/kops/util"
+	"k8s.io/kops/pkg/apis/kops/registry"
+	"k8s.io/kops/pkg/client/simple/vfsclientset"
+	"k8s.io/kops/pkg/commands/commandutils"
+	"k8s.io/kops/util/pkg/vfs"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	toolboxMigrateStateLong = templates.LongDesc(i18n.T(`
+	Copies the state of a cluster to a different state store, and updates the configBase,
+	keyStore and secretStore of the cluster to refer to the new location.
+
+	The configuration, instance groups, addons, keys, secrets and SSH public keys are copied,
+	and each copy is checked against the original. A key or secret store outside of the cluster's
+	state is left where it is. Etcd backups are not copied.
+
+	The nodes of the cluster keep reading the old state store until they are updated, so after
+	migrating, run kops update cluster and kops rolling-update cluster using the new state store.
+	With --delete-source, nodes that start before the rolling update is complete will fail to
+	join the cluster.`))
+
+	toolboxMigrateStateExample = templates.Examples(i18n.T(`
+	# Show what would be copied
+	kops toolbox migrate-state --name k8s-cluster.example.com --state s3://old-state-store --to gs://new-state-store
+
+	# Copy the state to the new state store
+	kops toolbox migrate-state --name k8s-cluster.example.com --state s3://old-state-store --to gs://new-state-store --yes
+	`))
+
+	toolboxMigrateStateShort = i18n.T(`Move the state of a cluster to a different state store`)
+)
+
+type ToolboxMigrateStateOptions struct {
+	ClusterName string
+
+	// To is the state store to move the cluster to
+	To string
+
+	// DeleteSource removes the copied files from the old state store
+	DeleteSource bool
+
+	Yes bool
+}
+
+func NewCmdToolboxMigrateState(f *util.Factory, out io.Writer) *cobra.Command {
+	options := &ToolboxMigrateStateOptions{}
+
+	cmd := &cobra.Command{
+		Use:               "migrate-state [CLUSTER]",
+		Short:             toolboxMigrateStateShort,
+		Long:              toolboxMigrateStateLong,
+		Example:           toolboxMigrateStateExample,
+		Args:              rootCommand.clusterNameArgs(&options.ClusterName),
+		ValidArgsFunction: commandutils.CompleteClusterName(f, true, false),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return RunToolboxMigrateState(context.TODO(), f, out, options)
+		},
+	}
+
+	cmd.Flags().StringVar(&options.To, "to", options.To, "State store to move the cluster to")
+	cmd.MarkFlagRequired("to")
+	cmd.RegisterFlagCompletionFunc("to", cobra.NoFileCompletions)
+	cmd.Flags().BoolVar(&options.DeleteSource, "delete-source", options.DeleteSource, "Delete the copied files from the old state store")
+	cmd.Flags().BoolVarP(&options.Yes, "yes", "y", options.Yes, "Copy the state of the cluster")
+
+	return cmd
+}
+
+func RunToolboxMigrateState(ctx context.Context, f *util.Factory, out io.Writer, options *ToolboxMigrateStateOptions) error {
+	clientset, err := f.Clientset()
+	if err != nil {
+		return err
+	}
+
+	cluster, err := clientset.GetCluster(ctx, options.ClusterName)
+	if err != nil {
+		return err
+	}
+	if cluster == nil {
+		return fmt.Errorf("cluster not found %q", options.ClusterName)
+	}
+
+	src, err := clientset.ConfigBaseFor(cluster)
+	if err != nil {
+		return fmt.Errorf("error building config base for cluster: %v", err)
+	}
+
+	dstStateStore, err := vfs.Context.BuildVfsPath(options.To)
+	if err != nil {
+		return fmt.Errorf("error building path for %q: %v", options.To, err)
+	}
+	dst := dstStateStore.Join(cluster.Name)
+
+	if dst.Path() == src.Path() {
+		return fmt.Errorf("cluster state is already stored in %s", dst)
+	}
+	if _, err := dst.Join(registry.PathCluster).ReadFile(); err == nil {
+		return fmt.Errorf("cluster %q already exists in %s", cluster.Name, dstStateStore)
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("error checking for cluster in %s: %v", dstStateStore, err)
+	}
+
+	oldConfigBase := cluster.Spec.ConfigBase
+	if oldConfigBase == "" {
+		oldConfigBase = src.Path()
+	}
+	configBase := strings.TrimSuffix(options.To, "/") + "/" + cluster.Name
+
+	// Stores within the cluster's state move with it
+	keyStore := rebaseStorePath(cluster.Spec.KeyStore, oldConfigBase, configBase)
+	secretStore := rebaseStorePath(cluster.Spec.SecretStore, oldConfigBase, configBase)
+
+	fmt.Fprintf(out, "Will copy the state of cluster %q from %s to %s\n", cluster.Name, src, dst)
+	fmt.Fprintf(out, "  configBase: %s -> %s\n", oldConfigBase, configBase)
+	if cluster.Spec.KeyStore != "" {
+		fmt.Fprintf(out, "  keyStore: %s -> %s\n", cluster.Spec.KeyStore, keyStore)
+	}
+	if cluster.Spec.SecretStore != "" {
+		fmt.Fprintf(out, "  secretStore: %s -> %s\n", cluster.Spec.SecretStore, secretStore)
+	}
+	if options.DeleteSource {
+		fmt.Fprintf(out, "Will delete the copied files from %s\n", src)
+	}
+
+	if !options.Yes {
+		fmt.Fprintf(out, "\nMust specify --yes to migrate\n")
+		return nil
+	}
+
+	copied, err := vfsclientset.CopyClusterState(src, dst, cluster)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Copied %d files\n", len(copied))
+
+	dstClientset := vfsclientset.NewVFSClientset(dstStateStore)
+	migrated, err := dstClientset.GetCluster(ctx, cluster.Name)
+	if err != nil {
+		return fmt.Errorf("error reading copied cluster: %v", err)
+	}
+	if migrated == nil {
+		return fmt.Errorf("cluster %q was not found in %s after copying", cluster.Name, dstStateStore)
+	}
+	migrated.Spec.ConfigBase = configBase
+	migrated.Spec.KeyStore = keyStore
+	migrated.Spec.SecretStore = secretStore
+	if _, err := dstClientset.UpdateCluster(ctx, migrated, nil); err != nil {
+		return fmt.Errorf("error updating copied cluster: %v", err)
+	}
+
+	if options.DeleteSource {
+		for _, relativePath := range copied {
+			p := src.Join(relativePath)
+			if err := p.Remove(); err != nil {
+				return fmt.Errorf("error deleting %s: %v", p, err)
+			}
+		}
+		fmt.Fprintf(out, "Deleted %d files from %s\n", len(copied), src)
+	}
+
+	fmt.Fprintf(out, "\nCluster %q has been copied to %s\n", cluster.Name, options.To)
+	fmt.Fprintf(out, "To move the nodes to the new state store, run:\n")
+	fmt.Fprintf(out, "  kops update cluster --name %s --state %s --yes\n", cluster.Name, options.To)
+	fmt.Fprintf(out, "  kops rolling-update cluster --name %s --state %s --yes\n", cluster.Name, options.To)
+	return nil
+}
+
+// rebaseStorePath returns the path p moved from oldBase to newBase, or p unchanged if it is not within oldBase
+func rebaseStorePath(p string, oldBase string, newBase string) string {
+	oldBase = strings.TrimSuffix(oldBase, "/")
+	if p == oldBase {
+		return newBase
+	}
+	if strings.HasPrefix(p, oldBase+"/") {
+		return strings.TrimSuffix(newBase, "/") + p[len(oldBase):]
+	}
+	return p
+}
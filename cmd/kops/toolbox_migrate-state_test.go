@@ -0,0 +1,56 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"testing"
+)
+
+func TestRebaseStorePath(t *testing.T) {
+	grid := []struct {
+		Path     string
+		Expected string
+	}{
+		{
+			Path:     "",
+			Expected: "",
+		},
+		{
+			Path:     "s3://old-state-store/test.k8s.local/pki",
+			Expected: "gs://new-state-store/test.k8s.local/pki",
+		},
+		{
+			Path:     "s3://old-state-store/test.k8s.local",
+			Expected: "gs://new-state-store/test.k8s.local",
+		},
+		{
+			Path:     "s3://old-state-store/test.k8s.local.other/pki",
+			Expected: "s3://old-state-store/test.k8s.local.other/pki",
+		},
+		{
+			Path:     "vault://vault.example.com:8200/kv/clusters/test.k8s.local/keys",
+			Expected: "vault://vault.example.com:8200/kv/clusters/test.k8s.local/keys",
+		},
+	}
+
+	for _, g := range grid {
+		actual := rebaseStorePath(g.Path, "s3://old-state-store/test.k8s.local", "gs://new-state-store/test.k8s.local")
+		if actual != g.Expected {
+			t.Errorf("rebasing %q: expected %q, got %q", g.Path, g.Expected, actual)
+		}
+	}
+}
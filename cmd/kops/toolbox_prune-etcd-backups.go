@@ -0,0 +1,140 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/kops/cmd/kops/util"
+	"k8s.io/kops/pkg/commands/commandutils"
+	"k8s.io/kops/pkg/etcdbackup"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	toolboxPruneEtcdBackupsLong = templates.LongDesc(i18n.T(`
+	Removes the etcd backups that are outside the retention policy of each etcd cluster.
+
+	The policy is the one configured in spec.etcdClusters[].backups.retention, which
+	etcd-manager also applies; by default one backup per hour is kept for 7 days and one
+	backup per day for 1 year. All backups taken within the last hour, and the most recent
+	backup, are always kept.`))
+
+	toolboxPruneEtcdBackupsExample = templates.Examples(i18n.T(`
+	# List the etcd backups that will be removed
+	kops toolbox prune-etcd-backups --name k8s-cluster.example.com
+
+	# Remove the expired backups of the events etcd cluster
+	kops toolbox prune-etcd-backups --name k8s-cluster.example.com --cluster events --yes
+	`))
+
+	toolboxPruneEtcdBackupsShort = i18n.T(`Remove etcd backups outside the retention policy`)
+)
+
+type ToolboxPruneEtcdBackupsOptions struct {
+	ClusterName string
+
+	// EtcdCluster limits the pruning to the named etcd cluster.
+	EtcdCluster string
+
+	Yes bool
+}
+
+func NewCmdToolboxPruneEtcdBackups(f *util.Factory, out io.Writer) *cobra.Command {
+	options := &ToolboxPruneEtcdBackupsOptions{}
+
+	cmd := &cobra.Command{
+		Use:               "prune-etcd-backups [CLUSTER]",
+		Short:             toolboxPruneEtcdBackupsShort,
+		Long:              toolboxPruneEtcdBackupsLong,
+		Example:           toolboxPruneEtcdBackupsExample,
+		Args:              rootCommand.clusterNameArgs(&options.ClusterName),
+		ValidArgsFunction: commandutils.CompleteClusterName(f, true, false),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return RunToolboxPruneEtcdBackups(context.TODO(), f, out, options)
+		},
+	}
+
+	cmd.Flags().StringVar(&options.EtcdCluster, "cluster", options.EtcdCluster, "Name of the etcd cluster, such as main or events")
+	cmd.RegisterFlagCompletionFunc("cluster", completeEtcdClusterName(f))
+	cmd.Flags().BoolVarP(&options.Yes, "yes", "y", options.Yes, "Remove the expired backups")
+
+	return cmd
+}
+
+func RunToolboxPruneEtcdBackups(ctx context.Context, f commandutils.Factory, out io.Writer, options *ToolboxPruneEtcdBackupsOptions) error {
+	clientset, err := f.Clientset()
+	if err != nil {
+		return err
+	}
+
+	cluster, err := clientset.GetCluster(ctx, options.ClusterName)
+	if err != nil {
+		return err
+	}
+	if cluster == nil {
+		return fmt.Errorf("cluster not found %q", options.ClusterName)
+	}
+	if options.EtcdCluster != "" && findEtcdCluster(cluster, options.EtcdCluster) == nil {
+		return fmt.Errorf("etcd cluster %q not found", options.EtcdCluster)
+	}
+
+	now := time.Now()
+	found := false
+	for i := range cluster.Spec.EtcdClusters {
+		etcdCluster := &cluster.Spec.EtcdClusters[i]
+		if options.EtcdCluster != "" && etcdCluster.Name != options.EtcdCluster {
+			continue
+		}
+
+		store, err := etcdBackupStore(clientset, cluster, etcdCluster)
+		if err != nil {
+			return err
+		}
+		backups, err := store.ListBackups()
+		if err != nil {
+			return err
+		}
+
+		expired := etcdbackup.RetentionPolicyFor(etcdCluster.Backups).Expired(backups, now)
+		for _, backup := range expired {
+			found = true
+			if !options.Yes {
+				fmt.Fprintf(out, "etcd cluster %s: backup %s\n", etcdCluster.Name, backup.Name)
+				continue
+			}
+			if err := store.DeleteBackup(backup.Name); err != nil {
+				return fmt.Errorf("error removing backup %q of etcd cluster %q: %v", backup.Name, etcdCluster.Name, err)
+			}
+			fmt.Fprintf(out, "removed backup %s of etcd cluster %s\n", backup.Name, etcdCluster.Name)
+		}
+	}
+
+	if !found {
+		fmt.Fprintf(out, "No etcd backups are outside the retention policy\n")
+		return nil
+	}
+	if !options.Yes {
+		fmt.Fprintf(out, "\nMust specify --yes to remove the backups\n")
+	}
+	return nil
+}
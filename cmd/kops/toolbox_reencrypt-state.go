@@ -0,0 +1,150 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"k8s.io/kops/cmd/kops/util"
+	"k8s.io/kops/pkg/commands/commandutils"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	toolboxReencryptStateLong = templates.LongDesc(i18n.T(`
+	Rewrites the secrets and keysets of a cluster using the state store encryption configured in
+	spec.stateStoreEncryption.
+
+	Use this after enabling, changing or disabling state store encryption. Files are read with
+	the key provider that encrypted them, so that provider must still be accessible.
+	If the secret or key store is mirrored to another location, run kops update cluster afterwards
+	to update the mirror.`))
+
+	toolboxReencryptStateExample = templates.Examples(i18n.T(`
+	# List the secrets and keysets that will be re-encrypted
+	kops toolbox reencrypt-state --name k8s-cluster.example.com
+
+	# Re-encrypt the secrets and keysets
+	kops toolbox reencrypt-state --name k8s-cluster.example.com --yes
+	`))
+
+	toolboxReencryptStateShort = i18n.T(`Re-encrypt the secrets and keys in the state store`)
+)
+
+type ToolboxReencryptStateOptions struct {
+	ClusterName string
+	Yes         bool
+}
+
+func NewCmdToolboxReencryptState(f *util.Factory, out io.Writer) *cobra.Command {
+	options := &ToolboxReencryptStateOptions{}
+
+	cmd := &cobra.Command{
+		Use:               "reencrypt-state [CLUSTER]",
+		Short:             toolboxReencryptStateShort,
+		Long:              toolboxReencryptStateLong,
+		Example:           toolboxReencryptStateExample,
+		Args:              rootCommand.clusterNameArgs(&options.ClusterName),
+		ValidArgsFunction: commandutils.CompleteClusterName(f, true, false),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return RunToolboxReencryptState(context.TODO(), f, out, options)
+		},
+	}
+
+	cmd.Flags().BoolVarP(&options.Yes, "yes", "y", options.Yes, "Re-encrypt the secrets and keysets")
+
+	return cmd
+}
+
+func RunToolboxReencryptState(ctx context.Context, f *util.Factory, out io.Writer, options *ToolboxReencryptStateOptions) error {
+	clientset, err := f.Clientset()
+	if err != nil {
+		return err
+	}
+
+	cluster, err := clientset.GetCluster(ctx, options.ClusterName)
+	if err != nil {
+		return err
+	}
+	if cluster == nil {
+		return fmt.Errorf("cluster not found %q", options.ClusterName)
+	}
+
+	secretStore, err := clientset.SecretStore(cluster)
+	if err != nil {
+		return err
+	}
+	keyStore, err := clientset.KeyStore(cluster)
+	if err != nil {
+		return err
+	}
+
+	secretNames, err := secretStore.ListSecrets()
+	if err != nil {
+		return fmt.Errorf("error listing secrets: %v", err)
+	}
+	sort.Strings(secretNames)
+
+	keysets, err := keyStore.ListKeysets()
+	if err != nil {
+		return fmt.Errorf("error listing keysets: %v", err)
+	}
+	var keysetNames []string
+	for name := range keysets {
+		keysetNames = append(keysetNames, name)
+	}
+	sort.Strings(keysetNames)
+
+	if !options.Yes {
+		for _, name := range secretNames {
+			fmt.Fprintf(out, "secret %s\n", name)
+		}
+		for _, name := range keysetNames {
+			fmt.Fprintf(out, "keyset %s\n", name)
+		}
+		fmt.Fprintf(out, "\nMust specify --yes to re-encrypt\n")
+		return nil
+	}
+
+	for _, name := range secretNames {
+		secret, err := secretStore.FindSecret(name)
+		if err != nil {
+			return fmt.Errorf("error reading secret %q: %v", name, err)
+		}
+		if secret == nil {
+			continue
+		}
+		if _, err := secretStore.ReplaceSecret(name, secret); err != nil {
+			return fmt.Errorf("error writing secret %q: %v", name, err)
+		}
+		fmt.Fprintf(out, "re-encrypted secret %s\n", name)
+	}
+
+	for _, name := range keysetNames {
+		if err := keyStore.StoreKeyset(name, keysets[name]); err != nil {
+			return fmt.Errorf("error writing keyset %q: %v", name, err)
+		}
+		fmt.Fprintf(out, "re-encrypted keyset %s\n", name)
+	}
+
+	return nil
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8s.io/kops/cmd/kops/util"
+	"k8s.io/kops/pkg/commands"
+	"k8s.io/kops/pkg/pretty"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	unsetClusterLong = pretty.LongDesc(i18n.T(`Unset a cluster field value.
+
+	This command changes the desired cluster configuration in the registry.
+	The configuration is validated before it is written.
+
+	kops unset does not update the cloud resources; to apply the changes use ` + pretty.Bash("kops update cluster") + `.`))
+
+	unsetClusterExample = templates.Examples(i18n.T(`
+	# Unset the cluster-wide kube-proxy configuration
+	kops unset cluster k8s-cluster.example.com spec.kubeProxy
+
+	# Unset several fields at once
+	kops unset cluster k8s-cluster.example.com spec.kubelet.authorizationMode spec.kubelet.authenticationTokenWebhook
+	`))
+
+	unsetClusterShort = i18n.T(`Unset cluster fields.`)
+)
+
+// NewCmdUnsetCluster builds a cobra command for the kops unset cluster command
+func NewCmdUnsetCluster(f *util.Factory, out io.Writer) *cobra.Command {
+	options := &commands.UnsetClusterOptions{}
+
+	cmd := &cobra.Command{
+		Use:     "cluster [CLUSTER] KEY...",
+		Short:   unsetClusterShort,
+		Long:    unsetClusterLong,
+		Example: unsetClusterExample,
+		Args: func(cmd *cobra.Command, args []string) error {
+			// The cluster name is optional, and is the first argument if present
+			if len(args) > 0 && !isFieldPath(args[0]) {
+				if err := rootCommand.ProcessArgs(args[:1]); err != nil {
+					return err
+				}
+				args = args[1:]
+			}
+
+			options.ClusterName = rootCommand.ClusterName(true)
+			if options.ClusterName == "" {
+				return fmt.Errorf("--name is required")
+			}
+
+			if len(args) == 0 {
+				return fmt.Errorf("must specify at least one field to unset")
+			}
+			for _, arg := range args {
+				if strings.Contains(arg, "=") {
+					return fmt.Errorf("unhandled field %q: fields to unset must not have a value", arg)
+				}
+			}
+			options.Fields = args
+
+			return nil
+		},
+		ValidArgsFunction: completeClusterThenFields(f),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return commands.RunUnsetCluster(context.TODO(), f, out, options)
+		},
+	}
+
+	return cmd
+}
+
+// isFieldPath returns true if the argument names a field of the cluster rather than the cluster itself
+func isFieldPath(arg string) bool {
+	arg = strings.TrimPrefix(arg, "cluster.")
+	return strings.HasPrefix(arg, "spec.") || strings.HasPrefix(arg, "metadata.")
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8s.io/kops/cmd/kops/util"
+	"k8s.io/kops/pkg/commands"
+	"k8s.io/kops/pkg/pretty"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	unsetInstancegroupLong = pretty.LongDesc(i18n.T(`Unset an instance group field value.
+
+	This command changes the desired instance group configuration in the registry.
+	The configuration is validated before it is written.
+
+	kops unset does not update the cloud resources; to apply the changes use ` + pretty.Bash("kops update cluster") + `.`))
+
+	unsetInstancegroupExample = templates.Examples(i18n.T(`
+	# Unset the maximum price of the nodes instance group
+	kops unset instancegroup --name k8s-cluster.example.com nodes spec.maxPrice
+	`))
+
+	unsetInstancegroupShort = i18n.T(`Unset instancegroup fields.`)
+)
+
+// NewCmdUnsetInstancegroup builds a cobra command for the kops unset instancegroup command
+func NewCmdUnsetInstancegroup(f *util.Factory, out io.Writer) *cobra.Command {
+	options := &commands.UnsetInstanceGroupOptions{}
+
+	cmd := &cobra.Command{
+		Use:     "instancegroup INSTANCE_GROUP KEY...",
+		Aliases: []string{"instancegroups", "ig"},
+		Short:   unsetInstancegroupShort,
+		Long:    unsetInstancegroupLong,
+		Example: unsetInstancegroupExample,
+		Args: func(cmd *cobra.Command, args []string) error {
+			options.ClusterName = rootCommand.ClusterName(true)
+			if options.ClusterName == "" {
+				return fmt.Errorf("--name is required")
+			}
+
+			if len(args) == 0 {
+				return fmt.Errorf("must specify the name of the instance group to unset")
+			}
+			options.InstanceGroupName = args[0]
+
+			if len(args) == 1 {
+				return fmt.Errorf("must specify at least one field to unset")
+			}
+			for _, arg := range args[1:] {
+				if strings.Contains(arg, "=") {
+					return fmt.Errorf("unhandled field %q: fields to unset must not have a value", arg)
+				}
+			}
+			options.Fields = args[1:]
+
+			return nil
+		},
+		ValidArgsFunction: completeInstanceGroupThenFields(f),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return commands.RunUnsetInstancegroup(context.TODO(), f, out, options)
+		},
+	}
+
+	return cmd
+}
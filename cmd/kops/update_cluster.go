@@ -82,6 +82,12 @@ type UpdateClusterOptions struct {
 	// LifecycleOverrides is a slice of taskName=lifecycle name values.  This slice is used
 	// to populate the LifecycleOverrides struct member in ApplyClusterCmd struct.
 	LifecycleOverrides []string
+
+	// TaskReport is the format in which to write the per-task execution timeline, if non-empty.
+	TaskReport string
+	// TaskReportFile is the path of the file to write the per-task execution timeline to.
+	// Defaults to task-report.json in OutDir.
+	TaskReportFile string
 }
 
 func (o *UpdateClusterOptions) InitDefaults() {
@@ -114,7 +120,7 @@ func NewCmdUpdateCluster(f *util.Factory, out io.Writer) *cobra.Command {
 	}
 
 	cmd.Flags().BoolVarP(&options.Yes, "yes", "y", options.Yes, "Create cloud resources, without --yes update is in dry run mode")
-	cmd.Flags().StringVar(&options.Target, "target", options.Target, "Target - direct, terraform, cloudformation")
+	cmd.Flags().StringVar(&options.Target, "target", options.Target, "Target - direct, terraform, terraform-json, cloudformation")
 	cmd.RegisterFlagCompletionFunc("target", completeUpdateClusterTarget(f, options))
 	cmd.Flags().StringVar(&options.SSHPublicKey, "ssh-public-key", options.SSHPublicKey, "SSH public key to use (deprecated: use kops create secret instead)")
 	cmd.Flags().StringVar(&options.OutDir, "out", options.OutDir, "Path to write any local output")
@@ -134,6 +140,13 @@ func NewCmdUpdateCluster(f *util.Factory, out io.Writer) *cobra.Command {
 	viper.BindPFlag("lifecycle-overrides", cmd.Flags().Lookup("lifecycle-overrides"))
 	viper.BindEnv("lifecycle-overrides", "KOPS_LIFECYCLE_OVERRIDES")
 	cmd.RegisterFlagCompletionFunc("lifecycle-overrides", completeLifecycleOverrides)
+	cmd.Flags().IntVar(&options.RunTasksOptions.MaxConcurrency, "max-task-concurrency", options.RunTasksOptions.MaxConcurrency, "Maximum number of tasks to run in parallel; 0 for no limit")
+	cmd.Flags().StringVar(&options.TaskReport, "task-report", options.TaskReport, "Write a report of the time taken by each task in the specified format: json")
+	cmd.RegisterFlagCompletionFunc("task-report", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{OutputJSON}, cobra.ShellCompDirectiveNoFileComp
+	})
+	cmd.Flags().StringVar(&options.TaskReportFile, "task-report-file", options.TaskReportFile, "Path of the file to write the task report to, keeping it apart from the update output (default: task-report.json in the --out directory)")
+	cmd.MarkFlagFilename("task-report-file")
 
 	return cmd
 }
@@ -151,6 +164,8 @@ type UpdateClusterResults struct {
 	FileAssets []*assets.FileAsset
 	// Cluster is the cluster spec (output).
 	Cluster *kops.Cluster
+	// InstanceGroups are the instance groups of the cluster (output).
+	InstanceGroups []*kops.InstanceGroup
 }
 
 func RunUpdateCluster(ctx context.Context, f *util.Factory, out io.Writer, c *UpdateClusterOptions) (*UpdateClusterResults, error) {
@@ -178,6 +193,10 @@ func RunUpdateCluster(ctx context.Context, f *util.Factory, out io.Writer, c *Up
 		c.CreateKubecfg = true
 	}
 
+	if c.TaskReport != "" && c.TaskReport != OutputJSON {
+		return nil, fmt.Errorf("unsupported task report format %q, supported formats: %s", c.TaskReport, OutputJSON)
+	}
+
 	// direct requires --yes (others do not, because they don't do anything!)
 	if c.Target == cloudup.TargetDirect {
 		if !c.Yes {
@@ -191,7 +210,7 @@ func RunUpdateCluster(ctx context.Context, f *util.Factory, out io.Writer, c *Up
 	}
 
 	if c.OutDir == "" {
-		if c.Target == cloudup.TargetTerraform {
+		if c.Target == cloudup.TargetTerraform || c.Target == cloudup.TargetTerraformJSON {
 			c.OutDir = "out/terraform"
 		} else if c.Target == cloudup.TargetCloudformation {
 			c.OutDir = "out/cloudformation"
@@ -200,6 +219,10 @@ func RunUpdateCluster(ctx context.Context, f *util.Factory, out io.Writer, c *Up
 		}
 	}
 
+	if c.TaskReport != "" && c.TaskReportFile == "" {
+		c.TaskReportFile = filepath.Join(c.OutDir, taskReportFileName)
+	}
+
 	cluster, err := GetCluster(ctx, f, c.ClusterName)
 	if err != nil {
 		return results, err
@@ -293,7 +316,15 @@ func RunUpdateCluster(ctx context.Context, f *util.Factory, out io.Writer, c *Up
 		GetAssets:          c.GetAssets,
 	}
 
-	if err := applyCmd.Run(ctx); err != nil {
+	err = applyCmd.Run(ctx)
+	if c.TaskReport != "" && applyCmd.TaskReport != nil {
+		if reportErr := writeTaskReport(c.TaskReportFile, applyCmd.TaskReport); reportErr != nil {
+			klog.Warningf("error writing task report: %v", reportErr)
+		} else {
+			klog.Infof("task report written to %s", c.TaskReportFile)
+		}
+	}
+	if err != nil {
 		return results, err
 	}
 
@@ -302,6 +333,7 @@ func RunUpdateCluster(ctx context.Context, f *util.Factory, out io.Writer, c *Up
 	results.ImageAssets = applyCmd.ImageAssets
 	results.FileAssets = applyCmd.FileAssets
 	results.Cluster = cluster
+	results.InstanceGroups = applyCmd.InstanceGroups
 
 	if isDryrun && !c.GetAssets {
 		target := applyCmd.Target.(*fi.DryRunTarget)
@@ -329,7 +361,7 @@ func RunUpdateCluster(ctx context.Context, f *util.Factory, out io.Writer, c *Up
 		useKopsAuthenticationPlugin := false
 		conf, err := kubeconfig.BuildKubecfg(
 			cluster,
-			keyStore,
+			kubeconfig.NewKeystoreSigner(keyStore),
 			secretStore,
 			cloud,
 			c.admin,
@@ -354,7 +386,7 @@ func RunUpdateCluster(ctx context.Context, f *util.Factory, out io.Writer, c *Up
 	if !isDryrun {
 		sb := new(bytes.Buffer)
 
-		if c.Target == cloudup.TargetTerraform {
+		if c.Target == cloudup.TargetTerraform || c.Target == cloudup.TargetTerraformJSON {
 			fmt.Fprintf(sb, "\n")
 			fmt.Fprintf(sb, "Terraform output has been placed into %s\n", c.OutDir)
 
@@ -480,6 +512,7 @@ func completeUpdateClusterTarget(f commandutils.Factory, options *UpdateClusterO
 				cloudup.TargetDryRun,
 				cloudup.TargetCloudformation,
 				cloudup.TargetTerraform,
+				cloudup.TargetTerraformJSON,
 			}, directive
 		}
 
@@ -489,7 +522,7 @@ func completeUpdateClusterTarget(f commandutils.Factory, options *UpdateClusterO
 		}
 		for _, cp := range cloudup.TerraformCloudProviders {
 			if cluster.Spec.GetCloudProvider() == cp {
-				completions = append(completions, cloudup.TargetTerraform)
+				completions = append(completions, cloudup.TargetTerraform, cloudup.TargetTerraformJSON)
 			}
 		}
 		if cluster.Spec.GetCloudProvider() == kops.CloudProviderAWS {
@@ -516,3 +549,22 @@ func completeLifecycleOverrides(cmd *cobra.Command, args []string, toComplete st
 	}
 	return completions, cobra.ShellCompDirectiveNoFileComp
 }
+
+// taskReportFileName is the name of the task report file in the output directory, when no file is specified.
+const taskReportFileName = "task-report.json"
+
+// writeTaskReport writes the per-task execution timeline as JSON to the file at path.
+func writeTaskReport(path string, report *fi.TaskReport) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("error creating directory for task report file %q: %v", path, err)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("error creating task report file %q: %v", path, err)
+	}
+	if err := report.WriteJSON(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -56,17 +56,25 @@ var (
 	validateClusterExample = templates.Examples(i18n.T(`
 	# Validate the cluster set as the current context of the kube config.
 	# Kops will try for 10 minutes to validate the cluster 3 times.
-	kops validate cluster --wait 10m --count 3`))
+	kops validate cluster --wait 10m --count 3
+
+	# Stream one JSON event per validation attempt and write a JUnit report,
+	# for example to track in CI how long each component took to converge.
+	kops validate cluster --wait 10m --output ndjson --junit validation.xml`))
 
 	validateClusterShort = i18n.T(`Validate a kOps cluster.`)
 )
 
+// OutputNDJSON streams one JSON object per validation attempt.
+const OutputNDJSON = "ndjson"
+
 type ValidateClusterOptions struct {
 	ClusterName string
 	output      string
 	wait        time.Duration
 	count       int
 	kubeconfig  string
+	junit       string
 }
 
 func (o *ValidateClusterOptions) InitDefaults() {
@@ -99,18 +107,25 @@ func NewCmdValidateCluster(f *util.Factory, out io.Writer) *cobra.Command {
 		},
 	}
 
-	cmd.Flags().StringVarP(&options.output, "output", "o", options.output, "Output format. One of json|yaml|table.")
+	cmd.Flags().StringVarP(&options.output, "output", "o", options.output, "Output format. One of json|yaml|table|ndjson.")
 	cmd.RegisterFlagCompletionFunc("output", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-		return []string{"json", "yaml", "table"}, cobra.ShellCompDirectiveNoFileComp
+		return []string{"json", "yaml", "table", "ndjson"}, cobra.ShellCompDirectiveNoFileComp
 	})
 	cmd.Flags().DurationVar(&options.wait, "wait", options.wait, "Amount of time to wait for the cluster to become ready")
 	cmd.Flags().IntVar(&options.count, "count", options.count, "Number of consecutive successful validations required")
 	cmd.Flags().StringVar(&options.kubeconfig, "kubeconfig", "", "Path to the kubeconfig file")
+	cmd.Flags().StringVar(&options.junit, "junit", options.junit, "Path to write a JUnit report of how long each component took to validate")
 
 	return cmd
 }
 
-func RunValidateCluster(ctx context.Context, f *util.Factory, out io.Writer, options *ValidateClusterOptions) (*validation.ValidationCluster, error) {
+func RunValidateCluster(ctx context.Context, f *util.Factory, out io.Writer, options *ValidateClusterOptions) (_ *validation.ValidationCluster, err error) {
+	switch options.output {
+	case OutputTable, OutputYaml, OutputJSON, OutputNDJSON:
+	default:
+		return nil, fmt.Errorf("unknown output format: %q", options.output)
+	}
+
 	clientSet, err := f.Clientset()
 	if err != nil {
 		return nil, err
@@ -171,6 +186,15 @@ func RunValidateCluster(ctx context.Context, f *util.Factory, out io.Writer, opt
 		return nil, fmt.Errorf("unexpected error creating validatior: %v", err)
 	}
 
+	history := validation.NewValidationHistory(time.Now())
+	if options.junit != "" {
+		defer func() {
+			if junitErr := writeValidationJUnit(options.junit, cluster.ObjectMeta.Name, history); junitErr != nil && err == nil {
+				err = junitErr
+			}
+		}()
+	}
+
 	consecutive := 0
 	for {
 		if options.wait > 0 && time.Now().After(timeout) {
@@ -178,6 +202,12 @@ func RunValidateCluster(ctx context.Context, f *util.Factory, out io.Writer, opt
 		}
 
 		result, err := validator.Validate()
+		attempt := history.Record(time.Now(), result, err)
+		if options.output == OutputNDJSON {
+			if err := writeValidationAttempt(out, attempt); err != nil {
+				return nil, err
+			}
+		}
 		if err != nil {
 			consecutive = 0
 			if options.wait > 0 {
@@ -210,6 +240,8 @@ func RunValidateCluster(ctx context.Context, f *util.Factory, out io.Writer, opt
 			if _, err := out.Write(j); err != nil {
 				return nil, fmt.Errorf("error writing to output: %v", err)
 			}
+		case OutputNDJSON:
+			// Each attempt has already been written as an event
 		default:
 			return nil, fmt.Errorf("unknown output format: %q", options.output)
 		}
@@ -240,6 +272,30 @@ func RunValidateCluster(ctx context.Context, f *util.Factory, out io.Writer, opt
 	}
 }
 
+// writeValidationAttempt writes the attempt as a single line of JSON.
+func writeValidationAttempt(out io.Writer, attempt *validation.ValidationAttempt) error {
+	j, err := json.Marshal(attempt)
+	if err != nil {
+		return fmt.Errorf("unable to marshal JSON: %v", err)
+	}
+	if _, err := out.Write(append(j, '\n')); err != nil {
+		return fmt.Errorf("error writing to output: %v", err)
+	}
+	return nil
+}
+
+func writeValidationJUnit(path string, clusterName string, history *validation.ValidationHistory) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("error creating JUnit report: %v", err)
+	}
+	if err := history.WriteJUnit(f, clusterName); err != nil {
+		f.Close()
+		return fmt.Errorf("error writing JUnit report %q: %v", path, err)
+	}
+	return f.Close()
+}
+
 func validateClusterOutputTable(result *validation.ValidationCluster, cluster *kopsapi.Cluster, instanceGroups []kopsapi.InstanceGroup, out io.Writer) error {
 	t := &tables.Table{}
 	t.AddColumn("NAME", func(c kopsapi.InstanceGroup) string {
@@ -17,6 +17,7 @@ limitations under the License.
 package main // import "k8s.io/kops/cmd/nodeup"
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"os"
@@ -38,7 +39,8 @@ func main() {
 
 	var flagConf, flagCacheDir, gitVersion string
 	var flagRetries int
-	var dryrun, installSystemdUnit bool
+	var dryrun, installSystemdUnit, reconcileApply bool
+	var reconcileInterval time.Duration
 	target := "direct"
 
 	if kops.GitVersion != "" {
@@ -49,8 +51,10 @@ func main() {
 	flag.StringVar(&flagCacheDir, "cache", "/var/cache/nodeup", "the location for the local asset cache")
 	flag.IntVar(&flagRetries, "retries", -1, "maximum number of retries on failure: -1 means retry forever")
 	flag.BoolVar(&dryrun, "dryrun", false, "Don't create cloud resources; just show what would be done")
-	flag.StringVar(&target, "target", target, "Target - direct, cloudinit")
+	flag.StringVar(&target, "target", target, "Target - direct, cloudinit, ignition")
 	flag.BoolVar(&installSystemdUnit, "install-systemd-unit", installSystemdUnit, "If true, will install a systemd unit instead of running directly")
+	flag.DurationVar(&reconcileInterval, "reconcile-interval", reconcileInterval, "If set, keep running and report drift from the node configuration at this interval, instead of applying it once")
+	flag.BoolVar(&reconcileApply, "reconcile-apply", reconcileApply, "If true, re-apply the drifted files, users and groups that can be changed without restarting anything")
 
 	if dryrun {
 		target = "dryrun"
@@ -63,6 +67,21 @@ func main() {
 		klog.Exitf("--conf is required")
 	}
 
+	if reconcileInterval > 0 {
+		cmd := &nodeup.ReconcileCommand{
+			NodeUpCommand: nodeup.NodeUpCommand{
+				ConfigLocation: flagConf,
+				CacheDir:       flagCacheDir,
+			},
+			Interval: reconcileInterval,
+			Apply:    reconcileApply,
+		}
+		if err := cmd.Run(context.Background(), os.Stdout); err != nil {
+			klog.Exitf("error reconciling node: %v", err)
+		}
+		os.Exit(0)
+	}
+
 	retries := flagRetries
 
 	for {
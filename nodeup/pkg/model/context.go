@@ -33,6 +33,7 @@ import (
 	"k8s.io/kops/pkg/apis/kops/model"
 	"k8s.io/kops/pkg/apis/kops/util"
 	"k8s.io/kops/pkg/apis/nodeup"
+	"k8s.io/kops/pkg/ospackages"
 	"k8s.io/kops/pkg/systemd"
 	"k8s.io/kops/upup/pkg/fi"
 	"k8s.io/kops/upup/pkg/fi/nodeup/nodetasks"
@@ -618,6 +619,29 @@ func (c *NodeupModelContext) InstallNvidiaRuntime() bool {
 		c.GPUVendor == architectures.GPUVendorNvidia
 }
 
+// OSPackages returns the settings of the node that determine the OS packages nodeup installs
+func (c *NodeupModelContext) OSPackages() *ospackages.Node {
+	n := &ospackages.Node{
+		Distribution:     c.Distribution,
+		KubernetesLT120:  c.IsKubernetesLT("1.20"),
+		OnGCE:            c.RunningOnGCE(),
+		ManagedNTP:       c.Cluster.Spec.NTP.IsManaged(),
+		AutomaticUpdates: c.NodeupConfig.UpdatePolicy != kops.UpdatePolicyExternal,
+		Calico:           c.Cluster.Spec.Networking != nil && c.Cluster.Spec.Networking.Calico != nil,
+	}
+	if c.InstallNvidiaRuntime() {
+		n.NvidiaDriverPackage = c.NodeupConfig.NvidiaGPU.DriverPackage
+	}
+	return n
+}
+
+// AddOSPackages adds the tasks that install the OS packages of a component
+func (c *NodeupModelContext) AddOSPackages(ctx *fi.ModelBuilderContext, component ospackages.Component) {
+	for _, name := range c.OSPackages().Packages(component) {
+		ctx.AddTask(&nodetasks.Package{Name: name})
+	}
+}
+
 // RunningOnGCE returns true if we are running on GCE
 func (c *NodeupModelContext) RunningOnGCE() bool {
 	return c.CloudProvider == kops.CloudProviderGCE
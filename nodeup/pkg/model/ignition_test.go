@@ -0,0 +1,196 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"k8s.io/kops/cloudmock/aws/mockec2"
+	"k8s.io/kops/pkg/ignition"
+	"k8s.io/kops/pkg/testutils"
+	"k8s.io/kops/upup/pkg/fi"
+	nodeupignition "k8s.io/kops/upup/pkg/fi/nodeup/ignition"
+	"k8s.io/kops/upup/pkg/fi/nodeup/nodetasks"
+	"k8s.io/kops/util/pkg/distributions"
+)
+
+// instanceMockEC2 describes a single instance, which the kubelet serving certificate is issued for
+type instanceMockEC2 struct {
+	*mockec2.MockEC2
+}
+
+func (m *instanceMockEC2) DescribeInstances(request *ec2.DescribeInstancesInput) (*ec2.DescribeInstancesOutput, error) {
+	return &ec2.DescribeInstancesOutput{
+		Reservations: []*ec2.Reservation{
+			{
+				Instances: []*ec2.Instance{
+					{
+						InstanceId:     request.InstanceIds[0],
+						PrivateDnsName: aws.String("ip-172-20-32-10.us-test-1.compute.internal"),
+					},
+				},
+			},
+		},
+	}, nil
+}
+
+// fakeBootstrapClientTask stands in for the BootstrapClientTask, issuing fixed certificates instead of calling kops-controller
+type fakeBootstrapClientTask struct {
+	Certs map[string]*nodetasks.BootstrapCert
+}
+
+func (t *fakeBootstrapClientTask) GetDependencies(tasks map[string]fi.Task) []fi.Task {
+	return nil
+}
+
+func (t *fakeBootstrapClientTask) GetName() *string {
+	return fi.String("BootstrapClient")
+}
+
+func (t *fakeBootstrapClientTask) Run(c *fi.Context) error {
+	for name, cert := range t.Certs {
+		cert.Cert.Resource = fi.NewStringResource("certificate for " + name)
+		cert.Key.Resource = fi.NewStringResource("private key for " + name)
+	}
+	return nil
+}
+
+// TestIgnitionTarget renders the tasks that nodeup builds for a Flatcar node as an Ignition config
+func TestIgnitionTarget(t *testing.T) {
+	h := testutils.NewIntegrationTestHarness(t)
+	defer h.Close()
+
+	h.MockKopsVersion("1.18.0")
+	cloud := h.SetupMockAWS()
+	cloud.MockEC2 = &instanceMockEC2{MockEC2: cloud.MockEC2.(*mockec2.MockEC2)}
+
+	basedir := "tests/kubelet/featuregates"
+
+	model, err := testutils.LoadModel(basedir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nodeupModelContext, err := BuildNodeupModelContext(model)
+	if err != nil {
+		t.Fatalf("error loading model %q: %v", basedir, err)
+	}
+	nodeupModelContext.Cloud = cloud
+	nodeupModelContext.InstanceID = "i-0123456789abcdef0"
+	nodeupModelContext.Distribution = distributions.DistributionFlatcar
+	nodeupModelContext.KeyStore = &fakeKeystore{T: t}
+	nodeupModelContext.Assets = fi.NewAssetStore("")
+	nodeupModelContext.Assets.AddForTest("kubelet", "/path/to/kubelet/asset", "testing kubelet content")
+	nodeupModelContext.Assets.AddForTest("kubectl", "/path/to/kubectl/asset", "testing kubectl content")
+	if err := nodeupModelContext.Init(); err != nil {
+		t.Fatalf("error from nodeupModelContext.Init(): %v", err)
+	}
+
+	// The builders of the node model in this package, in the order nodeup runs them;
+	// the networking and DNS builders live in other packages
+	builders := []fi.ModelBuilder{
+		&NTPBuilder{NodeupModelContext: nodeupModelContext},
+		&MiscUtilsBuilder{NodeupModelContext: nodeupModelContext},
+		&DirectoryBuilder{NodeupModelContext: nodeupModelContext},
+		&UpdateServiceBuilder{NodeupModelContext: nodeupModelContext},
+		&VolumesBuilder{NodeupModelContext: nodeupModelContext},
+		&ContainerdBuilder{NodeupModelContext: nodeupModelContext},
+		&DockerBuilder{NodeupModelContext: nodeupModelContext},
+		&ProtokubeBuilder{NodeupModelContext: nodeupModelContext},
+		&CloudConfigBuilder{NodeupModelContext: nodeupModelContext},
+		&FileAssetsBuilder{NodeupModelContext: nodeupModelContext},
+		&HookBuilder{NodeupModelContext: nodeupModelContext},
+		&KubeletBuilder{NodeupModelContext: nodeupModelContext},
+		&KubectlBuilder{NodeupModelContext: nodeupModelContext},
+		&LogrotateBuilder{NodeupModelContext: nodeupModelContext},
+		&ManifestsBuilder{NodeupModelContext: nodeupModelContext},
+		&PackagesBuilder{NodeupModelContext: nodeupModelContext},
+		&NvidiaBuilder{NodeupModelContext: nodeupModelContext},
+		&SecretBuilder{NodeupModelContext: nodeupModelContext},
+		&FirewallBuilder{NodeupModelContext: nodeupModelContext},
+		&SysctlBuilder{NodeupModelContext: nodeupModelContext},
+		&KubeProxyBuilder{NodeupModelContext: nodeupModelContext},
+		&WarmPoolBuilder{NodeupModelContext: nodeupModelContext},
+		&PrefixBuilder{NodeupModelContext: nodeupModelContext},
+	}
+
+	context := &fi.ModelBuilderContext{
+		Tasks: make(map[string]fi.Task),
+	}
+	for _, builder := range builders {
+		if err := builder.Build(context); err != nil {
+			t.Fatalf("error from %T Build: %v", builder, err)
+		}
+	}
+
+	bootstrapClientTask := &fakeBootstrapClientTask{Certs: nodeupModelContext.bootstrapCerts}
+	for _, cert := range nodeupModelContext.bootstrapCerts {
+		cert.Cert.Task = bootstrapClientTask
+		cert.Key.Task = bootstrapClientTask
+	}
+	context.AddTask(bootstrapClientTask)
+
+	if err := nodeupignition.CheckTasks(context.Tasks); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var out bytes.Buffer
+	target := nodeupignition.NewIgnitionTarget(&out)
+	c, err := fi.NewContext(target, nodeupModelContext.Cluster, nil, nil, nil, nil, false, context.Tasks)
+	if err != nil {
+		t.Fatalf("error building context: %v", err)
+	}
+	defer c.Close()
+
+	var options fi.RunTasksOptions
+	options.InitDefaults()
+	// Fail on the first error, rather than retrying
+	options.MaxTaskDuration = 0
+	if err := c.RunTasks(options); err != nil {
+		t.Fatalf("error running tasks: %v", err)
+	}
+	if err := target.Finish(context.Tasks); err != nil {
+		t.Fatalf("error finishing target: %v", err)
+	}
+
+	var config ignition.Config
+	if err := json.Unmarshal(out.Bytes(), &config); err != nil {
+		t.Fatalf("config is not valid JSON: %v\n%s", err, out.String())
+	}
+
+	files := make(map[string]bool)
+	for _, f := range config.Storage.Files {
+		files[f.Path] = true
+	}
+	for _, p := range []string{"/var/lib/kubelet/kubelet.conf", "/etc/sysconfig/kubelet", "/etc/sysctl.d/99-k8s-general.conf"} {
+		if !files[p] {
+			t.Errorf("expected file %q in config:\n%s", p, out.String())
+		}
+	}
+
+	units := make(map[string]ignition.Unit)
+	for _, u := range config.Systemd.Units {
+		units[u.Name] = u
+	}
+	if u, found := units["kubelet.service"]; !found || u.Contents == nil || !fi.BoolValue(u.Enabled) {
+		t.Errorf("expected an enabled kubelet.service unit with contents in config:\n%s", out.String())
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"strings"
 
 	"k8s.io/kops/pkg/apis/kops/model"
+	"k8s.io/kops/pkg/ospackages"
 	"k8s.io/kops/pkg/systemd"
 	"k8s.io/kops/upup/pkg/fi"
 	"k8s.io/kops/upup/pkg/fi/nodeup/nodetasks"
@@ -44,7 +45,7 @@ func (b *LogrotateBuilder) Build(c *fi.ModelBuilderContext) error {
 	case distributions.DistributionFlatcar:
 		klog.Infof("Detected Flatcar; won't install logrotate")
 	default:
-		c.AddTask(&nodetasks.Package{Name: "logrotate"})
+		b.AddOSPackages(c, ospackages.ComponentLogrotate)
 	}
 
 	b.addLogRotate(c, "docker", "/var/log/docker.log", logRotateOptions{})
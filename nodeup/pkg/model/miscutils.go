@@ -18,8 +18,8 @@ package model
 
 import (
 	"k8s.io/klog/v2"
+	"k8s.io/kops/pkg/ospackages"
 	"k8s.io/kops/upup/pkg/fi"
-	"k8s.io/kops/upup/pkg/fi/nodeup/nodetasks"
 	"k8s.io/kops/util/pkg/distributions"
 )
 
@@ -42,40 +42,12 @@ func (b *MiscUtilsBuilder) Build(c *fi.ModelBuilderContext) error {
 		return nil
 	}
 
-	var packages []string
-	if b.Distribution.IsDebianFamily() {
-		if b.IsKubernetesLT("1.20") {
-			packages = append(packages, "curl")
-			packages = append(packages, "wget")
-			packages = append(packages, "perl")
-			packages = append(packages, "apt-transport-https")
-
-			// TODO: Do we really need python-apt?
-			if (b.Distribution.IsUbuntu() && b.Distribution.Version() >= 20.10) || (!b.Distribution.IsUbuntu() && b.Distribution.Version() >= 11) {
-				// python-apt not available (though python3-apt is)
-			} else {
-				packages = append(packages, "python-apt")
-			}
-		}
-	} else if b.Distribution.IsRHELFamily() {
-		// TODO: These packages have been auto-installed for a long time, and likely we don't need all of them any longer
-		packages = append(packages, "curl")
-		packages = append(packages, "wget")
-		packages = append(packages, "python2")
-		packages = append(packages, "git")
-	} else {
+	if !b.Distribution.IsDebianFamily() && !b.Distribution.IsRHELFamily() && !b.Distribution.IsSUSEFamily() {
 		klog.Warningf("unknown distribution, skipping misc utils install: %v", b.Distribution)
 		return nil
 	}
 
-	if b.Distribution.IsUbuntu() && b.IsKubernetesLT("1.20") {
-		packages = append(packages, "netcat-traditional")
-		packages = append(packages, "git")
-	}
-
-	for _, p := range packages {
-		c.AddTask(&nodetasks.Package{Name: p})
-	}
+	b.AddOSPackages(c, ospackages.ComponentMiscUtils)
 
 	return nil
 }
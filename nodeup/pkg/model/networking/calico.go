@@ -18,8 +18,8 @@ package networking
 
 import (
 	"k8s.io/kops/nodeup/pkg/model"
+	"k8s.io/kops/pkg/ospackages"
 	"k8s.io/kops/upup/pkg/fi"
-	"k8s.io/kops/upup/pkg/fi/nodeup/nodetasks"
 )
 
 // CalicoBuilder configures the etcd TLS support for Calico
@@ -37,9 +37,7 @@ func (b *CalicoBuilder) Build(c *fi.ModelBuilderContext) error {
 		return nil
 	}
 
-	if b.Distribution.IsUbuntu() {
-		c.AddTask(&nodetasks.Package{Name: "wireguard"})
-	}
+	b.AddOSPackages(c, ospackages.ComponentCalico)
 
 	return nil
 }
@@ -19,6 +19,7 @@ package model
 import (
 	"k8s.io/klog/v2"
 	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/ospackages"
 	"k8s.io/kops/upup/pkg/fi"
 	"k8s.io/kops/upup/pkg/fi/nodeup/nodetasks"
 	"k8s.io/kops/util/pkg/distributions"
@@ -35,7 +36,7 @@ var _ fi.ModelBuilder = &NTPBuilder{}
 
 // Build is responsible for configuring NTP
 func (b *NTPBuilder) Build(c *fi.ModelBuilderContext) error {
-	if !b.managed() {
+	if !b.Cluster.Spec.NTP.IsManaged() {
 		klog.Infof("Managed is set to false; won't install NTP")
 		return nil
 	}
@@ -59,19 +60,19 @@ func (b *NTPBuilder) Build(c *fi.ModelBuilderContext) error {
 		ntpHost = ""
 	}
 
+	b.AddOSPackages(c, ospackages.ComponentNTP)
+
 	if !b.RunningOnGCE() && b.Distribution.IsUbuntu() && b.Distribution.Version() <= 20.04 {
 		if ntpHost != "" {
 			c.AddTask(b.buildTimesyncdConf("/etc/systemd/timesyncd.conf", ntpHost))
 		}
 		c.AddTask((&nodetasks.Service{Name: "systemd-timesyncd"}).InitDefaults())
 	} else if b.Distribution.IsDebianFamily() {
-		c.AddTask(&nodetasks.Package{Name: "chrony"})
 		if ntpHost != "" {
 			c.AddTask(b.buildChronydConf("/etc/chrony/chrony.conf", ntpHost))
 		}
 		c.AddTask((&nodetasks.Service{Name: "chrony"}).InitDefaults())
-	} else if b.Distribution.IsRHELFamily() {
-		c.AddTask(&nodetasks.Package{Name: "chrony"})
+	} else if b.Distribution.IsRHELFamily() || b.Distribution.IsSUSEFamily() {
 		if ntpHost != "" {
 			c.AddTask(b.buildChronydConf("/etc/chrony.conf", ntpHost))
 		}
@@ -116,11 +117,3 @@ NTP=` + host + `
 		Mode:     s("0644"),
 	}
 }
-
-// managed determines if kops should manage the installation and configuration of NTP.
-func (b *NTPBuilder) managed() bool {
-	n := b.Cluster.Spec.NTP
-	// Consider the NTP is managed when the NTP configuration
-	// is not specified (for backward compatibility).
-	return n == nil || n.Managed == nil || *n.Managed
-}
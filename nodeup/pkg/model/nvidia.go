@@ -17,6 +17,7 @@ limitations under the License.
 package model
 
 import (
+	"k8s.io/kops/pkg/ospackages"
 	"k8s.io/kops/upup/pkg/fi"
 	"k8s.io/kops/upup/pkg/fi/nodeup/nodetasks"
 )
@@ -33,15 +34,10 @@ func (b *NvidiaBuilder) Build(c *fi.ModelBuilderContext) error {
 	if b.InstallNvidiaRuntime() && b.Distribution.IsUbuntu() {
 		c.AddTask(&nodetasks.AptSource{
 			Name:    "nvidia-container-runtime",
-			Keyring: "https://nvidia.github.io/nvidia-container-runtime/gpgkey",
-			Sources: []string{
-				"deb https://nvidia.github.io/nvidia-container-runtime/stable/ubuntu18.04/$(ARCH) /",
-				"deb https://nvidia.github.io/libnvidia-container/stable/ubuntu18.04/$(ARCH) /",
-				"deb https://nvidia.github.io/nvidia-docker/ubuntu18.04/$(ARCH) /",
-			},
+			Keyring: ospackages.NvidiaKeyring,
+			Sources: ospackages.NvidiaSources,
 		})
-		c.AddTask(&nodetasks.Package{Name: "nvidia-container-runtime"})
-		c.AddTask(&nodetasks.Package{Name: b.NodeupConfig.NvidiaGPU.DriverPackage})
+		b.AddOSPackages(c, ospackages.ComponentNvidia)
 	}
 	return nil
 }
@@ -0,0 +1,125 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/apis/nodeup"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/nodeup/nodetasks"
+	"k8s.io/kops/util/pkg/architectures"
+	"k8s.io/kops/util/pkg/distributions"
+)
+
+// TestOSPackagesMatchBuilders checks that the builders install exactly the packages listed for the node,
+// since the OS package bundles are resolved from that list
+func TestOSPackagesMatchBuilders(t *testing.T) {
+	distros := []distributions.Distribution{
+		distributions.DistributionDebian10,
+		distributions.DistributionDebian11,
+		distributions.DistributionUbuntu1804,
+		distributions.DistributionUbuntu2004,
+		distributions.DistributionUbuntu2204,
+		distributions.DistributionAmazonLinux2,
+		distributions.DistributionAmazonLinux2023,
+		distributions.DistributionRocky8,
+		distributions.DistributionRocky9,
+		distributions.DistributionSLES15,
+		distributions.DistributionFlatcar,
+		distributions.DistributionContainerOS,
+	}
+
+	for _, distro := range distros {
+		for _, kubernetesVersion := range []string{"1.19.0", "1.24.0"} {
+			for _, cloudProvider := range []kops.CloudProviderID{kops.CloudProviderAWS, kops.CloudProviderGCE} {
+				for _, nvidia := range []bool{false, true} {
+					for _, updatePolicy := range []string{kops.UpdatePolicyAutomatic, kops.UpdatePolicyExternal} {
+						name := fmt.Sprintf("%s/%s/%s/nvidia=%v/%s", distro.ID(), kubernetesVersion, cloudProvider, nvidia, updatePolicy)
+						t.Run(name, func(t *testing.T) {
+							testOSPackagesMatchBuilders(t, distro, kubernetesVersion, cloudProvider, nvidia, updatePolicy)
+						})
+					}
+				}
+			}
+		}
+	}
+}
+
+func testOSPackagesMatchBuilders(t *testing.T, distro distributions.Distribution, kubernetesVersion string, cloudProvider kops.CloudProviderID, nvidia bool, updatePolicy string) {
+	modelContext := &NodeupModelContext{
+		Cluster: &kops.Cluster{
+			Spec: kops.ClusterSpec{
+				KubernetesVersion: kubernetesVersion,
+				Networking: &kops.NetworkingSpec{
+					Kubenet: &kops.KubenetNetworkingSpec{},
+				},
+			},
+		},
+		BootConfig: &nodeup.BootConfig{},
+		NodeupConfig: &nodeup.Config{
+			UpdatePolicy: updatePolicy,
+		},
+		CloudProvider: cloudProvider,
+		Distribution:  distro,
+	}
+	if nvidia {
+		modelContext.NodeupConfig.NvidiaGPU = &kops.NvidiaGPUConfig{
+			DriverPackage: kops.NvidiaDefaultDriverPackage,
+			Enabled:       fi.Bool(true),
+		}
+		modelContext.GPUVendor = architectures.GPUVendorNvidia
+	}
+	if err := modelContext.Init(); err != nil {
+		t.Fatalf("error initializing the model context: %v", err)
+	}
+
+	c := &fi.ModelBuilderContext{Tasks: make(map[string]fi.Task)}
+	builders := []fi.ModelBuilder{
+		&PackagesBuilder{NodeupModelContext: modelContext},
+		&MiscUtilsBuilder{NodeupModelContext: modelContext},
+		&NTPBuilder{NodeupModelContext: modelContext},
+		&LogrotateBuilder{NodeupModelContext: modelContext},
+		&UpdateServiceBuilder{NodeupModelContext: modelContext},
+		&NvidiaBuilder{NodeupModelContext: modelContext},
+	}
+	for _, builder := range builders {
+		if err := builder.Build(c); err != nil {
+			t.Fatalf("error building %T: %v", builder, err)
+		}
+	}
+
+	var installed []string
+	for _, task := range c.Tasks {
+		if p, ok := task.(*nodetasks.Package); ok {
+			installed = append(installed, p.Name)
+		}
+	}
+	sort.Strings(installed)
+
+	// The Calico packages are installed by the networking builders, and the cluster does not use Calico
+	listed := modelContext.OSPackages().All()
+	sort.Strings(listed)
+
+	if !reflect.DeepEqual(installed, listed) {
+		t.Errorf("builders install packages that do not match the OS package list, installed=%v, listed=%v", installed, listed)
+	}
+}
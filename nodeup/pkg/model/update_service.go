@@ -18,6 +18,7 @@ package model
 
 import (
 	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/ospackages"
 	"k8s.io/kops/pkg/systemd"
 	"k8s.io/kops/upup/pkg/fi"
 	"k8s.io/kops/upup/pkg/fi/nodeup/nodetasks"
@@ -94,7 +95,7 @@ func (b *UpdateServiceBuilder) buildDebianPackage(c *fi.ModelBuilderContext) {
 	} else {
 
 		klog.Infof("Detected OS %v; installing %s package", b.Distribution, debianPackageName)
-		c.AddTask(&nodetasks.Package{Name: debianPackageName})
+		b.AddOSPackages(c, ospackages.ComponentUpdateService)
 
 		contents = `APT::Periodic::Update-Package-Lists "1";
 APT::Periodic::Unattended-Upgrade "1";
@@ -90,6 +90,16 @@ const (
 	InstanceManagerKarpenter  InstanceManager = "Karpenter"
 )
 
+// UserDataFormat is the format of the user data of the instances in an InstanceGroup
+type UserDataFormat string
+
+const (
+	// UserDataFormatScript is a shell script, combined with any AdditionalUserData in a MIME multi-part archive
+	UserDataFormatScript UserDataFormat = "Script"
+	// UserDataFormatIgnition is an Ignition v3 config, as used by Flatcar and Fedora CoreOS
+	UserDataFormatIgnition UserDataFormat = "Ignition"
+)
+
 // InstanceGroupSpec is the specification for an InstanceGroup
 type InstanceGroupSpec struct {
 	// Manager determines what is managing the node lifecycle
@@ -180,6 +190,8 @@ type InstanceGroupSpec struct {
 	InstanceInterruptionBehavior *string `json:"instanceInterruptionBehavior,omitempty"`
 	// CompressUserData compresses parts of the user data to save space
 	CompressUserData *bool `json:"compressUserData,omitempty"`
+	// UserDataFormat is the format of the user data, Script (default) or Ignition for Flatcar and Fedora CoreOS.
+	UserDataFormat UserDataFormat `json:"userDataFormat,omitempty"`
 	// InstanceMetadata defines the EC2 instance metadata service options (AWS Only)
 	InstanceMetadata *InstanceMetadataOptions `json:"instanceMetadata,omitempty"`
 	// UpdatePolicy determines the policy for applying upgrades automatically.
@@ -22,3 +22,9 @@ type NTPConfig struct {
 	// The NTP configuration task is skipped if this is set to false.
 	Managed *bool `json:"managed,omitempty"`
 }
+
+// IsManaged returns true if kOps manages the NTP configuration.
+// NTP is considered managed when the configuration is not specified, for backward compatibility.
+func (n *NTPConfig) IsManaged() bool {
+	return n == nil || n.Managed == nil || *n.Managed
+}
@@ -52,6 +52,9 @@ type InstanceGroupRole string
 
 type InstanceManager string
 
+// UserDataFormat is the format of the user data of the instances in an InstanceGroup
+type UserDataFormat string
+
 // InstanceGroupSpec is the specification for an InstanceGroup
 type InstanceGroupSpec struct {
 	// Manager determines what is managing the node lifecycle
@@ -146,6 +149,8 @@ type InstanceGroupSpec struct {
 	InstanceInterruptionBehavior *string `json:"instanceInterruptionBehavior,omitempty"`
 	// CompressUserData compresses parts of the user data to save space
 	CompressUserData *bool `json:"compressUserData,omitempty"`
+	// UserDataFormat is the format of the user data, Script (default) or Ignition for Flatcar and Fedora CoreOS.
+	UserDataFormat UserDataFormat `json:"userDataFormat,omitempty"`
 	// InstanceMetadata defines the EC2 instance metadata service options (AWS Only)
 	InstanceMetadata *InstanceMetadataOptions `json:"instanceMetadata,omitempty"`
 	// UpdatePolicy determines the policy for applying upgrades automatically.
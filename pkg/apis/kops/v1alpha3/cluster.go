@@ -92,6 +92,8 @@ type ClusterSpec struct {
 	KeyStore string `json:"keyStore,omitempty"`
 	// ConfigStore is the VFS path to where the configuration (Cluster, InstanceGroups etc) is stored
 	ConfigStore string `json:"configStore,omitempty"`
+	// StateStoreEncryption configures client-side encryption of secrets and private keys in the state store
+	StateStoreEncryption *StateStoreEncryptionSpec `json:"stateStoreEncryption,omitempty"`
 	// DNSZone is the DNS zone we should use when configuring DNS
 	// This is because some clouds let us define a managed zone foo.bar, and then have
 	// kubernetes.dev.foo.bar, without needing to define dev.foo.bar as a hosted zone.
@@ -204,6 +206,10 @@ type ClusterSpec struct {
 	SysctlParameters []string `json:"sysctlParameters,omitempty"`
 	// RollingUpdate defines the default rolling-update settings for instance groups
 	RollingUpdate *RollingUpdate `json:"rollingUpdate,omitempty"`
+	// ValidationChecks are additional checks that must pass for the cluster to validate.
+	// Failures gate rolling updates in the same way as unready nodes or system pods.
+	// +optional
+	ValidationChecks []ValidationCheck `json:"validationChecks,omitempty"`
 	// ClusterAutoscaler defines the cluaster autoscaler configuration.
 	ClusterAutoscaler *ClusterAutoscalerConfig `json:"clusterAutoscaler,omitempty"`
 	// WarmPool defines the default warm pool settings for instance groups (AWS only).
@@ -216,6 +222,8 @@ type ClusterSpec struct {
 	Karpenter *KarpenterConfig `json:"karpenter,omitempty"`
 	// PodIdentityWebhook determines the EKS Pod Identity Webhook configuration.
 	PodIdentityWebhook *PodIdentityWebhookConfig `json:"podIdentityWebhook,omitempty"`
+	// KopsController defines the kops-controller configuration.
+	KopsController *KopsControllerConfig `json:"kopsController,omitempty"`
 }
 
 // PodIdentityWebhookConfig configures an EKS Pod Identity Webhook.
@@ -259,6 +267,49 @@ type KarpenterConfig struct {
 	Enabled bool `json:"enabled,omitempty"`
 }
 
+// KopsControllerConfig configures kops-controller.
+type KopsControllerConfig struct {
+	// MetricsPort is the port on which kops-controller serves Prometheus metrics.
+	// Metrics are not served if it is not set.
+	MetricsPort *int32 `json:"metricsPort,omitempty"`
+	// AdminCredentials configures kops-controller to issue short-lived admin credentials to authenticated users,
+	// so that they do not need access to the CA private key in the state store.
+	AdminCredentials *KopsControllerAdminCredentialsConfig `json:"adminCredentials,omitempty"`
+	// RateLimit limits how often each node or user may request credentials from kops-controller.
+	RateLimit *KopsControllerRateLimitConfig `json:"rateLimit,omitempty"`
+}
+
+// KopsControllerRateLimitConfig limits how often each node or user may request credentials from kops-controller.
+type KopsControllerRateLimitConfig struct {
+	// Requests is the number of requests each node or user may make in each period.
+	// Defaults to 10. Setting it to 0 disables rate limiting.
+	Requests *int32 `json:"requests,omitempty"`
+	// Period is the period in which the requests are counted. Defaults to 1 hour.
+	Period *metav1.Duration `json:"period,omitempty"`
+}
+
+// KopsControllerAdminCredentialsConfig configures the issuing of admin credentials by kops-controller.
+type KopsControllerAdminCredentialsConfig struct {
+	// MaxValidity is the longest lifetime of an issued credential. Defaults to 1 hour.
+	MaxValidity *metav1.Duration `json:"maxValidity,omitempty"`
+	// AWSPrincipals are the ARNs of the IAM users and roles that may request admin credentials.
+	AWSPrincipals []string `json:"awsPrincipals,omitempty"`
+	// OIDC configures authenticating users with OIDC ID tokens.
+	OIDC *KopsControllerOIDCConfig `json:"oidc,omitempty"`
+}
+
+// KopsControllerOIDCConfig configures authenticating users with OIDC ID tokens.
+type KopsControllerOIDCConfig struct {
+	// IssuerURL is the URL of the OIDC issuer, which must use https.
+	IssuerURL string `json:"issuerURL,omitempty"`
+	// ClientID is the audience that ID tokens must be issued for.
+	ClientID string `json:"clientID,omitempty"`
+	// UsernameClaim is the claim that holds the user name. Defaults to "sub".
+	UsernameClaim *string `json:"usernameClaim,omitempty"`
+	// AllowedUsers are the users that may request admin credentials.
+	AllowedUsers []string `json:"allowedUsers,omitempty"`
+}
+
 // ServiceAccountIssuerDiscoveryConfig configures an OIDC Issuer.
 type ServiceAccountIssuerDiscoveryConfig struct {
 	// DiscoveryStore is the VFS path to where OIDC Issuer Discovery metadata is stored.
@@ -317,6 +368,8 @@ type Assets struct {
 	FileRepository *string `json:"fileRepository,omitempty"`
 	// ContainerProxy is a url for a pull-through proxy of a docker registry
 	ContainerProxy *string `json:"containerProxy,omitempty"`
+	// OSPackageBundle installs OS packages from the bundles in the file repository, instead of from the repositories of the distribution
+	OSPackageBundle *bool `json:"osPackageBundle,omitempty"`
 }
 
 // IAMSpec adds control over the IAM security policies applied to resources
@@ -598,6 +651,18 @@ type EtcdBackupSpec struct {
 	BackupStore string `json:"backupStore,omitempty"`
 	// Image is the etcd backup manager image to use.  Setting this will create a sidecar container in the etcd pod with the specified image.
 	Image string `json:"image,omitempty"`
+	// Retention describes how long etcd-manager keeps the backups.
+	Retention *EtcdBackupRetentionSpec `json:"retention,omitempty"`
+}
+
+// EtcdBackupRetentionSpec describes how long the backups of an etcd cluster are kept
+type EtcdBackupRetentionSpec struct {
+	// HourlyBackups is the number of hours for which one backup per hour is kept. Defaults to 168 (7 days).
+	HourlyBackups *int32 `json:"hourlyBackups,omitempty"`
+	// DailyBackups is the number of days for which one backup per day is kept. Defaults to 365.
+	DailyBackups *int32 `json:"dailyBackups,omitempty"`
+	// MaxAge is the age after which backups are removed, even if they are within the hourly or daily backups.
+	MaxAge *metav1.Duration `json:"maxAge,omitempty"`
 }
 
 // EtcdManagerSpec describes how we configure the etcd manager
@@ -788,6 +853,118 @@ type RollingUpdate struct {
 	// nodes.
 	// +optional
 	MaxSurge *intstr.IntOrString `json:"maxSurge,omitempty"`
+	// Canary configures a canary phase for instance groups with role "Node".
+	// A small number of instances is replaced first and the cluster must keep
+	// validating for a soak period before the rest of the instance group is updated.
+	// If the canary fails validation, the whole cluster rolling update is aborted.
+	// +optional
+	Canary *RollingUpdateCanary `json:"canary,omitempty"`
+}
+
+// RollingUpdateCanary configures the canary phase of a rolling update.
+type RollingUpdateCanary struct {
+	// Instances is the number of instances to replace in the canary phase.
+	// The value can be an absolute number (for example 1) or a percentage of
+	// desired nodes (for example 10%).
+	// The absolute number is calculated from a percentage by rounding up.
+	// Defaults to 1.
+	// +optional
+	Instances *intstr.IntOrString `json:"instances,omitempty"`
+	// SoakDuration is how long the cluster must keep validating after the
+	// canary instances have been replaced.
+	// Defaults to 5 minutes.
+	// +optional
+	SoakDuration *metav1.Duration `json:"soakDuration,omitempty"`
+	// StrictValidation fails the canary on validation failures in any instance
+	// group, not just those in the instance group being updated or the control plane.
+	// +optional
+	StrictValidation *bool `json:"strictValidation,omitempty"`
+}
+
+// ValidationCheck is an additional check that is evaluated when validating the cluster.
+// Exactly one of Deployment, HTTPGet or CustomResourceDefinition must be set.
+type ValidationCheck struct {
+	// Name identifies the check in validation failures.
+	Name string `json:"name,omitempty"`
+	// Deployment checks that a Deployment has all of its replicas updated and available.
+	// +optional
+	Deployment *DeploymentValidationCheck `json:"deployment,omitempty"`
+	// HTTPGet checks that an HTTP endpoint of a Service, reached through the API server proxy, returns a 2xx status.
+	// +optional
+	HTTPGet *HTTPGetValidationCheck `json:"httpGet,omitempty"`
+	// CustomResourceDefinition checks that a CustomResourceDefinition is established.
+	// +optional
+	CustomResourceDefinition *CustomResourceDefinitionValidationCheck `json:"customResourceDefinition,omitempty"`
+}
+
+// DeploymentValidationCheck identifies a Deployment that must be ready.
+type DeploymentValidationCheck struct {
+	// Namespace is the namespace of the Deployment.
+	Namespace string `json:"namespace,omitempty"`
+	// Name is the name of the Deployment.
+	Name string `json:"name,omitempty"`
+}
+
+// HTTPGetValidationCheck identifies an HTTP endpoint of a Service that must return a 2xx status.
+type HTTPGetValidationCheck struct {
+	// Namespace is the namespace of the Service.
+	Namespace string `json:"namespace,omitempty"`
+	// Service is the name of the Service.
+	Service string `json:"service,omitempty"`
+	// Port is the name or number of the Service port.
+	Port string `json:"port,omitempty"`
+	// Path is the path to request. Defaults to "/".
+	// +optional
+	Path string `json:"path,omitempty"`
+}
+
+// CustomResourceDefinitionValidationCheck identifies a CustomResourceDefinition that must be established.
+type CustomResourceDefinitionValidationCheck struct {
+	// Name is the name of the CustomResourceDefinition, for example "widgets.example.com".
+	Name string `json:"name,omitempty"`
+}
+
+// StateStoreEncryptionSpec configures client-side encryption of secrets and private keys in the state store.
+// Each file is encrypted with its own data key, which is sealed by the key provider.
+// Exactly one of KeyFile, VaultTransit or AWSKMS must be set.
+type StateStoreEncryptionSpec struct {
+	// KeyFile seals data keys in NaCl sealed boxes to an X25519 key read from a local file.
+	// +optional
+	KeyFile *StateStoreEncryptionKeyFileSpec `json:"keyFile,omitempty"`
+	// VaultTransit seals data keys using the Vault transit secrets engine.
+	// +optional
+	VaultTransit *StateStoreEncryptionVaultTransitSpec `json:"vaultTransit,omitempty"`
+	// AWSKMS seals data keys using AWS KMS.
+	// +optional
+	AWSKMS *StateStoreEncryptionAWSKMSSpec `json:"awsKMS,omitempty"`
+}
+
+// StateStoreEncryptionKeyFileSpec identifies a local key file.
+type StateStoreEncryptionKeyFileSpec struct {
+	// Path is the path to a file containing an X25519 private key, encoded as an age identity as generated by age-keygen.
+	// The KOPS_STATE_ENCRYPTION_KEY_FILE environment variable overrides it. kOps does not distribute the file;
+	// it must be provisioned wherever the state store is read, including on the control plane nodes.
+	Path string `json:"path,omitempty"`
+}
+
+// StateStoreEncryptionVaultTransitSpec identifies a Vault transit key.
+type StateStoreEncryptionVaultTransitSpec struct {
+	// Address is the address of the Vault server, for example https://vault.example.com:8200.
+	Address string `json:"address,omitempty"`
+	// MountPath is the path where the transit secrets engine is mounted. Defaults to "transit".
+	// +optional
+	MountPath string `json:"mountPath,omitempty"`
+	// Key is the name of the transit key.
+	Key string `json:"key,omitempty"`
+}
+
+// StateStoreEncryptionAWSKMSSpec identifies an AWS KMS key.
+type StateStoreEncryptionAWSKMSSpec struct {
+	// KeyID is the ID, ARN or alias of the KMS key.
+	KeyID string `json:"keyID,omitempty"`
+	// Region is the region of the KMS key. Required unless KeyID is an ARN.
+	// +optional
+	Region string `json:"region,omitempty"`
 }
 
 type PackagesConfig struct {
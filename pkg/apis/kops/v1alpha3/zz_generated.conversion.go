@@ -344,6 +344,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*CustomResourceDefinitionValidationCheck)(nil), (*kops.CustomResourceDefinitionValidationCheck)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha3_CustomResourceDefinitionValidationCheck_To_kops_CustomResourceDefinitionValidationCheck(a.(*CustomResourceDefinitionValidationCheck), b.(*kops.CustomResourceDefinitionValidationCheck), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*kops.CustomResourceDefinitionValidationCheck)(nil), (*CustomResourceDefinitionValidationCheck)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_kops_CustomResourceDefinitionValidationCheck_To_v1alpha3_CustomResourceDefinitionValidationCheck(a.(*kops.CustomResourceDefinitionValidationCheck), b.(*CustomResourceDefinitionValidationCheck), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*DNSAccessSpec)(nil), (*kops.DNSAccessSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha3_DNSAccessSpec_To_kops_DNSAccessSpec(a.(*DNSAccessSpec), b.(*kops.DNSAccessSpec), scope)
 	}); err != nil {
@@ -394,6 +404,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*DeploymentValidationCheck)(nil), (*kops.DeploymentValidationCheck)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha3_DeploymentValidationCheck_To_kops_DeploymentValidationCheck(a.(*DeploymentValidationCheck), b.(*kops.DeploymentValidationCheck), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*kops.DeploymentValidationCheck)(nil), (*DeploymentValidationCheck)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_kops_DeploymentValidationCheck_To_v1alpha3_DeploymentValidationCheck(a.(*kops.DeploymentValidationCheck), b.(*DeploymentValidationCheck), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*DockerConfig)(nil), (*kops.DockerConfig)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha3_DockerConfig_To_kops_DockerConfig(a.(*DockerConfig), b.(*kops.DockerConfig), scope)
 	}); err != nil {
@@ -424,6 +444,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*EtcdBackupRetentionSpec)(nil), (*kops.EtcdBackupRetentionSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha3_EtcdBackupRetentionSpec_To_kops_EtcdBackupRetentionSpec(a.(*EtcdBackupRetentionSpec), b.(*kops.EtcdBackupRetentionSpec), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*kops.EtcdBackupRetentionSpec)(nil), (*EtcdBackupRetentionSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_kops_EtcdBackupRetentionSpec_To_v1alpha3_EtcdBackupRetentionSpec(a.(*kops.EtcdBackupRetentionSpec), b.(*EtcdBackupRetentionSpec), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*EtcdBackupSpec)(nil), (*kops.EtcdBackupSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha3_EtcdBackupSpec_To_kops_EtcdBackupSpec(a.(*EtcdBackupSpec), b.(*kops.EtcdBackupSpec), scope)
 	}); err != nil {
@@ -564,6 +594,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*HTTPGetValidationCheck)(nil), (*kops.HTTPGetValidationCheck)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha3_HTTPGetValidationCheck_To_kops_HTTPGetValidationCheck(a.(*HTTPGetValidationCheck), b.(*kops.HTTPGetValidationCheck), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*kops.HTTPGetValidationCheck)(nil), (*HTTPGetValidationCheck)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_kops_HTTPGetValidationCheck_To_v1alpha3_HTTPGetValidationCheck(a.(*kops.HTTPGetValidationCheck), b.(*HTTPGetValidationCheck), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*HTTPProxy)(nil), (*kops.HTTPProxy)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha3_HTTPProxy_To_kops_HTTPProxy(a.(*HTTPProxy), b.(*kops.HTTPProxy), scope)
 	}); err != nil {
@@ -744,6 +784,46 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*KopsControllerAdminCredentialsConfig)(nil), (*kops.KopsControllerAdminCredentialsConfig)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha3_KopsControllerAdminCredentialsConfig_To_kops_KopsControllerAdminCredentialsConfig(a.(*KopsControllerAdminCredentialsConfig), b.(*kops.KopsControllerAdminCredentialsConfig), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*kops.KopsControllerAdminCredentialsConfig)(nil), (*KopsControllerAdminCredentialsConfig)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_kops_KopsControllerAdminCredentialsConfig_To_v1alpha3_KopsControllerAdminCredentialsConfig(a.(*kops.KopsControllerAdminCredentialsConfig), b.(*KopsControllerAdminCredentialsConfig), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*KopsControllerConfig)(nil), (*kops.KopsControllerConfig)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha3_KopsControllerConfig_To_kops_KopsControllerConfig(a.(*KopsControllerConfig), b.(*kops.KopsControllerConfig), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*kops.KopsControllerConfig)(nil), (*KopsControllerConfig)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_kops_KopsControllerConfig_To_v1alpha3_KopsControllerConfig(a.(*kops.KopsControllerConfig), b.(*KopsControllerConfig), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*KopsControllerOIDCConfig)(nil), (*kops.KopsControllerOIDCConfig)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha3_KopsControllerOIDCConfig_To_kops_KopsControllerOIDCConfig(a.(*KopsControllerOIDCConfig), b.(*kops.KopsControllerOIDCConfig), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*kops.KopsControllerOIDCConfig)(nil), (*KopsControllerOIDCConfig)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_kops_KopsControllerOIDCConfig_To_v1alpha3_KopsControllerOIDCConfig(a.(*kops.KopsControllerOIDCConfig), b.(*KopsControllerOIDCConfig), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*KopsControllerRateLimitConfig)(nil), (*kops.KopsControllerRateLimitConfig)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha3_KopsControllerRateLimitConfig_To_kops_KopsControllerRateLimitConfig(a.(*KopsControllerRateLimitConfig), b.(*kops.KopsControllerRateLimitConfig), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*kops.KopsControllerRateLimitConfig)(nil), (*KopsControllerRateLimitConfig)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_kops_KopsControllerRateLimitConfig_To_v1alpha3_KopsControllerRateLimitConfig(a.(*kops.KopsControllerRateLimitConfig), b.(*KopsControllerRateLimitConfig), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*KubeAPIServerConfig)(nil), (*kops.KubeAPIServerConfig)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha3_KubeAPIServerConfig_To_kops_KubeAPIServerConfig(a.(*KubeAPIServerConfig), b.(*kops.KubeAPIServerConfig), scope)
 	}); err != nil {
@@ -1064,6 +1144,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*RollingUpdateCanary)(nil), (*kops.RollingUpdateCanary)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha3_RollingUpdateCanary_To_kops_RollingUpdateCanary(a.(*RollingUpdateCanary), b.(*kops.RollingUpdateCanary), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*kops.RollingUpdateCanary)(nil), (*RollingUpdateCanary)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_kops_RollingUpdateCanary_To_v1alpha3_RollingUpdateCanary(a.(*kops.RollingUpdateCanary), b.(*RollingUpdateCanary), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*RouteSpec)(nil), (*kops.RouteSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha3_RouteSpec_To_kops_RouteSpec(a.(*RouteSpec), b.(*kops.RouteSpec), scope)
 	}); err != nil {
@@ -1134,6 +1224,46 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*StateStoreEncryptionAWSKMSSpec)(nil), (*kops.StateStoreEncryptionAWSKMSSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha3_StateStoreEncryptionAWSKMSSpec_To_kops_StateStoreEncryptionAWSKMSSpec(a.(*StateStoreEncryptionAWSKMSSpec), b.(*kops.StateStoreEncryptionAWSKMSSpec), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*kops.StateStoreEncryptionAWSKMSSpec)(nil), (*StateStoreEncryptionAWSKMSSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_kops_StateStoreEncryptionAWSKMSSpec_To_v1alpha3_StateStoreEncryptionAWSKMSSpec(a.(*kops.StateStoreEncryptionAWSKMSSpec), b.(*StateStoreEncryptionAWSKMSSpec), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*StateStoreEncryptionKeyFileSpec)(nil), (*kops.StateStoreEncryptionKeyFileSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha3_StateStoreEncryptionKeyFileSpec_To_kops_StateStoreEncryptionKeyFileSpec(a.(*StateStoreEncryptionKeyFileSpec), b.(*kops.StateStoreEncryptionKeyFileSpec), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*kops.StateStoreEncryptionKeyFileSpec)(nil), (*StateStoreEncryptionKeyFileSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_kops_StateStoreEncryptionKeyFileSpec_To_v1alpha3_StateStoreEncryptionKeyFileSpec(a.(*kops.StateStoreEncryptionKeyFileSpec), b.(*StateStoreEncryptionKeyFileSpec), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*StateStoreEncryptionSpec)(nil), (*kops.StateStoreEncryptionSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha3_StateStoreEncryptionSpec_To_kops_StateStoreEncryptionSpec(a.(*StateStoreEncryptionSpec), b.(*kops.StateStoreEncryptionSpec), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*kops.StateStoreEncryptionSpec)(nil), (*StateStoreEncryptionSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_kops_StateStoreEncryptionSpec_To_v1alpha3_StateStoreEncryptionSpec(a.(*kops.StateStoreEncryptionSpec), b.(*StateStoreEncryptionSpec), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*StateStoreEncryptionVaultTransitSpec)(nil), (*kops.StateStoreEncryptionVaultTransitSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha3_StateStoreEncryptionVaultTransitSpec_To_kops_StateStoreEncryptionVaultTransitSpec(a.(*StateStoreEncryptionVaultTransitSpec), b.(*kops.StateStoreEncryptionVaultTransitSpec), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*kops.StateStoreEncryptionVaultTransitSpec)(nil), (*StateStoreEncryptionVaultTransitSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_kops_StateStoreEncryptionVaultTransitSpec_To_v1alpha3_StateStoreEncryptionVaultTransitSpec(a.(*kops.StateStoreEncryptionVaultTransitSpec), b.(*StateStoreEncryptionVaultTransitSpec), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*TargetSpec)(nil), (*kops.TargetSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha3_TargetSpec_To_kops_TargetSpec(a.(*TargetSpec), b.(*kops.TargetSpec), scope)
 	}); err != nil {
@@ -1174,6 +1304,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*ValidationCheck)(nil), (*kops.ValidationCheck)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha3_ValidationCheck_To_kops_ValidationCheck(a.(*ValidationCheck), b.(*kops.ValidationCheck), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*kops.ValidationCheck)(nil), (*ValidationCheck)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_kops_ValidationCheck_To_v1alpha3_ValidationCheck(a.(*kops.ValidationCheck), b.(*ValidationCheck), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*VolumeMountSpec)(nil), (*kops.VolumeMountSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha3_VolumeMountSpec_To_kops_VolumeMountSpec(a.(*VolumeMountSpec), b.(*kops.VolumeMountSpec), scope)
 	}); err != nil {
@@ -1551,6 +1691,7 @@ func autoConvert_v1alpha3_Assets_To_kops_Assets(in *Assets, out *kops.Assets, s
 	out.ContainerRegistry = in.ContainerRegistry
 	out.FileRepository = in.FileRepository
 	out.ContainerProxy = in.ContainerProxy
+	out.OSPackageBundle = in.OSPackageBundle
 	return nil
 }
 
@@ -1563,6 +1704,7 @@ func autoConvert_kops_Assets_To_v1alpha3_Assets(in *kops.Assets, out *Assets, s
 	out.ContainerRegistry = in.ContainerRegistry
 	out.FileRepository = in.FileRepository
 	out.ContainerProxy = in.ContainerProxy
+	out.OSPackageBundle = in.OSPackageBundle
 	return nil
 }
 
@@ -2492,6 +2634,15 @@ func autoConvert_v1alpha3_ClusterSpec_To_kops_ClusterSpec(in *ClusterSpec, out *
 	out.SecretStore = in.SecretStore
 	out.KeyStore = in.KeyStore
 	out.ConfigStore = in.ConfigStore
+	if in.StateStoreEncryption != nil {
+		in, out := &in.StateStoreEncryption, &out.StateStoreEncryption
+		*out = new(kops.StateStoreEncryptionSpec)
+		if err := Convert_v1alpha3_StateStoreEncryptionSpec_To_kops_StateStoreEncryptionSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.StateStoreEncryption = nil
+	}
 	out.DNSZone = in.DNSZone
 	if in.DNSControllerGossipConfig != nil {
 		in, out := &in.DNSControllerGossipConfig, &out.DNSControllerGossipConfig
@@ -2797,6 +2948,17 @@ func autoConvert_v1alpha3_ClusterSpec_To_kops_ClusterSpec(in *ClusterSpec, out *
 	} else {
 		out.RollingUpdate = nil
 	}
+	if in.ValidationChecks != nil {
+		in, out := &in.ValidationChecks, &out.ValidationChecks
+		*out = make([]kops.ValidationCheck, len(*in))
+		for i := range *in {
+			if err := Convert_v1alpha3_ValidationCheck_To_kops_ValidationCheck(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.ValidationChecks = nil
+	}
 	if in.ClusterAutoscaler != nil {
 		in, out := &in.ClusterAutoscaler, &out.ClusterAutoscaler
 		*out = new(kops.ClusterAutoscalerConfig)
@@ -2851,6 +3013,15 @@ func autoConvert_v1alpha3_ClusterSpec_To_kops_ClusterSpec(in *ClusterSpec, out *
 	} else {
 		out.PodIdentityWebhook = nil
 	}
+	if in.KopsController != nil {
+		in, out := &in.KopsController, &out.KopsController
+		*out = new(kops.KopsControllerConfig)
+		if err := Convert_v1alpha3_KopsControllerConfig_To_kops_KopsControllerConfig(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.KopsController = nil
+	}
 	return nil
 }
 
@@ -2916,6 +3087,15 @@ func autoConvert_kops_ClusterSpec_To_v1alpha3_ClusterSpec(in *kops.ClusterSpec,
 	out.SecretStore = in.SecretStore
 	out.KeyStore = in.KeyStore
 	out.ConfigStore = in.ConfigStore
+	if in.StateStoreEncryption != nil {
+		in, out := &in.StateStoreEncryption, &out.StateStoreEncryption
+		*out = new(StateStoreEncryptionSpec)
+		if err := Convert_kops_StateStoreEncryptionSpec_To_v1alpha3_StateStoreEncryptionSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.StateStoreEncryption = nil
+	}
 	out.DNSZone = in.DNSZone
 	if in.DNSControllerGossipConfig != nil {
 		in, out := &in.DNSControllerGossipConfig, &out.DNSControllerGossipConfig
@@ -3221,6 +3401,17 @@ func autoConvert_kops_ClusterSpec_To_v1alpha3_ClusterSpec(in *kops.ClusterSpec,
 	} else {
 		out.RollingUpdate = nil
 	}
+	if in.ValidationChecks != nil {
+		in, out := &in.ValidationChecks, &out.ValidationChecks
+		*out = make([]ValidationCheck, len(*in))
+		for i := range *in {
+			if err := Convert_kops_ValidationCheck_To_v1alpha3_ValidationCheck(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.ValidationChecks = nil
+	}
 	if in.ClusterAutoscaler != nil {
 		in, out := &in.ClusterAutoscaler, &out.ClusterAutoscaler
 		*out = new(ClusterAutoscalerConfig)
@@ -3275,6 +3466,15 @@ func autoConvert_kops_ClusterSpec_To_v1alpha3_ClusterSpec(in *kops.ClusterSpec,
 	} else {
 		out.PodIdentityWebhook = nil
 	}
+	if in.KopsController != nil {
+		in, out := &in.KopsController, &out.KopsController
+		*out = new(KopsControllerConfig)
+		if err := Convert_kops_KopsControllerConfig_To_v1alpha3_KopsControllerConfig(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.KopsController = nil
+	}
 	return nil
 }
 
@@ -3411,6 +3611,26 @@ func Convert_kops_ContainerdConfig_To_v1alpha3_ContainerdConfig(in *kops.Contain
 	return autoConvert_kops_ContainerdConfig_To_v1alpha3_ContainerdConfig(in, out, s)
 }
 
+func autoConvert_v1alpha3_CustomResourceDefinitionValidationCheck_To_kops_CustomResourceDefinitionValidationCheck(in *CustomResourceDefinitionValidationCheck, out *kops.CustomResourceDefinitionValidationCheck, s conversion.Scope) error {
+	out.Name = in.Name
+	return nil
+}
+
+// Convert_v1alpha3_CustomResourceDefinitionValidationCheck_To_kops_CustomResourceDefinitionValidationCheck is an autogenerated conversion function.
+func Convert_v1alpha3_CustomResourceDefinitionValidationCheck_To_kops_CustomResourceDefinitionValidationCheck(in *CustomResourceDefinitionValidationCheck, out *kops.CustomResourceDefinitionValidationCheck, s conversion.Scope) error {
+	return autoConvert_v1alpha3_CustomResourceDefinitionValidationCheck_To_kops_CustomResourceDefinitionValidationCheck(in, out, s)
+}
+
+func autoConvert_kops_CustomResourceDefinitionValidationCheck_To_v1alpha3_CustomResourceDefinitionValidationCheck(in *kops.CustomResourceDefinitionValidationCheck, out *CustomResourceDefinitionValidationCheck, s conversion.Scope) error {
+	out.Name = in.Name
+	return nil
+}
+
+// Convert_kops_CustomResourceDefinitionValidationCheck_To_v1alpha3_CustomResourceDefinitionValidationCheck is an autogenerated conversion function.
+func Convert_kops_CustomResourceDefinitionValidationCheck_To_v1alpha3_CustomResourceDefinitionValidationCheck(in *kops.CustomResourceDefinitionValidationCheck, out *CustomResourceDefinitionValidationCheck, s conversion.Scope) error {
+	return autoConvert_kops_CustomResourceDefinitionValidationCheck_To_v1alpha3_CustomResourceDefinitionValidationCheck(in, out, s)
+}
+
 func autoConvert_v1alpha3_DNSAccessSpec_To_kops_DNSAccessSpec(in *DNSAccessSpec, out *kops.DNSAccessSpec, s conversion.Scope) error {
 	return nil
 }
@@ -3537,6 +3757,28 @@ func Convert_kops_DOSpec_To_v1alpha3_DOSpec(in *kops.DOSpec, out *DOSpec, s conv
 	return autoConvert_kops_DOSpec_To_v1alpha3_DOSpec(in, out, s)
 }
 
+func autoConvert_v1alpha3_DeploymentValidationCheck_To_kops_DeploymentValidationCheck(in *DeploymentValidationCheck, out *kops.DeploymentValidationCheck, s conversion.Scope) error {
+	out.Namespace = in.Namespace
+	out.Name = in.Name
+	return nil
+}
+
+// Convert_v1alpha3_DeploymentValidationCheck_To_kops_DeploymentValidationCheck is an autogenerated conversion function.
+func Convert_v1alpha3_DeploymentValidationCheck_To_kops_DeploymentValidationCheck(in *DeploymentValidationCheck, out *kops.DeploymentValidationCheck, s conversion.Scope) error {
+	return autoConvert_v1alpha3_DeploymentValidationCheck_To_kops_DeploymentValidationCheck(in, out, s)
+}
+
+func autoConvert_kops_DeploymentValidationCheck_To_v1alpha3_DeploymentValidationCheck(in *kops.DeploymentValidationCheck, out *DeploymentValidationCheck, s conversion.Scope) error {
+	out.Namespace = in.Namespace
+	out.Name = in.Name
+	return nil
+}
+
+// Convert_kops_DeploymentValidationCheck_To_v1alpha3_DeploymentValidationCheck is an autogenerated conversion function.
+func Convert_kops_DeploymentValidationCheck_To_v1alpha3_DeploymentValidationCheck(in *kops.DeploymentValidationCheck, out *DeploymentValidationCheck, s conversion.Scope) error {
+	return autoConvert_kops_DeploymentValidationCheck_To_v1alpha3_DeploymentValidationCheck(in, out, s)
+}
+
 func autoConvert_v1alpha3_DockerConfig_To_kops_DockerConfig(in *DockerConfig, out *kops.DockerConfig, s conversion.Scope) error {
 	out.AuthorizationPlugins = in.AuthorizationPlugins
 	out.Bridge = in.Bridge
@@ -3687,9 +3929,42 @@ func Convert_kops_EnvVar_To_v1alpha3_EnvVar(in *kops.EnvVar, out *EnvVar, s conv
 	return autoConvert_kops_EnvVar_To_v1alpha3_EnvVar(in, out, s)
 }
 
+func autoConvert_v1alpha3_EtcdBackupRetentionSpec_To_kops_EtcdBackupRetentionSpec(in *EtcdBackupRetentionSpec, out *kops.EtcdBackupRetentionSpec, s conversion.Scope) error {
+	out.HourlyBackups = in.HourlyBackups
+	out.DailyBackups = in.DailyBackups
+	out.MaxAge = in.MaxAge
+	return nil
+}
+
+// Convert_v1alpha3_EtcdBackupRetentionSpec_To_kops_EtcdBackupRetentionSpec is an autogenerated conversion function.
+func Convert_v1alpha3_EtcdBackupRetentionSpec_To_kops_EtcdBackupRetentionSpec(in *EtcdBackupRetentionSpec, out *kops.EtcdBackupRetentionSpec, s conversion.Scope) error {
+	return autoConvert_v1alpha3_EtcdBackupRetentionSpec_To_kops_EtcdBackupRetentionSpec(in, out, s)
+}
+
+func autoConvert_kops_EtcdBackupRetentionSpec_To_v1alpha3_EtcdBackupRetentionSpec(in *kops.EtcdBackupRetentionSpec, out *EtcdBackupRetentionSpec, s conversion.Scope) error {
+	out.HourlyBackups = in.HourlyBackups
+	out.DailyBackups = in.DailyBackups
+	out.MaxAge = in.MaxAge
+	return nil
+}
+
+// Convert_kops_EtcdBackupRetentionSpec_To_v1alpha3_EtcdBackupRetentionSpec is an autogenerated conversion function.
+func Convert_kops_EtcdBackupRetentionSpec_To_v1alpha3_EtcdBackupRetentionSpec(in *kops.EtcdBackupRetentionSpec, out *EtcdBackupRetentionSpec, s conversion.Scope) error {
+	return autoConvert_kops_EtcdBackupRetentionSpec_To_v1alpha3_EtcdBackupRetentionSpec(in, out, s)
+}
+
 func autoConvert_v1alpha3_EtcdBackupSpec_To_kops_EtcdBackupSpec(in *EtcdBackupSpec, out *kops.EtcdBackupSpec, s conversion.Scope) error {
 	out.BackupStore = in.BackupStore
 	out.Image = in.Image
+	if in.Retention != nil {
+		in, out := &in.Retention, &out.Retention
+		*out = new(kops.EtcdBackupRetentionSpec)
+		if err := Convert_v1alpha3_EtcdBackupRetentionSpec_To_kops_EtcdBackupRetentionSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.Retention = nil
+	}
 	return nil
 }
 
@@ -3701,6 +3976,15 @@ func Convert_v1alpha3_EtcdBackupSpec_To_kops_EtcdBackupSpec(in *EtcdBackupSpec,
 func autoConvert_kops_EtcdBackupSpec_To_v1alpha3_EtcdBackupSpec(in *kops.EtcdBackupSpec, out *EtcdBackupSpec, s conversion.Scope) error {
 	out.BackupStore = in.BackupStore
 	out.Image = in.Image
+	if in.Retention != nil {
+		in, out := &in.Retention, &out.Retention
+		*out = new(EtcdBackupRetentionSpec)
+		if err := Convert_kops_EtcdBackupRetentionSpec_To_v1alpha3_EtcdBackupRetentionSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.Retention = nil
+	}
 	return nil
 }
 
@@ -4137,6 +4421,32 @@ func Convert_kops_GossipConfigSecondary_To_v1alpha3_GossipConfigSecondary(in *ko
 	return autoConvert_kops_GossipConfigSecondary_To_v1alpha3_GossipConfigSecondary(in, out, s)
 }
 
+func autoConvert_v1alpha3_HTTPGetValidationCheck_To_kops_HTTPGetValidationCheck(in *HTTPGetValidationCheck, out *kops.HTTPGetValidationCheck, s conversion.Scope) error {
+	out.Namespace = in.Namespace
+	out.Service = in.Service
+	out.Port = in.Port
+	out.Path = in.Path
+	return nil
+}
+
+// Convert_v1alpha3_HTTPGetValidationCheck_To_kops_HTTPGetValidationCheck is an autogenerated conversion function.
+func Convert_v1alpha3_HTTPGetValidationCheck_To_kops_HTTPGetValidationCheck(in *HTTPGetValidationCheck, out *kops.HTTPGetValidationCheck, s conversion.Scope) error {
+	return autoConvert_v1alpha3_HTTPGetValidationCheck_To_kops_HTTPGetValidationCheck(in, out, s)
+}
+
+func autoConvert_kops_HTTPGetValidationCheck_To_v1alpha3_HTTPGetValidationCheck(in *kops.HTTPGetValidationCheck, out *HTTPGetValidationCheck, s conversion.Scope) error {
+	out.Namespace = in.Namespace
+	out.Service = in.Service
+	out.Port = in.Port
+	out.Path = in.Path
+	return nil
+}
+
+// Convert_kops_HTTPGetValidationCheck_To_v1alpha3_HTTPGetValidationCheck is an autogenerated conversion function.
+func Convert_kops_HTTPGetValidationCheck_To_v1alpha3_HTTPGetValidationCheck(in *kops.HTTPGetValidationCheck, out *HTTPGetValidationCheck, s conversion.Scope) error {
+	return autoConvert_kops_HTTPGetValidationCheck_To_v1alpha3_HTTPGetValidationCheck(in, out, s)
+}
+
 func autoConvert_v1alpha3_HTTPProxy_To_kops_HTTPProxy(in *HTTPProxy, out *kops.HTTPProxy, s conversion.Scope) error {
 	out.Host = in.Host
 	out.Port = in.Port
@@ -4536,6 +4846,7 @@ func autoConvert_v1alpha3_InstanceGroupSpec_To_kops_InstanceGroupSpec(in *Instan
 	}
 	out.InstanceInterruptionBehavior = in.InstanceInterruptionBehavior
 	out.CompressUserData = in.CompressUserData
+	out.UserDataFormat = kops.UserDataFormat(in.UserDataFormat)
 	if in.InstanceMetadata != nil {
 		in, out := &in.InstanceMetadata, &out.InstanceMetadata
 		*out = new(kops.InstanceMetadataOptions)
@@ -4708,6 +5019,7 @@ func autoConvert_kops_InstanceGroupSpec_To_v1alpha3_InstanceGroupSpec(in *kops.I
 	}
 	out.InstanceInterruptionBehavior = in.InstanceInterruptionBehavior
 	out.CompressUserData = in.CompressUserData
+	out.UserDataFormat = UserDataFormat(in.UserDataFormat)
 	if in.InstanceMetadata != nil {
 		in, out := &in.InstanceMetadata, &out.InstanceMetadata
 		*out = new(InstanceMetadataOptions)
@@ -5023,6 +5335,150 @@ func Convert_kops_KopeioNetworkingSpec_To_v1alpha3_KopeioNetworkingSpec(in *kops
 	return autoConvert_kops_KopeioNetworkingSpec_To_v1alpha3_KopeioNetworkingSpec(in, out, s)
 }
 
+func autoConvert_v1alpha3_KopsControllerAdminCredentialsConfig_To_kops_KopsControllerAdminCredentialsConfig(in *KopsControllerAdminCredentialsConfig, out *kops.KopsControllerAdminCredentialsConfig, s conversion.Scope) error {
+	out.MaxValidity = in.MaxValidity
+	out.AWSPrincipals = in.AWSPrincipals
+	if in.OIDC != nil {
+		in, out := &in.OIDC, &out.OIDC
+		*out = new(kops.KopsControllerOIDCConfig)
+		if err := Convert_v1alpha3_KopsControllerOIDCConfig_To_kops_KopsControllerOIDCConfig(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.OIDC = nil
+	}
+	return nil
+}
+
+// Convert_v1alpha3_KopsControllerAdminCredentialsConfig_To_kops_KopsControllerAdminCredentialsConfig is an autogenerated conversion function.
+func Convert_v1alpha3_KopsControllerAdminCredentialsConfig_To_kops_KopsControllerAdminCredentialsConfig(in *KopsControllerAdminCredentialsConfig, out *kops.KopsControllerAdminCredentialsConfig, s conversion.Scope) error {
+	return autoConvert_v1alpha3_KopsControllerAdminCredentialsConfig_To_kops_KopsControllerAdminCredentialsConfig(in, out, s)
+}
+
+func autoConvert_kops_KopsControllerAdminCredentialsConfig_To_v1alpha3_KopsControllerAdminCredentialsConfig(in *kops.KopsControllerAdminCredentialsConfig, out *KopsControllerAdminCredentialsConfig, s conversion.Scope) error {
+	out.MaxValidity = in.MaxValidity
+	out.AWSPrincipals = in.AWSPrincipals
+	if in.OIDC != nil {
+		in, out := &in.OIDC, &out.OIDC
+		*out = new(KopsControllerOIDCConfig)
+		if err := Convert_kops_KopsControllerOIDCConfig_To_v1alpha3_KopsControllerOIDCConfig(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.OIDC = nil
+	}
+	return nil
+}
+
+// Convert_kops_KopsControllerAdminCredentialsConfig_To_v1alpha3_KopsControllerAdminCredentialsConfig is an autogenerated conversion function.
+func Convert_kops_KopsControllerAdminCredentialsConfig_To_v1alpha3_KopsControllerAdminCredentialsConfig(in *kops.KopsControllerAdminCredentialsConfig, out *KopsControllerAdminCredentialsConfig, s conversion.Scope) error {
+	return autoConvert_kops_KopsControllerAdminCredentialsConfig_To_v1alpha3_KopsControllerAdminCredentialsConfig(in, out, s)
+}
+
+func autoConvert_v1alpha3_KopsControllerConfig_To_kops_KopsControllerConfig(in *KopsControllerConfig, out *kops.KopsControllerConfig, s conversion.Scope) error {
+	out.MetricsPort = in.MetricsPort
+	if in.AdminCredentials != nil {
+		in, out := &in.AdminCredentials, &out.AdminCredentials
+		*out = new(kops.KopsControllerAdminCredentialsConfig)
+		if err := Convert_v1alpha3_KopsControllerAdminCredentialsConfig_To_kops_KopsControllerAdminCredentialsConfig(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.AdminCredentials = nil
+	}
+	if in.RateLimit != nil {
+		in, out := &in.RateLimit, &out.RateLimit
+		*out = new(kops.KopsControllerRateLimitConfig)
+		if err := Convert_v1alpha3_KopsControllerRateLimitConfig_To_kops_KopsControllerRateLimitConfig(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.RateLimit = nil
+	}
+	return nil
+}
+
+// Convert_v1alpha3_KopsControllerConfig_To_kops_KopsControllerConfig is an autogenerated conversion function.
+func Convert_v1alpha3_KopsControllerConfig_To_kops_KopsControllerConfig(in *KopsControllerConfig, out *kops.KopsControllerConfig, s conversion.Scope) error {
+	return autoConvert_v1alpha3_KopsControllerConfig_To_kops_KopsControllerConfig(in, out, s)
+}
+
+func autoConvert_kops_KopsControllerConfig_To_v1alpha3_KopsControllerConfig(in *kops.KopsControllerConfig, out *KopsControllerConfig, s conversion.Scope) error {
+	out.MetricsPort = in.MetricsPort
+	if in.AdminCredentials != nil {
+		in, out := &in.AdminCredentials, &out.AdminCredentials
+		*out = new(KopsControllerAdminCredentialsConfig)
+		if err := Convert_kops_KopsControllerAdminCredentialsConfig_To_v1alpha3_KopsControllerAdminCredentialsConfig(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.AdminCredentials = nil
+	}
+	if in.RateLimit != nil {
+		in, out := &in.RateLimit, &out.RateLimit
+		*out = new(KopsControllerRateLimitConfig)
+		if err := Convert_kops_KopsControllerRateLimitConfig_To_v1alpha3_KopsControllerRateLimitConfig(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.RateLimit = nil
+	}
+	return nil
+}
+
+// Convert_kops_KopsControllerConfig_To_v1alpha3_KopsControllerConfig is an autogenerated conversion function.
+func Convert_kops_KopsControllerConfig_To_v1alpha3_KopsControllerConfig(in *kops.KopsControllerConfig, out *KopsControllerConfig, s conversion.Scope) error {
+	return autoConvert_kops_KopsControllerConfig_To_v1alpha3_KopsControllerConfig(in, out, s)
+}
+
+func autoConvert_v1alpha3_KopsControllerOIDCConfig_To_kops_KopsControllerOIDCConfig(in *KopsControllerOIDCConfig, out *kops.KopsControllerOIDCConfig, s conversion.Scope) error {
+	out.IssuerURL = in.IssuerURL
+	out.ClientID = in.ClientID
+	out.UsernameClaim = in.UsernameClaim
+	out.AllowedUsers = in.AllowedUsers
+	return nil
+}
+
+// Convert_v1alpha3_KopsControllerOIDCConfig_To_kops_KopsControllerOIDCConfig is an autogenerated conversion function.
+func Convert_v1alpha3_KopsControllerOIDCConfig_To_kops_KopsControllerOIDCConfig(in *KopsControllerOIDCConfig, out *kops.KopsControllerOIDCConfig, s conversion.Scope) error {
+	return autoConvert_v1alpha3_KopsControllerOIDCConfig_To_kops_KopsControllerOIDCConfig(in, out, s)
+}
+
+func autoConvert_kops_KopsControllerOIDCConfig_To_v1alpha3_KopsControllerOIDCConfig(in *kops.KopsControllerOIDCConfig, out *KopsControllerOIDCConfig, s conversion.Scope) error {
+	out.IssuerURL = in.IssuerURL
+	out.ClientID = in.ClientID
+	out.UsernameClaim = in.UsernameClaim
+	out.AllowedUsers = in.AllowedUsers
+	return nil
+}
+
+// Convert_kops_KopsControllerOIDCConfig_To_v1alpha3_KopsControllerOIDCConfig is an autogenerated conversion function.
+func Convert_kops_KopsControllerOIDCConfig_To_v1alpha3_KopsControllerOIDCConfig(in *kops.KopsControllerOIDCConfig, out *KopsControllerOIDCConfig, s conversion.Scope) error {
+	return autoConvert_kops_KopsControllerOIDCConfig_To_v1alpha3_KopsControllerOIDCConfig(in, out, s)
+}
+
+func autoConvert_v1alpha3_KopsControllerRateLimitConfig_To_kops_KopsControllerRateLimitConfig(in *KopsControllerRateLimitConfig, out *kops.KopsControllerRateLimitConfig, s conversion.Scope) error {
+	out.Requests = in.Requests
+	out.Period = in.Period
+	return nil
+}
+
+// Convert_v1alpha3_KopsControllerRateLimitConfig_To_kops_KopsControllerRateLimitConfig is an autogenerated conversion function.
+func Convert_v1alpha3_KopsControllerRateLimitConfig_To_kops_KopsControllerRateLimitConfig(in *KopsControllerRateLimitConfig, out *kops.KopsControllerRateLimitConfig, s conversion.Scope) error {
+	return autoConvert_v1alpha3_KopsControllerRateLimitConfig_To_kops_KopsControllerRateLimitConfig(in, out, s)
+}
+
+func autoConvert_kops_KopsControllerRateLimitConfig_To_v1alpha3_KopsControllerRateLimitConfig(in *kops.KopsControllerRateLimitConfig, out *KopsControllerRateLimitConfig, s conversion.Scope) error {
+	out.Requests = in.Requests
+	out.Period = in.Period
+	return nil
+}
+
+// Convert_kops_KopsControllerRateLimitConfig_To_v1alpha3_KopsControllerRateLimitConfig is an autogenerated conversion function.
+func Convert_kops_KopsControllerRateLimitConfig_To_v1alpha3_KopsControllerRateLimitConfig(in *kops.KopsControllerRateLimitConfig, out *KopsControllerRateLimitConfig, s conversion.Scope) error {
+	return autoConvert_kops_KopsControllerRateLimitConfig_To_v1alpha3_KopsControllerRateLimitConfig(in, out, s)
+}
+
 func autoConvert_v1alpha3_KubeAPIServerConfig_To_kops_KubeAPIServerConfig(in *KubeAPIServerConfig, out *kops.KubeAPIServerConfig, s conversion.Scope) error {
 	out.Image = in.Image
 	out.DisableBasicAuth = in.DisableBasicAuth
@@ -6799,6 +7255,15 @@ func autoConvert_v1alpha3_RollingUpdate_To_kops_RollingUpdate(in *RollingUpdate,
 	out.DrainAndTerminate = in.DrainAndTerminate
 	out.MaxUnavailable = in.MaxUnavailable
 	out.MaxSurge = in.MaxSurge
+	if in.Canary != nil {
+		in, out := &in.Canary, &out.Canary
+		*out = new(kops.RollingUpdateCanary)
+		if err := Convert_v1alpha3_RollingUpdateCanary_To_kops_RollingUpdateCanary(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.Canary = nil
+	}
 	return nil
 }
 
@@ -6811,6 +7276,15 @@ func autoConvert_kops_RollingUpdate_To_v1alpha3_RollingUpdate(in *kops.RollingUp
 	out.DrainAndTerminate = in.DrainAndTerminate
 	out.MaxUnavailable = in.MaxUnavailable
 	out.MaxSurge = in.MaxSurge
+	if in.Canary != nil {
+		in, out := &in.Canary, &out.Canary
+		*out = new(RollingUpdateCanary)
+		if err := Convert_kops_RollingUpdateCanary_To_v1alpha3_RollingUpdateCanary(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.Canary = nil
+	}
 	return nil
 }
 
@@ -6819,6 +7293,30 @@ func Convert_kops_RollingUpdate_To_v1alpha3_RollingUpdate(in *kops.RollingUpdate
 	return autoConvert_kops_RollingUpdate_To_v1alpha3_RollingUpdate(in, out, s)
 }
 
+func autoConvert_v1alpha3_RollingUpdateCanary_To_kops_RollingUpdateCanary(in *RollingUpdateCanary, out *kops.RollingUpdateCanary, s conversion.Scope) error {
+	out.Instances = in.Instances
+	out.SoakDuration = in.SoakDuration
+	out.StrictValidation = in.StrictValidation
+	return nil
+}
+
+// Convert_v1alpha3_RollingUpdateCanary_To_kops_RollingUpdateCanary is an autogenerated conversion function.
+func Convert_v1alpha3_RollingUpdateCanary_To_kops_RollingUpdateCanary(in *RollingUpdateCanary, out *kops.RollingUpdateCanary, s conversion.Scope) error {
+	return autoConvert_v1alpha3_RollingUpdateCanary_To_kops_RollingUpdateCanary(in, out, s)
+}
+
+func autoConvert_kops_RollingUpdateCanary_To_v1alpha3_RollingUpdateCanary(in *kops.RollingUpdateCanary, out *RollingUpdateCanary, s conversion.Scope) error {
+	out.Instances = in.Instances
+	out.SoakDuration = in.SoakDuration
+	out.StrictValidation = in.StrictValidation
+	return nil
+}
+
+// Convert_kops_RollingUpdateCanary_To_v1alpha3_RollingUpdateCanary is an autogenerated conversion function.
+func Convert_kops_RollingUpdateCanary_To_v1alpha3_RollingUpdateCanary(in *kops.RollingUpdateCanary, out *RollingUpdateCanary, s conversion.Scope) error {
+	return autoConvert_kops_RollingUpdateCanary_To_v1alpha3_RollingUpdateCanary(in, out, s)
+}
+
 func autoConvert_v1alpha3_RouteSpec_To_kops_RouteSpec(in *RouteSpec, out *kops.RouteSpec, s conversion.Scope) error {
 	out.CIDR = in.CIDR
 	out.Target = in.Target
@@ -7015,6 +7513,144 @@ func Convert_kops_SnapshotControllerConfig_To_v1alpha3_SnapshotControllerConfig(
 	return autoConvert_kops_SnapshotControllerConfig_To_v1alpha3_SnapshotControllerConfig(in, out, s)
 }
 
+func autoConvert_v1alpha3_StateStoreEncryptionAWSKMSSpec_To_kops_StateStoreEncryptionAWSKMSSpec(in *StateStoreEncryptionAWSKMSSpec, out *kops.StateStoreEncryptionAWSKMSSpec, s conversion.Scope) error {
+	out.KeyID = in.KeyID
+	out.Region = in.Region
+	return nil
+}
+
+// Convert_v1alpha3_StateStoreEncryptionAWSKMSSpec_To_kops_StateStoreEncryptionAWSKMSSpec is an autogenerated conversion function.
+func Convert_v1alpha3_StateStoreEncryptionAWSKMSSpec_To_kops_StateStoreEncryptionAWSKMSSpec(in *StateStoreEncryptionAWSKMSSpec, out *kops.StateStoreEncryptionAWSKMSSpec, s conversion.Scope) error {
+	return autoConvert_v1alpha3_StateStoreEncryptionAWSKMSSpec_To_kops_StateStoreEncryptionAWSKMSSpec(in, out, s)
+}
+
+func autoConvert_kops_StateStoreEncryptionAWSKMSSpec_To_v1alpha3_StateStoreEncryptionAWSKMSSpec(in *kops.StateStoreEncryptionAWSKMSSpec, out *StateStoreEncryptionAWSKMSSpec, s conversion.Scope) error {
+	out.KeyID = in.KeyID
+	out.Region = in.Region
+	return nil
+}
+
+// Convert_kops_StateStoreEncryptionAWSKMSSpec_To_v1alpha3_StateStoreEncryptionAWSKMSSpec is an autogenerated conversion function.
+func Convert_kops_StateStoreEncryptionAWSKMSSpec_To_v1alpha3_StateStoreEncryptionAWSKMSSpec(in *kops.StateStoreEncryptionAWSKMSSpec, out *StateStoreEncryptionAWSKMSSpec, s conversion.Scope) error {
+	return autoConvert_kops_StateStoreEncryptionAWSKMSSpec_To_v1alpha3_StateStoreEncryptionAWSKMSSpec(in, out, s)
+}
+
+func autoConvert_v1alpha3_StateStoreEncryptionKeyFileSpec_To_kops_StateStoreEncryptionKeyFileSpec(in *StateStoreEncryptionKeyFileSpec, out *kops.StateStoreEncryptionKeyFileSpec, s conversion.Scope) error {
+	out.Path = in.Path
+	return nil
+}
+
+// Convert_v1alpha3_StateStoreEncryptionKeyFileSpec_To_kops_StateStoreEncryptionKeyFileSpec is an autogenerated conversion function.
+func Convert_v1alpha3_StateStoreEncryptionKeyFileSpec_To_kops_StateStoreEncryptionKeyFileSpec(in *StateStoreEncryptionKeyFileSpec, out *kops.StateStoreEncryptionKeyFileSpec, s conversion.Scope) error {
+	return autoConvert_v1alpha3_StateStoreEncryptionKeyFileSpec_To_kops_StateStoreEncryptionKeyFileSpec(in, out, s)
+}
+
+func autoConvert_kops_StateStoreEncryptionKeyFileSpec_To_v1alpha3_StateStoreEncryptionKeyFileSpec(in *kops.StateStoreEncryptionKeyFileSpec, out *StateStoreEncryptionKeyFileSpec, s conversion.Scope) error {
+	out.Path = in.Path
+	return nil
+}
+
+// Convert_kops_StateStoreEncryptionKeyFileSpec_To_v1alpha3_StateStoreEncryptionKeyFileSpec is an autogenerated conversion function.
+func Convert_kops_StateStoreEncryptionKeyFileSpec_To_v1alpha3_StateStoreEncryptionKeyFileSpec(in *kops.StateStoreEncryptionKeyFileSpec, out *StateStoreEncryptionKeyFileSpec, s conversion.Scope) error {
+	return autoConvert_kops_StateStoreEncryptionKeyFileSpec_To_v1alpha3_StateStoreEncryptionKeyFileSpec(in, out, s)
+}
+
+func autoConvert_v1alpha3_StateStoreEncryptionSpec_To_kops_StateStoreEncryptionSpec(in *StateStoreEncryptionSpec, out *kops.StateStoreEncryptionSpec, s conversion.Scope) error {
+	if in.KeyFile != nil {
+		in, out := &in.KeyFile, &out.KeyFile
+		*out = new(kops.StateStoreEncryptionKeyFileSpec)
+		if err := Convert_v1alpha3_StateStoreEncryptionKeyFileSpec_To_kops_StateStoreEncryptionKeyFileSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.KeyFile = nil
+	}
+	if in.VaultTransit != nil {
+		in, out := &in.VaultTransit, &out.VaultTransit
+		*out = new(kops.StateStoreEncryptionVaultTransitSpec)
+		if err := Convert_v1alpha3_StateStoreEncryptionVaultTransitSpec_To_kops_StateStoreEncryptionVaultTransitSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.VaultTransit = nil
+	}
+	if in.AWSKMS != nil {
+		in, out := &in.AWSKMS, &out.AWSKMS
+		*out = new(kops.StateStoreEncryptionAWSKMSSpec)
+		if err := Convert_v1alpha3_StateStoreEncryptionAWSKMSSpec_To_kops_StateStoreEncryptionAWSKMSSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.AWSKMS = nil
+	}
+	return nil
+}
+
+// Convert_v1alpha3_StateStoreEncryptionSpec_To_kops_StateStoreEncryptionSpec is an autogenerated conversion function.
+func Convert_v1alpha3_StateStoreEncryptionSpec_To_kops_StateStoreEncryptionSpec(in *StateStoreEncryptionSpec, out *kops.StateStoreEncryptionSpec, s conversion.Scope) error {
+	return autoConvert_v1alpha3_StateStoreEncryptionSpec_To_kops_StateStoreEncryptionSpec(in, out, s)
+}
+
+func autoConvert_kops_StateStoreEncryptionSpec_To_v1alpha3_StateStoreEncryptionSpec(in *kops.StateStoreEncryptionSpec, out *StateStoreEncryptionSpec, s conversion.Scope) error {
+	if in.KeyFile != nil {
+		in, out := &in.KeyFile, &out.KeyFile
+		*out = new(StateStoreEncryptionKeyFileSpec)
+		if err := Convert_kops_StateStoreEncryptionKeyFileSpec_To_v1alpha3_StateStoreEncryptionKeyFileSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.KeyFile = nil
+	}
+	if in.VaultTransit != nil {
+		in, out := &in.VaultTransit, &out.VaultTransit
+		*out = new(StateStoreEncryptionVaultTransitSpec)
+		if err := Convert_kops_StateStoreEncryptionVaultTransitSpec_To_v1alpha3_StateStoreEncryptionVaultTransitSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.VaultTransit = nil
+	}
+	if in.AWSKMS != nil {
+		in, out := &in.AWSKMS, &out.AWSKMS
+		*out = new(StateStoreEncryptionAWSKMSSpec)
+		if err := Convert_kops_StateStoreEncryptionAWSKMSSpec_To_v1alpha3_StateStoreEncryptionAWSKMSSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.AWSKMS = nil
+	}
+	return nil
+}
+
+// Convert_kops_StateStoreEncryptionSpec_To_v1alpha3_StateStoreEncryptionSpec is an autogenerated conversion function.
+func Convert_kops_StateStoreEncryptionSpec_To_v1alpha3_StateStoreEncryptionSpec(in *kops.StateStoreEncryptionSpec, out *StateStoreEncryptionSpec, s conversion.Scope) error {
+	return autoConvert_kops_StateStoreEncryptionSpec_To_v1alpha3_StateStoreEncryptionSpec(in, out, s)
+}
+
+func autoConvert_v1alpha3_StateStoreEncryptionVaultTransitSpec_To_kops_StateStoreEncryptionVaultTransitSpec(in *StateStoreEncryptionVaultTransitSpec, out *kops.StateStoreEncryptionVaultTransitSpec, s conversion.Scope) error {
+	out.Address = in.Address
+	out.MountPath = in.MountPath
+	out.Key = in.Key
+	return nil
+}
+
+// Convert_v1alpha3_StateStoreEncryptionVaultTransitSpec_To_kops_StateStoreEncryptionVaultTransitSpec is an autogenerated conversion function.
+func Convert_v1alpha3_StateStoreEncryptionVaultTransitSpec_To_kops_StateStoreEncryptionVaultTransitSpec(in *StateStoreEncryptionVaultTransitSpec, out *kops.StateStoreEncryptionVaultTransitSpec, s conversion.Scope) error {
+	return autoConvert_v1alpha3_StateStoreEncryptionVaultTransitSpec_To_kops_StateStoreEncryptionVaultTransitSpec(in, out, s)
+}
+
+func autoConvert_kops_StateStoreEncryptionVaultTransitSpec_To_v1alpha3_StateStoreEncryptionVaultTransitSpec(in *kops.StateStoreEncryptionVaultTransitSpec, out *StateStoreEncryptionVaultTransitSpec, s conversion.Scope) error {
+	out.Address = in.Address
+	out.MountPath = in.MountPath
+	out.Key = in.Key
+	return nil
+}
+
+// Convert_kops_StateStoreEncryptionVaultTransitSpec_To_v1alpha3_StateStoreEncryptionVaultTransitSpec is an autogenerated conversion function.
+func Convert_kops_StateStoreEncryptionVaultTransitSpec_To_v1alpha3_StateStoreEncryptionVaultTransitSpec(in *kops.StateStoreEncryptionVaultTransitSpec, out *StateStoreEncryptionVaultTransitSpec, s conversion.Scope) error {
+	return autoConvert_kops_StateStoreEncryptionVaultTransitSpec_To_v1alpha3_StateStoreEncryptionVaultTransitSpec(in, out, s)
+}
+
 func autoConvert_v1alpha3_TargetSpec_To_kops_TargetSpec(in *TargetSpec, out *kops.TargetSpec, s conversion.Scope) error {
 	if in.Terraform != nil {
 		in, out := &in.Terraform, &out.Terraform
@@ -7155,6 +7791,80 @@ func Convert_kops_UserData_To_v1alpha3_UserData(in *kops.UserData, out *UserData
 	return autoConvert_kops_UserData_To_v1alpha3_UserData(in, out, s)
 }
 
+func autoConvert_v1alpha3_ValidationCheck_To_kops_ValidationCheck(in *ValidationCheck, out *kops.ValidationCheck, s conversion.Scope) error {
+	out.Name = in.Name
+	if in.Deployment != nil {
+		in, out := &in.Deployment, &out.Deployment
+		*out = new(kops.DeploymentValidationCheck)
+		if err := Convert_v1alpha3_DeploymentValidationCheck_To_kops_DeploymentValidationCheck(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.Deployment = nil
+	}
+	if in.HTTPGet != nil {
+		in, out := &in.HTTPGet, &out.HTTPGet
+		*out = new(kops.HTTPGetValidationCheck)
+		if err := Convert_v1alpha3_HTTPGetValidationCheck_To_kops_HTTPGetValidationCheck(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.HTTPGet = nil
+	}
+	if in.CustomResourceDefinition != nil {
+		in, out := &in.CustomResourceDefinition, &out.CustomResourceDefinition
+		*out = new(kops.CustomResourceDefinitionValidationCheck)
+		if err := Convert_v1alpha3_CustomResourceDefinitionValidationCheck_To_kops_CustomResourceDefinitionValidationCheck(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.CustomResourceDefinition = nil
+	}
+	return nil
+}
+
+// Convert_v1alpha3_ValidationCheck_To_kops_ValidationCheck is an autogenerated conversion function.
+func Convert_v1alpha3_ValidationCheck_To_kops_ValidationCheck(in *ValidationCheck, out *kops.ValidationCheck, s conversion.Scope) error {
+	return autoConvert_v1alpha3_ValidationCheck_To_kops_ValidationCheck(in, out, s)
+}
+
+func autoConvert_kops_ValidationCheck_To_v1alpha3_ValidationCheck(in *kops.ValidationCheck, out *ValidationCheck, s conversion.Scope) error {
+	out.Name = in.Name
+	if in.Deployment != nil {
+		in, out := &in.Deployment, &out.Deployment
+		*out = new(DeploymentValidationCheck)
+		if err := Convert_kops_DeploymentValidationCheck_To_v1alpha3_DeploymentValidationCheck(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.Deployment = nil
+	}
+	if in.HTTPGet != nil {
+		in, out := &in.HTTPGet, &out.HTTPGet
+		*out = new(HTTPGetValidationCheck)
+		if err := Convert_kops_HTTPGetValidationCheck_To_v1alpha3_HTTPGetValidationCheck(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.HTTPGet = nil
+	}
+	if in.CustomResourceDefinition != nil {
+		in, out := &in.CustomResourceDefinition, &out.CustomResourceDefinition
+		*out = new(CustomResourceDefinitionValidationCheck)
+		if err := Convert_kops_CustomResourceDefinitionValidationCheck_To_v1alpha3_CustomResourceDefinitionValidationCheck(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.CustomResourceDefinition = nil
+	}
+	return nil
+}
+
+// Convert_kops_ValidationCheck_To_v1alpha3_ValidationCheck is an autogenerated conversion function.
+func Convert_kops_ValidationCheck_To_v1alpha3_ValidationCheck(in *kops.ValidationCheck, out *ValidationCheck, s conversion.Scope) error {
+	return autoConvert_kops_ValidationCheck_To_v1alpha3_ValidationCheck(in, out, s)
+}
+
 func autoConvert_v1alpha3_VolumeMountSpec_To_kops_VolumeMountSpec(in *VolumeMountSpec, out *kops.VolumeMountSpec, s conversion.Scope) error {
 	out.Device = in.Device
 	out.Filesystem = in.Filesystem
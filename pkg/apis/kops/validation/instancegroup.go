@@ -104,6 +104,16 @@ func ValidateInstanceGroup(g *kops.InstanceGroup, cloud fi.Cloud, strict bool) f
 		allErrs = append(allErrs, validateExtraUserData(&UserDataInfo)...)
 	}
 
+	switch g.Spec.UserDataFormat {
+	case "", kops.UserDataFormatScript:
+	case kops.UserDataFormatIgnition:
+		if len(g.Spec.AdditionalUserData) > 0 {
+			allErrs = append(allErrs, field.Forbidden(field.NewPath("spec", "additionalUserData"), "additionalUserData is not supported with the Ignition user data format"))
+		}
+	default:
+		allErrs = append(allErrs, field.NotSupported(field.NewPath("spec", "userDataFormat"), g.Spec.UserDataFormat, []string{string(kops.UserDataFormatScript), string(kops.UserDataFormatIgnition)}))
+	}
+
 	// @step: iterate and check the volume specs
 	for i, x := range g.Spec.Volumes {
 		devices := make(map[string]bool)
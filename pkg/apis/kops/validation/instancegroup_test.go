@@ -382,6 +382,52 @@ func TestIGUpdatePolicy(t *testing.T) {
 	}
 }
 
+func TestIGUserDataFormat(t *testing.T) {
+	for _, test := range []struct {
+		label              string
+		format             kops.UserDataFormat
+		additionalUserData []kops.UserData
+		expected           []string
+	}{
+		{
+			label: "missing",
+		},
+		{
+			label:  "script",
+			format: kops.UserDataFormatScript,
+			additionalUserData: []kops.UserData{
+				{Name: "extra.sh", Type: "text/x-shellscript", Content: "#!/bin/sh"},
+			},
+		},
+		{
+			label:  "ignition",
+			format: kops.UserDataFormatIgnition,
+		},
+		{
+			label:  "ignition with additional user data",
+			format: kops.UserDataFormatIgnition,
+			additionalUserData: []kops.UserData{
+				{Name: "extra.sh", Type: "text/x-shellscript", Content: "#!/bin/sh"},
+			},
+			expected: []string{"Forbidden::spec.additionalUserData"},
+		},
+		{
+			label:    "unknown",
+			format:   "cloud-config",
+			expected: []string{"Unsupported value::spec.userDataFormat"},
+		},
+	} {
+		ig := createMinimalInstanceGroup()
+
+		t.Run(test.label, func(t *testing.T) {
+			ig.Spec.UserDataFormat = test.format
+			ig.Spec.AdditionalUserData = test.additionalUserData
+			errs := ValidateInstanceGroup(ig, nil, true)
+			testErrors(t, test.label, errs, test.expected)
+		})
+	}
+}
+
 func TestValidInstanceGroup(t *testing.T) {
 	grid := []struct {
 		IG             *kops.InstanceGroup
@@ -23,6 +23,7 @@ import (
 	"net/url"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws/arn"
 	"github.com/blang/semver/v4"
@@ -36,10 +37,12 @@ import (
 	"k8s.io/apimachinery/pkg/util/validation/field"
 
 	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/apis/kops/model"
 	"k8s.io/kops/pkg/dns"
 	"k8s.io/kops/pkg/featureflag"
 	"k8s.io/kops/pkg/model/components"
 	"k8s.io/kops/pkg/model/iam"
+	"k8s.io/kops/pkg/wellknownports"
 	"k8s.io/kops/upup/pkg/fi"
 	"k8s.io/kops/upup/pkg/fi/utils"
 )
@@ -238,12 +241,25 @@ func validateClusterSpec(spec *kops.ClusterSpec, c *kops.Cluster, fieldPath *fie
 		if spec.Assets.ContainerProxy != nil && spec.Assets.ContainerRegistry != nil {
 			allErrs = append(allErrs, field.Forbidden(fieldPath.Child("assets", "containerProxy"), "containerProxy cannot be used in conjunction with containerRegistry"))
 		}
+		if fi.BoolValue(spec.Assets.OSPackageBundle) && fi.StringValue(spec.Assets.FileRepository) == "" {
+			allErrs = append(allErrs, field.Forbidden(fieldPath.Child("assets", "osPackageBundle"), "osPackageBundle requires fileRepository"))
+		}
 	}
 
 	if spec.RollingUpdate != nil {
 		allErrs = append(allErrs, validateRollingUpdate(spec.RollingUpdate, fieldPath.Child("rollingUpdate"), false)...)
 	}
 
+	allErrs = append(allErrs, validateValidationChecks(spec.ValidationChecks, fieldPath.Child("validationChecks"))...)
+
+	if spec.StateStoreEncryption != nil {
+		allErrs = append(allErrs, validateStateStoreEncryption(spec.StateStoreEncryption, fieldPath.Child("stateStoreEncryption"))...)
+	}
+
+	if spec.KopsController != nil {
+		allErrs = append(allErrs, validateKopsController(c, spec.KopsController, fieldPath.Child("kopsController"))...)
+	}
+
 	if spec.API != nil && spec.API.LoadBalancer != nil {
 		lbSpec := spec.API.LoadBalancer
 		lbPath := fieldPath.Child("api", "loadBalancer")
@@ -1130,6 +1146,27 @@ func validateEtcdClusterSpec(spec kops.EtcdClusterSpec, c *kops.Cluster, fieldPa
 	for i, m := range spec.Members {
 		allErrs = append(allErrs, validateEtcdMemberSpec(m, fieldPath.Child("etcdMembers").Index(i))...)
 	}
+	if spec.Backups != nil && spec.Backups.Retention != nil {
+		allErrs = append(allErrs, validateEtcdBackupRetention(spec.Backups.Retention, fieldPath.Child("backups", "retention"))...)
+	}
+
+	return allErrs
+}
+
+// validateEtcdBackupRetention checks the retention of etcd backups
+func validateEtcdBackupRetention(spec *kops.EtcdBackupRetentionSpec, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if spec.HourlyBackups != nil && *spec.HourlyBackups < 1 {
+		allErrs = append(allErrs, field.Invalid(fieldPath.Child("hourlyBackups"), *spec.HourlyBackups, "must be at least 1"))
+	}
+	if spec.DailyBackups != nil && *spec.DailyBackups < 1 {
+		allErrs = append(allErrs, field.Invalid(fieldPath.Child("dailyBackups"), *spec.DailyBackups, "must be at least 1"))
+	}
+	// Backups taken within the last hour are always kept
+	if spec.MaxAge != nil && spec.MaxAge.Duration < time.Hour {
+		allErrs = append(allErrs, field.Invalid(fieldPath.Child("maxAge"), spec.MaxAge.Duration.String(), "must be at least 1h"))
+	}
 
 	return allErrs
 }
@@ -1554,6 +1591,189 @@ func validateRollingUpdate(rollingUpdate *kops.RollingUpdate, fldpath *field.Pat
 			allErrs = append(allErrs, field.Forbidden(fldpath.Child("maxSurge"), "Cannot be zero if maxUnavailable is zero"))
 		}
 	}
+	if rollingUpdate.Canary != nil {
+		allErrs = append(allErrs, validateRollingUpdateCanary(rollingUpdate.Canary, fldpath.Child("canary"), onMasterInstanceGroup)...)
+	}
+	return allErrs
+}
+
+func validateRollingUpdateCanary(canary *kops.RollingUpdateCanary, fldpath *field.Path, onMasterInstanceGroup bool) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if onMasterInstanceGroup {
+		allErrs = append(allErrs, field.Forbidden(fldpath, "Cannot canary instance groups with role \"Master\""))
+	}
+	if canary.Instances != nil {
+		instances, err := intstr.GetScaledValueFromIntOrPercent(canary.Instances, 1000, true)
+		if err != nil {
+			allErrs = append(allErrs, field.Invalid(fldpath.Child("instances"), canary.Instances,
+				fmt.Sprintf("Unable to parse: %v", err)))
+		} else if instances <= 0 {
+			allErrs = append(allErrs, field.Invalid(fldpath.Child("instances"), canary.Instances, "Must be positive"))
+		}
+	}
+	if canary.SoakDuration != nil && canary.SoakDuration.Duration < 0 {
+		allErrs = append(allErrs, field.Invalid(fldpath.Child("soakDuration"), canary.SoakDuration, "Cannot be negative"))
+	}
+	return allErrs
+}
+
+func validateValidationChecks(checks []kops.ValidationCheck, fldpath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	names := sets.NewString()
+	for i, check := range checks {
+		p := fldpath.Index(i)
+		if check.Name == "" {
+			allErrs = append(allErrs, field.Required(p.Child("name"), "name cannot be empty"))
+		} else if names.Has(check.Name) {
+			allErrs = append(allErrs, field.Duplicate(p.Child("name"), check.Name))
+		}
+		names.Insert(check.Name)
+
+		count := 0
+		if check.Deployment != nil {
+			count++
+			if check.Deployment.Namespace == "" {
+				allErrs = append(allErrs, field.Required(p.Child("deployment", "namespace"), "namespace cannot be empty"))
+			}
+			if check.Deployment.Name == "" {
+				allErrs = append(allErrs, field.Required(p.Child("deployment", "name"), "name cannot be empty"))
+			}
+		}
+		if check.HTTPGet != nil {
+			count++
+			if check.HTTPGet.Namespace == "" {
+				allErrs = append(allErrs, field.Required(p.Child("httpGet", "namespace"), "namespace cannot be empty"))
+			}
+			if check.HTTPGet.Service == "" {
+				allErrs = append(allErrs, field.Required(p.Child("httpGet", "service"), "service cannot be empty"))
+			}
+			if check.HTTPGet.Port == "" {
+				allErrs = append(allErrs, field.Required(p.Child("httpGet", "port"), "port cannot be empty"))
+			}
+			if check.HTTPGet.Path != "" && !strings.HasPrefix(check.HTTPGet.Path, "/") {
+				allErrs = append(allErrs, field.Invalid(p.Child("httpGet", "path"), check.HTTPGet.Path, "path must start with /"))
+			}
+		}
+		if check.CustomResourceDefinition != nil {
+			count++
+			if !strings.Contains(check.CustomResourceDefinition.Name, ".") {
+				allErrs = append(allErrs, field.Invalid(p.Child("customResourceDefinition", "name"), check.CustomResourceDefinition.Name, "name must be of the form <plural>.<group>"))
+			}
+		}
+		if count != 1 {
+			allErrs = append(allErrs, field.Forbidden(p, "exactly one of deployment, httpGet or customResourceDefinition must be set"))
+		}
+	}
+	return allErrs
+}
+
+func validateStateStoreEncryption(spec *kops.StateStoreEncryptionSpec, fldpath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	count := 0
+	if spec.KeyFile != nil {
+		count++
+		if spec.KeyFile.Path == "" {
+			allErrs = append(allErrs, field.Required(fldpath.Child("keyFile", "path"), "path cannot be empty"))
+		}
+	}
+	if spec.VaultTransit != nil {
+		count++
+		if spec.VaultTransit.Address == "" {
+			allErrs = append(allErrs, field.Required(fldpath.Child("vaultTransit", "address"), "address cannot be empty"))
+		} else if u, err := url.Parse(spec.VaultTransit.Address); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			allErrs = append(allErrs, field.Invalid(fldpath.Child("vaultTransit", "address"), spec.VaultTransit.Address, "address must be an http or https URL"))
+		}
+		if spec.VaultTransit.Key == "" {
+			allErrs = append(allErrs, field.Required(fldpath.Child("vaultTransit", "key"), "key cannot be empty"))
+		}
+	}
+	if spec.AWSKMS != nil {
+		count++
+		if spec.AWSKMS.KeyID == "" {
+			allErrs = append(allErrs, field.Required(fldpath.Child("awsKMS", "keyID"), "keyID cannot be empty"))
+		} else if !arn.IsARN(spec.AWSKMS.KeyID) && spec.AWSKMS.Region == "" {
+			allErrs = append(allErrs, field.Required(fldpath.Child("awsKMS", "region"), "region must be set unless keyID is an ARN"))
+		}
+	}
+	if count != 1 {
+		allErrs = append(allErrs, field.Forbidden(fldpath, "exactly one of keyFile, vaultTransit or awsKMS must be set"))
+	}
+	return allErrs
+}
+
+func validateKopsController(c *kops.Cluster, spec *kops.KopsControllerConfig, fldpath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if spec.MetricsPort != nil {
+		port := int(*spec.MetricsPort)
+		for _, msg := range utilvalidation.IsValidPortNum(port) {
+			allErrs = append(allErrs, field.Invalid(fldpath.Child("metricsPort"), port, msg))
+		}
+		if port == wellknownports.KopsControllerPort {
+			allErrs = append(allErrs, field.Forbidden(fldpath.Child("metricsPort"), fmt.Sprintf("port %d is used by kops-controller to serve node bootstrap requests", port)))
+		}
+	}
+	if spec.AdminCredentials != nil {
+		allErrs = append(allErrs, validateKopsControllerAdminCredentials(c, spec.AdminCredentials, fldpath.Child("adminCredentials"))...)
+	}
+	if spec.RateLimit != nil {
+		if spec.RateLimit.Requests != nil && *spec.RateLimit.Requests < 0 {
+			allErrs = append(allErrs, field.Invalid(fldpath.Child("rateLimit", "requests"), *spec.RateLimit.Requests, "must not be negative"))
+		}
+		if spec.RateLimit.Period != nil && spec.RateLimit.Period.Duration <= 0 {
+			allErrs = append(allErrs, field.Invalid(fldpath.Child("rateLimit", "period"), spec.RateLimit.Period.Duration.String(), "must be positive"))
+		}
+	}
+	return allErrs
+}
+
+func validateKopsControllerAdminCredentials(c *kops.Cluster, spec *kops.KopsControllerAdminCredentialsConfig, fldpath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if !model.UseKopsControllerForNodeBootstrap(c) {
+		allErrs = append(allErrs, field.Forbidden(fldpath, "admin credentials can only be issued by kops-controller when it bootstraps nodes"))
+	}
+
+	if spec.MaxValidity != nil && spec.MaxValidity.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(fldpath.Child("maxValidity"), spec.MaxValidity.Duration.String(), "must be positive"))
+	}
+
+	if len(spec.AWSPrincipals) == 0 && spec.OIDC == nil {
+		allErrs = append(allErrs, field.Required(fldpath, "awsPrincipals or oidc must be set to authenticate users"))
+	}
+
+	if len(spec.AWSPrincipals) != 0 && c.Spec.GetCloudProvider() != kops.CloudProviderAWS {
+		allErrs = append(allErrs, field.Forbidden(fldpath.Child("awsPrincipals"), "awsPrincipals are only supported on AWS"))
+	}
+	for i, principal := range spec.AWSPrincipals {
+		fieldPath := fldpath.Child("awsPrincipals").Index(i)
+		parsed, err := arn.Parse(principal)
+		if err != nil {
+			allErrs = append(allErrs, field.Invalid(fieldPath, principal, fmt.Sprintf("not a valid ARN: %v", err)))
+			continue
+		}
+		if parsed.Service != "iam" || !(strings.HasPrefix(parsed.Resource, "user/") || strings.HasPrefix(parsed.Resource, "role/")) {
+			allErrs = append(allErrs, field.Invalid(fieldPath, principal, "must be the ARN of an IAM user or role"))
+		}
+	}
+
+	if spec.OIDC != nil {
+		fieldPath := fldpath.Child("oidc")
+		if spec.OIDC.IssuerURL == "" {
+			allErrs = append(allErrs, field.Required(fieldPath.Child("issuerURL"), ""))
+		} else if u, err := url.Parse(spec.OIDC.IssuerURL); err != nil || u.Scheme != "https" || u.Host == "" {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Child("issuerURL"), spec.OIDC.IssuerURL, "must be an https URL"))
+		}
+		if spec.OIDC.ClientID == "" {
+			allErrs = append(allErrs, field.Required(fieldPath.Child("clientID"), ""))
+		}
+		if spec.OIDC.UsernameClaim != nil && *spec.OIDC.UsernameClaim == "" {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Child("usernameClaim"), "", "must not be empty"))
+		}
+		if len(spec.OIDC.AllowedUsers) == 0 {
+			allErrs = append(allErrs, field.Required(fieldPath.Child("allowedUsers"), "at least one user must be allowed"))
+		}
+	}
+
 	return allErrs
 }
 
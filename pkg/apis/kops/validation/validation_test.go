@@ -18,7 +18,9 @@ package validation
 
 import (
 	"testing"
+	"time"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/validation"
@@ -457,6 +459,51 @@ func Test_Validate_AdditionalPolicies(t *testing.T) {
 	}
 }
 
+func Test_Validate_AssetsOSPackageBundle(t *testing.T) {
+	grid := []struct {
+		Input          kops.Assets
+		ExpectedErrors []string
+	}{
+		{
+			Input: kops.Assets{},
+		},
+		{
+			Input: kops.Assets{
+				FileRepository:  fi.String("https://example.com/kops"),
+				OSPackageBundle: fi.Bool(true),
+			},
+		},
+		{
+			Input: kops.Assets{
+				OSPackageBundle: fi.Bool(true),
+			},
+			ExpectedErrors: []string{"Forbidden::spec.assets.osPackageBundle"},
+		},
+	}
+	for _, g := range grid {
+		clusterSpec := &kops.ClusterSpec{
+			KubernetesVersion: "1.23.0",
+			Assets:            &g.Input,
+			Subnets: []kops.ClusterSubnetSpec{
+				{Name: "subnet1", Type: kops.SubnetTypePublic},
+			},
+			EtcdClusters: []kops.EtcdClusterSpec{
+				{
+					Name: "main",
+					Members: []kops.EtcdMemberSpec{
+						{
+							Name:          "us-test-1a",
+							InstanceGroup: fi.String("master-us-test-1a"),
+						},
+					},
+				},
+			},
+		}
+		errs := validateClusterSpec(clusterSpec, &kops.Cluster{Spec: *clusterSpec}, field.NewPath("spec"))
+		testErrors(t, g.Input, errs, g.ExpectedErrors)
+	}
+}
+
 type caliInput struct {
 	Cluster *kops.ClusterSpec
 	Calico  *kops.CalicoNetworkingSpec
@@ -1077,6 +1124,45 @@ func Test_Validate_RollingUpdate(t *testing.T) {
 			},
 			ExpectedErrors: []string{"Forbidden::testField.maxSurge"},
 		},
+		{
+			Input: kops.RollingUpdate{
+				Canary: &kops.RollingUpdateCanary{
+					Instances:    intStr(intstr.FromString("10%")),
+					SoakDuration: &metav1.Duration{Duration: time.Minute},
+				},
+			},
+		},
+		{
+			Input: kops.RollingUpdate{
+				Canary: &kops.RollingUpdateCanary{
+					Instances: intStr(intstr.FromInt(0)),
+				},
+			},
+			ExpectedErrors: []string{"Invalid value::testField.canary.instances"},
+		},
+		{
+			Input: kops.RollingUpdate{
+				Canary: &kops.RollingUpdateCanary{
+					Instances: intStr(intstr.FromString("nope")),
+				},
+			},
+			ExpectedErrors: []string{"Invalid value::testField.canary.instances"},
+		},
+		{
+			Input: kops.RollingUpdate{
+				Canary: &kops.RollingUpdateCanary{
+					SoakDuration: &metav1.Duration{Duration: -time.Minute},
+				},
+			},
+			ExpectedErrors: []string{"Invalid value::testField.canary.soakDuration"},
+		},
+		{
+			Input: kops.RollingUpdate{
+				Canary: &kops.RollingUpdateCanary{},
+			},
+			OnMasterIG:     true,
+			ExpectedErrors: []string{"Forbidden::testField.canary"},
+		},
 	}
 	for _, g := range grid {
 		errs := validateRollingUpdate(&g.Input, field.NewPath("testField"), g.OnMasterIG)
@@ -1373,6 +1459,179 @@ func TestValidateSAExternalPermissions(t *testing.T) {
 	}
 }
 
+func TestValidateValidationChecks(t *testing.T) {
+	grid := []struct {
+		Description    string
+		Input          []kops.ValidationCheck
+		ExpectedErrors []string
+	}{
+		{
+			Description: "Valid checks",
+			Input: []kops.ValidationCheck{
+				{
+					Name:       "ingress",
+					Deployment: &kops.DeploymentValidationCheck{Namespace: "ingress", Name: "ingress-nginx"},
+				},
+				{
+					Name:    "api",
+					HTTPGet: &kops.HTTPGetValidationCheck{Namespace: "default", Service: "api", Port: "http", Path: "/healthz"},
+				},
+				{
+					Name:                     "widgets",
+					CustomResourceDefinition: &kops.CustomResourceDefinitionValidationCheck{Name: "widgets.example.com"},
+				},
+			},
+		},
+		{
+			Description: "Missing name",
+			Input: []kops.ValidationCheck{
+				{
+					Deployment: &kops.DeploymentValidationCheck{Namespace: "ingress", Name: "ingress-nginx"},
+				},
+			},
+			ExpectedErrors: []string{"Required value::spec.validationChecks[0].name"},
+		},
+		{
+			Description: "Duplicate name",
+			Input: []kops.ValidationCheck{
+				{
+					Name:       "ingress",
+					Deployment: &kops.DeploymentValidationCheck{Namespace: "ingress", Name: "ingress-nginx"},
+				},
+				{
+					Name:       "ingress",
+					Deployment: &kops.DeploymentValidationCheck{Namespace: "ingress", Name: "default-backend"},
+				},
+			},
+			ExpectedErrors: []string{"Duplicate value::spec.validationChecks[1].name"},
+		},
+		{
+			Description: "No check type",
+			Input: []kops.ValidationCheck{
+				{
+					Name: "empty",
+				},
+			},
+			ExpectedErrors: []string{"Forbidden::spec.validationChecks[0]"},
+		},
+		{
+			Description: "Multiple check types",
+			Input: []kops.ValidationCheck{
+				{
+					Name:                     "both",
+					Deployment:               &kops.DeploymentValidationCheck{Namespace: "ingress", Name: "ingress-nginx"},
+					CustomResourceDefinition: &kops.CustomResourceDefinitionValidationCheck{Name: "widgets.example.com"},
+				},
+			},
+			ExpectedErrors: []string{"Forbidden::spec.validationChecks[0]"},
+		},
+		{
+			Description: "Incomplete deployment",
+			Input: []kops.ValidationCheck{
+				{
+					Name:       "ingress",
+					Deployment: &kops.DeploymentValidationCheck{Name: "ingress-nginx"},
+				},
+			},
+			ExpectedErrors: []string{"Required value::spec.validationChecks[0].deployment.namespace"},
+		},
+		{
+			Description: "Incomplete httpGet",
+			Input: []kops.ValidationCheck{
+				{
+					Name:    "api",
+					HTTPGet: &kops.HTTPGetValidationCheck{Namespace: "default", Service: "api", Path: "healthz"},
+				},
+			},
+			ExpectedErrors: []string{
+				"Required value::spec.validationChecks[0].httpGet.port",
+				"Invalid value::spec.validationChecks[0].httpGet.path",
+			},
+		},
+		{
+			Description: "Invalid CRD name",
+			Input: []kops.ValidationCheck{
+				{
+					Name:                     "widgets",
+					CustomResourceDefinition: &kops.CustomResourceDefinitionValidationCheck{Name: "widgets"},
+				},
+			},
+			ExpectedErrors: []string{"Invalid value::spec.validationChecks[0].customResourceDefinition.name"},
+		},
+	}
+
+	for _, g := range grid {
+		t.Run(g.Description, func(t *testing.T) {
+			errs := validateValidationChecks(g.Input, field.NewPath("spec", "validationChecks"))
+			testErrors(t, g.Input, errs, g.ExpectedErrors)
+		})
+	}
+}
+
+func TestValidateStateStoreEncryption(t *testing.T) {
+	grid := []struct {
+		Description    string
+		Input          kops.StateStoreEncryptionSpec
+		ExpectedErrors []string
+	}{
+		{
+			Description: "Key file",
+			Input: kops.StateStoreEncryptionSpec{
+				KeyFile: &kops.StateStoreEncryptionKeyFileSpec{Path: "/etc/kops/state.key"},
+			},
+		},
+		{
+			Description: "Vault transit",
+			Input: kops.StateStoreEncryptionSpec{
+				VaultTransit: &kops.StateStoreEncryptionVaultTransitSpec{Address: "https://vault.example.com:8200", Key: "kops"},
+			},
+		},
+		{
+			Description: "AWS KMS key ARN",
+			Input: kops.StateStoreEncryptionSpec{
+				AWSKMS: &kops.StateStoreEncryptionAWSKMSSpec{KeyID: "arn:aws:kms:us-east-1:123456789012:key/1234abcd-12ab-34cd-56ef-1234567890ab"},
+			},
+		},
+		{
+			Description: "AWS KMS alias without region",
+			Input: kops.StateStoreEncryptionSpec{
+				AWSKMS: &kops.StateStoreEncryptionAWSKMSSpec{KeyID: "alias/kops"},
+			},
+			ExpectedErrors: []string{"Required value::spec.stateStoreEncryption.awsKMS.region"},
+		},
+		{
+			Description:    "No provider",
+			Input:          kops.StateStoreEncryptionSpec{},
+			ExpectedErrors: []string{"Forbidden::spec.stateStoreEncryption"},
+		},
+		{
+			Description: "Multiple providers",
+			Input: kops.StateStoreEncryptionSpec{
+				KeyFile: &kops.StateStoreEncryptionKeyFileSpec{Path: "/etc/kops/state.key"},
+				AWSKMS:  &kops.StateStoreEncryptionAWSKMSSpec{KeyID: "alias/kops", Region: "us-east-1"},
+			},
+			ExpectedErrors: []string{"Forbidden::spec.stateStoreEncryption"},
+		},
+		{
+			Description: "Incomplete vault transit",
+			Input: kops.StateStoreEncryptionSpec{
+				VaultTransit: &kops.StateStoreEncryptionVaultTransitSpec{Address: "vault.example.com"},
+			},
+			ExpectedErrors: []string{
+				"Invalid value::spec.stateStoreEncryption.vaultTransit.address",
+				"Required value::spec.stateStoreEncryption.vaultTransit.key",
+			},
+		},
+	}
+
+	for _, g := range grid {
+		t.Run(g.Description, func(t *testing.T) {
+			errs := validateStateStoreEncryption(&g.Input, field.NewPath("spec", "stateStoreEncryption"))
+			testErrors(t, g.Input, errs, g.ExpectedErrors)
+		})
+	}
+}
+
 func Test_Validate_Nvidia_Cluster(t *testing.T) {
 	grid := []struct {
 		Input          kops.ClusterSpec
@@ -1505,3 +1764,196 @@ func Test_Validate_Nvidia_Ig(t *testing.T) {
 		testErrors(t, g.Input, errs, g.ExpectedErrors)
 	}
 }
+
+func TestValidateKopsController(t *testing.T) {
+	grid := []struct {
+		Description    string
+		Cloud          kops.CloudProviderID
+		Input          kops.KopsControllerConfig
+		ExpectedErrors []string
+	}{
+		{
+			Description: "Metrics disabled",
+			Input:       kops.KopsControllerConfig{},
+		},
+		{
+			Description: "Metrics port",
+			Input:       kops.KopsControllerConfig{MetricsPort: fi.Int32(3987)},
+		},
+		{
+			Description:    "Invalid metrics port",
+			Input:          kops.KopsControllerConfig{MetricsPort: fi.Int32(70000)},
+			ExpectedErrors: []string{"Invalid value::spec.kopsController.metricsPort"},
+		},
+		{
+			Description:    "Metrics port conflicts with bootstrap port",
+			Input:          kops.KopsControllerConfig{MetricsPort: fi.Int32(3988)},
+			ExpectedErrors: []string{"Forbidden::spec.kopsController.metricsPort"},
+		},
+		{
+			Description: "Rate limit",
+			Input: kops.KopsControllerConfig{
+				RateLimit: &kops.KopsControllerRateLimitConfig{
+					Requests: fi.Int32(5),
+					Period:   &metav1.Duration{Duration: 10 * time.Minute},
+				},
+			},
+		},
+		{
+			Description: "Rate limit disabled",
+			Input: kops.KopsControllerConfig{
+				RateLimit: &kops.KopsControllerRateLimitConfig{Requests: fi.Int32(0)},
+			},
+		},
+		{
+			Description: "Invalid rate limit",
+			Input: kops.KopsControllerConfig{
+				RateLimit: &kops.KopsControllerRateLimitConfig{
+					Requests: fi.Int32(-1),
+					Period:   &metav1.Duration{},
+				},
+			},
+			ExpectedErrors: []string{"Invalid value::spec.kopsController.rateLimit.requests", "Invalid value::spec.kopsController.rateLimit.period"},
+		},
+		{
+			Description: "Admin credentials for AWS principals",
+			Input: kops.KopsControllerConfig{
+				AdminCredentials: &kops.KopsControllerAdminCredentialsConfig{
+					MaxValidity:   &metav1.Duration{Duration: time.Hour},
+					AWSPrincipals: []string{"arn:aws:iam::123456789012:role/admin", "arn:aws:iam::123456789012:user/alice"},
+				},
+			},
+		},
+		{
+			Description: "Admin credentials for OIDC users",
+			Input: kops.KopsControllerConfig{
+				AdminCredentials: &kops.KopsControllerAdminCredentialsConfig{
+					OIDC: &kops.KopsControllerOIDCConfig{
+						IssuerURL:    "https://accounts.example.com",
+						ClientID:     "kops",
+						AllowedUsers: []string{"alice@example.com"},
+					},
+				},
+			},
+		},
+		{
+			Description: "Admin credentials without authentication",
+			Input: kops.KopsControllerConfig{
+				AdminCredentials: &kops.KopsControllerAdminCredentialsConfig{},
+			},
+			ExpectedErrors: []string{"Required value::spec.kopsController.adminCredentials"},
+		},
+		{
+			Description: "Admin credentials with invalid settings",
+			Input: kops.KopsControllerConfig{
+				AdminCredentials: &kops.KopsControllerAdminCredentialsConfig{
+					MaxValidity:   &metav1.Duration{Duration: -time.Hour},
+					AWSPrincipals: []string{"admin", "arn:aws:s3:::bucket"},
+					OIDC: &kops.KopsControllerOIDCConfig{
+						IssuerURL: "http://accounts.example.com",
+					},
+				},
+			},
+			ExpectedErrors: []string{
+				"Invalid value::spec.kopsController.adminCredentials.maxValidity",
+				"Invalid value::spec.kopsController.adminCredentials.awsPrincipals[0]",
+				"Invalid value::spec.kopsController.adminCredentials.awsPrincipals[1]",
+				"Invalid value::spec.kopsController.adminCredentials.oidc.issuerURL",
+				"Required value::spec.kopsController.adminCredentials.oidc.clientID",
+				"Required value::spec.kopsController.adminCredentials.oidc.allowedUsers",
+			},
+		},
+		{
+			Description: "Admin credentials for AWS principals on GCE",
+			Cloud:       kops.CloudProviderGCE,
+			Input: kops.KopsControllerConfig{
+				AdminCredentials: &kops.KopsControllerAdminCredentialsConfig{
+					AWSPrincipals: []string{"arn:aws:iam::123456789012:role/admin"},
+				},
+			},
+			ExpectedErrors: []string{"Forbidden::spec.kopsController.adminCredentials.awsPrincipals"},
+		},
+		{
+			Description: "Admin credentials without kops-controller bootstrap",
+			Cloud:       kops.CloudProviderOpenstack,
+			Input: kops.KopsControllerConfig{
+				AdminCredentials: &kops.KopsControllerAdminCredentialsConfig{
+					OIDC: &kops.KopsControllerOIDCConfig{
+						IssuerURL:    "https://accounts.example.com",
+						ClientID:     "kops",
+						AllowedUsers: []string{"alice@example.com"},
+					},
+				},
+			},
+			ExpectedErrors: []string{"Forbidden::spec.kopsController.adminCredentials"},
+		},
+	}
+
+	for _, g := range grid {
+		t.Run(g.Description, func(t *testing.T) {
+			cluster := &kops.Cluster{
+				Spec: kops.ClusterSpec{
+					KubernetesVersion: "1.23.0",
+					CloudProvider:     kops.CloudProviderSpec{AWS: &kops.AWSSpec{}},
+				},
+			}
+			switch g.Cloud {
+			case kops.CloudProviderGCE:
+				cluster.Spec.CloudProvider = kops.CloudProviderSpec{GCE: &kops.GCESpec{}}
+			case kops.CloudProviderOpenstack:
+				cluster.Spec.CloudProvider = kops.CloudProviderSpec{Openstack: &kops.OpenstackSpec{}}
+			}
+			errs := validateKopsController(cluster, &g.Input, field.NewPath("spec", "kopsController"))
+			testErrors(t, g.Input, errs, g.ExpectedErrors)
+		})
+	}
+}
+
+func TestValidateEtcdBackupRetention(t *testing.T) {
+	grid := []struct {
+		Description    string
+		Input          kops.EtcdBackupRetentionSpec
+		ExpectedErrors []string
+	}{
+		{
+			Description: "Defaults",
+			Input:       kops.EtcdBackupRetentionSpec{},
+		},
+		{
+			Description: "Counts and max age",
+			Input: kops.EtcdBackupRetentionSpec{
+				HourlyBackups: fi.Int32(24),
+				DailyBackups:  fi.Int32(30),
+				MaxAge:        &metav1.Duration{Duration: 14 * 24 * time.Hour},
+			},
+		},
+		{
+			Description: "No hourly backups",
+			Input: kops.EtcdBackupRetentionSpec{
+				HourlyBackups: fi.Int32(0),
+			},
+			ExpectedErrors: []string{"Invalid value::spec.etcdClusters[0].backups.retention.hourlyBackups"},
+		},
+		{
+			Description: "Negative daily backups",
+			Input: kops.EtcdBackupRetentionSpec{
+				DailyBackups: fi.Int32(-1),
+			},
+			ExpectedErrors: []string{"Invalid value::spec.etcdClusters[0].backups.retention.dailyBackups"},
+		},
+		{
+			Description: "Max age within the last hour",
+			Input: kops.EtcdBackupRetentionSpec{
+				MaxAge: &metav1.Duration{Duration: 30 * time.Minute},
+			},
+			ExpectedErrors: []string{"Invalid value::spec.etcdClusters[0].backups.retention.maxAge"},
+		},
+	}
+
+	for _, g := range grid {
+		t.Run(g.Description, func(t *testing.T) {
+			errs := validateEtcdBackupRetention(&g.Input, field.NewPath("spec", "etcdClusters").Index(0).Child("backups", "retention"))
+			testErrors(t, g.Input, errs, g.ExpectedErrors)
+		})
+	}
+}
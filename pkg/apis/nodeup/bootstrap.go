@@ -58,3 +58,20 @@ type NodeConfigCertificate struct {
 	// Cert is the certificate data.
 	Cert string `json:"cert,omitempty"`
 }
+
+// AdminCredentialsRequest is a request from kops to kops-controller for a cluster admin credential.
+type AdminCredentialsRequest struct {
+	// APIVersion defines the versioned schema of this representation of a request.
+	APIVersion string `json:"apiVersion"`
+	// PublicKey is the public key to issue the certificate for.
+	PublicKey string `json:"publicKey"`
+	// ValiditySeconds is the requested lifetime of the certificate.
+	// kops-controller may issue a certificate with a shorter lifetime.
+	ValiditySeconds int64 `json:"validitySeconds,omitempty"`
+}
+
+// AdminCredentialsResponse is a response to an AdminCredentialsRequest.
+type AdminCredentialsResponse struct {
+	// Certificate is the issued client certificate.
+	Certificate string `json:"certificate"`
+}
@@ -43,6 +43,10 @@ type Config struct {
 	WarmPoolImages []string `json:"warmPoolImages,omitempty"`
 	// Packages specifies additional packages to be installed.
 	Packages []string `json:"packages,omitempty"`
+	// OSPackageBundle is the location of the bundles to install OS packages from, instead of the repositories of the distribution.
+	OSPackageBundle string `json:"osPackageBundle,omitempty"`
+	// OSPackageBundleHashes are the sha256 hashes of the indexes of the OS package bundles, by distribution and architecture, e.g. "jammy/amd64".
+	OSPackageBundleHashes map[string]string `json:"osPackageBundleHashes,omitempty"`
 
 	// Manifests for running etcd
 	EtcdManifests []string `json:"etcdManifests,omitempty"`
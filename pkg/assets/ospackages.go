@@ -0,0 +1,112 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package assets
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"k8s.io/klog/v2"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/ospackages"
+	"k8s.io/kops/util/pkg/hashing"
+	"k8s.io/kops/util/pkg/vfs"
+)
+
+// WriteOSPackageBundle writes the index of an OS package bundle to bundleURL, returning the sha256 hash of the index.
+// It should be called once the package files are copied, as nodeup trusts the index to list files that exist.
+func WriteOSPackageBundle(cluster *kops.Cluster, bundle *ospackages.Bundle, bundleURL string) (string, error) {
+	data, err := bundle.Marshal()
+	if err != nil {
+		return "", fmt.Errorf("error serializing OS package bundle: %v", err)
+	}
+	hash, err := hashing.HashAlgorithmSHA256.Hash(bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+
+	p, err := osPackagePath(strings.TrimSuffix(bundleURL, "/") + "/" + ospackages.IndexFile)
+	if err != nil {
+		return "", err
+	}
+
+	klog.Infof("writing OS package bundle index %q", p)
+	if err := writeFile(cluster, p, data); err != nil {
+		return "", err
+	}
+	return hash.Hex(), nil
+}
+
+// ReadOSPackageManifest reads the manifest of the OS package bundles below base.
+// The error satisfies os.IsNotExist if no bundles have been written.
+func ReadOSPackageManifest(base string) (*ospackages.Manifest, error) {
+	p, err := osPackagePath(strings.TrimSuffix(base, "/") + "/" + ospackages.ManifestFile)
+	if err != nil {
+		return nil, err
+	}
+	data, err := p.ReadFile()
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("error reading OS package bundle manifest %q: %v", p, err)
+	}
+	return ospackages.ParseManifest(data)
+}
+
+// WriteOSPackageManifest records the hashes of the indexes of OS package bundles in the manifest below base,
+// keeping the hashes of the bundles written by earlier runs for other distributions.
+// It should be called once the indexes are written.
+func WriteOSPackageManifest(cluster *kops.Cluster, base string, hashes map[string]string) error {
+	manifest, err := ReadOSPackageManifest(base)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return err
+		}
+		manifest = &ospackages.Manifest{Indexes: make(map[string]string)}
+	}
+	for key, hash := range hashes {
+		manifest.Indexes[key] = hash
+	}
+
+	data, err := manifest.Marshal()
+	if err != nil {
+		return fmt.Errorf("error serializing OS package bundle manifest: %v", err)
+	}
+	p, err := osPackagePath(strings.TrimSuffix(base, "/") + "/" + ospackages.ManifestFile)
+	if err != nil {
+		return err
+	}
+
+	klog.Infof("writing OS package bundle manifest %q", p)
+	return writeFile(cluster, p, data)
+}
+
+// osPackagePath returns the path of a file of the OS package bundles in the file repository
+func osPackagePath(target string) (vfs.Path, error) {
+	target, err := buildVFSPath(target)
+	if err != nil {
+		return nil, err
+	}
+	p, err := vfs.Context.BuildVfsPath(target)
+	if err != nil {
+		return nil, fmt.Errorf("error building path %q: %v", target, err)
+	}
+	return p, nil
+}
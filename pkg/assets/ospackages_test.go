@@ -0,0 +1,76 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package assets
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/ospackages"
+	"k8s.io/kops/util/pkg/hashing"
+)
+
+func TestWriteOSPackageManifest(t *testing.T) {
+	cluster := &kops.Cluster{}
+	base := filepath.Join(t.TempDir(), ospackages.BundlePath)
+
+	if _, err := ReadOSPackageManifest(base); !os.IsNotExist(err) {
+		t.Fatalf("expected a missing manifest to be reported as not existing, got %v", err)
+	}
+
+	bundle := &ospackages.Bundle{Distribution: "jammy", Architecture: "amd64"}
+	hash, err := WriteOSPackageBundle(cluster, bundle, base+"/jammy/amd64")
+	if err != nil {
+		t.Fatalf("error writing bundle: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(base, "jammy", "amd64", ospackages.IndexFile))
+	if err != nil {
+		t.Fatalf("error reading index: %v", err)
+	}
+	actual, err := hashing.HashAlgorithmSHA256.Hash(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("error hashing index: %v", err)
+	}
+	if hash != actual.Hex() {
+		t.Errorf("expected hash %q of the written index, got %q", actual.Hex(), hash)
+	}
+
+	if err := WriteOSPackageManifest(cluster, base, map[string]string{bundle.Key(): hash, "rocky9/amd64": "1111"}); err != nil {
+		t.Fatalf("error writing manifest: %v", err)
+	}
+	// A later run for another distribution keeps the hashes of the earlier bundles, and replaces rebuilt ones
+	if err := WriteOSPackageManifest(cluster, base, map[string]string{"rocky9/amd64": "2222", "bookworm/arm64": "3333"}); err != nil {
+		t.Fatalf("error writing manifest: %v", err)
+	}
+
+	manifest, err := ReadOSPackageManifest(base)
+	if err != nil {
+		t.Fatalf("error reading manifest: %v", err)
+	}
+	expected := map[string]string{"jammy/amd64": hash, "rocky9/amd64": "2222", "bookworm/arm64": "3333"}
+	if len(manifest.Indexes) != len(expected) {
+		t.Errorf("expected indexes %v, got %v", expected, manifest.Indexes)
+	}
+	for key, hash := range expected {
+		if manifest.Indexes[key] != hash {
+			t.Errorf("expected hash %q for %s, got %q", hash, key, manifest.Indexes[key])
+		}
+	}
+}
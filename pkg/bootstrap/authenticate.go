@@ -35,6 +35,9 @@ type VerifyResult struct {
 
 	// CertificateNames is the alternate names the node is authorized to use for certificates.
 	CertificateNames []string
+
+	// User is the name of the authenticated user, for requests made by users rather than nodes.
+	User string
 }
 
 // Verifier verifies authentication credentials for requests.
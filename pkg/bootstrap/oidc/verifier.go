@@ -0,0 +1,208 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+	"k8s.io/kops/pkg/bootstrap"
+)
+
+// AuthenticationTokenPrefix is the prefix of authorization headers that carry an OIDC ID token.
+const AuthenticationTokenPrefix = "Bearer "
+
+// DefaultUsernameClaim is the claim that holds the user name, if not otherwise configured.
+const DefaultUsernameClaim = "sub"
+
+// keyRefreshInterval bounds how often the issuer's keys are fetched for tokens signed with an unknown key.
+const keyRefreshInterval = time.Minute
+
+// VerifierOptions configures the verification of users by their OIDC ID tokens.
+type VerifierOptions struct {
+	// IssuerURL is the URL of the OIDC issuer.
+	IssuerURL string `json:"issuerURL"`
+	// ClientID is the audience that ID tokens must be issued for.
+	ClientID string `json:"clientID"`
+	// UsernameClaim is the claim that holds the user name.
+	UsernameClaim string `json:"usernameClaim,omitempty"`
+	// AllowedUsers are the users that are permitted.
+	AllowedUsers []string `json:"allowedUsers"`
+}
+
+type verifier struct {
+	opt    VerifierOptions
+	client *http.Client
+
+	mutex       sync.Mutex
+	keys        *jose.JSONWebKeySet
+	keysFetched time.Time
+}
+
+var _ bootstrap.Verifier = &verifier{}
+
+// NewVerifier returns a verifier for requests carrying an ID token of a permitted user, issued by the OIDC issuer.
+func NewVerifier(opt *VerifierOptions) (bootstrap.Verifier, error) {
+	if opt.IssuerURL == "" || opt.ClientID == "" {
+		return nil, fmt.Errorf("issuerURL and clientID are required")
+	}
+	v := &verifier{
+		opt: *opt,
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+	if v.opt.UsernameClaim == "" {
+		v.opt.UsernameClaim = DefaultUsernameClaim
+	}
+	return v, nil
+}
+
+func (v *verifier) VerifyToken(ctx context.Context, token string, body []byte, useInstanceIDForNodeName bool) (*bootstrap.VerifyResult, error) {
+	if !strings.HasPrefix(token, AuthenticationTokenPrefix) {
+		return nil, fmt.Errorf("incorrect authorization type")
+	}
+
+	idToken, err := jwt.ParseSigned(strings.TrimPrefix(token, AuthenticationTokenPrefix))
+	if err != nil {
+		return nil, fmt.Errorf("parsing ID token: %v", err)
+	}
+
+	keys, err := v.getKeys(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+
+	claims := jwt.Claims{}
+	extra := map[string]interface{}{}
+	if err := idToken.Claims(keys, &claims, &extra); err != nil {
+		// The issuer may have rotated its keys since we fetched them
+		refreshed, refreshErr := v.getKeys(ctx, true)
+		if refreshErr != nil {
+			return nil, refreshErr
+		}
+		if refreshed == keys {
+			return nil, fmt.Errorf("verifying ID token: %v", err)
+		}
+		if err := idToken.Claims(refreshed, &claims, &extra); err != nil {
+			return nil, fmt.Errorf("verifying ID token: %v", err)
+		}
+	}
+
+	if claims.Expiry == nil {
+		return nil, fmt.Errorf("ID token has no expiry")
+	}
+	expected := jwt.Expected{
+		Issuer:   v.opt.IssuerURL,
+		Audience: jwt.Audience{v.opt.ClientID},
+		Time:     time.Now(),
+	}
+	if err := claims.Validate(expected); err != nil {
+		return nil, fmt.Errorf("validating ID token: %v", err)
+	}
+
+	user, ok := extra[v.opt.UsernameClaim].(string)
+	if !ok || user == "" {
+		return nil, fmt.Errorf("ID token has no %q claim", v.opt.UsernameClaim)
+	}
+
+	allowed := false
+	for _, u := range v.opt.AllowedUsers {
+		if u == user {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return nil, fmt.Errorf("user %q is not permitted", user)
+	}
+
+	return &bootstrap.VerifyResult{
+		User: user,
+	}, nil
+}
+
+// getKeys returns the signing keys of the issuer, fetching them if we don't have them or
+// if a refresh is requested and they were not fetched recently.
+func (v *verifier) getKeys(ctx context.Context, refresh bool) (*jose.JSONWebKeySet, error) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	if v.keys != nil && (!refresh || time.Since(v.keysFetched) < keyRefreshInterval) {
+		return v.keys, nil
+	}
+
+	keys, err := v.fetchKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	v.keys = keys
+	v.keysFetched = time.Now()
+	return keys, nil
+}
+
+type providerConfiguration struct {
+	Issuer  string `json:"issuer"`
+	JWKSURI string `json:"jwks_uri"`
+}
+
+func (v *verifier) fetchKeys(ctx context.Context) (*jose.JSONWebKeySet, error) {
+	discoveryURL := strings.TrimSuffix(v.opt.IssuerURL, "/") + "/.well-known/openid-configuration"
+	provider := &providerConfiguration{}
+	if err := v.getJSON(ctx, discoveryURL, provider); err != nil {
+		return nil, fmt.Errorf("fetching OIDC discovery document: %v", err)
+	}
+	if provider.Issuer != v.opt.IssuerURL {
+		return nil, fmt.Errorf("OIDC discovery document is for issuer %q, expected %q", provider.Issuer, v.opt.IssuerURL)
+	}
+	if provider.JWKSURI == "" {
+		return nil, fmt.Errorf("OIDC discovery document has no jwks_uri")
+	}
+
+	keys := &jose.JSONWebKeySet{}
+	if err := v.getJSON(ctx, provider.JWKSURI, keys); err != nil {
+		return nil, fmt.Errorf("fetching OIDC signing keys: %v", err)
+	}
+	return keys, nil
+}
+
+func (v *verifier) getJSON(ctx context.Context, url string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code %d from %s", resp.StatusCode, url)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decoding response from %s: %v", url, err)
+	}
+	return nil
+}
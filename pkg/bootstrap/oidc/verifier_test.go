@@ -0,0 +1,169 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+)
+
+type testIssuer struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+	keyID  string
+}
+
+func newTestIssuer(t *testing.T) *testIssuer {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("error generating key: %v", err)
+	}
+	issuer := &testIssuer{key: key, keyID: "key-1"}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":   issuer.server.URL,
+			"jwks_uri": issuer.server.URL + "/keys",
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(jose.JSONWebKeySet{
+			Keys: []jose.JSONWebKey{{Key: &issuer.key.PublicKey, KeyID: issuer.keyID, Algorithm: string(jose.RS256), Use: "sig"}},
+		})
+	})
+	issuer.server = httptest.NewServer(mux)
+	t.Cleanup(issuer.server.Close)
+	return issuer
+}
+
+func (i *testIssuer) token(t *testing.T, key *rsa.PrivateKey, claims jwt.Claims, extra map[string]interface{}) string {
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.RS256, Key: key}, (&jose.SignerOptions{}).WithHeader("kid", i.keyID))
+	if err != nil {
+		t.Fatalf("error building signer: %v", err)
+	}
+	token, err := jwt.Signed(signer).Claims(claims).Claims(extra).CompactSerialize()
+	if err != nil {
+		t.Fatalf("error signing token: %v", err)
+	}
+	return token
+}
+
+func TestVerifyToken(t *testing.T) {
+	issuer := newTestIssuer(t)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("error generating key: %v", err)
+	}
+
+	v, err := NewVerifier(&VerifierOptions{
+		IssuerURL:     issuer.server.URL,
+		ClientID:      "kops",
+		UsernameClaim: "email",
+		AllowedUsers:  []string{"alice@example.com"},
+	})
+	if err != nil {
+		t.Fatalf("error building verifier: %v", err)
+	}
+
+	now := time.Now()
+	validClaims := jwt.Claims{
+		Issuer:   issuer.server.URL,
+		Subject:  "1234",
+		Audience: jwt.Audience{"kops"},
+		Expiry:   jwt.NewNumericDate(now.Add(time.Hour)),
+		IssuedAt: jwt.NewNumericDate(now),
+	}
+
+	grid := []struct {
+		name          string
+		token         string
+		expectedUser  string
+		expectedError string
+	}{
+		{
+			name:         "allowed user",
+			token:        "Bearer " + issuer.token(t, issuer.key, validClaims, map[string]interface{}{"email": "alice@example.com"}),
+			expectedUser: "alice@example.com",
+		},
+		{
+			name:          "user not allowed",
+			token:         "Bearer " + issuer.token(t, issuer.key, validClaims, map[string]interface{}{"email": "mallory@example.com"}),
+			expectedError: "not permitted",
+		},
+		{
+			name:          "missing username claim",
+			token:         "Bearer " + issuer.token(t, issuer.key, validClaims, nil),
+			expectedError: "no \"email\" claim",
+		},
+		{
+			name: "wrong audience",
+			token: func() string {
+				claims := validClaims
+				claims.Audience = jwt.Audience{"other"}
+				return "Bearer " + issuer.token(t, issuer.key, claims, map[string]interface{}{"email": "alice@example.com"})
+			}(),
+			expectedError: "validating ID token",
+		},
+		{
+			name: "expired",
+			token: func() string {
+				claims := validClaims
+				claims.Expiry = jwt.NewNumericDate(now.Add(-time.Hour))
+				return "Bearer " + issuer.token(t, issuer.key, claims, map[string]interface{}{"email": "alice@example.com"})
+			}(),
+			expectedError: "validating ID token",
+		},
+		{
+			name:          "signed by another key",
+			token:         "Bearer " + issuer.token(t, otherKey, validClaims, map[string]interface{}{"email": "alice@example.com"}),
+			expectedError: "verifying ID token",
+		},
+		{
+			name:          "not a bearer token",
+			token:         "x-aws-sts abcdef",
+			expectedError: "incorrect authorization type",
+		},
+	}
+	for _, g := range grid {
+		t.Run(g.name, func(t *testing.T) {
+			result, err := v.VerifyToken(context.Background(), g.token, nil, false)
+			if g.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), g.expectedError) {
+					t.Fatalf("expected error containing %q, got %v", g.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.User != g.expectedUser {
+				t.Errorf("expected user %q, got %q", g.expectedUser, result.User)
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package carotation defines the record of a CA rotation that is persisted in the state store.
+package carotation
+
+import (
+	"fmt"
+	"time"
+)
+
+// Phase is a step of a CA rotation.
+type Phase string
+
+const (
+	// PhaseCreateKeypairs adds a new secondary keypair to each keyset being rotated.
+	PhaseCreateKeypairs Phase = "CreateKeypairs"
+	// PhaseUpdateTrust applies the cluster and rolls the instances so that they trust the new keypairs.
+	PhaseUpdateTrust Phase = "UpdateTrust"
+	// PhaseExportTrust regenerates the kubeconfig so that it trusts the new CA.
+	PhaseExportTrust Phase = "ExportTrust"
+	// PhasePromoteKeypairs makes the new keypairs primary.
+	PhasePromoteKeypairs Phase = "PromoteKeypairs"
+	// PhaseUpdatePromoted applies the cluster and rolls the instances so that they use credentials issued by the new keypairs.
+	PhaseUpdatePromoted Phase = "UpdatePromoted"
+	// PhaseExportPromoted regenerates the kubeconfig so that its credentials are issued by the new CA.
+	PhaseExportPromoted Phase = "ExportPromoted"
+	// PhaseDistrustKeypairs distrusts the previous keypairs.
+	PhaseDistrustKeypairs Phase = "DistrustKeypairs"
+	// PhaseUpdateDistrusted applies the cluster and rolls the instances so that they no longer trust the previous keypairs.
+	PhaseUpdateDistrusted Phase = "UpdateDistrusted"
+	// PhaseExportDistrusted regenerates the kubeconfig so that it no longer trusts the previous CA.
+	PhaseExportDistrusted Phase = "ExportDistrusted"
+	// PhaseCompleted is the phase of a rotation that has finished.
+	PhaseCompleted Phase = "Completed"
+)
+
+// Phases are the phases of a CA rotation, in the order they are performed.
+var Phases = []Phase{
+	PhaseCreateKeypairs,
+	PhaseUpdateTrust,
+	PhaseExportTrust,
+	PhasePromoteKeypairs,
+	PhaseUpdatePromoted,
+	PhaseExportPromoted,
+	PhaseDistrustKeypairs,
+	PhaseUpdateDistrusted,
+	PhaseExportDistrusted,
+	PhaseCompleted,
+}
+
+// Status is the overall status of a CA rotation.
+type Status string
+
+const (
+	StatusInProgress Status = "InProgress"
+	StatusCompleted  Status = "Completed"
+	StatusFailed     Status = "Failed"
+)
+
+// Record is the persisted record of a CA rotation.
+type Record struct {
+	// Name identifies the rotation; it is derived from the start time so that records sort chronologically.
+	Name string `json:"name"`
+	// Status is the overall status of the rotation.
+	Status Status `json:"status"`
+	// Phase is the next phase to perform; phases before it have completed.
+	Phase Phase `json:"phase"`
+
+	// StartedAt is the time the rotation was started.
+	StartedAt time.Time `json:"startedAt"`
+	// UpdatedAt is the time the record was last updated.
+	UpdatedAt time.Time `json:"updatedAt"`
+	// Resumed counts the number of times the rotation was resumed.
+	Resumed int `json:"resumed,omitempty"`
+
+	// Keyset is the keyset the rotation was started for, or "all".
+	Keyset string `json:"keyset"`
+	// Keysets are the keysets being rotated.
+	Keysets []Keyset `json:"keysets,omitempty"`
+
+	// Error is the error that stopped the rotation, if it failed.
+	Error string `json:"error,omitempty"`
+}
+
+// Keyset records the keypairs of a keyset being rotated.
+type Keyset struct {
+	// Name is the name of the keyset.
+	Name string `json:"name"`
+	// PreviousPrimary is the ID of the keypair that was primary when the rotation started.
+	PreviousPrimary string `json:"previousPrimary"`
+	// NewKeypair is the ID of the keypair created by the rotation.
+	NewKeypair string `json:"newKeypair,omitempty"`
+}
+
+// NewRecord builds a new in-progress record for a CA rotation of the keyset started at the specified time.
+func NewRecord(now time.Time, keyset string) *Record {
+	return &Record{
+		Name:      now.UTC().Format("20060102-150405"),
+		Status:    StatusInProgress,
+		Phase:     Phases[0],
+		StartedAt: now,
+		UpdatedAt: now,
+		Keyset:    keyset,
+	}
+}
+
+// Advance marks the current phase as completed and moves to the next one.
+func (r *Record) Advance(now time.Time) error {
+	for i, phase := range Phases {
+		if phase != r.Phase {
+			continue
+		}
+		if phase == PhaseCompleted {
+			return fmt.Errorf("rotation %q has already completed", r.Name)
+		}
+		r.Phase = Phases[i+1]
+		r.UpdatedAt = now
+		r.Error = ""
+		if r.Phase == PhaseCompleted {
+			r.Status = StatusCompleted
+		} else {
+			r.Status = StatusInProgress
+		}
+		return nil
+	}
+	return fmt.Errorf("rotation %q has unknown phase %q", r.Name, r.Phase)
+}
+
+// Fail marks the rotation as failed in its current phase, so that it can be resumed from that phase.
+func (r *Record) Fail(now time.Time, err error) {
+	r.Status = StatusFailed
+	r.Error = err.Error()
+	r.UpdatedAt = now
+}
+
+// FindKeyset returns the record of the named keyset, or nil if it is not being rotated.
+func (r *Record) FindKeyset(name string) *Keyset {
+	for i := range r.Keysets {
+		if r.Keysets[i].Name == name {
+			return &r.Keysets[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package carotation
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestAdvance(t *testing.T) {
+	now := time.Date(2022, 3, 1, 12, 0, 0, 0, time.UTC)
+	record := NewRecord(now, "all")
+	if record.Name != "20220301-120000" || record.Phase != PhaseCreateKeypairs || record.Status != StatusInProgress {
+		t.Fatalf("unexpected new record: %+v", record)
+	}
+
+	record.Fail(now, fmt.Errorf("validation failed"))
+	if record.Status != StatusFailed || record.Phase != PhaseCreateKeypairs {
+		t.Fatalf("expected failed rotation to stay in its phase, got %+v", record)
+	}
+
+	for i := 1; i < len(Phases); i++ {
+		if err := record.Advance(now); err != nil {
+			t.Fatalf("error advancing rotation: %v", err)
+		}
+		if record.Phase != Phases[i] {
+			t.Fatalf("expected phase %q, got %q", Phases[i], record.Phase)
+		}
+		if record.Error != "" {
+			t.Errorf("expected error to be cleared, got %q", record.Error)
+		}
+	}
+	if record.Status != StatusCompleted {
+		t.Errorf("expected completed rotation, got status %q", record.Status)
+	}
+
+	if err := record.Advance(now); err == nil {
+		t.Errorf("expected error advancing completed rotation")
+	}
+}
@@ -55,6 +55,24 @@ func (c *RESTClientset) AddonsFor(cluster *kops.Cluster) simple.AddonsClient {
 	return nil
 }
 
+// RolloutsFor fetches the RolloutsClient for the cluster
+func (c *RESTClientset) RolloutsFor(cluster *kops.Cluster) simple.RolloutsClient {
+	klog.Fatalf("RolloutsFor not implemented for RESTClientset")
+	return nil
+}
+
+// HistoryFor fetches the HistoryClient for the cluster
+func (c *RESTClientset) HistoryFor(cluster *kops.Cluster) simple.HistoryClient {
+	klog.Fatalf("HistoryFor not implemented for RESTClientset")
+	return nil
+}
+
+// CARotationsFor fetches the CARotationsClient for the cluster
+func (c *RESTClientset) CARotationsFor(cluster *kops.Cluster) simple.CARotationsClient {
+	klog.Fatalf("CARotationsFor not implemented for RESTClientset")
+	return nil
+}
+
 // CreateCluster implements the CreateCluster method of Clientset for a kubernetes-API state store
 func (c *RESTClientset) CreateCluster(ctx context.Context, cluster *kops.Cluster) (*kops.Cluster, error) {
 	namespace := restNamespaceForClusterName(cluster.Name)
@@ -21,8 +21,11 @@ import (
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/carotation"
 	kopsinternalversion "k8s.io/kops/pkg/client/clientset_generated/clientset/typed/kops/internalversion"
+	"k8s.io/kops/pkg/history"
 	"k8s.io/kops/pkg/kubemanifest"
+	"k8s.io/kops/pkg/rollout"
 	"k8s.io/kops/upup/pkg/fi"
 	"k8s.io/kops/util/pkg/vfs"
 )
@@ -49,6 +52,15 @@ type Clientset interface {
 	// AddonsFor returns the client for addon objects for a particular Cluster
 	AddonsFor(cluster *kops.Cluster) AddonsClient
 
+	// RolloutsFor returns the client for rolling-update records for a particular Cluster
+	RolloutsFor(cluster *kops.Cluster) RolloutsClient
+
+	// HistoryFor returns the client for the configuration revisions of a particular Cluster
+	HistoryFor(cluster *kops.Cluster) HistoryClient
+
+	// CARotationsFor returns the client for CA rotation records for a particular Cluster
+	CARotationsFor(cluster *kops.Cluster) CARotationsClient
+
 	// SecretStore builds the secret store for the specified cluster
 	SecretStore(cluster *kops.Cluster) (fi.SecretStore, error)
 
@@ -71,3 +83,41 @@ type AddonsClient interface {
 	// List returns all the addon objects
 	List() (kubemanifest.ObjectList, error)
 }
+
+// RolloutsClient is a client for the records of rolling updates
+type RolloutsClient interface {
+	// Get returns the named rollout record, or nil if it does not exist
+	Get(name string) (*rollout.Record, error)
+
+	// List returns all the rollout records, oldest first
+	List() ([]*rollout.Record, error)
+
+	// Write creates or replaces a rollout record
+	Write(record *rollout.Record) error
+}
+
+// CARotationsClient is a client for the records of CA rotations
+type CARotationsClient interface {
+	// Get returns the named rotation record, or nil if it does not exist
+	Get(name string) (*carotation.Record, error)
+
+	// List returns all the rotation records, oldest first
+	List() ([]*carotation.Record, error)
+
+	// Write creates or replaces a rotation record
+	Write(record *carotation.Record) error
+}
+
+// HistoryClient is a client for the revisions of a cluster's configuration.
+// Revisions are recorded by the clientset whenever the cluster or its instance groups are written.
+type HistoryClient interface {
+	// Get returns the revision with the given number, or nil if it does not exist
+	Get(number int) (*history.Revision, error)
+
+	// List returns all the revisions, oldest first
+	List() ([]*history.Revision, error)
+
+	// Record records the current configuration as a revision, unless it is unchanged from the latest revision.
+	// It is used to record changes written with a context from history.WithoutRecording.
+	Record(ctx context.Context, change string) error
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vfsclientset
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/carotation"
+	"k8s.io/kops/util/pkg/vfs"
+)
+
+func TestCARotationsRoundTrip(t *testing.T) {
+	vfs.Context.ResetMemfsContext(true)
+	basePath, err := vfs.Context.BuildVfsPath("memfs://tests")
+	if err != nil {
+		t.Fatalf("error building path: %v", err)
+	}
+	cluster := &kops.Cluster{}
+	cluster.Name = "cluster.example.com"
+
+	client := NewVFSClientset(basePath).CARotationsFor(cluster)
+
+	records, err := client.List()
+	if err != nil {
+		t.Fatalf("error listing empty CA rotations: %v", err)
+	}
+	if len(records) != 0 {
+		t.Fatalf("expected no CA rotations, got %d", len(records))
+	}
+
+	later := carotation.NewRecord(time.Date(2022, 3, 1, 12, 0, 0, 0, time.UTC), "all")
+	earlier := carotation.NewRecord(time.Date(2022, 2, 1, 12, 0, 0, 0, time.UTC), "kubernetes-ca")
+	earlier.Keysets = []carotation.Keyset{{Name: "kubernetes-ca", PreviousPrimary: "1", NewKeypair: "2"}}
+	earlier.Phase = carotation.PhasePromoteKeypairs
+	for _, r := range []*carotation.Record{later, earlier} {
+		if err := client.Write(r); err != nil {
+			t.Fatalf("error writing CA rotation: %v", err)
+		}
+	}
+
+	records, err = client.List()
+	if err != nil {
+		t.Fatalf("error listing CA rotations: %v", err)
+	}
+	if len(records) != 2 || records[0].Name != "20220201-120000" || records[1].Name != "20220301-120000" {
+		t.Fatalf("unexpected CA rotations: %v", records)
+	}
+	if keyset := records[0].FindKeyset("kubernetes-ca"); keyset == nil || keyset.NewKeypair != "2" || records[0].Phase != carotation.PhasePromoteKeypairs {
+		t.Errorf("CA rotation not round-tripped: %v", records[0])
+	}
+
+	missing, err := client.Get("missing")
+	if err != nil || missing != nil {
+		t.Errorf("expected no CA rotation and no error, got %v, %v", missing, err)
+	}
+}
+
+func TestDeleteClusterWithCARotation(t *testing.T) {
+	vfs.Context.ResetMemfsContext(true)
+	basePath, err := vfs.Context.BuildVfsPath("memfs://tests")
+	if err != nil {
+		t.Fatalf("error building path: %v", err)
+	}
+	cluster := &kops.Cluster{}
+	cluster.Name = "cluster.example.com"
+
+	clientset := NewVFSClientset(basePath)
+	if err := clientset.CARotationsFor(cluster).Write(carotation.NewRecord(time.Date(2022, 3, 1, 12, 0, 0, 0, time.UTC), "all")); err != nil {
+		t.Fatalf("error writing CA rotation: %v", err)
+	}
+
+	if err := DeleteAllClusterState(basePath.Join(cluster.Name)); err != nil {
+		t.Fatalf("error deleting cluster state: %v", err)
+	}
+
+	records, err := clientset.CARotationsFor(cluster).List()
+	if err != nil {
+		t.Fatalf("error listing CA rotations: %v", err)
+	}
+	if len(records) != 0 {
+		t.Errorf("expected the CA rotations to be deleted, got %v", records)
+	}
+}
@@ -50,12 +50,22 @@ func (c *VFSClientset) GetCluster(ctx context.Context, name string) (*kops.Clust
 
 // UpdateCluster implements the UpdateCluster method of simple.Clientset for a VFS-backed state store
 func (c *VFSClientset) UpdateCluster(ctx context.Context, cluster *kops.Cluster, status *kops.ClusterStatus) (*kops.Cluster, error) {
-	return c.clusters().Update(cluster, status)
+	updated, err := c.clusters().Update(cluster, status)
+	if err != nil {
+		return nil, err
+	}
+	newHistoryVFS(c, cluster).record(ctx, "Updated cluster")
+	return updated, nil
 }
 
 // CreateCluster implements the CreateCluster method of simple.Clientset for a VFS-backed state store
 func (c *VFSClientset) CreateCluster(ctx context.Context, cluster *kops.Cluster) (*kops.Cluster, error) {
-	return c.clusters().Create(cluster)
+	created, err := c.clusters().Create(cluster)
+	if err != nil {
+		return nil, err
+	}
+	newHistoryVFS(c, cluster).record(ctx, "Created cluster")
+	return created, nil
 }
 
 // ListClusters implements the ListClusters method of simple.Clientset for a VFS-backed state store
@@ -80,6 +90,18 @@ func (c *VFSClientset) AddonsFor(cluster *kops.Cluster) simple.AddonsClient {
 	return newAddonsVFS(c, cluster)
 }
 
+func (c *VFSClientset) RolloutsFor(cluster *kops.Cluster) simple.RolloutsClient {
+	return newRolloutsVFS(c, cluster)
+}
+
+func (c *VFSClientset) HistoryFor(cluster *kops.Cluster) simple.HistoryClient {
+	return newHistoryVFS(c, cluster)
+}
+
+func (c *VFSClientset) CARotationsFor(cluster *kops.Cluster) simple.CARotationsClient {
+	return newCARotationsVFS(c, cluster)
+}
+
 func (c *VFSClientset) SecretStore(cluster *kops.Cluster) (fi.SecretStore, error) {
 	if cluster.Spec.SecretStore == "" {
 		configBase, err := registry.ConfigBase(cluster)
@@ -167,6 +189,15 @@ func DeleteAllClusterState(basePath vfs.Path) error {
 		if strings.HasPrefix(relativePath, "manifests/") {
 			continue
 		}
+		if strings.HasPrefix(relativePath, "rollouts/") {
+			continue
+		}
+		if strings.HasPrefix(relativePath, "history/") {
+			continue
+		}
+		if strings.HasPrefix(relativePath, "carotations/") {
+			continue
+		}
 		// TODO: offer an option _not_ to delete backups?
 		if strings.HasPrefix(relativePath, "backups/") {
 			continue
@@ -142,8 +142,8 @@ func (r *ClusterVFS) Update(c *api.Cluster, status *api.ClusterStatus) (*api.Clu
 		c.SetGeneration(old.GetGeneration() + 1)
 	}
 
-	if err := r.writeConfig(c, r.basePath.Join(clusterName, registry.PathCluster), c, vfs.WriteOptionOnlyIfExists); err != nil {
-		if os.IsNotExist(err) {
+	if err := r.writeConfigIfVersion(c, r.basePath.Join(clusterName, registry.PathCluster), c, old.ResourceVersion); err != nil {
+		if errors.IsConflict(err) {
 			return nil, err
 		}
 		return nil, fmt.Errorf("error writing Cluster: %v", err)
@@ -0,0 +1,113 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vfsclientset
+
+import (
+	"context"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kops/pkg/testutils"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/util/pkg/vfs"
+)
+
+func TestUpdateClusterConflict(t *testing.T) {
+	ctx := context.Background()
+	clientset := NewVFSClientset(vfs.NewMemFSPath(vfs.NewMemFSContext(), "state"))
+
+	if _, err := clientset.CreateCluster(ctx, testutils.BuildMinimalCluster("test.k8s.local")); err != nil {
+		t.Fatalf("error creating cluster: %v", err)
+	}
+
+	first, err := clientset.GetCluster(ctx, "test.k8s.local")
+	if err != nil {
+		t.Fatalf("error getting cluster: %v", err)
+	}
+	if first.ResourceVersion == "" {
+		t.Fatalf("expected cluster to have a resourceVersion")
+	}
+	second := first.DeepCopy()
+
+	first.Spec.KubernetesVersion = "1.24.0"
+	updated, err := clientset.UpdateCluster(ctx, first, nil)
+	if err != nil {
+		t.Fatalf("error updating cluster: %v", err)
+	}
+	if updated.ResourceVersion == second.ResourceVersion {
+		t.Errorf("expected resourceVersion to change after update")
+	}
+
+	second.Spec.KubernetesVersion = "1.23.0"
+	if _, err := clientset.UpdateCluster(ctx, second, nil); !errors.IsConflict(err) {
+		t.Fatalf("expected Conflict updating stale cluster, got: %v", err)
+	}
+
+	actual, err := clientset.GetCluster(ctx, "test.k8s.local")
+	if err != nil {
+		t.Fatalf("error getting cluster: %v", err)
+	}
+	if actual.Spec.KubernetesVersion != "1.24.0" {
+		t.Errorf("expected stale update to be rejected, got kubernetesVersion %q", actual.Spec.KubernetesVersion)
+	}
+	if actual.ResourceVersion != updated.ResourceVersion {
+		t.Errorf("expected resourceVersion %q, got %q", updated.ResourceVersion, actual.ResourceVersion)
+	}
+
+	// Without a resourceVersion, the update replaces the current version
+	second.ResourceVersion = ""
+	if _, err := clientset.UpdateCluster(ctx, second, nil); err != nil {
+		t.Fatalf("error updating cluster without resourceVersion: %v", err)
+	}
+}
+
+func TestUpdateInstanceGroupConflict(t *testing.T) {
+	ctx := context.Background()
+	clientset := NewVFSClientset(vfs.NewMemFSPath(vfs.NewMemFSContext(), "state"))
+
+	cluster := testutils.BuildMinimalCluster("test.k8s.local")
+	ig := testutils.BuildMinimalNodeInstanceGroup("nodes", "subnet-us-test-1a")
+	ig.Spec.Image = "ubuntu/images/hvm-ssd/ubuntu-focal-20.04-amd64-server-20220404"
+	if _, err := clientset.InstanceGroupsFor(cluster).Create(ctx, &ig, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("error creating instance group: %v", err)
+	}
+
+	first, err := clientset.InstanceGroupsFor(cluster).Get(ctx, "nodes", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("error getting instance group: %v", err)
+	}
+	second := first.DeepCopy()
+
+	first.Spec.MaxSize = fi.Int32(3)
+	if _, err := clientset.InstanceGroupsFor(cluster).Update(ctx, first, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("error updating instance group: %v", err)
+	}
+
+	second.Spec.MaxSize = fi.Int32(5)
+	if _, err := clientset.InstanceGroupsFor(cluster).Update(ctx, second, metav1.UpdateOptions{}); !errors.IsConflict(err) {
+		t.Fatalf("expected Conflict updating stale instance group, got: %v", err)
+	}
+
+	actual, err := clientset.InstanceGroupsFor(cluster).Get(ctx, "nodes", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("error getting instance group: %v", err)
+	}
+	if *actual.Spec.MaxSize != 3 {
+		t.Errorf("expected stale update to be rejected, got maxSize %d", *actual.Spec.MaxSize)
+	}
+}
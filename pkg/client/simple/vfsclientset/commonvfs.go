@@ -19,15 +19,18 @@ package vfsclientset
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"reflect"
 	"sort"
 	"time"
 
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/klog/v2"
 	"k8s.io/kops/pkg/acls"
 	"k8s.io/kops/pkg/apis/kops"
@@ -104,6 +107,13 @@ func (c *commonVFS) create(ctx context.Context, cluster *kops.Cluster, i runtime
 }
 
 func (c *commonVFS) serialize(o runtime.Object) ([]byte, error) {
+	// The resourceVersion identifies the version of the stored file, so it isn't stored in the file itself
+	if objectMeta, err := meta.Accessor(o); err == nil && objectMeta.GetResourceVersion() != "" {
+		o = o.DeepCopyObject()
+		objectMeta, _ = meta.Accessor(o)
+		objectMeta.SetResourceVersion("")
+	}
+
 	var b bytes.Buffer
 	err := c.encoder.Encode(o, &b)
 	if err != nil {
@@ -114,7 +124,7 @@ func (c *commonVFS) serialize(o runtime.Object) ([]byte, error) {
 }
 
 func (c *commonVFS) readConfig(configPath vfs.Path) (runtime.Object, error) {
-	data, err := configPath.ReadFile()
+	data, version, err := vfs.ReadFileWithVersion(configPath)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return nil, err
@@ -126,6 +136,13 @@ func (c *commonVFS) readConfig(configPath vfs.Path) (runtime.Object, error) {
 	if err != nil {
 		return nil, fmt.Errorf("error parsing %s: %v", configPath, err)
 	}
+
+	objectMeta, err := meta.Accessor(object)
+	if err != nil {
+		return nil, err
+	}
+	objectMeta.SetResourceVersion(version)
+
 	return object, nil
 }
 
@@ -174,7 +191,54 @@ func (c *commonVFS) writeConfig(cluster *kops.Cluster, configPath vfs.Path, o ru
 	return nil
 }
 
-func (c *commonVFS) update(ctx context.Context, cluster *kops.Cluster, i runtime.Object) error {
+// writeConfigIfVersion replaces the configuration file if it has not been modified since the given version was read.
+// It sets the resourceVersion of the object to the version that was written.
+func (c *commonVFS) writeConfigIfVersion(cluster *kops.Cluster, configPath vfs.Path, o runtime.Object, version string) error {
+	objectMeta, err := meta.Accessor(o)
+	if err != nil {
+		return err
+	}
+
+	if err := checkResourceVersion(c.kind, objectMeta.GetName(), objectMeta.GetResourceVersion(), version); err != nil {
+		return err
+	}
+
+	data, err := c.serialize(o)
+	if err != nil {
+		return fmt.Errorf("error marshaling object: %v", err)
+	}
+
+	acl, err := acls.GetACL(configPath, cluster)
+	if err != nil {
+		return err
+	}
+
+	newVersion, err := vfs.WriteFileIfVersion(configPath, bytes.NewReader(data), acl, version)
+	if err != nil {
+		if errors.Is(err, vfs.ErrVersionMismatch) {
+			return conflictError(c.kind, objectMeta.GetName())
+		}
+		return fmt.Errorf("error writing configuration file %s: %v", configPath, err)
+	}
+
+	objectMeta.SetResourceVersion(newVersion)
+	return nil
+}
+
+// checkResourceVersion returns a Conflict error if the object was read from a different version than the current version.
+// Objects without a resourceVersion replace whatever version is current.
+func checkResourceVersion(kind string, name string, resourceVersion string, currentVersion string) error {
+	if resourceVersion != "" && resourceVersion != currentVersion {
+		return conflictError(kind, name)
+	}
+	return nil
+}
+
+func conflictError(kind string, name string) error {
+	return apierrors.NewConflict(schema.GroupResource{Group: kops.GroupName, Resource: kind}, name, fmt.Errorf("the object has been modified; please apply your changes to the latest version and try again"))
+}
+
+func (c *commonVFS) update(ctx context.Context, cluster *kops.Cluster, i runtime.Object, currentVersion string) error {
 	objectMeta, err := meta.Accessor(i)
 	if err != nil {
 		return err
@@ -192,8 +256,11 @@ func (c *commonVFS) update(ctx context.Context, cluster *kops.Cluster, i runtime
 		objectMeta.SetCreationTimestamp(metav1.NewTime(time.Now().UTC()))
 	}
 
-	err = c.writeConfig(cluster, c.basePath.Join(objectMeta.GetName()), i, vfs.WriteOptionOnlyIfExists)
+	err = c.writeConfigIfVersion(cluster, c.basePath.Join(objectMeta.GetName()), i, currentVersion)
 	if err != nil {
+		if apierrors.IsConflict(err) {
+			return err
+		}
 		return fmt.Errorf("error writing %s: %v", c.kind, err)
 	}
 
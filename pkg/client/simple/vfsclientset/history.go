@@ -0,0 +1,247 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vfsclientset
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/user"
+	"sort"
+	"strconv"
+	"time"
+
+	"k8s.io/klog/v2"
+	kopsbase "k8s.io/kops"
+	"k8s.io/kops/pkg/acls"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/apis/kops/registry"
+	"k8s.io/kops/pkg/client/simple"
+	"k8s.io/kops/pkg/history"
+	"k8s.io/kops/util/pkg/vfs"
+	"sigs.k8s.io/yaml"
+)
+
+// maxRecordAttempts bounds the retries when another writer records a revision with the same number
+const maxRecordAttempts = 10
+
+// maxRevisions is the number of revisions kept; older revisions are removed as new ones are recorded
+const maxRevisions = 100
+
+type vfsHistoryClient struct {
+	basePath vfs.Path
+
+	cluster     *kops.Cluster
+	clusterPath vfs.Path
+}
+
+var _ simple.HistoryClient = &vfsHistoryClient{}
+
+func newHistoryVFS(c *VFSClientset, cluster *kops.Cluster) *vfsHistoryClient {
+	if cluster == nil || cluster.Name == "" {
+		klog.Fatalf("cluster / cluster.Name is required")
+	}
+
+	return &vfsHistoryClient{
+		basePath:    c.basePath.Join(cluster.Name, "history"),
+		cluster:     cluster,
+		clusterPath: c.basePath.Join(cluster.Name),
+	}
+}
+
+func (c *vfsHistoryClient) Get(number int) (*history.Revision, error) {
+	p := c.basePath.Join(revisionName(number))
+
+	b, err := p.ReadFile()
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error reading revision file %s: %v", p, err)
+	}
+
+	revision := &history.Revision{}
+	if err := yaml.Unmarshal(b, revision); err != nil {
+		return nil, fmt.Errorf("error parsing revision file %s: %v", p, err)
+	}
+	return revision, nil
+}
+
+func (c *vfsHistoryClient) List() ([]*history.Revision, error) {
+	numbers, err := c.listNumbers()
+	if err != nil {
+		return nil, err
+	}
+
+	var revisions []*history.Revision
+	for _, number := range numbers {
+		revision, err := c.Get(number)
+		if err != nil {
+			return nil, err
+		}
+		if revision != nil {
+			revisions = append(revisions, revision)
+		}
+	}
+	return revisions, nil
+}
+
+// listNumbers returns the numbers of the recorded revisions, in ascending order
+func (c *vfsHistoryClient) listNumbers() ([]int, error) {
+	files, err := c.basePath.ReadDir()
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error listing revisions in %s: %v", c.basePath, err)
+	}
+
+	var numbers []int
+	for _, f := range files {
+		number, err := strconv.Atoi(f.Base())
+		if err != nil {
+			klog.Warningf("ignoring unexpected file in revision history: %s", f)
+			continue
+		}
+		numbers = append(numbers, number)
+	}
+	sort.Ints(numbers)
+	return numbers, nil
+}
+
+// record stores the current configuration of the cluster and its instance groups as a new revision,
+// unless it is unchanged from the latest revision or recording is disabled for the context.
+// The configuration has already been written, so failures are logged rather than returned.
+func (c *vfsHistoryClient) record(ctx context.Context, change string) {
+	if history.RecordingDisabled(ctx) {
+		return
+	}
+	if err := c.Record(ctx, change); err != nil {
+		klog.Warningf("unable to record revision history: %v", err)
+	}
+}
+
+func (c *vfsHistoryClient) Record(ctx context.Context, change string) error {
+	revision := &history.Revision{
+		Timestamp:   time.Now().UTC(),
+		User:        currentUser(),
+		KopsVersion: kopsbase.Version,
+		Change:      change,
+	}
+
+	clusterConfig, err := c.clusterPath.Join(registry.PathCluster).ReadFile()
+	if err != nil {
+		return fmt.Errorf("error reading cluster configuration: %v", err)
+	}
+	revision.Cluster = string(clusterConfig)
+
+	igPath := c.clusterPath.Join("instancegroup")
+	names, err := listChildNames(ctx, igPath)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		igConfig, err := igPath.Join(name).ReadFile()
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return fmt.Errorf("error reading instance group %q: %v", name, err)
+		}
+		if revision.InstanceGroups == nil {
+			revision.InstanceGroups = make(map[string]string)
+		}
+		revision.InstanceGroups[name] = string(igConfig)
+	}
+
+	numbers, err := c.listNumbers()
+	if err != nil {
+		return err
+	}
+	next := 1
+	if len(numbers) != 0 {
+		latestNumber := numbers[len(numbers)-1]
+		latest, err := c.Get(latestNumber)
+		if err != nil {
+			return err
+		}
+		if latest != nil && latest.SameConfig(revision) {
+			klog.V(4).Infof("configuration is unchanged from revision %d; not recording a revision", latestNumber)
+			return nil
+		}
+		next = latestNumber + 1
+	}
+
+	// Another writer may record a revision concurrently, so we create the file exclusively and move on if the number is taken
+	for attempt := 0; attempt < maxRecordAttempts; attempt++ {
+		revision.Number = next + attempt
+
+		b, err := yaml.Marshal(revision)
+		if err != nil {
+			return fmt.Errorf("error serializing revision: %v", err)
+		}
+
+		p := c.basePath.Join(revisionName(revision.Number))
+		acl, err := acls.GetACL(p, c.cluster)
+		if err != nil {
+			return err
+		}
+		if err := p.CreateFile(bytes.NewReader(b), acl); err != nil {
+			if os.IsExist(err) {
+				continue
+			}
+			return fmt.Errorf("error writing revision file %s: %v", p, err)
+		}
+		klog.V(2).Infof("recorded revision %d of cluster configuration", revision.Number)
+		c.prune(revision.Number)
+		return nil
+	}
+	return fmt.Errorf("unable to find an unused revision number after %d", next)
+}
+
+// prune removes the revisions older than the latest maxRevisions.
+// The new revision has been recorded, so failures are logged rather than returned.
+func (c *vfsHistoryClient) prune(latest int) {
+	numbers, err := c.listNumbers()
+	if err != nil {
+		klog.Warningf("unable to prune revision history: %v", err)
+		return
+	}
+	for _, number := range numbers {
+		if number > latest-maxRevisions {
+			break
+		}
+		p := c.basePath.Join(revisionName(number))
+		if err := p.Remove(); err != nil && !os.IsNotExist(err) {
+			klog.Warningf("unable to remove revision file %s: %v", p, err)
+		}
+	}
+}
+
+// revisionName is the name of the file for a revision; it is zero-padded so that the files sort in order
+func revisionName(number int) string {
+	return fmt.Sprintf("%06d", number)
+}
+
+// currentUser returns the name of the operating system user running kOps
+func currentUser() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
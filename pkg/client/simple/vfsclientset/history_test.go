@@ -0,0 +1,174 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vfsclientset
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kopsbase "k8s.io/kops"
+	"k8s.io/kops/pkg/history"
+	"k8s.io/kops/pkg/testutils"
+	"k8s.io/kops/util/pkg/vfs"
+)
+
+func TestHistoryRecordsWrites(t *testing.T) {
+	ctx := context.Background()
+	clientset := NewVFSClientset(vfs.NewMemFSPath(vfs.NewMemFSContext(), "state"))
+
+	cluster, err := clientset.CreateCluster(ctx, testutils.BuildMinimalCluster("test.k8s.local"))
+	if err != nil {
+		t.Fatalf("error creating cluster: %v", err)
+	}
+
+	ig := testutils.BuildMinimalNodeInstanceGroup("nodes", "subnet-us-test-1a")
+	ig.Spec.Image = "ubuntu/images/hvm-ssd/ubuntu-focal-20.04-amd64-server-20220404"
+	if _, err := clientset.InstanceGroupsFor(cluster).Create(ctx, &ig, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("error creating instance group: %v", err)
+	}
+
+	cluster, err = clientset.GetCluster(ctx, "test.k8s.local")
+	if err != nil {
+		t.Fatalf("error getting cluster: %v", err)
+	}
+	cluster.Spec.KubernetesVersion = "1.24.0"
+	if _, err := clientset.UpdateCluster(ctx, cluster, nil); err != nil {
+		t.Fatalf("error updating cluster: %v", err)
+	}
+
+	// Writing an unchanged configuration doesn't record a revision
+	if _, err := clientset.UpdateCluster(ctx, cluster, nil); err != nil {
+		t.Fatalf("error updating cluster: %v", err)
+	}
+
+	revisions, err := clientset.HistoryFor(cluster).List()
+	if err != nil {
+		t.Fatalf("error listing revisions: %v", err)
+	}
+	if len(revisions) != 3 {
+		t.Fatalf("expected 3 revisions, got %d", len(revisions))
+	}
+
+	expectedChanges := []string{"Created cluster", `Created instance group "nodes"`, "Updated cluster"}
+	for i, revision := range revisions {
+		if revision.Number != i+1 {
+			t.Errorf("expected revision %d, got %d", i+1, revision.Number)
+		}
+		if revision.Change != expectedChanges[i] {
+			t.Errorf("expected change %q for revision %d, got %q", expectedChanges[i], revision.Number, revision.Change)
+		}
+		if revision.KopsVersion != kopsbase.Version {
+			t.Errorf("expected kops version %q, got %q", kopsbase.Version, revision.KopsVersion)
+		}
+	}
+
+	if len(revisions[0].InstanceGroups) != 0 {
+		t.Errorf("expected no instance groups in revision 1, got %v", revisions[0].InstanceGroups)
+	}
+	if _, found := revisions[1].InstanceGroups["nodes"]; !found {
+		t.Errorf("expected instance group in revision 2")
+	}
+	if strings.Contains(revisions[1].Cluster, "1.24.0") || !strings.Contains(revisions[2].Cluster, "kubernetesVersion: 1.24.0") {
+		t.Errorf("expected kubernetesVersion to change in revision 3")
+	}
+
+	revision, err := clientset.HistoryFor(cluster).Get(2)
+	if err != nil {
+		t.Fatalf("error getting revision: %v", err)
+	}
+	if revision == nil || !revision.SameConfig(revisions[1]) {
+		t.Errorf("unexpected revision 2: %v", revision)
+	}
+
+	missing, err := clientset.HistoryFor(cluster).Get(4)
+	if err != nil || missing != nil {
+		t.Errorf("expected no revision and no error, got %v, %v", missing, err)
+	}
+}
+
+func TestHistoryRecordsDeferredWritesOnce(t *testing.T) {
+	ctx := context.Background()
+	clientset := NewVFSClientset(vfs.NewMemFSPath(vfs.NewMemFSContext(), "state"))
+
+	cluster, err := clientset.CreateCluster(ctx, testutils.BuildMinimalCluster("test.k8s.local"))
+	if err != nil {
+		t.Fatalf("error creating cluster: %v", err)
+	}
+
+	writeCtx := history.WithoutRecording(ctx)
+	igClient := clientset.InstanceGroupsFor(cluster)
+	for _, name := range []string{"nodes-a", "nodes-b"} {
+		ig := testutils.BuildMinimalNodeInstanceGroup(name, "subnet-us-test-1a")
+		ig.Spec.Image = "ubuntu/images/hvm-ssd/ubuntu-focal-20.04-amd64-server-20220404"
+		if _, err := igClient.Create(writeCtx, &ig, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("error creating instance group: %v", err)
+		}
+	}
+	if err := clientset.HistoryFor(cluster).Record(ctx, "Created instance groups"); err != nil {
+		t.Fatalf("error recording revision: %v", err)
+	}
+
+	if err := igClient.DeleteCollection(ctx, metav1.DeleteOptions{}, metav1.ListOptions{}); err != nil {
+		t.Fatalf("error deleting instance groups: %v", err)
+	}
+
+	revisions, err := clientset.HistoryFor(cluster).List()
+	if err != nil {
+		t.Fatalf("error listing revisions: %v", err)
+	}
+	var changes []string
+	for _, revision := range revisions {
+		changes = append(changes, revision.Change)
+	}
+	expectedChanges := []string{"Created cluster", "Created instance groups", `Deleted instance groups "nodes-a", "nodes-b"`}
+	if strings.Join(changes, "\n") != strings.Join(expectedChanges, "\n") {
+		t.Fatalf("expected changes %q, got %q", expectedChanges, changes)
+	}
+	if len(revisions[1].InstanceGroups) != 2 || len(revisions[2].InstanceGroups) != 0 {
+		t.Errorf("unexpected instance groups in revisions: %v, %v", revisions[1].InstanceGroups, revisions[2].InstanceGroups)
+	}
+}
+
+func TestHistoryRetention(t *testing.T) {
+	ctx := context.Background()
+	clientset := NewVFSClientset(vfs.NewMemFSPath(vfs.NewMemFSContext(), "state"))
+
+	cluster, err := clientset.CreateCluster(ctx, testutils.BuildMinimalCluster("test.k8s.local"))
+	if err != nil {
+		t.Fatalf("error creating cluster: %v", err)
+	}
+	for i := 0; i < maxRevisions+5; i++ {
+		cluster.Spec.KubernetesVersion = fmt.Sprintf("1.24.%d", i)
+		if cluster, err = clientset.UpdateCluster(ctx, cluster, nil); err != nil {
+			t.Fatalf("error updating cluster: %v", err)
+		}
+	}
+
+	revisions, err := clientset.HistoryFor(cluster).List()
+	if err != nil {
+		t.Fatalf("error listing revisions: %v", err)
+	}
+	if len(revisions) != maxRevisions {
+		t.Fatalf("expected %d revisions, got %d", maxRevisions, len(revisions))
+	}
+	if first, last := revisions[0].Number, revisions[len(revisions)-1].Number; first != 7 || last != maxRevisions+6 {
+		t.Errorf("expected revisions 7 to %d, got %d to %d", maxRevisions+6, first, last)
+	}
+}
@@ -19,6 +19,7 @@ package vfsclientset
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -38,6 +39,7 @@ type InstanceGroupVFS struct {
 
 	clusterName string
 	cluster     *kopsapi.Cluster
+	history     *vfsHistoryClient
 }
 
 func newInstanceGroupVFS(c *VFSClientset, cluster *kopsapi.Cluster) *InstanceGroupVFS {
@@ -51,6 +53,7 @@ func newInstanceGroupVFS(c *VFSClientset, cluster *kopsapi.Cluster) *InstanceGro
 	r := &InstanceGroupVFS{
 		cluster:     cluster,
 		clusterName: clusterName,
+		history:     newHistoryVFS(c, cluster),
 	}
 	r.init(kind, c.basePath.Join(clusterName, "instancegroup"), StoreVersion)
 	r.validate = func(o runtime.Object) error {
@@ -105,6 +108,7 @@ func (c *InstanceGroupVFS) Create(ctx context.Context, g *kopsapi.InstanceGroup,
 	if err != nil {
 		return nil, err
 	}
+	c.history.record(ctx, fmt.Sprintf("Created instance group %q", g.Name))
 	return g, nil
 }
 
@@ -119,19 +123,41 @@ func (c *InstanceGroupVFS) Update(ctx context.Context, g *kopsapi.InstanceGroup,
 	}
 
 	validation.ValidateInstanceGroup(g, nil, true)
-	err = c.update(ctx, c.cluster, g)
+	err = c.update(ctx, c.cluster, g, old.ResourceVersion)
 	if err != nil {
 		return nil, err
 	}
+	c.history.record(ctx, fmt.Sprintf("Updated instance group %q", g.Name))
 	return g, nil
 }
 
 func (c *InstanceGroupVFS) Delete(ctx context.Context, name string, options metav1.DeleteOptions) error {
-	return c.delete(ctx, name, options)
+	if err := c.delete(ctx, name, options); err != nil {
+		return err
+	}
+	c.history.record(ctx, fmt.Sprintf("Deleted instance group %q", name))
+	return nil
 }
 
-func (r *InstanceGroupVFS) DeleteCollection(ctx context.Context, options metav1.DeleteOptions, listOptions metav1.ListOptions) error {
-	return fmt.Errorf("InstanceGroupVFS DeleteCollection not implemented for vfs store")
+func (c *InstanceGroupVFS) DeleteCollection(ctx context.Context, options metav1.DeleteOptions, listOptions metav1.ListOptions) error {
+	list, err := c.List(ctx, listOptions)
+	if err != nil {
+		return err
+	}
+
+	// The instance groups deleted before any failure are recorded as one revision
+	var names []string
+	for i := range list.Items {
+		name := list.Items[i].Name
+		if err = c.delete(ctx, name, options); err != nil {
+			break
+		}
+		names = append(names, fmt.Sprintf("%q", name))
+	}
+	if len(names) != 0 {
+		c.history.record(ctx, fmt.Sprintf("Deleted instance groups %s", strings.Join(names, ", ")))
+	}
+	return err
 }
 
 func (r *InstanceGroupVFS) Watch(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error) {
@@ -0,0 +1,96 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vfsclientset
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/klog/v2"
+	"k8s.io/kops/pkg/acls"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/util/pkg/hashing"
+	"k8s.io/kops/util/pkg/vfs"
+)
+
+// CopyClusterState copies the files that make up the state of a cluster from src to dst, and checks
+// that each copy has the same contents as the original.
+// Etcd backups are not copied; they are managed by etcd-manager in the backup store.
+// It returns the paths of the copied files, relative to src.
+func CopyClusterState(src vfs.Path, dst vfs.Path, cluster *kops.Cluster) ([]string, error) {
+	paths, err := src.ReadTree()
+	if err != nil {
+		return nil, fmt.Errorf("error listing files in %s: %v", src, err)
+	}
+
+	var relativePaths []string
+	for _, p := range paths {
+		relativePath, err := vfs.RelativePath(src, p)
+		if err != nil {
+			return nil, err
+		}
+		if relativePath == "" || strings.HasPrefix(relativePath, "backups/") {
+			continue
+		}
+		relativePaths = append(relativePaths, relativePath)
+	}
+	sort.Strings(relativePaths)
+
+	for _, relativePath := range relativePaths {
+		if err := copyStateFile(src.Join(relativePath), dst.Join(relativePath), cluster); err != nil {
+			return nil, err
+		}
+	}
+	return relativePaths, nil
+}
+
+// copyStateFile copies a single file, checking that the copy has the expected hash
+func copyStateFile(from vfs.Path, to vfs.Path, cluster *kops.Cluster) error {
+	data, err := from.ReadFile()
+	if err != nil {
+		return fmt.Errorf("error reading %s: %v", from, err)
+	}
+	expected, err := hashing.HashAlgorithmSHA256.Hash(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+
+	acl, err := acls.GetACL(to, cluster)
+	if err != nil {
+		return err
+	}
+	if err := to.WriteFile(bytes.NewReader(data), acl); err != nil {
+		return fmt.Errorf("error writing %s: %v", to, err)
+	}
+
+	copied, err := to.ReadFile()
+	if err != nil {
+		return fmt.Errorf("error reading %s: %v", to, err)
+	}
+	actual, err := hashing.HashAlgorithmSHA256.Hash(bytes.NewReader(copied))
+	if err != nil {
+		return err
+	}
+	if !actual.Equal(expected) {
+		return fmt.Errorf("hash of %s (%s) does not match %s (%s)", to, actual.Hex(), from, expected.Hex())
+	}
+
+	klog.V(2).Infof("copied %s to %s (sha256 %s)", from, to, actual.Hex())
+	return nil
+}
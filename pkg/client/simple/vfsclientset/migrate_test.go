@@ -0,0 +1,96 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vfsclientset
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kops/pkg/testutils"
+	"k8s.io/kops/util/pkg/vfs"
+)
+
+func TestCopyClusterState(t *testing.T) {
+	ctx := context.Background()
+	srcStateStore := vfs.NewMemFSPath(vfs.NewMemFSContext(), "old")
+	clientset := NewVFSClientset(srcStateStore)
+
+	cluster, err := clientset.CreateCluster(ctx, testutils.BuildMinimalCluster("test.k8s.local"))
+	if err != nil {
+		t.Fatalf("error creating cluster: %v", err)
+	}
+	ig := testutils.BuildMinimalNodeInstanceGroup("nodes", "subnet-us-test-1a")
+	ig.Spec.Image = "ubuntu/images/hvm-ssd/ubuntu-focal-20.04-amd64-server-20220404"
+	if _, err := clientset.InstanceGroupsFor(cluster).Create(ctx, &ig, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("error creating instance group: %v", err)
+	}
+
+	src := srcStateStore.Join("test.k8s.local")
+	for _, f := range []string{"pki/private/kubernetes-ca/keyset.yaml", "secrets/admin", "backups/etcd/main/backup.tgz"} {
+		if err := src.Join(f).WriteFile(bytes.NewReader([]byte(f)), nil); err != nil {
+			t.Fatalf("error writing %s: %v", f, err)
+		}
+	}
+
+	dstStateStore := vfs.NewMemFSPath(vfs.NewMemFSContext(), "new")
+	dst := dstStateStore.Join("test.k8s.local")
+	copied, err := CopyClusterState(src, dst, cluster)
+	if err != nil {
+		t.Fatalf("error copying cluster state: %v", err)
+	}
+
+	expected := []string{
+		"config",
+		"history/000001",
+		"history/000002",
+		"instancegroup/nodes",
+		"pki/private/kubernetes-ca/keyset.yaml",
+		"secrets/admin",
+	}
+	if !reflect.DeepEqual(copied, expected) {
+		t.Fatalf("expected files %v to be copied, got %v", expected, copied)
+	}
+
+	for _, f := range expected {
+		original, err := src.Join(f).ReadFile()
+		if err != nil {
+			t.Fatalf("error reading %s: %v", f, err)
+		}
+		actual, err := dst.Join(f).ReadFile()
+		if err != nil {
+			t.Fatalf("error reading copy of %s: %v", f, err)
+		}
+		if !bytes.Equal(original, actual) {
+			t.Errorf("copy of %s does not match the original", f)
+		}
+	}
+
+	if _, err := dst.Join("backups/etcd/main/backup.tgz").ReadFile(); err == nil {
+		t.Errorf("expected etcd backups not to be copied")
+	}
+
+	migrated, err := NewVFSClientset(dstStateStore).GetCluster(ctx, "test.k8s.local")
+	if err != nil {
+		t.Fatalf("error getting copied cluster: %v", err)
+	}
+	if migrated == nil {
+		t.Fatalf("copied cluster not found")
+	}
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vfsclientset
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sort"
+
+	"k8s.io/klog/v2"
+	"k8s.io/kops/pkg/acls"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/util/pkg/vfs"
+	"sigs.k8s.io/yaml"
+)
+
+// vfsRecordStore stores records of a cluster's operations, one file per record.
+// Records are named after the time they were started, so listing them by name lists them oldest first.
+type vfsRecordStore[T any] struct {
+	basePath vfs.Path
+
+	cluster *kops.Cluster
+
+	// kind describes the records in errors
+	kind string
+	// nameOf returns the name of a record, which is also the name of its file
+	nameOf func(record *T) string
+}
+
+func newRecordStoreVFS[T any](c *VFSClientset, cluster *kops.Cluster, dir string, kind string, nameOf func(record *T) string) *vfsRecordStore[T] {
+	if cluster == nil || cluster.Name == "" {
+		klog.Fatalf("cluster / cluster.Name is required")
+	}
+
+	return &vfsRecordStore[T]{
+		basePath: c.basePath.Join(cluster.Name, dir),
+		cluster:  cluster,
+		kind:     kind,
+		nameOf:   nameOf,
+	}
+}
+
+func (c *vfsRecordStore[T]) Get(name string) (*T, error) {
+	p := c.basePath.Join(name)
+
+	b, err := p.ReadFile()
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error reading %s file %s: %v", c.kind, p, err)
+	}
+
+	record := new(T)
+	if err := yaml.Unmarshal(b, record); err != nil {
+		return nil, fmt.Errorf("error parsing %s file %s: %v", c.kind, p, err)
+	}
+	return record, nil
+}
+
+func (c *vfsRecordStore[T]) List() ([]*T, error) {
+	files, err := c.basePath.ReadDir()
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error listing %s records in %s: %v", c.kind, c.basePath, err)
+	}
+
+	var records []*T
+	for _, f := range files {
+		record, err := c.Get(f.Base())
+		if err != nil {
+			return nil, err
+		}
+		if record != nil {
+			records = append(records, record)
+		}
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		return c.nameOf(records[i]) < c.nameOf(records[j])
+	})
+	return records, nil
+}
+
+func (c *vfsRecordStore[T]) Write(record *T) error {
+	name := c.nameOf(record)
+	if name == "" {
+		return fmt.Errorf("%s name is required", c.kind)
+	}
+
+	b, err := yaml.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("error serializing %s: %v", c.kind, err)
+	}
+
+	p := c.basePath.Join(name)
+
+	acl, err := acls.GetACL(p, c.cluster)
+	if err != nil {
+		return err
+	}
+
+	if err := p.WriteFile(bytes.NewReader(b), acl); err != nil {
+		return fmt.Errorf("error writing %s file %s: %v", c.kind, p, err)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vfsclientset
+
+import (
+	"testing"
+
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/util/pkg/vfs"
+)
+
+type testRecord struct {
+	Name  string `json:"name"`
+	Value string `json:"value,omitempty"`
+}
+
+func TestRecordStoreRoundTrip(t *testing.T) {
+	vfs.Context.ResetMemfsContext(true)
+	basePath, err := vfs.Context.BuildVfsPath("memfs://tests")
+	if err != nil {
+		t.Fatalf("error building path: %v", err)
+	}
+	cluster := &kops.Cluster{}
+	cluster.Name = "cluster.example.com"
+
+	client := newRecordStoreVFS(NewVFSClientset(basePath).(*VFSClientset), cluster, "records", "test record", func(record *testRecord) string {
+		return record.Name
+	})
+
+	records, err := client.List()
+	if err != nil {
+		t.Fatalf("error listing empty records: %v", err)
+	}
+	if len(records) != 0 {
+		t.Fatalf("expected no records, got %d", len(records))
+	}
+
+	for _, r := range []*testRecord{{Name: "20220301-120000"}, {Name: "20220201-120000", Value: "earlier"}} {
+		if err := client.Write(r); err != nil {
+			t.Fatalf("error writing record: %v", err)
+		}
+	}
+	if err := client.Write(&testRecord{}); err == nil {
+		t.Errorf("expected error writing a record without a name")
+	}
+
+	records, err = client.List()
+	if err != nil {
+		t.Fatalf("error listing records: %v", err)
+	}
+	if len(records) != 2 || records[0].Name != "20220201-120000" || records[1].Name != "20220301-120000" {
+		t.Fatalf("unexpected records: %v", records)
+	}
+	if records[0].Value != "earlier" {
+		t.Errorf("record not round-tripped: %v", records[0])
+	}
+
+	if _, err := basePath.Join(cluster.Name, "records", "20220201-120000").ReadFile(); err != nil {
+		t.Errorf("expected the record to be stored in its own file: %v", err)
+	}
+
+	missing, err := client.Get("missing")
+	if err != nil || missing != nil {
+		t.Errorf("expected no record and no error, got %v, %v", missing, err)
+	}
+}
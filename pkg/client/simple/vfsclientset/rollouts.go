@@ -0,0 +1,31 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vfsclientset
+
+import (
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/client/simple"
+	"k8s.io/kops/pkg/rollout"
+)
+
+var _ simple.RolloutsClient = &vfsRecordStore[rollout.Record]{}
+
+func newRolloutsVFS(c *VFSClientset, cluster *kops.Cluster) *vfsRecordStore[rollout.Record] {
+	return newRecordStoreVFS(c, cluster, "rollouts", "rollout", func(record *rollout.Record) string {
+		return record.Name
+	})
+}
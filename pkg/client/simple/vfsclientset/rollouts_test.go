@@ -0,0 +1,88 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vfsclientset
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/rollout"
+	"k8s.io/kops/util/pkg/vfs"
+)
+
+func TestRolloutsRoundTrip(t *testing.T) {
+	vfs.Context.ResetMemfsContext(true)
+	basePath, err := vfs.Context.BuildVfsPath("memfs://tests")
+	if err != nil {
+		t.Fatalf("error building path: %v", err)
+	}
+	cluster := &kops.Cluster{}
+	cluster.Name = "cluster.example.com"
+
+	client := NewVFSClientset(basePath).RolloutsFor(cluster)
+
+	records, err := client.List()
+	if err != nil {
+		t.Fatalf("error listing empty rollouts: %v", err)
+	}
+	if len(records) != 0 {
+		t.Fatalf("expected no rollouts, got %d", len(records))
+	}
+
+	later := rollout.NewRecord(time.Date(2022, 3, 1, 12, 0, 0, 0, time.UTC), rollout.Options{})
+	earlier := rollout.NewRecord(time.Date(2022, 2, 1, 12, 0, 0, 0, time.UTC), rollout.Options{InstanceGroups: []string{"nodes"}})
+	earlier.CompletedGroups = []string{"nodes"}
+	earlier.Status = rollout.StatusCompleted
+	for _, r := range []*rollout.Record{later, earlier} {
+		if err := client.Write(r); err != nil {
+			t.Fatalf("error writing rollout: %v", err)
+		}
+	}
+
+	records, err = client.List()
+	if err != nil {
+		t.Fatalf("error listing rollouts: %v", err)
+	}
+	if len(records) != 2 || records[0].Name != earlier.Name || records[1].Name != later.Name {
+		t.Fatalf("unexpected rollouts: %v", records)
+	}
+	if !records[0].IsGroupCompleted("nodes") || records[0].Status != rollout.StatusCompleted {
+		t.Errorf("rollout not round-tripped: %v", records[0])
+	}
+
+	later.Status = rollout.StatusFailed
+	later.Error = "instance group \"nodes\" did not validate"
+	if err := client.Write(later); err != nil {
+		t.Fatalf("error updating rollout: %v", err)
+	}
+	updated, err := client.Get(later.Name)
+	if err != nil {
+		t.Fatalf("error reading rollout: %v", err)
+	}
+	if updated == nil || updated.Status != rollout.StatusFailed || updated.Error != later.Error {
+		t.Errorf("rollout not updated: %v", updated)
+	}
+	if records, err := client.List(); err != nil || len(records) != 2 {
+		t.Errorf("expected updating a rollout to keep 2 rollouts, got %v, %v", records, err)
+	}
+
+	missing, err := client.Get("missing")
+	if err != nil || missing != nil {
+		t.Errorf("expected no rollout and no error, got %v, %v", missing, err)
+	}
+}
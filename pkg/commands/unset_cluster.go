@@ -17,8 +17,12 @@ limitations under the License.
 package commands
 
 import (
+	"context"
+	"fmt"
+	"io"
 	"strings"
 
+	"k8s.io/kops/cmd/kops/util"
 	api "k8s.io/kops/pkg/apis/kops"
 	"k8s.io/kops/util/pkg/reflectutils"
 )
@@ -34,3 +38,36 @@ func UnsetClusterFields(fields []string, cluster *api.Cluster) error {
 	}
 	return nil
 }
+
+// UnsetClusterOptions holds the options for `kops unset cluster`
+type UnsetClusterOptions struct {
+	Fields      []string
+	ClusterName string
+}
+
+// RunUnsetCluster unsets the specified fields in the cluster and writes it back to the state store, after performing validation
+func RunUnsetCluster(ctx context.Context, f *util.Factory, out io.Writer, options *UnsetClusterOptions) error {
+	clientset, err := f.Clientset()
+	if err != nil {
+		return err
+	}
+
+	cluster, err := clientset.GetCluster(ctx, options.ClusterName)
+	if err != nil {
+		return err
+	}
+	if cluster == nil {
+		return fmt.Errorf("cluster %q not found", options.ClusterName)
+	}
+
+	instanceGroups, err := ReadAllInstanceGroups(ctx, clientset, cluster)
+	if err != nil {
+		return err
+	}
+
+	if err := UnsetClusterFields(options.Fields, cluster); err != nil {
+		return err
+	}
+
+	return UpdateCluster(ctx, clientset, cluster, instanceGroups)
+}
@@ -17,8 +17,12 @@ limitations under the License.
 package commands
 
 import (
+	"context"
+	"fmt"
+	"io"
 	"strings"
 
+	"k8s.io/kops/cmd/kops/util"
 	api "k8s.io/kops/pkg/apis/kops"
 	"k8s.io/kops/util/pkg/reflectutils"
 )
@@ -35,3 +39,47 @@ func UnsetInstancegroupFields(fields []string, instanceGroup *api.InstanceGroup)
 
 	return nil
 }
+
+// UnsetInstanceGroupOptions holds the options for `kops unset instancegroup`
+type UnsetInstanceGroupOptions struct {
+	Fields            []string
+	ClusterName       string
+	InstanceGroupName string
+}
+
+// RunUnsetInstancegroup unsets the specified fields in the instance group and writes it back to the state store, after performing validation
+func RunUnsetInstancegroup(ctx context.Context, f *util.Factory, out io.Writer, options *UnsetInstanceGroupOptions) error {
+	clientset, err := f.Clientset()
+	if err != nil {
+		return err
+	}
+
+	cluster, err := clientset.GetCluster(ctx, options.ClusterName)
+	if err != nil {
+		return err
+	}
+	if cluster == nil {
+		return fmt.Errorf("cluster %q not found", options.ClusterName)
+	}
+
+	instanceGroups, err := ReadAllInstanceGroups(ctx, clientset, cluster)
+	if err != nil {
+		return err
+	}
+
+	var instanceGroupToUpdate *api.InstanceGroup
+	for _, instanceGroup := range instanceGroups {
+		if instanceGroup.GetName() == options.InstanceGroupName {
+			instanceGroupToUpdate = instanceGroup
+		}
+	}
+	if instanceGroupToUpdate == nil {
+		return fmt.Errorf("InstanceGroup %q not found", options.InstanceGroupName)
+	}
+
+	if err := UnsetInstancegroupFields(options.Fields, instanceGroupToUpdate); err != nil {
+		return err
+	}
+
+	return UpdateInstanceGroup(ctx, clientset, cluster, instanceGroups, instanceGroupToUpdate)
+}
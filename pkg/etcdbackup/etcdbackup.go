@@ -0,0 +1,306 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package etcdbackup reads the backups and writes the commands that etcd-manager keeps in its backup store,
+// so that backups can be listed and restored without running etcd-manager-ctl.
+package etcdbackup
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/util/pkg/vfs"
+)
+
+const (
+	// MetaFilename is the name of the file describing a backup, in the directory of the backup
+	MetaFilename = "_etcd_backup.meta"
+	// CommandFilename is the name of the file holding a command, in the directory of the command
+	CommandFilename = "_command.json"
+
+	// controlDir is the directory of the backup store where etcd-manager reads its commands
+	controlDir = "control"
+)
+
+// ClusterSpec is the shape of the etcd cluster that a backup is restored onto
+type ClusterSpec struct {
+	MemberCount int32  `json:"memberCount,omitempty"`
+	EtcdVersion string `json:"etcdVersion,omitempty"`
+}
+
+// BackupInfo is the content of the meta file of a backup
+type BackupInfo struct {
+	EtcdVersion string       `json:"etcdVersion,omitempty"`
+	Timestamp   Int64        `json:"timestamp,omitempty"`
+	ClusterSpec *ClusterSpec `json:"clusterSpec,omitempty"`
+}
+
+// Backup is a backup in the backup store
+type Backup struct {
+	// Name is the name of the directory of the backup, which is what etcd-manager restores by
+	Name string
+	BackupInfo
+}
+
+// Time returns the time the backup was taken
+func (b *Backup) Time() time.Time {
+	return time.Unix(0, int64(b.Timestamp)).UTC()
+}
+
+// RestoreBackupCommand asks etcd-manager to restore a backup onto a new etcd cluster
+type RestoreBackupCommand struct {
+	ClusterSpec *ClusterSpec `json:"clusterSpec,omitempty"`
+	Backup      string       `json:"backup,omitempty"`
+}
+
+// Command is a command for etcd-manager. Commands of other types are read but not interpreted.
+type Command struct {
+	// Name is the name of the directory of the command; it is not part of the command file
+	Name string `json:"-"`
+
+	Timestamp     Int64                 `json:"timestamp,omitempty"`
+	RestoreBackup *RestoreBackupCommand `json:"restoreBackup,omitempty"`
+}
+
+// Int64 is an int64 in the protobuf JSON encoding used by etcd-manager, which writes it as a string
+type Int64 int64
+
+// MarshalJSON implements json.Marshaler
+func (i Int64) MarshalJSON() ([]byte, error) {
+	return json.Marshal(strconv.FormatInt(int64(i), 10))
+}
+
+// UnmarshalJSON implements json.Unmarshaler, accepting both strings and numbers
+func (i *Int64) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), "\"")
+	if s == "" || s == "null" {
+		*i = 0
+		return nil
+	}
+	v, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid int64 %s: %v", data, err)
+	}
+	*i = Int64(v)
+	return nil
+}
+
+// BackupStorePath returns the backup store of an etcd cluster, applying the same default as the etcd-manager model
+func BackupStorePath(configBase vfs.Path, etcdCluster *kops.EtcdClusterSpec) (vfs.Path, error) {
+	if etcdCluster.Backups != nil && etcdCluster.Backups.BackupStore != "" {
+		p, err := vfs.Context.BuildVfsPath(etcdCluster.Backups.BackupStore)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing backupStore of etcd cluster %q: %v", etcdCluster.Name, err)
+		}
+		return p, nil
+	}
+	return configBase.Join("backups", "etcd", etcdCluster.Name), nil
+}
+
+// Store reads and writes the backup store of an etcd cluster
+type Store struct {
+	base vfs.Path
+}
+
+// NewStore returns a Store for the backup store at base
+func NewStore(base vfs.Path) *Store {
+	return &Store{base: base}
+}
+
+// Path returns the location of the backup store
+func (s *Store) Path() vfs.Path {
+	return s.base
+}
+
+// ListBackups returns the backups in the store, oldest first
+func (s *Store) ListBackups() ([]*Backup, error) {
+	files, err := s.base.ReadTree()
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error listing backups in %s: %v", s.base, err)
+	}
+
+	var backups []*Backup
+	for _, f := range files {
+		if f.Base() != MetaFilename {
+			continue
+		}
+		name, ok := parentDirName(s.base, f)
+		if !ok {
+			continue
+		}
+		backup, err := s.LoadBackup(name)
+		if err != nil {
+			return nil, err
+		}
+		if backup != nil {
+			backups = append(backups, backup)
+		}
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].Name < backups[j].Name
+	})
+	return backups, nil
+}
+
+// LoadBackup returns the backup with the given name, or nil if there is no such backup
+func (s *Store) LoadBackup(name string) (*Backup, error) {
+	p := s.base.Join(name, MetaFilename)
+	data, err := p.ReadFile()
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error reading %s: %v", p, err)
+	}
+
+	backup := &Backup{Name: name}
+	if err := json.Unmarshal(data, &backup.BackupInfo); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %v", p, err)
+	}
+	return backup, nil
+}
+
+// DeleteBackup removes the files of the backup with the given name
+func (s *Store) DeleteBackup(name string) error {
+	// Remove the meta file first, so that a partially deleted backup is no longer listed
+	metaPath := s.base.Join(name, MetaFilename)
+	if err := metaPath.Remove(); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("error deleting %s: %v", metaPath, err)
+	}
+
+	p := s.base.Join(name)
+	files, err := p.ReadTree()
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("error listing files of backup %s: %v", p, err)
+	}
+	for _, f := range files {
+		if err := f.Remove(); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("error deleting %s: %v", f, err)
+		}
+	}
+	return nil
+}
+
+// ListCommands returns the commands that etcd-manager has not yet processed, oldest first
+func (s *Store) ListCommands() ([]*Command, error) {
+	controlPath := s.base.Join(controlDir)
+	files, err := controlPath.ReadTree()
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error listing commands in %s: %v", controlPath, err)
+	}
+
+	var commands []*Command
+	for _, f := range files {
+		if f.Base() != CommandFilename {
+			continue
+		}
+		name, ok := parentDirName(controlPath, f)
+		if !ok {
+			continue
+		}
+		command, err := s.loadCommand(name)
+		if err != nil {
+			return nil, err
+		}
+		if command != nil {
+			commands = append(commands, command)
+		}
+	}
+	sort.Slice(commands, func(i, j int) bool {
+		return commands[i].Name < commands[j].Name
+	})
+	return commands, nil
+}
+
+// IsPending returns whether the command is still waiting to be processed by etcd-manager,
+// which removes a command once it has completed.
+func (s *Store) IsPending(command *Command) (bool, error) {
+	loaded, err := s.loadCommand(command.Name)
+	if err != nil {
+		return false, err
+	}
+	return loaded != nil, nil
+}
+
+// AddRestoreCommand asks etcd-manager to restore the backup onto a new cluster with the given spec
+func (s *Store) AddRestoreCommand(backup *Backup, spec *ClusterSpec, now time.Time) (*Command, error) {
+	command := &Command{
+		Name:      now.UTC().Format(time.RFC3339Nano),
+		Timestamp: Int64(now.UnixNano()),
+		RestoreBackup: &RestoreBackupCommand{
+			ClusterSpec: spec,
+			Backup:      backup.Name,
+		},
+	}
+
+	data, err := json.Marshal(command)
+	if err != nil {
+		return nil, fmt.Errorf("error serializing command: %v", err)
+	}
+
+	p := s.base.Join(controlDir, command.Name, CommandFilename)
+	if err := p.CreateFile(bytes.NewReader(data), nil); err != nil {
+		return nil, fmt.Errorf("error writing command %s: %v", p, err)
+	}
+	return command, nil
+}
+
+func (s *Store) loadCommand(name string) (*Command, error) {
+	p := s.base.Join(controlDir, name, CommandFilename)
+	data, err := p.ReadFile()
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error reading %s: %v", p, err)
+	}
+
+	command := &Command{}
+	if err := json.Unmarshal(data, command); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %v", p, err)
+	}
+	command.Name = name
+	return command, nil
+}
+
+// parentDirName returns the name of the directory containing f, if that directory is directly in base
+func parentDirName(base vfs.Path, f vfs.Path) (string, bool) {
+	rel := strings.TrimPrefix(f.Path(), strings.TrimSuffix(base.Path(), "/")+"/")
+	if rel == f.Path() {
+		return "", false
+	}
+	tokens := strings.Split(rel, "/")
+	if len(tokens) != 2 {
+		return "", false
+	}
+	return tokens[0], true
+}
@@ -0,0 +1,167 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcdbackup
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/util/pkg/vfs"
+)
+
+func writeFile(t *testing.T, p vfs.Path, data string) {
+	if err := p.WriteFile(bytes.NewReader([]byte(data)), nil); err != nil {
+		t.Fatalf("error writing %s: %v", p, err)
+	}
+}
+
+func TestListBackups(t *testing.T) {
+	base := vfs.NewMemFSPath(vfs.NewMemFSContext(), "backups/etcd/main")
+	store := NewStore(base)
+
+	backups, err := store.ListBackups()
+	if err != nil {
+		t.Fatalf("error listing empty store: %v", err)
+	}
+	if len(backups) != 0 {
+		t.Fatalf("expected no backups, got %d", len(backups))
+	}
+
+	// Written as etcd-manager writes them: int64 values are strings
+	writeFile(t, base.Join("2022-06-02T10:00:00Z-000002", MetaFilename), `{"etcdVersion":"3.5.4","timestamp":"1654164000000000000","clusterSpec":{"memberCount":3,"etcdVersion":"3.5.4"}}`)
+	writeFile(t, base.Join("2022-06-02T10:00:00Z-000002", "etcd.backup.gz"), "data")
+	writeFile(t, base.Join("2022-06-01T10:00:00Z-000001", MetaFilename), `{"etcdVersion":"3.5.3","timestamp":1654077600000000000}`)
+	writeFile(t, base.Join("control", "etcd-cluster-spec"), `{"memberCount":3,"etcdVersion":"3.5.4"}`)
+
+	backups, err = store.ListBackups()
+	if err != nil {
+		t.Fatalf("error listing backups: %v", err)
+	}
+	if len(backups) != 2 {
+		t.Fatalf("expected 2 backups, got %d", len(backups))
+	}
+	if backups[0].Name != "2022-06-01T10:00:00Z-000001" || backups[1].Name != "2022-06-02T10:00:00Z-000002" {
+		t.Errorf("unexpected backups %q, %q", backups[0].Name, backups[1].Name)
+	}
+	if expected := time.Date(2022, 6, 1, 10, 0, 0, 0, time.UTC); !backups[0].Time().Equal(expected) {
+		t.Errorf("expected time %v, got %v", expected, backups[0].Time())
+	}
+	if backups[1].ClusterSpec == nil || backups[1].ClusterSpec.MemberCount != 3 {
+		t.Errorf("unexpected cluster spec %+v", backups[1].ClusterSpec)
+	}
+
+	if err := store.DeleteBackup("2022-06-01T10:00:00Z-000001"); err != nil {
+		t.Fatalf("error deleting backup: %v", err)
+	}
+	backups, err = store.ListBackups()
+	if err != nil {
+		t.Fatalf("error listing backups: %v", err)
+	}
+	if len(backups) != 1 || backups[0].Name != "2022-06-02T10:00:00Z-000002" {
+		t.Fatalf("unexpected backups after delete: %v", backups)
+	}
+
+	missing, err := store.LoadBackup("2022-06-03T10:00:00Z-000003")
+	if err != nil {
+		t.Fatalf("error loading missing backup: %v", err)
+	}
+	if missing != nil {
+		t.Errorf("expected missing backup to be nil, got %+v", missing)
+	}
+}
+
+func TestAddRestoreCommand(t *testing.T) {
+	base := vfs.NewMemFSPath(vfs.NewMemFSContext(), "backups/etcd/events")
+	store := NewStore(base)
+
+	backup := &Backup{Name: "2022-06-01T10:00:00Z-000001"}
+	now := time.Date(2022, 6, 3, 8, 30, 0, 0, time.UTC)
+	command, err := store.AddRestoreCommand(backup, &ClusterSpec{MemberCount: 3, EtcdVersion: "3.5.4"}, now)
+	if err != nil {
+		t.Fatalf("error adding command: %v", err)
+	}
+
+	data, err := base.Join("control", command.Name, CommandFilename).ReadFile()
+	if err != nil {
+		t.Fatalf("error reading command: %v", err)
+	}
+	var actual map[string]interface{}
+	if err := json.Unmarshal(data, &actual); err != nil {
+		t.Fatalf("error parsing command: %v", err)
+	}
+	if actual["timestamp"] != "1654245000000000000" {
+		t.Errorf("unexpected timestamp %v", actual["timestamp"])
+	}
+	restore, _ := actual["restoreBackup"].(map[string]interface{})
+	if restore == nil || restore["backup"] != backup.Name {
+		t.Errorf("unexpected restoreBackup %v", actual["restoreBackup"])
+	}
+
+	commands, err := store.ListCommands()
+	if err != nil {
+		t.Fatalf("error listing commands: %v", err)
+	}
+	if len(commands) != 1 || commands[0].Name != command.Name || commands[0].RestoreBackup == nil {
+		t.Fatalf("unexpected commands %+v", commands)
+	}
+
+	pending, err := store.IsPending(command)
+	if err != nil {
+		t.Fatalf("error checking command: %v", err)
+	}
+	if !pending {
+		t.Errorf("expected command to be pending")
+	}
+
+	// etcd-manager removes the command once the restore has completed
+	if err := base.Join("control", command.Name, CommandFilename).Remove(); err != nil {
+		t.Fatalf("error removing command: %v", err)
+	}
+	pending, err = store.IsPending(command)
+	if err != nil {
+		t.Fatalf("error checking command: %v", err)
+	}
+	if pending {
+		t.Errorf("expected command to have completed")
+	}
+}
+
+func TestBackupStorePath(t *testing.T) {
+	configBase := vfs.NewMemFSPath(vfs.NewMemFSContext(), "state/cluster.example.com")
+
+	p, err := BackupStorePath(configBase, &kops.EtcdClusterSpec{Name: "main"})
+	if err != nil {
+		t.Fatalf("error building default path: %v", err)
+	}
+	if p.Path() != "memfs://state/cluster.example.com/backups/etcd/main" {
+		t.Errorf("unexpected default path %q", p.Path())
+	}
+
+	p, err = BackupStorePath(configBase, &kops.EtcdClusterSpec{
+		Name:    "events",
+		Backups: &kops.EtcdBackupSpec{BackupStore: "file:///var/backups/events"},
+	})
+	if err != nil {
+		t.Fatalf("error building configured path: %v", err)
+	}
+	if p.Path() != "/var/backups/events" {
+		t.Errorf("unexpected configured path %q", p.Path())
+	}
+}
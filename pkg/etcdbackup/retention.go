@@ -0,0 +1,162 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcdbackup
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/kops/pkg/apis/kops"
+)
+
+const (
+	// DefaultHourlyBackups is the number of hours for which etcd-manager keeps one backup per hour by default
+	DefaultHourlyBackups = 7 * 24
+	// DefaultDailyBackups is the number of days for which etcd-manager keeps one backup per day by default
+	DefaultDailyBackups = 365
+
+	// EnvHourlyBackupsRetention is the etcd-manager environment variable for the retention of hourly backups
+	EnvHourlyBackupsRetention = "ETCD_MANAGER_HOURLY_BACKUPS_RETENTION"
+	// EnvDailyBackupsRetention is the etcd-manager environment variable for the retention of daily backups
+	EnvDailyBackupsRetention = "ETCD_MANAGER_DAILY_BACKUPS_RETENTION"
+)
+
+// RetentionPolicy is how long etcd backups are kept.
+// All backups taken within the last hour are kept, then one backup per hour up to Hourly,
+// then one backup per day up to Daily; older backups are removed.
+type RetentionPolicy struct {
+	// Hourly is the age up to which one backup per hour is kept
+	Hourly time.Duration
+	// Daily is the age up to which one backup per day is kept
+	Daily time.Duration
+}
+
+// RetentionPolicyFor returns the retention policy of the backup spec, applying the etcd-manager defaults
+func RetentionPolicyFor(spec *kops.EtcdBackupSpec) RetentionPolicy {
+	hourly := int32(DefaultHourlyBackups)
+	daily := int32(DefaultDailyBackups)
+	var maxAge time.Duration
+
+	if spec != nil && spec.Retention != nil {
+		if spec.Retention.HourlyBackups != nil {
+			hourly = *spec.Retention.HourlyBackups
+		}
+		if spec.Retention.DailyBackups != nil {
+			daily = *spec.Retention.DailyBackups
+		}
+		if spec.Retention.MaxAge != nil {
+			maxAge = spec.Retention.MaxAge.Duration
+		}
+	}
+
+	policy := RetentionPolicy{
+		Hourly: time.Duration(hourly) * time.Hour,
+		Daily:  time.Duration(daily) * 24 * time.Hour,
+	}
+	if maxAge > 0 {
+		if policy.Hourly > maxAge {
+			policy.Hourly = maxAge
+		}
+		if policy.Daily > maxAge {
+			policy.Daily = maxAge
+		}
+	}
+	return policy
+}
+
+// Env returns the environment variables that configure etcd-manager to apply the policy
+func (p RetentionPolicy) Env() []corev1.EnvVar {
+	return []corev1.EnvVar{
+		{Name: EnvHourlyBackupsRetention, Value: formatRetention(p.Hourly)},
+		{Name: EnvDailyBackupsRetention, Value: formatRetention(p.Daily)},
+	}
+}
+
+// formatRetention formats a duration as etcd-manager expects, in days where possible and otherwise in whole hours
+func formatRetention(d time.Duration) string {
+	day := 24 * time.Hour
+	if d%day == 0 {
+		return fmt.Sprintf("%dd", d/day)
+	}
+	hours := (d + time.Hour - 1) / time.Hour
+	return fmt.Sprintf("%dh", hours)
+}
+
+// Expired returns the backups that the policy removes, oldest first.
+// The most recent backup is always kept, as are backups for which the time they were taken is not known.
+func (p RetentionPolicy) Expired(backups []*Backup, now time.Time) []*Backup {
+	type dated struct {
+		backup *Backup
+		taken  time.Time
+	}
+	var candidates []dated
+	for _, backup := range backups {
+		taken, ok := backup.TakenAt()
+		if !ok {
+			continue
+		}
+		candidates = append(candidates, dated{backup: backup, taken: taken})
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].taken.Before(candidates[j].taken)
+	})
+
+	// The first backup taken in each hour or day is the one that is kept, so that the choice does not change as backups are added
+	kept := make(map[string]bool)
+	var expired []*Backup
+	for _, c := range candidates[:len(candidates)-1] {
+		age := now.Sub(c.taken)
+		var bucket string
+		switch {
+		case age <= time.Hour:
+			continue
+		case age <= p.Hourly:
+			bucket = "hour/" + c.taken.UTC().Format("2006-01-02T15")
+		case age <= p.Daily:
+			bucket = "day/" + c.taken.UTC().Format("2006-01-02")
+		}
+		if bucket != "" && !kept[bucket] {
+			kept[bucket] = true
+			continue
+		}
+		expired = append(expired, c.backup)
+	}
+	return expired
+}
+
+// TakenAt returns the time the backup was taken, from its meta file or else from its name
+func (b *Backup) TakenAt() (time.Time, bool) {
+	if b.Timestamp != 0 {
+		return b.Time(), true
+	}
+	// etcd-manager names backups by the time they were taken, followed by a sequence number
+	name := b.Name
+	if i := strings.LastIndex(name, "-"); i > strings.Index(name, "T") {
+		name = name[:i]
+	}
+	taken, err := time.Parse(time.RFC3339, name)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return taken, true
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcdbackup
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/upup/pkg/fi"
+)
+
+func TestRetentionPolicyFor(t *testing.T) {
+	grid := []struct {
+		Description string
+		Input       *kops.EtcdBackupSpec
+		Expected    RetentionPolicy
+		ExpectedEnv []string
+	}{
+		{
+			Description: "defaults",
+			Input:       &kops.EtcdBackupSpec{},
+			Expected:    RetentionPolicy{Hourly: 7 * 24 * time.Hour, Daily: 365 * 24 * time.Hour},
+			ExpectedEnv: []string{"7d", "365d"},
+		},
+		{
+			Description: "counts",
+			Input: &kops.EtcdBackupSpec{
+				Retention: &kops.EtcdBackupRetentionSpec{HourlyBackups: fi.Int32(36), DailyBackups: fi.Int32(30)},
+			},
+			Expected:    RetentionPolicy{Hourly: 36 * time.Hour, Daily: 30 * 24 * time.Hour},
+			ExpectedEnv: []string{"36h", "30d"},
+		},
+		{
+			Description: "max age caps both",
+			Input: &kops.EtcdBackupSpec{
+				Retention: &kops.EtcdBackupRetentionSpec{MaxAge: &metav1.Duration{Duration: 90 * time.Minute}},
+			},
+			Expected:    RetentionPolicy{Hourly: 90 * time.Minute, Daily: 90 * time.Minute},
+			ExpectedEnv: []string{"2h", "2h"},
+		},
+	}
+
+	for _, g := range grid {
+		t.Run(g.Description, func(t *testing.T) {
+			actual := RetentionPolicyFor(g.Input)
+			if actual != g.Expected {
+				t.Errorf("expected %+v, got %+v", g.Expected, actual)
+			}
+			expectedEnv := []corev1.EnvVar{
+				{Name: EnvHourlyBackupsRetention, Value: g.ExpectedEnv[0]},
+				{Name: EnvDailyBackupsRetention, Value: g.ExpectedEnv[1]},
+			}
+			if env := actual.Env(); !reflect.DeepEqual(env, expectedEnv) {
+				t.Errorf("expected env %v, got %v", expectedEnv, env)
+			}
+		})
+	}
+}
+
+func TestExpired(t *testing.T) {
+	now := time.Date(2022, 6, 10, 12, 0, 0, 0, time.UTC)
+	policy := RetentionPolicy{Hourly: 3 * time.Hour, Daily: 3 * 24 * time.Hour}
+
+	var backups []*Backup
+	add := func(name string, age time.Duration) {
+		backups = append(backups, &Backup{
+			Name:       name,
+			BackupInfo: BackupInfo{Timestamp: Int64(now.Add(-age).UnixNano())},
+		})
+	}
+	add("recent-1", 15*time.Minute)
+	add("recent-2", 45*time.Minute)
+	add("hour-2a", 2*time.Hour+20*time.Minute)
+	add("hour-2b", 2*time.Hour+5*time.Minute)
+	add("day-1a", 26*time.Hour)
+	add("day-1b", 25*time.Hour)
+	add("day-2", 50*time.Hour)
+	add("old", 5*24*time.Hour)
+	// The time of this backup is taken from its name
+	backups = append(backups, &Backup{Name: now.Add(-10*24*time.Hour).Format(time.RFC3339) + "-000001"})
+	// A backup with no known time is kept
+	backups = append(backups, &Backup{Name: "unknown"})
+
+	var names []string
+	for _, backup := range policy.Expired(backups, now) {
+		names = append(names, backup.Name)
+	}
+	expected := []string{"2022-05-31T12:00:00Z-000001", "old", "day-1b", "hour-2b"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected %v to expire, got %v", expected, names)
+	}
+
+	// The most recent backup is kept, however old it is
+	expired := policy.Expired([]*Backup{{Name: "2022-01-01T00:00:00Z-000001"}}, now)
+	if len(expired) != 0 {
+		t.Errorf("expected only backup to be kept, got %v", expired)
+	}
+}
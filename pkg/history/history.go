@@ -0,0 +1,89 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package history defines the revisions of a cluster's configuration that are recorded in the state store.
+package history
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Revision is a snapshot of the configuration of a cluster and its instance groups,
+// recorded each time the configuration is written.
+type Revision struct {
+	// Number identifies the revision; revisions are numbered consecutively from 1.
+	Number int `json:"number"`
+	// Timestamp is the time the revision was recorded.
+	Timestamp time.Time `json:"timestamp"`
+	// User is the operating system user that made the change.
+	User string `json:"user,omitempty"`
+	// KopsVersion is the version of kOps that made the change.
+	KopsVersion string `json:"kopsVersion,omitempty"`
+	// Change describes the write that created the revision.
+	Change string `json:"change,omitempty"`
+
+	// Cluster is the cluster configuration, as stored in the state store.
+	Cluster string `json:"cluster"`
+	// InstanceGroups are the instance group configurations, as stored in the state store, by name.
+	InstanceGroups map[string]string `json:"instanceGroups,omitempty"`
+}
+
+// Config returns the configuration of the revision as a multi-document YAML file,
+// with the cluster followed by the instance groups in name order.
+func (r *Revision) Config() string {
+	documents := []string{strings.TrimSpace(r.Cluster)}
+
+	var names []string
+	for name := range r.InstanceGroups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		documents = append(documents, strings.TrimSpace(r.InstanceGroups[name]))
+	}
+
+	return strings.Join(documents, "\n---\n") + "\n"
+}
+
+// SameConfig returns true if the two revisions record the same configuration.
+func (r *Revision) SameConfig(other *Revision) bool {
+	if r.Cluster != other.Cluster || len(r.InstanceGroups) != len(other.InstanceGroups) {
+		return false
+	}
+	for name, config := range r.InstanceGroups {
+		if otherConfig, found := other.InstanceGroups[name]; !found || otherConfig != config {
+			return false
+		}
+	}
+	return true
+}
+
+type withoutRecordingKey struct{}
+
+// WithoutRecording returns a context in which writes to the configuration do not record revisions,
+// so that a change made of several writes can be recorded as a single revision.
+func WithoutRecording(ctx context.Context) context.Context {
+	return context.WithValue(ctx, withoutRecordingKey{}, true)
+}
+
+// RecordingDisabled returns true if writes made with the context should not record revisions.
+func RecordingDisabled(ctx context.Context) bool {
+	disabled, _ := ctx.Value(withoutRecordingKey{}).(bool)
+	return disabled
+}
@@ -0,0 +1,154 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package ignition holds the subset of the Ignition v3 config specification used by kOps,
+// see https://coreos.github.io/ignition/configuration-v3_3/
+package ignition
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"os"
+)
+
+// Version is the version of the Ignition config specification that is generated
+const Version = "3.3.0"
+
+// Config is an Ignition config
+type Config struct {
+	Ignition Ignition `json:"ignition"`
+	Passwd   Passwd   `json:"passwd,omitempty"`
+	Storage  Storage  `json:"storage,omitempty"`
+	Systemd  Systemd  `json:"systemd,omitempty"`
+}
+
+// Ignition holds the metadata of the config
+type Ignition struct {
+	Version string `json:"version"`
+}
+
+// Passwd holds the users and groups to create
+type Passwd struct {
+	Users  []PasswdUser  `json:"users,omitempty"`
+	Groups []PasswdGroup `json:"groups,omitempty"`
+}
+
+// PasswdUser is a user account
+type PasswdUser struct {
+	Name    string  `json:"name"`
+	UID     *int    `json:"uid,omitempty"`
+	HomeDir *string `json:"homeDir,omitempty"`
+	Shell   *string `json:"shell,omitempty"`
+}
+
+// PasswdGroup is a group
+type PasswdGroup struct {
+	Name   string `json:"name"`
+	Gid    *int   `json:"gid,omitempty"`
+	System *bool  `json:"system,omitempty"`
+}
+
+// Storage holds the filesystem nodes to create
+type Storage struct {
+	Directories []Directory `json:"directories,omitempty"`
+	Files       []File      `json:"files,omitempty"`
+	Links       []Link      `json:"links,omitempty"`
+}
+
+// Node holds the fields common to files, directories and links
+type Node struct {
+	Path      string     `json:"path"`
+	Overwrite *bool      `json:"overwrite,omitempty"`
+	User      *NodeUser  `json:"user,omitempty"`
+	Group     *NodeGroup `json:"group,omitempty"`
+}
+
+// NodeUser is the owner of a node
+type NodeUser struct {
+	Name string `json:"name,omitempty"`
+}
+
+// NodeGroup is the group of a node
+type NodeGroup struct {
+	Name string `json:"name,omitempty"`
+}
+
+// Directory is a directory to create
+type Directory struct {
+	Node
+	Mode *int `json:"mode,omitempty"`
+}
+
+// File is a regular file to write
+type File struct {
+	Node
+	Contents Resource `json:"contents"`
+	Mode     *int     `json:"mode,omitempty"`
+}
+
+// Resource is the source of the contents of a file
+type Resource struct {
+	Source       *string      `json:"source,omitempty"`
+	Verification Verification `json:"verification,omitempty"`
+}
+
+// Verification holds the expected hash of a resource, as <algorithm>-<hex>
+type Verification struct {
+	Hash *string `json:"hash,omitempty"`
+}
+
+// Link is a symbolic link to create
+type Link struct {
+	Node
+	Target string `json:"target"`
+}
+
+// Systemd holds the systemd units to write
+type Systemd struct {
+	Units []Unit `json:"units,omitempty"`
+}
+
+// Unit is a systemd unit
+type Unit struct {
+	Name     string  `json:"name"`
+	Enabled  *bool   `json:"enabled,omitempty"`
+	Contents *string `json:"contents,omitempty"`
+}
+
+// NewConfig returns an empty config
+func NewConfig() *Config {
+	return &Config{
+		Ignition: Ignition{
+			Version: Version,
+		},
+	}
+}
+
+// DataURL returns a data URL holding the given contents, for use as a Resource source
+func DataURL(data []byte) string {
+	return "data:;base64," + base64.StdEncoding.EncodeToString(data)
+}
+
+// Mode converts a file mode to the integer Ignition expects
+func Mode(mode os.FileMode) *int {
+	m := int(mode.Perm())
+	return &m
+}
+
+// Marshal serializes the config to JSON
+func (c *Config) Marshal() ([]byte, error) {
+	return json.Marshal(c)
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package instancegroups
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"k8s.io/klog/v2"
+	api "k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/cloudinstances"
+)
+
+// CanaryFailedError is returned when the canary instances of an instance group fail validation.
+// It stops the rolling update of the whole cluster.
+type CanaryFailedError struct {
+	InstanceGroup string
+	Err           error
+}
+
+func (e *CanaryFailedError) Error() string {
+	return fmt.Sprintf("canary of instance group %q failed: %v", e.InstanceGroup, e.Err)
+}
+
+func (e *CanaryFailedError) Unwrap() error {
+	return e.Err
+}
+
+// updateCanaries replaces the canary instances one at a time and then requires the cluster to keep validating for the soak duration.
+// Unlike the rest of the rolling update, a canary that does not validate always fails, regardless of FailOnValidate.
+func (c *RollingUpdateCluster) updateCanaries(group *cloudinstances.CloudInstanceGroup, canaries []*cloudinstances.CloudInstance, canary *api.RollingUpdateCanary, sleepAfterTerminate time.Duration) error {
+	noun := "instances"
+	if len(canaries) == 1 {
+		noun = "instance"
+	}
+	klog.Infof("Replacing %d canary %s in instance group %q.", len(canaries), noun, group.InstanceGroup.Name)
+
+	for _, u := range canaries {
+		if err := c.drainTerminateAndWait(u, sleepAfterTerminate); err != nil {
+			return &CanaryFailedError{InstanceGroup: group.InstanceGroup.Name, Err: err}
+		}
+	}
+
+	if c.CloudOnly {
+		klog.Warningf("Not validating canary as cloudonly flag is set.")
+		return nil
+	}
+
+	klog.Info("Validating the cluster after replacing canary instances.")
+	if err := c.validateClusterWithTimeout(c.ValidateCount, group); err != nil {
+		return &CanaryFailedError{InstanceGroup: group.InstanceGroup.Name, Err: err}
+	}
+
+	if err := c.soakCanaries(group, canary.SoakDuration.Duration, *canary.StrictValidation); err != nil {
+		return &CanaryFailedError{InstanceGroup: group.InstanceGroup.Name, Err: err}
+	}
+
+	klog.Infof("Canary of instance group %q passed; updating the remaining instances.", group.InstanceGroup.Name)
+	return nil
+}
+
+// soakCanaries validates the cluster repeatedly for the soak duration, failing on the first validation failure.
+// Unless strict is set, only failures relevant to the instance group are considered.
+func (c *RollingUpdateCluster) soakCanaries(group *cloudinstances.CloudInstanceGroup, soakDuration time.Duration, strict bool) error {
+	klog.Infof("Soaking canary instances of instance group %q for %s.", group.InstanceGroup.Name, soakDuration)
+	deadline := time.Now().Add(soakDuration)
+
+	for {
+		result, err := c.ClusterValidator.Validate()
+		if err != nil {
+			c.recordValidationFailure(err.Error())
+			return fmt.Errorf("cluster did not validate during canary soak: %v", err)
+		}
+
+		if len(result.Failures) > 0 && (strict || hasFailureRelevantToGroup(result.Failures, group)) {
+			messages := []string{}
+			for _, failure := range result.Failures {
+				messages = append(messages, failure.Message)
+			}
+			c.recordValidationFailure(strings.Join(messages, ", "))
+			return fmt.Errorf("cluster did not pass validation during canary soak: %s", strings.Join(messages, ", "))
+		}
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return nil
+		}
+		if remaining > c.ValidateTickDuration {
+			remaining = c.ValidateTickDuration
+		}
+		time.Sleep(remaining)
+	}
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package instancegroups
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"github.com/stretchr/testify/assert"
+	v1meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	kopsapi "k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/cloudinstances"
+	"k8s.io/kops/pkg/validation"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup/awsup"
+)
+
+func setCanary(c *RollingUpdateCluster, instances intstr.IntOrString, strict bool) {
+	c.Cluster.Spec.RollingUpdate = &kopsapi.RollingUpdate{
+		Canary: &kopsapi.RollingUpdateCanary{
+			Instances:        &instances,
+			SoakDuration:     &v1meta.Duration{Duration: 10 * time.Millisecond},
+			StrictValidation: fi.Bool(strict),
+		},
+	}
+}
+
+// shrunkGroupClusterValidator fails validation once the named group has fewer instances than expected
+type shrunkGroupClusterValidator struct {
+	Cloud    awsup.AWSCloud
+	Group    string
+	Expected int
+}
+
+func (v *shrunkGroupClusterValidator) Validate() (*validation.ValidationCluster, error) {
+	asgGroups, _ := v.Cloud.Autoscaling().DescribeAutoScalingGroups(&autoscaling.DescribeAutoScalingGroupsInput{
+		AutoScalingGroupNames: []*string{aws.String(v.Group)},
+	})
+	for _, group := range asgGroups.AutoScalingGroups {
+		if len(group.Instances) < v.Expected {
+			return &validation.ValidationCluster{
+				Failures: []*validation.ValidationError{
+					{
+						Kind:    "testing",
+						Name:    "canary",
+						Message: "canary not ready",
+					},
+				},
+			}, nil
+		}
+	}
+	return &validation.ValidationCluster{}, nil
+}
+
+func TestRollingUpdateCanaryPasses(t *testing.T) {
+	c, cloud := getTestSetup()
+	setCanary(c, intstr.FromInt(1), false)
+
+	groups := getGroupsAllNeedUpdate(c.K8sClient, cloud)
+	err := c.RollingUpdate(groups, &kopsapi.InstanceGroupList{})
+	assert.NoError(t, err, "rolling update")
+
+	assertGroupInstanceCount(t, cloud, "node-1", 0)
+	assertGroupInstanceCount(t, cloud, "node-2", 0)
+	assertGroupInstanceCount(t, cloud, "master-1", 0)
+}
+
+func TestRollingUpdateCanaryFailureStopsClusterUpdate(t *testing.T) {
+	c, cloud := getTestSetup()
+	setCanary(c, intstr.FromInt(1), false)
+	c.FailOnValidate = false
+	c.ValidationTimeout = 10 * time.Millisecond
+	c.ClusterValidator = &shrunkGroupClusterValidator{Cloud: cloud, Group: "node-1", Expected: 3}
+
+	groups := make(map[string]*cloudinstances.CloudInstanceGroup)
+	makeGroup(groups, c.K8sClient, cloud, "node-1", kopsapi.InstanceGroupRoleNode, 3, 3)
+	makeGroup(groups, c.K8sClient, cloud, "node-2", kopsapi.InstanceGroupRoleNode, 3, 3)
+	err := c.RollingUpdate(groups, &kopsapi.InstanceGroupList{})
+
+	var canaryErr *CanaryFailedError
+	if assert.True(t, errors.As(err, &canaryErr), "expected canary failure, got %v", err) {
+		assert.Equal(t, "node-1", canaryErr.InstanceGroup)
+	}
+	assertGroupInstanceCount(t, cloud, "node-1", 2)
+	assertGroupInstanceCount(t, cloud, "node-2", 3)
+}
+
+func TestRollingUpdateCanaryStrictValidation(t *testing.T) {
+	c, cloud := getTestSetup()
+	setCanary(c, intstr.FromString("50%"), true)
+
+	groups := make(map[string]*cloudinstances.CloudInstanceGroup)
+	makeGroup(groups, c.K8sClient, cloud, "node-1", kopsapi.InstanceGroupRoleNode, 4, 4)
+	makeGroup(groups, c.K8sClient, cloud, "node-2", kopsapi.InstanceGroupRoleNode, 3, 3)
+	c.ClusterValidator = &instanceGroupNodeSpecificErrorClusterValidator{
+		InstanceGroup: groups["node-2"].InstanceGroup,
+	}
+
+	err := c.RollingUpdate(groups, &kopsapi.InstanceGroupList{})
+	var canaryErr *CanaryFailedError
+	assert.True(t, errors.As(err, &canaryErr), "expected canary failure, got %v", err)
+
+	assertGroupInstanceCount(t, cloud, "node-1", 2)
+	assertGroupInstanceCount(t, cloud, "node-2", 3)
+}
@@ -0,0 +1,149 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package instancegroups
+
+import (
+	"time"
+
+	"k8s.io/klog/v2"
+	"k8s.io/kops/pkg/cloudinstances"
+	"k8s.io/kops/pkg/rollout"
+)
+
+// checkpoint applies the mutation to the rollout record and persists it, if we are recording the rollout.
+// Failure to persist the record is logged but does not stop the rolling update.
+func (c *RollingUpdateCluster) checkpoint(mutate func(r *rollout.Record)) {
+	if c.Rollout == nil {
+		return
+	}
+
+	c.rolloutMutex.Lock()
+	defer c.rolloutMutex.Unlock()
+
+	mutate(c.Rollout)
+	c.Rollout.UpdatedAt = time.Now()
+
+	if c.Rollouts == nil {
+		return
+	}
+	if err := c.Rollouts.Write(c.Rollout); err != nil {
+		klog.Warningf("unable to record progress of rolling update %q: %v", c.Rollout.Name, err)
+	}
+}
+
+// emit sends the event to the event sink, if one is configured.
+func (c *RollingUpdateCluster) emit(event *rollout.Event) {
+	if c.Events == nil {
+		return
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	c.Events.Emit(event)
+}
+
+// isGroupCompleted returns true if the instance group was completed by an earlier run of a resumed rollout.
+func (c *RollingUpdateCluster) isGroupCompleted(group *cloudinstances.CloudInstanceGroup) bool {
+	if c.Rollout == nil {
+		return false
+	}
+
+	c.rolloutMutex.Lock()
+	defer c.rolloutMutex.Unlock()
+
+	return c.Rollout.IsGroupCompleted(group.InstanceGroup.Name)
+}
+
+func (c *RollingUpdateCluster) recordPhase(phase rollout.Phase) {
+	c.checkpoint(func(r *rollout.Record) {
+		r.Phase = phase
+	})
+}
+
+func (c *RollingUpdateCluster) recordGroupStarted(group *cloudinstances.CloudInstanceGroup) {
+	c.emit(&rollout.Event{
+		Type:          rollout.EventInstanceGroupStarted,
+		InstanceGroup: group.InstanceGroup.Name,
+	})
+}
+
+func (c *RollingUpdateCluster) recordGroupCompleted(group *cloudinstances.CloudInstanceGroup) {
+	c.emit(&rollout.Event{
+		Type:          rollout.EventInstanceGroupCompleted,
+		InstanceGroup: group.InstanceGroup.Name,
+	})
+	c.checkpoint(func(r *rollout.Record) {
+		if !r.IsGroupCompleted(group.InstanceGroup.Name) {
+			r.CompletedGroups = append(r.CompletedGroups, group.InstanceGroup.Name)
+		}
+	})
+}
+
+func (c *RollingUpdateCluster) recordInstanceReplaced(u *cloudinstances.CloudInstance) {
+	replaced := rollout.ReplacedInstance{
+		InstanceGroup: u.CloudInstanceGroup.InstanceGroup.Name,
+		ID:            u.ID,
+		ReplacedAt:    time.Now(),
+	}
+	if u.Node != nil {
+		replaced.Node = u.Node.Name
+	}
+
+	c.emit(&rollout.Event{
+		Time:          replaced.ReplacedAt,
+		Type:          rollout.EventInstanceReplaced,
+		InstanceGroup: replaced.InstanceGroup,
+		Instance:      replaced.ID,
+		Node:          replaced.Node,
+	})
+	c.checkpoint(func(r *rollout.Record) {
+		r.ReplacedInstances = append(r.ReplacedInstances, replaced)
+	})
+}
+
+func (c *RollingUpdateCluster) recordValidationFailure(message string) {
+	c.emit(&rollout.Event{
+		Type:    rollout.EventValidationFailed,
+		Message: message,
+	})
+	c.checkpoint(func(r *rollout.Record) {
+		r.LastValidationFailure = message
+	})
+}
+
+func (c *RollingUpdateCluster) recordResult(err error) {
+	if err != nil {
+		c.emit(&rollout.Event{
+			Type:    rollout.EventFailed,
+			Message: err.Error(),
+		})
+	} else {
+		c.emit(&rollout.Event{
+			Type: rollout.EventCompleted,
+		})
+	}
+	c.checkpoint(func(r *rollout.Record) {
+		if err != nil {
+			r.Status = rollout.StatusFailed
+			r.Error = err.Error()
+			return
+		}
+		r.Status = rollout.StatusCompleted
+		r.Phase = rollout.PhaseCompleted
+		r.Error = ""
+	})
+}
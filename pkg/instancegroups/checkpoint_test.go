@@ -0,0 +1,149 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package instancegroups
+
+import (
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	kopsapi "k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/rollout"
+)
+
+type memoryRolloutsClient struct {
+	writes int
+	last   rollout.Record
+}
+
+func (m *memoryRolloutsClient) Get(name string) (*rollout.Record, error) {
+	if m.last.Name != name {
+		return nil, nil
+	}
+	r := m.last
+	return &r, nil
+}
+
+func (m *memoryRolloutsClient) List() ([]*rollout.Record, error) {
+	if m.writes == 0 {
+		return nil, nil
+	}
+	r := m.last
+	return []*rollout.Record{&r}, nil
+}
+
+func (m *memoryRolloutsClient) Write(record *rollout.Record) error {
+	m.writes++
+	m.last = *record
+	return nil
+}
+
+func TestRollingUpdateRecordsProgress(t *testing.T) {
+	c, cloud := getTestSetup()
+
+	rollouts := &memoryRolloutsClient{}
+	c.Rollout = rollout.NewRecord(time.Now(), rollout.Options{})
+	c.Rollouts = rollouts
+
+	groups := getGroupsAllNeedUpdate(c.K8sClient, cloud)
+	err := c.RollingUpdate(groups, &kopsapi.InstanceGroupList{})
+	assert.NoError(t, err, "rolling update")
+
+	assert.Equal(t, rollout.StatusCompleted, rollouts.last.Status)
+	assert.Equal(t, rollout.PhaseCompleted, rollouts.last.Phase)
+	completed := append([]string{}, rollouts.last.CompletedGroups...)
+	sort.Strings(completed)
+	assert.Equal(t, []string{"bastion-1", "master-1", "node-1", "node-2"}, completed)
+	assert.Len(t, rollouts.last.ReplacedInstances, 9)
+}
+
+func TestRollingUpdateRecordsFailure(t *testing.T) {
+	c, cloud := getTestSetup()
+
+	rollouts := &memoryRolloutsClient{}
+	c.Rollout = rollout.NewRecord(time.Now(), rollout.Options{})
+	c.Rollouts = rollouts
+	c.ClusterValidator = &failingClusterValidator{}
+
+	groups := getGroupsAllNeedUpdate(c.K8sClient, cloud)
+	err := c.RollingUpdate(groups, &kopsapi.InstanceGroupList{})
+	assert.Error(t, err, "rolling update")
+
+	assert.Equal(t, rollout.StatusFailed, rollouts.last.Status)
+	assert.Equal(t, rollout.PhaseBastions, rollouts.last.Phase)
+	assert.Empty(t, rollouts.last.CompletedGroups)
+	assert.Len(t, rollouts.last.ReplacedInstances, 1)
+	assert.NotEmpty(t, rollouts.last.LastValidationFailure)
+	assert.NotEmpty(t, rollouts.last.Error)
+}
+
+func TestRollingUpdateResumeSkipsCompletedGroups(t *testing.T) {
+	c, cloud := getTestSetup()
+
+	c.Rollout = rollout.NewRecord(time.Now(), rollout.Options{})
+	c.Rollout.CompletedGroups = []string{"master-1", "node-1"}
+	c.Rollouts = &memoryRolloutsClient{}
+
+	groups := getGroupsAllNeedUpdate(c.K8sClient, cloud)
+	err := c.RollingUpdate(groups, &kopsapi.InstanceGroupList{})
+	assert.NoError(t, err, "rolling update")
+
+	assertGroupInstanceCount(t, cloud, "master-1", 2)
+	assertGroupInstanceCount(t, cloud, "node-1", 3)
+	assertGroupInstanceCount(t, cloud, "node-2", 0)
+	assertGroupInstanceCount(t, cloud, "bastion-1", 0)
+}
+
+type recordingEventSink struct {
+	mutex  sync.Mutex
+	events []*rollout.Event
+}
+
+func (s *recordingEventSink) Emit(event *rollout.Event) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.events = append(s.events, event)
+}
+
+func (s *recordingEventSink) count(eventType rollout.EventType) int {
+	n := 0
+	for _, event := range s.events {
+		if event.Type == eventType {
+			n++
+		}
+	}
+	return n
+}
+
+func TestRollingUpdateEmitsEvents(t *testing.T) {
+	c, cloud := getTestSetup()
+
+	events := &recordingEventSink{}
+	c.Events = events
+
+	groups := getGroupsAllNeedUpdate(c.K8sClient, cloud)
+	err := c.RollingUpdate(groups, &kopsapi.InstanceGroupList{})
+	assert.NoError(t, err, "rolling update")
+
+	assert.Equal(t, 4, events.count(rollout.EventInstanceGroupStarted))
+	assert.Equal(t, 4, events.count(rollout.EventInstanceGroupCompleted))
+	assert.Equal(t, 8, events.count(rollout.EventDrainStarted))
+	assert.Equal(t, 9, events.count(rollout.EventInstanceReplaced))
+	assert.Equal(t, rollout.EventCompleted, events.events[len(events.events)-1].Type)
+}
@@ -0,0 +1,457 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package instancegroups
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+	"k8s.io/kops/pkg/cloudinstances"
+	"k8s.io/kops/pkg/rollout"
+)
+
+// disruptionBudget is a PodDisruptionBudget with its parsed selector.
+type disruptionBudget struct {
+	key                string
+	namespace          string
+	name               string
+	selector           labels.Selector
+	disruptionsAllowed int32
+}
+
+// matches returns true if the budget covers the pod.
+func (b *disruptionBudget) matches(pod *corev1.Pod) bool {
+	return pod.Namespace == b.namespace && b.selector.Matches(labels.Set(pod.Labels))
+}
+
+// listDisruptionBudgets returns all the PodDisruptionBudgets in the cluster.
+func listDisruptionBudgets(ctx context.Context, client kubernetes.Interface) ([]*disruptionBudget, error) {
+	pdbs, err := client.PolicyV1().PodDisruptionBudgets(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error listing PodDisruptionBudgets: %w", err)
+	}
+
+	var budgets []*disruptionBudget
+	for i := range pdbs.Items {
+		pdb := &pdbs.Items[i]
+		if pdb.Spec.Selector == nil {
+			// A nil selector selects no pods
+			continue
+		}
+		selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+		if err != nil {
+			klog.Warningf("ignoring PodDisruptionBudget %s/%s with invalid selector: %v", pdb.Namespace, pdb.Name, err)
+			continue
+		}
+		budgets = append(budgets, &disruptionBudget{
+			key:                pdb.Namespace + "/" + pdb.Name,
+			namespace:          pdb.Namespace,
+			name:               pdb.Name,
+			selector:           selector,
+			disruptionsAllowed: pdb.Status.DisruptionsAllowed,
+		})
+	}
+	return budgets, nil
+}
+
+// listEvictablePods returns the pods on the node that a drain would evict.
+func listEvictablePods(ctx context.Context, client kubernetes.Interface, nodeName string) ([]corev1.Pod, error) {
+	pods, err := client.CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("spec.nodeName", nodeName).String(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing pods on node %q: %w", nodeName, err)
+	}
+
+	var evictable []corev1.Pod
+	for _, pod := range pods.Items {
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		if _, found := pod.Annotations[corev1.MirrorPodAnnotationKey]; found {
+			continue
+		}
+		if controller := metav1.GetControllerOf(&pod); controller != nil && controller.Kind == "DaemonSet" {
+			continue
+		}
+		evictable = append(evictable, pod)
+	}
+	return evictable, nil
+}
+
+// nodeDisruptionBudgets maps node names to the budgets covering the pods on that node.
+type nodeDisruptionBudgets map[string][]*disruptionBudget
+
+// newClusterDisruptionTracker determines which PodDisruptionBudgets the pods on the node of each instance to be updated
+// participate in, across all the instance groups, and returns a tracker that paces the drains of those nodes.
+// Errors are logged rather than returned, as the budgets are only used to order and pace the drains.
+func (c *RollingUpdateCluster) newClusterDisruptionTracker(groups map[string]*cloudinstances.CloudInstanceGroup) *disruptionTracker {
+	if c.CloudOnly || c.K8sClient == nil {
+		return nil
+	}
+
+	t := newDisruptionTracker(c.K8sClient)
+
+	var nodeNames []string
+	for _, k := range sortGroups(groups) {
+		group := groups[k]
+		update := group.NeedUpdate
+		if c.Force {
+			update = append(update, group.Ready...)
+		}
+		for _, u := range update {
+			if u.Node != nil {
+				nodeNames = append(nodeNames, u.Node.Name)
+			}
+		}
+	}
+	if len(nodeNames) == 0 {
+		return t
+	}
+
+	budgets, err := listDisruptionBudgets(c.Ctx, c.K8sClient)
+	if err != nil {
+		klog.Warningf("not taking PodDisruptionBudgets into account: %v", err)
+		return t
+	}
+	if len(budgets) == 0 {
+		return t
+	}
+	for _, budget := range budgets {
+		t.budgets[budget.key] = budget
+	}
+
+	for _, nodeName := range nodeNames {
+		pods, err := listEvictablePods(c.Ctx, c.K8sClient, nodeName)
+		if err != nil {
+			klog.Warningf("not taking PodDisruptionBudgets into account for node %q: %v", nodeName, err)
+			continue
+		}
+		seen := make(map[string]bool)
+		for i := range pods {
+			for _, budget := range budgets {
+				if !seen[budget.key] && budget.matches(&pods[i]) {
+					seen[budget.key] = true
+					t.nodeBudgets[nodeName] = append(t.nodeBudgets[nodeName], budget)
+				}
+			}
+		}
+	}
+	return t
+}
+
+// orderGroupsByDisruptionBudgets reorders the named instance groups, which are updated one after another,
+// so that a group does not follow one whose nodes' pods share a PodDisruptionBudget with its own,
+// giving the pods evicted from one group time to become ready before the next group disrupts the same budget.
+// Otherwise the order is kept.
+func orderGroupsByDisruptionBudgets(names []string, groups map[string]*cloudinstances.CloudInstanceGroup, t *disruptionTracker) []string {
+	if t == nil || len(names) <= 2 {
+		return names
+	}
+
+	budgetsOf := func(name string) map[string]bool {
+		keys := make(map[string]bool)
+		for _, u := range append(groups[name].NeedUpdate, groups[name].Ready...) {
+			if u.Node == nil {
+				continue
+			}
+			for _, budget := range t.budgetsFor(u.Node.Name) {
+				keys[budget.key] = true
+			}
+		}
+		return keys
+	}
+
+	remaining := append([]string(nil), names...)
+	result := make([]string, 0, len(names))
+	var previous map[string]bool
+	for len(remaining) > 0 {
+		pick := 0
+		for i, name := range remaining {
+			conflict := false
+			for key := range budgetsOf(name) {
+				if previous[key] {
+					conflict = true
+					break
+				}
+			}
+			if !conflict {
+				pick = i
+				break
+			}
+		}
+		previous = budgetsOf(remaining[pick])
+		result = append(result, remaining[pick])
+		remaining = append(remaining[:pick], remaining[pick+1:]...)
+	}
+	return result
+}
+
+// orderByDisruptionBudgets reorders the instances so that instances that are drained concurrently
+// share as few PodDisruptionBudgets as possible.  Detached instances are kept at the end.
+func orderByDisruptionBudgets(update []*cloudinstances.CloudInstance, budgets nodeDisruptionBudgets, maxConcurrency int) []*cloudinstances.CloudInstance {
+	if len(budgets) == 0 || maxConcurrency <= 1 {
+		return update
+	}
+
+	budgetsOf := func(u *cloudinstances.CloudInstance) []*disruptionBudget {
+		if u.Node == nil {
+			return nil
+		}
+		return budgets[u.Node.Name]
+	}
+
+	var attached, detached []*cloudinstances.CloudInstance
+	for _, u := range update {
+		if u.Status == cloudinstances.CloudInstanceStatusDetached {
+			detached = append(detached, u)
+		} else {
+			attached = append(attached, u)
+		}
+	}
+
+	result := make([]*cloudinstances.CloudInstance, 0, len(update))
+	for len(attached) > 0 {
+		// Budgets of the instances that may still be draining when the next one starts
+		inFlight := make(map[string]bool)
+		for i := len(result) - 1; i >= 0 && i >= len(result)-(maxConcurrency-1); i-- {
+			for _, budget := range budgetsOf(result[i]) {
+				inFlight[budget.key] = true
+			}
+		}
+
+		pick := 0
+		for i, u := range attached {
+			conflict := false
+			for _, budget := range budgetsOf(u) {
+				if inFlight[budget.key] {
+					conflict = true
+					break
+				}
+			}
+			if !conflict {
+				pick = i
+				break
+			}
+		}
+
+		result = append(result, attached[pick])
+		attached = append(attached[:pick], attached[pick+1:]...)
+	}
+
+	return append(result, detached...)
+}
+
+// disruptionTracker makes the drain of a node wait while, for any PodDisruptionBudget covering its pods,
+// the budget allows no more disruptions than the drains already in progress. One tracker spans all the
+// instance groups of a rolling update, including the bastion groups that are updated in parallel.
+// The disruptions the budgets allow are re-read while a drain waits. A nil tracker does not limit anything.
+type disruptionTracker struct {
+	client kubernetes.Interface
+
+	mutex sync.Mutex
+	// changed is closed and replaced whenever a reservation is released
+	changed     chan struct{}
+	inFlight    map[string]int
+	budgets     map[string]*disruptionBudget
+	nodeBudgets nodeDisruptionBudgets
+}
+
+func newDisruptionTracker(client kubernetes.Interface) *disruptionTracker {
+	return &disruptionTracker{
+		client:      client,
+		changed:     make(chan struct{}),
+		inFlight:    make(map[string]int),
+		budgets:     make(map[string]*disruptionBudget),
+		nodeBudgets: make(nodeDisruptionBudgets),
+	}
+}
+
+// budgetsFor returns the budgets covering the pods of the node.
+func (t *disruptionTracker) budgetsFor(nodeName string) []*disruptionBudget {
+	if t == nil {
+		return nil
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return t.nodeBudgets[nodeName]
+}
+
+// allNodeBudgets returns the budgets covering the pods of each node.
+func (t *disruptionTracker) allNodeBudgets() nodeDisruptionBudgets {
+	if t == nil {
+		return nil
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	result := make(nodeDisruptionBudgets, len(t.nodeBudgets))
+	for nodeName, b := range t.nodeBudgets {
+		result[nodeName] = b
+	}
+	return result
+}
+
+// acquire waits until all the budgets have room for another drain, then reserves it.
+// The budgets are re-read every refreshInterval while waiting; an error is returned if ctx is done first.
+func (t *disruptionTracker) acquire(ctx context.Context, budgets []*disruptionBudget, refreshInterval time.Duration) error {
+	if t == nil || len(budgets) == 0 {
+		return nil
+	}
+
+	ticker := time.NewTicker(refreshInterval)
+	defer ticker.Stop()
+
+	for {
+		t.mutex.Lock()
+		if t.hasRoom(budgets) {
+			for _, budget := range budgets {
+				t.inFlight[budget.key]++
+			}
+			t.mutex.Unlock()
+			return nil
+		}
+		changed := t.changed
+		t.mutex.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timed out waiting for PodDisruptionBudgets to allow disruptions: %w", ctx.Err())
+		case <-changed:
+		case <-ticker.C:
+			t.refresh(ctx)
+		}
+	}
+}
+
+// release returns the reservation made by acquire.
+func (t *disruptionTracker) release(budgets []*disruptionBudget) {
+	if t == nil || len(budgets) == 0 {
+		return
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	for _, budget := range budgets {
+		t.inFlight[budget.key]--
+	}
+	close(t.changed)
+	t.changed = make(chan struct{})
+}
+
+// refresh re-reads the disruptions that the budgets allow.
+func (t *disruptionTracker) refresh(ctx context.Context) {
+	if t.client == nil {
+		return
+	}
+
+	budgets, err := listDisruptionBudgets(ctx, t.client)
+	if err != nil {
+		klog.Warningf("unable to refresh PodDisruptionBudgets: %v", err)
+		return
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	for _, budget := range budgets {
+		if existing := t.budgets[budget.key]; existing != nil {
+			existing.disruptionsAllowed = budget.disruptionsAllowed
+		}
+	}
+}
+
+// hasRoom returns true if every budget allows more disruptions than the drains in progress that it covers.
+// The disruptions a budget allows already account for evicted pods that are not ready again,
+// so a drain also waits until the pods evicted by an earlier drain have been replaced.
+func (t *disruptionTracker) hasRoom(budgets []*disruptionBudget) bool {
+	for _, budget := range budgets {
+		if t.inFlight[budget.key] >= int(budget.disruptionsAllowed) {
+			return false
+		}
+	}
+	return true
+}
+
+// findBlockingBudgets returns the PodDisruptionBudgets that currently allow no disruptions of the pods remaining on the node.
+func findBlockingBudgets(ctx context.Context, client kubernetes.Interface, nodeName string) ([]rollout.BlockingBudget, error) {
+	budgets, err := listDisruptionBudgets(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	pods, err := listEvictablePods(ctx, client, nodeName)
+	if err != nil {
+		return nil, err
+	}
+
+	var blocking []rollout.BlockingBudget
+	for i := range pods {
+		for _, budget := range budgets {
+			if budget.disruptionsAllowed <= 0 && budget.matches(&pods[i]) {
+				blocking = append(blocking, rollout.BlockingBudget{
+					Namespace:          budget.namespace,
+					Name:               budget.name,
+					Pod:                pods[i].Name,
+					DisruptionsAllowed: budget.disruptionsAllowed,
+				})
+			}
+		}
+	}
+	sort.Slice(blocking, func(i, j int) bool {
+		if blocking[i].Namespace != blocking[j].Namespace {
+			return blocking[i].Namespace < blocking[j].Namespace
+		}
+		if blocking[i].Name != blocking[j].Name {
+			return blocking[i].Name < blocking[j].Name
+		}
+		return blocking[i].Pod < blocking[j].Pod
+	})
+	return blocking, nil
+}
+
+// DrainBlockedError is returned when draining a node failed while PodDisruptionBudgets prevented the eviction of its pods.
+type DrainBlockedError struct {
+	Node            string
+	BlockingBudgets []rollout.BlockingBudget
+	Err             error
+}
+
+func (e *DrainBlockedError) Error() string {
+	var blocking []string
+	for _, b := range e.BlockingBudgets {
+		blocking = append(blocking, b.String())
+	}
+	return fmt.Sprintf("%v; blocked by %s", e.Err, strings.Join(blocking, ", "))
+}
+
+func (e *DrainBlockedError) Unwrap() error {
+	return e.Err
+}
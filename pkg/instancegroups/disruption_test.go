@@ -0,0 +1,248 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package instancegroups
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	v1meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/kops/pkg/cloudinstances"
+	"k8s.io/kops/pkg/rollout"
+)
+
+func newTestBudget(name string, disruptionsAllowed int32) *disruptionBudget {
+	return &disruptionBudget{
+		key:                "default/" + name,
+		namespace:          "default",
+		name:               name,
+		selector:           labels.SelectorFromSet(labels.Set{"app": name}),
+		disruptionsAllowed: disruptionsAllowed,
+	}
+}
+
+func Test_orderByDisruptionBudgets(t *testing.T) {
+	pdb1 := newTestBudget("pdb1", 1)
+	pdb2 := newTestBudget("pdb2", 1)
+
+	var update []*cloudinstances.CloudInstance
+	for _, id := range []string{"a", "b", "c", "d", "e"} {
+		update = append(update, &cloudinstances.CloudInstance{
+			ID:   id,
+			Node: &v1.Node{ObjectMeta: v1meta.ObjectMeta{Name: id}},
+		})
+	}
+	update[4].Status = cloudinstances.CloudInstanceStatusDetached
+
+	budgets := nodeDisruptionBudgets{
+		"a": {pdb1},
+		"b": {pdb1},
+		"c": {pdb2},
+		"d": {pdb2},
+		"e": {pdb1},
+	}
+
+	grid := []struct {
+		maxConcurrency int
+		expected       []string
+	}{
+		{
+			maxConcurrency: 1,
+			expected:       []string{"a", "b", "c", "d", "e"},
+		},
+		{
+			maxConcurrency: 2,
+			expected:       []string{"a", "c", "b", "d", "e"},
+		},
+	}
+	for _, g := range grid {
+		var actual []string
+		for _, u := range orderByDisruptionBudgets(update, budgets, g.maxConcurrency) {
+			actual = append(actual, u.ID)
+		}
+		assert.Equal(t, g.expected, actual, "maxConcurrency %d", g.maxConcurrency)
+	}
+}
+
+func Test_orderGroupsByDisruptionBudgets(t *testing.T) {
+	newGroup := func(nodes ...string) *cloudinstances.CloudInstanceGroup {
+		group := &cloudinstances.CloudInstanceGroup{}
+		for _, node := range nodes {
+			group.NeedUpdate = append(group.NeedUpdate, &cloudinstances.CloudInstance{
+				ID:   node,
+				Node: &v1.Node{ObjectMeta: v1meta.ObjectMeta{Name: node}},
+			})
+		}
+		return group
+	}
+	groups := map[string]*cloudinstances.CloudInstanceGroup{
+		"a": newGroup("a-1"),
+		"b": newGroup("b-1"),
+		"c": newGroup("c-1"),
+	}
+
+	tracker := newDisruptionTracker(nil)
+	tracker.nodeBudgets = nodeDisruptionBudgets{
+		"a-1": {newTestBudget("pdb1", 1)},
+		"b-1": {newTestBudget("pdb1", 1)},
+		"c-1": {newTestBudget("pdb2", 1)},
+	}
+
+	assert.Equal(t, []string{"a", "c", "b"}, orderGroupsByDisruptionBudgets([]string{"a", "b", "c"}, groups, tracker))
+	assert.Equal(t, []string{"a", "b", "c"}, orderGroupsByDisruptionBudgets([]string{"a", "b", "c"}, groups, nil))
+}
+
+func Test_disruptionTracker(t *testing.T) {
+	ctx := context.Background()
+	tracker := newDisruptionTracker(nil)
+	tight := []*disruptionBudget{newTestBudget("tight", 1)}
+	loose := []*disruptionBudget{newTestBudget("loose", 2)}
+
+	assert.NoError(t, tracker.acquire(ctx, loose, time.Second))
+	assert.NoError(t, tracker.acquire(ctx, loose, time.Second))
+	assert.NoError(t, tracker.acquire(ctx, tight, time.Second))
+
+	acquired := make(chan error)
+	go func() {
+		acquired <- tracker.acquire(ctx, tight, time.Second)
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatalf("second drain acquired a budget that allows one disruption")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	tracker.release(tight)
+	select {
+	case err := <-acquired:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatalf("drain not unblocked after release")
+	}
+
+	var nilTracker *disruptionTracker
+	assert.NoError(t, nilTracker.acquire(ctx, tight, time.Second))
+	nilTracker.release(tight)
+	assert.Nil(t, nilTracker.budgetsFor("node"))
+}
+
+func Test_disruptionTrackerTimeout(t *testing.T) {
+	tracker := newDisruptionTracker(nil)
+	blocked := []*disruptionBudget{newTestBudget("blocked", 0)}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := tracker.acquire(ctx, blocked, time.Second)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func Test_disruptionTrackerRefresh(t *testing.T) {
+	ctx := context.Background()
+	pdb := &policyv1.PodDisruptionBudget{
+		ObjectMeta: v1meta.ObjectMeta{Namespace: "default", Name: "db"},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			Selector: &v1meta.LabelSelector{MatchLabels: map[string]string{"app": "db"}},
+		},
+		Status: policyv1.PodDisruptionBudgetStatus{DisruptionsAllowed: 0},
+	}
+	k8sClient := fake.NewSimpleClientset(pdb)
+
+	tracker := newDisruptionTracker(k8sClient)
+	budgets, err := listDisruptionBudgets(ctx, k8sClient)
+	assert.NoError(t, err)
+	for _, budget := range budgets {
+		tracker.budgets[budget.key] = budget
+	}
+
+	acquired := make(chan error)
+	go func() {
+		acquired <- tracker.acquire(ctx, budgets, 5*time.Millisecond)
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatalf("drain acquired a budget that allows no disruptions")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	// The evicted pods became ready again
+	pdb.Status.DisruptionsAllowed = 1
+	_, err = k8sClient.PolicyV1().PodDisruptionBudgets("default").UpdateStatus(ctx, pdb, v1meta.UpdateOptions{})
+	assert.NoError(t, err)
+
+	select {
+	case err := <-acquired:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatalf("drain not unblocked after the budget allowed a disruption")
+	}
+}
+
+func Test_findBlockingBudgets(t *testing.T) {
+	isController := true
+	k8sClient := fake.NewSimpleClientset(
+		&policyv1.PodDisruptionBudget{
+			ObjectMeta: v1meta.ObjectMeta{Namespace: "default", Name: "db"},
+			Spec: policyv1.PodDisruptionBudgetSpec{
+				Selector: &v1meta.LabelSelector{MatchLabels: map[string]string{"app": "db"}},
+			},
+			Status: policyv1.PodDisruptionBudgetStatus{DisruptionsAllowed: 0},
+		},
+		&policyv1.PodDisruptionBudget{
+			ObjectMeta: v1meta.ObjectMeta{Namespace: "default", Name: "web"},
+			Spec: policyv1.PodDisruptionBudgetSpec{
+				Selector: &v1meta.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			},
+			Status: policyv1.PodDisruptionBudgetStatus{DisruptionsAllowed: 1},
+		},
+		&v1.Pod{
+			ObjectMeta: v1meta.ObjectMeta{Namespace: "default", Name: "db-0", Labels: map[string]string{"app": "db"}},
+			Spec:       v1.PodSpec{NodeName: "node-1"},
+		},
+		&v1.Pod{
+			ObjectMeta: v1meta.ObjectMeta{Namespace: "default", Name: "web-abc", Labels: map[string]string{"app": "web"}},
+			Spec:       v1.PodSpec{NodeName: "node-1"},
+		},
+		&v1.Pod{
+			ObjectMeta: v1meta.ObjectMeta{
+				Namespace: "default",
+				Name:      "agent-xyz",
+				Labels:    map[string]string{"app": "db"},
+				OwnerReferences: []v1meta.OwnerReference{
+					{Kind: "DaemonSet", Name: "agent", Controller: &isController},
+				},
+			},
+			Spec: v1.PodSpec{NodeName: "node-1"},
+		},
+	)
+
+	blocking, err := findBlockingBudgets(context.Background(), k8sClient, "node-1")
+	assert.NoError(t, err)
+	assert.Equal(t, []rollout.BlockingBudget{
+		{Namespace: "default", Name: "db", Pod: "db-0", DisruptionsAllowed: 0},
+	}, blocking)
+
+	drainErr := &DrainBlockedError{Node: "node-1", BlockingBudgets: blocking, Err: context.DeadlineExceeded}
+	assert.Equal(t, "context deadline exceeded; blocked by PodDisruptionBudget default/db (0 disruptions allowed) protecting pod default/db-0", drainErr.Error())
+}
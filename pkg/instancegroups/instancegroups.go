@@ -38,6 +38,7 @@ import (
 
 	api "k8s.io/kops/pkg/apis/kops"
 	"k8s.io/kops/pkg/cloudinstances"
+	"k8s.io/kops/pkg/rollout"
 	"k8s.io/kops/pkg/validation"
 )
 
@@ -149,6 +150,24 @@ func (c *RollingUpdateCluster) rollingUpdateInstanceGroup(group *cloudinstances.
 
 	update = prioritizeUpdate(update)
 
+	update = orderByDisruptionBudgets(update, c.disruptions.allNodeBudgets(), maxConcurrency)
+
+	if settings.Canary != nil && group.InstanceGroup.Spec.Role == api.InstanceGroupRoleNode && *settings.DrainAndTerminate {
+		canaryCount := settings.Canary.Instances.IntValue()
+		if canaryCount < len(update) {
+			if err := c.updateCanaries(group, update[:canaryCount], settings.Canary, sleepAfterTerminate); err != nil {
+				return err
+			}
+			update = update[canaryCount:]
+			noneReady = false
+			if maxSurge > len(update) {
+				maxSurge = len(update)
+			}
+		} else {
+			klog.Infof("Not running a canary for instance group %q as only %d instances need updating.", group.InstanceGroup.Name, len(update))
+		}
+	}
+
 	if maxSurge > 0 && !c.CloudOnly {
 		skippedNodes := 0
 		for numSurge := 1; numSurge <= maxSurge; numSurge++ {
@@ -426,6 +445,8 @@ func (c *RollingUpdateCluster) drainTerminateAndWait(u *cloudinstances.CloudInst
 		return err
 	}
 
+	c.recordInstanceReplaced(u)
+
 	if err := c.reconcileInstanceGroup(); err != nil {
 		klog.Errorf("error reconciling instance group %q: %v", u.CloudInstanceGroup.HumanName, err)
 		return err
@@ -508,6 +529,7 @@ func (c *RollingUpdateCluster) validateClusterWithTimeout(validateCount int, gro
 		}
 
 		if err != nil {
+			c.recordValidationFailure(err.Error())
 			if ctx.Err() != nil {
 				klog.Infof("Cluster did not validate within deadline: %v.", err)
 				break
@@ -518,6 +540,7 @@ func (c *RollingUpdateCluster) validateClusterWithTimeout(validateCount int, gro
 			for _, failure := range result.Failures {
 				messages = append(messages, failure.Message)
 			}
+			c.recordValidationFailure(strings.Join(messages, ", "))
 			if ctx.Err() != nil {
 				klog.Infof("Cluster did not pass validation within deadline: %s.", strings.Join(messages, ", "))
 				break
@@ -616,13 +639,18 @@ func (c *RollingUpdateCluster) drainNode(u *cloudinstances.CloudInstance) error
 		return fmt.Errorf("node name not set")
 	}
 
+	drainOut := c.DrainOut
+	if drainOut == nil {
+		drainOut = os.Stdout
+	}
+
 	helper := &drain.Helper{
 		Ctx:                 c.Ctx,
 		Client:              c.K8sClient,
 		Force:               true,
 		GracePeriodSeconds:  -1,
 		IgnoreAllDaemonSets: true,
-		Out:                 os.Stdout,
+		Out:                 drainOut,
 		ErrOut:              os.Stderr,
 		Timeout:             c.DrainTimeout,
 
@@ -648,10 +676,42 @@ func (c *RollingUpdateCluster) drainNode(u *cloudinstances.CloudInstance) error
 		return fmt.Errorf("error deregistering instance %q, node %q: %v", u.ID, u.Node.Name, err)
 	}
 
+	// Hold back drains that would disrupt the same PodDisruptionBudgets as drains still in progress,
+	// in this or any other instance group, until the budgets allow another disruption.
+	budgets := c.disruptions.budgetsFor(u.Node.Name)
+	if err := c.acquireDisruptionBudgets(u, budgets); err != nil {
+		return err
+	}
+	defer c.disruptions.release(budgets)
+
+	c.emit(&rollout.Event{
+		Type:          rollout.EventDrainStarted,
+		InstanceGroup: u.CloudInstanceGroup.InstanceGroup.Name,
+		Instance:      u.ID,
+		Node:          u.Node.Name,
+	})
+
 	if err := drain.RunNodeDrain(helper, u.Node.Name); err != nil {
 		if apierrors.IsNotFound(err) {
 			return nil
 		}
+
+		blocking, findErr := findBlockingBudgets(c.Ctx, c.K8sClient, u.Node.Name)
+		if findErr != nil {
+			klog.Warningf("unable to determine PodDisruptionBudgets blocking the drain of node %q: %v", u.Node.Name, findErr)
+		}
+		if len(blocking) > 0 {
+			blockedErr := &DrainBlockedError{Node: u.Node.Name, BlockingBudgets: blocking, Err: err}
+			c.emit(&rollout.Event{
+				Type:            rollout.EventDrainBlocked,
+				InstanceGroup:   u.CloudInstanceGroup.InstanceGroup.Name,
+				Instance:        u.ID,
+				Node:            u.Node.Name,
+				Message:         blockedErr.Error(),
+				BlockingBudgets: blocking,
+			})
+			return blockedErr
+		}
 		return fmt.Errorf("error draining node: %v", err)
 	}
 
@@ -663,6 +723,46 @@ func (c *RollingUpdateCluster) drainNode(u *cloudinstances.CloudInstance) error
 	return nil
 }
 
+// acquireDisruptionBudgets waits, for at most the validation timeout, until the budgets allow the node to be drained.
+// If they do not, the budgets blocking the drain are reported.
+func (c *RollingUpdateCluster) acquireDisruptionBudgets(u *cloudinstances.CloudInstance, budgets []*disruptionBudget) error {
+	if len(budgets) == 0 {
+		return nil
+	}
+
+	ctx := c.Ctx
+	if c.ValidationTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.ValidationTimeout)
+		defer cancel()
+	}
+
+	refreshInterval := c.ValidateTickDuration
+	if refreshInterval <= 0 {
+		refreshInterval = 10 * time.Second
+	}
+
+	err := c.disruptions.acquire(ctx, budgets, refreshInterval)
+	if err == nil {
+		return nil
+	}
+
+	blocking, findErr := findBlockingBudgets(c.Ctx, c.K8sClient, u.Node.Name)
+	if findErr != nil {
+		klog.Warningf("unable to determine PodDisruptionBudgets blocking the drain of node %q: %v", u.Node.Name, findErr)
+	}
+	blockedErr := &DrainBlockedError{Node: u.Node.Name, BlockingBudgets: blocking, Err: err}
+	c.emit(&rollout.Event{
+		Type:            rollout.EventDrainBlocked,
+		InstanceGroup:   u.CloudInstanceGroup.InstanceGroup.Name,
+		Instance:        u.ID,
+		Node:            u.Node.Name,
+		Message:         blockedErr.Error(),
+		BlockingBudgets: blocking,
+	})
+	return blockedErr
+}
+
 // deleteNode deletes a node from the k8s API.  It does not delete the underlying instance.
 func (c *RollingUpdateCluster) deleteNode(node *corev1.Node) error {
 	var options metav1.DeleteOptions
@@ -18,7 +18,9 @@ package instancegroups
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"sort"
 	"sync"
 	"time"
@@ -29,6 +31,7 @@ import (
 	"k8s.io/klog/v2"
 	api "k8s.io/kops/pkg/apis/kops"
 	"k8s.io/kops/pkg/cloudinstances"
+	"k8s.io/kops/pkg/rollout"
 	"k8s.io/kops/pkg/validation"
 	"k8s.io/kops/upup/pkg/fi"
 )
@@ -80,6 +83,23 @@ type RollingUpdateCluster struct {
 
 	// DrainTimeout is the maximum amount of time to wait while draining a node.
 	DrainTimeout time.Duration
+
+	// Rollout is the record of this rolling update, if it is being recorded.
+	// Instance groups that it lists as completed are skipped, so that an interrupted rolling update can be resumed.
+	Rollout *rollout.Record
+	// Rollouts is where progress on Rollout is persisted; if nil, progress is only kept in memory.
+	Rollouts simple.RolloutsClient
+
+	// Events receives the events of the rolling update, if set.
+	Events rollout.EventSink
+
+	// DrainOut receives the progress of node drains; defaults to stdout.
+	DrainOut io.Writer
+
+	rolloutMutex sync.Mutex
+
+	// disruptions paces the drains of nodes whose pods share PodDisruptionBudgets.
+	disruptions *disruptionTracker
 }
 
 // AdjustNeedUpdate adjusts the set of instances that need updating, using factors outside those known by the cloud implementation
@@ -92,6 +112,12 @@ func (*RollingUpdateCluster) AdjustNeedUpdate(groups map[string]*cloudinstances.
 
 // RollingUpdate performs a rolling update on a K8s Cluster.
 func (c *RollingUpdateCluster) RollingUpdate(groups map[string]*cloudinstances.CloudInstanceGroup, instanceGroups *api.InstanceGroupList) error {
+	err := c.rollingUpdate(groups, instanceGroups)
+	c.recordResult(err)
+	return err
+}
+
+func (c *RollingUpdateCluster) rollingUpdate(groups map[string]*cloudinstances.CloudInstanceGroup, instanceGroups *api.InstanceGroupList) error {
 	if len(groups) == 0 {
 		klog.Info("Cloud Instance Group length is zero. Not doing a rolling-update.")
 		return nil
@@ -105,6 +131,10 @@ func (c *RollingUpdateCluster) RollingUpdate(groups map[string]*cloudinstances.C
 	nodeGroups := make(map[string]*cloudinstances.CloudInstanceGroup)
 	bastionGroups := make(map[string]*cloudinstances.CloudInstanceGroup)
 	for k, group := range groups {
+		if c.isGroupCompleted(group) {
+			klog.Infof("Skipping instance group %q, which was completed earlier in this rolling update.", group.InstanceGroup.Name)
+			continue
+		}
 		switch group.InstanceGroup.Spec.Role {
 		case api.InstanceGroupRoleNode:
 			nodeGroups[k] = group
@@ -119,7 +149,11 @@ func (c *RollingUpdateCluster) RollingUpdate(groups map[string]*cloudinstances.C
 		}
 	}
 
+	// A single tracker paces drains across all the instance groups, including the bastions updated in parallel
+	c.disruptions = c.newClusterDisruptionTracker(groups)
+
 	// Upgrade bastions first; if these go down we can't see anything
+	c.recordPhase(rollout.PhaseBastions)
 	{
 		var wg sync.WaitGroup
 
@@ -132,7 +166,7 @@ func (c *RollingUpdateCluster) RollingUpdate(groups map[string]*cloudinstances.C
 
 				defer wg.Done()
 
-				err := c.rollingUpdateInstanceGroup(bastionGroups[k], c.BastionInterval)
+				err := c.updateGroup(bastionGroups[k], c.BastionInterval)
 
 				resultsMutex.Lock()
 				results[k] = err
@@ -151,13 +185,14 @@ func (c *RollingUpdateCluster) RollingUpdate(groups map[string]*cloudinstances.C
 	}
 
 	// Upgrade masters next
+	c.recordPhase(rollout.PhaseControlPlane)
 	{
 		// We run master nodes in series, even if they are in separate instance groups
 		// typically they will be in separate instance groups, so we can force the zones,
 		// and we don't want to roll all the masters at the same time.  See issue #284
 
 		for _, k := range sortGroups(masterGroups) {
-			err := c.rollingUpdateInstanceGroup(masterGroups[k], c.MasterInterval)
+			err := c.updateGroup(masterGroups[k], c.MasterInterval)
 			// Do not continue update if master(s) failed, cluster is potentially in an unhealthy state
 			if err != nil {
 				return fmt.Errorf("master not healthy after update, stopping rolling-update: %q", err)
@@ -166,13 +201,14 @@ func (c *RollingUpdateCluster) RollingUpdate(groups map[string]*cloudinstances.C
 	}
 
 	// Upgrade API servers
+	c.recordPhase(rollout.PhaseAPIServers)
 	{
 		for k := range apiServerGroups {
 			results[k] = fmt.Errorf("function panic apiservers")
 		}
 
 		for _, k := range sortGroups(apiServerGroups) {
-			err := c.rollingUpdateInstanceGroup(apiServerGroups[k], c.NodeInterval)
+			err := c.updateGroup(apiServerGroups[k], c.NodeInterval)
 
 			results[k] = err
 
@@ -181,6 +217,7 @@ func (c *RollingUpdateCluster) RollingUpdate(groups map[string]*cloudinstances.C
 	}
 
 	// Upgrade nodes
+	c.recordPhase(rollout.PhaseNodes)
 	{
 		// We run nodes in series, even if they are in separate instance groups
 		// typically they will not being separate instance groups. If you roll the nodes in parallel
@@ -192,11 +229,17 @@ func (c *RollingUpdateCluster) RollingUpdate(groups map[string]*cloudinstances.C
 			results[k] = fmt.Errorf("function panic nodes")
 		}
 
-		for _, k := range sortGroups(nodeGroups) {
-			err := c.rollingUpdateInstanceGroup(nodeGroups[k], c.NodeInterval)
+		for _, k := range orderGroupsByDisruptionBudgets(sortGroups(nodeGroups), nodeGroups, c.disruptions) {
+			err := c.updateGroup(nodeGroups[k], c.NodeInterval)
 
 			results[k] = err
 
+			// A failed canary means the new specification is not working; do not touch any other instance groups
+			var canaryErr *CanaryFailedError
+			if errors.As(err, &canaryErr) {
+				return fmt.Errorf("canary not healthy after update, stopping rolling-update: %w", err)
+			}
+
 			// TODO: Bail on error?
 		}
 	}
@@ -211,6 +254,16 @@ func (c *RollingUpdateCluster) RollingUpdate(groups map[string]*cloudinstances.C
 	return nil
 }
 
+// updateGroup performs a rolling update of the instance group, recording it as completed if it succeeds.
+func (c *RollingUpdateCluster) updateGroup(group *cloudinstances.CloudInstanceGroup, sleepAfterTerminate time.Duration) error {
+	c.recordGroupStarted(group)
+	if err := c.rollingUpdateInstanceGroup(group, sleepAfterTerminate); err != nil {
+		return err
+	}
+	c.recordGroupCompleted(group)
+	return nil
+}
+
 func sortGroups(groupMap map[string]*cloudinstances.CloudInstanceGroup) []string {
 	groups := make([]string, 0, len(groupMap))
 	for group := range groupMap {
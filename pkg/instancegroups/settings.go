@@ -17,6 +17,9 @@ limitations under the License.
 package instancegroups
 
 import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 
 	"k8s.io/kops/pkg/apis/kops"
@@ -40,6 +43,9 @@ func resolveSettings(cluster *kops.Cluster, group *kops.InstanceGroup, numInstan
 		if rollingUpdate.MaxSurge == nil {
 			rollingUpdate.MaxSurge = def.MaxSurge
 		}
+		if rollingUpdate.Canary == nil {
+			rollingUpdate.Canary = def.Canary
+		}
 	}
 
 	if rollingUpdate.DrainAndTerminate == nil {
@@ -78,5 +84,41 @@ func resolveSettings(cluster *kops.Cluster, group *kops.InstanceGroup, numInstan
 		rollingUpdate.MaxUnavailable = &unavailableInt
 	}
 
+	if rollingUpdate.Canary != nil {
+		rollingUpdate.Canary = resolveCanarySettings(rollingUpdate.Canary, numInstances)
+	}
+
 	return rollingUpdate
 }
+
+func resolveCanarySettings(spec *kops.RollingUpdateCanary, numInstances int) *kops.RollingUpdateCanary {
+	canary := &kops.RollingUpdateCanary{
+		Instances:        spec.Instances,
+		SoakDuration:     spec.SoakDuration,
+		StrictValidation: spec.StrictValidation,
+	}
+
+	if canary.Instances == nil {
+		val := intstr.FromInt(1)
+		canary.Instances = &val
+	}
+
+	if canary.Instances.Type == intstr.String {
+		instances, _ := intstr.GetScaledValueFromIntOrPercent(canary.Instances, numInstances, true)
+		if instances <= 0 {
+			instances = 1
+		}
+		instancesInt := intstr.FromInt(instances)
+		canary.Instances = &instancesInt
+	}
+
+	if canary.SoakDuration == nil {
+		canary.SoakDuration = &metav1.Duration{Duration: 5 * time.Minute}
+	}
+
+	if canary.StrictValidation == nil {
+		canary.StrictValidation = fi.Bool(false)
+	}
+
+	return canary
+}
@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -223,3 +224,46 @@ func TestAWSDefault(t *testing.T) {
 	assert.Equal(t, intstr.Int, resolved.MaxUnavailable.Type)
 	assert.Equal(t, int32(0), resolved.MaxUnavailable.IntVal)
 }
+
+func TestCanary(t *testing.T) {
+	for _, tc := range []struct {
+		numInstances int
+		value        string
+		expected     int32
+	}{
+		{
+			numInstances: 10,
+			value:        "2",
+			expected:     2,
+		},
+		{
+			numInstances: 10,
+			value:        "11%",
+			expected:     2,
+		},
+		{
+			numInstances: 10,
+			value:        "5%",
+			expected:     1,
+		},
+	} {
+		t.Run(fmt.Sprintf("%s %d", tc.value, tc.numInstances), func(t *testing.T) {
+			value := intstr.Parse(tc.value)
+			cluster := kops.Cluster{
+				Spec: kops.ClusterSpec{
+					RollingUpdate: &kops.RollingUpdate{
+						Canary: &kops.RollingUpdateCanary{
+							Instances: &value,
+						},
+					},
+				},
+			}
+			resolved := resolveSettings(&cluster, &kops.InstanceGroup{}, tc.numInstances)
+			assert.Equal(t, intstr.Int, resolved.Canary.Instances.Type)
+			assert.Equal(t, tc.expected, resolved.Canary.Instances.IntVal)
+			assert.Equal(t, 5*time.Minute, resolved.Canary.SoakDuration.Duration, "SoakDuration default")
+			assert.False(t, *resolved.Canary.StrictValidation, "StrictValidation default")
+			assert.Equal(t, &value, cluster.Spec.RollingUpdate.Canary.Instances, "cluster not modified")
+		})
+	}
+}
@@ -33,7 +33,7 @@ import (
 
 const DefaultKubecfgAdminLifetime = 18 * time.Hour
 
-func BuildKubecfg(cluster *kops.Cluster, keyStore fi.Keystore, secretStore fi.SecretStore, cloud fi.Cloud, admin time.Duration, configUser string, internal bool, kopsStateStore string, useKopsAuthenticationPlugin bool) (*KubeconfigBuilder, error) {
+func BuildKubecfg(cluster *kops.Cluster, signer Signer, secretStore fi.SecretStore, cloud fi.Cloud, admin time.Duration, configUser string, internal bool, kopsStateStore string, useKopsAuthenticationPlugin bool) (*KubeconfigBuilder, error) {
 	clusterName := cluster.ObjectMeta.Name
 
 	var master string
@@ -109,39 +109,15 @@ func BuildKubecfg(cluster *kops.Cluster, keyStore fi.Keystore, secretStore fi.Se
 	// add the CA Cert to the kubeconfig only if we didn't specify a certificate for the LB
 	//  or if we're using admin credentials and the secondary port
 	if cluster.Spec.API == nil || cluster.Spec.API.LoadBalancer == nil || cluster.Spec.API.LoadBalancer.SSLCertificate == "" || cluster.Spec.API.LoadBalancer.Class == kops.LoadBalancerClassNetwork || internal {
-		keySet, err := keyStore.FindKeyset(fi.CertificateIDCA)
+		caCerts, err := signer.CACertificates()
 		if err != nil {
-			return nil, fmt.Errorf("error fetching CA keypair: %v", err)
-		}
-		if keySet != nil {
-			b.CACerts, err = keySet.ToCertificateBytes()
-			if err != nil {
-				return nil, err
-			}
-		} else {
-			return nil, fmt.Errorf("cannot find CA certificate")
+			return nil, err
 		}
+		b.CACerts = caCerts
 	}
 
 	if admin != 0 {
-		cn := "kubecfg"
-		user, err := user.Current()
-		if err != nil || user == nil {
-			klog.Infof("unable to get user: %v", err)
-		} else {
-			cn += "-" + user.Name
-		}
-
-		req := pki.IssueCertRequest{
-			Signer: fi.CertificateIDCA,
-			Type:   "client",
-			Subject: pkix.Name{
-				CommonName:   cn,
-				Organization: []string{rbac.SystemPrivilegedGroup},
-			},
-			Validity: admin,
-		}
-		cert, privateKey, _, err := pki.IssueCert(&req, keyStore)
+		cert, privateKey, err := signer.IssueAdminCertificate(admin)
 		if err != nil {
 			return nil, err
 		}
@@ -179,3 +155,54 @@ func BuildKubecfg(cluster *kops.Cluster, keyStore fi.Keystore, secretStore fi.Se
 
 	return b, nil
 }
+
+// Signer provides the cluster CA certificates and issues the client certificate of the cluster admin user.
+type Signer interface {
+	// CACertificates returns the certificates of the cluster CA.
+	CACertificates() ([]byte, error)
+	// IssueAdminCertificate issues a client certificate for the cluster admin user,
+	// returning the certificate and its private key.
+	IssueAdminCertificate(validity time.Duration) (*pki.Certificate, *pki.PrivateKey, error)
+}
+
+type keystoreSigner struct {
+	keyStore fi.Keystore
+}
+
+// NewKeystoreSigner returns a Signer that signs with the CA private key from the keystore.
+func NewKeystoreSigner(keyStore fi.Keystore) Signer {
+	return &keystoreSigner{keyStore: keyStore}
+}
+
+func (s *keystoreSigner) CACertificates() ([]byte, error) {
+	keySet, err := s.keyStore.FindKeyset(fi.CertificateIDCA)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching CA keypair: %v", err)
+	}
+	if keySet == nil {
+		return nil, fmt.Errorf("cannot find CA certificate")
+	}
+	return keySet.ToCertificateBytes()
+}
+
+func (s *keystoreSigner) IssueAdminCertificate(validity time.Duration) (*pki.Certificate, *pki.PrivateKey, error) {
+	cn := "kubecfg"
+	user, err := user.Current()
+	if err != nil || user == nil {
+		klog.Infof("unable to get user: %v", err)
+	} else {
+		cn += "-" + user.Name
+	}
+
+	req := pki.IssueCertRequest{
+		Signer: fi.CertificateIDCA,
+		Type:   "client",
+		Subject: pkix.Name{
+			CommonName:   cn,
+			Organization: []string{rbac.SystemPrivilegedGroup},
+		},
+		Validity: validity,
+	}
+	cert, privateKey, _, err := pki.IssueCert(&req, s.keyStore)
+	return cert, privateKey, err
+}
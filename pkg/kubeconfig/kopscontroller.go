@@ -0,0 +1,138 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeconfig
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"time"
+
+	"k8s.io/klog/v2"
+	"k8s.io/kops/pkg/apis/nodeup"
+	"k8s.io/kops/pkg/bootstrap"
+	"k8s.io/kops/pkg/pki"
+)
+
+// KopsControllerSigner requests the client certificate of the cluster admin user from kops-controller,
+// so that the CA private key does not need to be read from the state store.
+type KopsControllerSigner struct {
+	// Authenticator generates authentication credentials for requests.
+	Authenticator bootstrap.Authenticator
+	// CAs are the certificates of the cluster CA, which also signs the kops-controller serving certificate.
+	CAs []byte
+	// BaseURL is the base URL of kops-controller.
+	BaseURL url.URL
+	// ServerName is the name the kops-controller serving certificate is verified against,
+	// if it differs from the host of BaseURL.
+	ServerName string
+}
+
+var _ Signer = &KopsControllerSigner{}
+
+func (s *KopsControllerSigner) CACertificates() ([]byte, error) {
+	return s.CAs, nil
+}
+
+func (s *KopsControllerSigner) IssueAdminCertificate(validity time.Duration) (*pki.Certificate, *pki.PrivateKey, error) {
+	ctx := context.TODO()
+
+	privateKey, err := pki.GeneratePrivateKey()
+	if err != nil {
+		return nil, nil, fmt.Errorf("generating private key: %v", err)
+	}
+	pkData, err := x509.MarshalPKIXPublicKey(privateKey.Key.Public())
+	if err != nil {
+		return nil, nil, fmt.Errorf("marshalling public key: %v", err)
+	}
+
+	req := &nodeup.AdminCredentialsRequest{
+		APIVersion:      nodeup.BootstrapAPIVersion,
+		PublicKey:       string(pem.EncodeToMemory(&pem.Block{Type: "RSA PUBLIC KEY", Bytes: pkData})),
+		ValiditySeconds: int64(validity / time.Second),
+	}
+	reqBytes, err := json.Marshal(req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	adminURL := s.BaseURL
+	adminURL.Path = path.Join(adminURL.Path, "/admin-credentials")
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", adminURL.String(), bytes.NewReader(reqBytes))
+	if err != nil {
+		return nil, nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	token, err := s.Authenticator.CreateToken(reqBytes)
+	if err != nil {
+		return nil, nil, err
+	}
+	httpReq.Header.Set("Authorization", token)
+
+	certPool := x509.NewCertPool()
+	certPool.AppendCertsFromPEM(s.CAs)
+	httpClient := &http.Client{
+		Timeout: 30 * time.Second,
+		Transport: &http.Transport{
+			Proxy: http.ProxyFromEnvironment,
+			TLSClientConfig: &tls.Config{
+				RootCAs:    certPool,
+				ServerName: s.ServerName,
+				MinVersion: tls.VersionTLS12,
+			},
+		},
+	}
+
+	resp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return nil, nil, fmt.Errorf("requesting admin credentials from kops-controller: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		detail := ""
+		scanner := bufio.NewScanner(resp.Body)
+		if scanner.Scan() {
+			detail = scanner.Text()
+		}
+		return nil, nil, fmt.Errorf("kops-controller returned status code %d: %s", resp.StatusCode, detail)
+	}
+
+	adminResp := &nodeup.AdminCredentialsResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(adminResp); err != nil {
+		return nil, nil, fmt.Errorf("decoding kops-controller response: %v", err)
+	}
+	cert, err := pki.ParsePEMCertificate([]byte(adminResp.Certificate))
+	if err != nil {
+		return nil, nil, fmt.Errorf("parsing admin certificate: %v", err)
+	}
+
+	if expiry := cert.Certificate.NotAfter; time.Until(expiry) < validity-time.Minute {
+		klog.Infof("kops-controller issued an admin credential that expires at %s", expiry.Local().Format(time.RFC3339))
+	}
+
+	return cert, privateKey, nil
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeconfig
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"k8s.io/kops/pkg/apis/nodeup"
+	"k8s.io/kops/pkg/bootstrap/oidc"
+	"k8s.io/kops/pkg/pki"
+	"k8s.io/kops/upup/pkg/fi"
+)
+
+func TestKopsControllerSigner(t *testing.T) {
+	originalPKIDefaultPrivateKeySize := pki.DefaultPrivateKeySize
+	pki.DefaultPrivateKeySize = 512
+	defer func() {
+		pki.DefaultPrivateKeySize = originalPKIDefaultPrivateKeySize
+	}()
+
+	keyStore := fakeKeyStore{
+		FindKeysetFn: func(name string) (*fi.Keyset, error) {
+			return fakeKeyset(), nil
+		},
+	}
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/admin-credentials" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer id-token" {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte("failed to verify token"))
+			return
+		}
+
+		req := &nodeup.AdminCredentialsRequest{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			t.Errorf("error decoding request: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if req.ValiditySeconds != 3600 {
+			t.Errorf("expected validity of 3600 seconds, got %d", req.ValiditySeconds)
+		}
+		block, _ := pem.Decode([]byte(req.PublicKey))
+		publicKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			t.Errorf("error parsing public key: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		cert, _, _, err := pki.IssueCert(&pki.IssueCertRequest{
+			Signer:    fi.CertificateIDCA,
+			Type:      "client",
+			Subject:   pkix.Name{CommonName: "alice@example.com"},
+			PublicKey: publicKey,
+			Validity:  time.Hour,
+		}, keyStore)
+		if err != nil {
+			t.Errorf("error issuing certificate: %v", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		certString, _ := cert.AsString()
+		_ = json.NewEncoder(w).Encode(&nodeup.AdminCredentialsResponse{Certificate: certString})
+	}))
+	defer server.Close()
+
+	serverURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("error parsing server URL: %v", err)
+	}
+	serverCA := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+
+	signer := &KopsControllerSigner{
+		Authenticator: oidc.NewTokenAuthenticator("id-token"),
+		CAs:           serverCA,
+		BaseURL:       *serverURL,
+		ServerName:    "example.com",
+	}
+	cert, key, err := signer.IssueAdminCertificate(time.Hour)
+	if err != nil {
+		t.Fatalf("error issuing admin certificate: %v", err)
+	}
+	if cert.Subject.CommonName != "alice@example.com" {
+		t.Errorf("unexpected subject %q", cert.Subject.CommonName)
+	}
+	certKey, _ := x509.MarshalPKIXPublicKey(cert.Certificate.PublicKey)
+	generatedKey, _ := x509.MarshalPKIXPublicKey(key.Key.Public())
+	if string(certKey) != string(generatedKey) {
+		t.Errorf("certificate was not issued for the generated private key")
+	}
+
+	signer.Authenticator = oidc.NewTokenAuthenticator("other-token")
+	if _, _, err := signer.IssueAdminCertificate(time.Hour); err == nil {
+		t.Errorf("expected error when kops-controller rejects the token")
+	}
+}
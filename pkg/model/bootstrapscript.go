@@ -410,6 +410,14 @@ func (b *BootstrapScript) Run(c *fi.Context) error {
 			return nil, err
 		}
 
+		if b.ig.Spec.UserDataFormat == kops.UserDataFormatIgnition {
+			ignitionUserData, err := resources.IgnitionUserData(nodeupScript, b.ig)
+			if err != nil {
+				return nil, err
+			}
+			return []byte(ignitionUserData), nil
+		}
+
 		awsUserData, err := resources.AWSMultipartMIME(nodeupScript, b.ig)
 		if err != nil {
 			return nil, err
@@ -27,6 +27,7 @@ import (
 	"k8s.io/kops/pkg/apis/kops"
 	"k8s.io/kops/pkg/assets"
 	"k8s.io/kops/pkg/dns"
+	"k8s.io/kops/pkg/etcdbackup"
 	"k8s.io/kops/pkg/featureflag"
 	"k8s.io/kops/pkg/flagbuilder"
 	"k8s.io/kops/pkg/k8scodecs"
@@ -475,6 +476,10 @@ func (b *EtcdManagerBuilder) buildPod(etcdCluster kops.EtcdClusterSpec) (*v1.Pod
 
 	container.Env = envMap.ToEnvVars()
 
+	if etcdCluster.Backups != nil && etcdCluster.Backups.Retention != nil {
+		container.Env = append(container.Env, etcdbackup.RetentionPolicyFor(etcdCluster.Backups).Env()...)
+	}
+
 	if etcdCluster.Manager != nil && len(etcdCluster.Manager.Env) > 0 {
 		for _, envVar := range etcdCluster.Manager.Env {
 			klog.Warningf("overloading ENV var in manifest %s with %s=%s", bundle, envVar.Name, envVar.Value)
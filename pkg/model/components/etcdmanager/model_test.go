@@ -36,6 +36,7 @@ func Test_RunEtcdManagerBuilder(t *testing.T) {
 		"tests/pollinterval",
 		"tests/proxy",
 		"tests/overwrite_settings",
+		"tests/retention",
 	}
 	for _, basedir := range tests {
 		basedir := basedir
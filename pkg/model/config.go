@@ -43,7 +43,10 @@ func (b *ConfigBuilder) Build(c *fi.ModelBuilderContext) error {
 		Contents:  fi.NewStringResource(kopsbase.Version),
 	})
 
-	versionedYaml, err := kopscodecs.ToVersionedYamlWithVersion(b.Cluster, v1alpha2.SchemeGroupVersion)
+	// The resourceVersion identifies the version of the cluster in the state store, not of the completed spec
+	cluster := b.Cluster.DeepCopy()
+	cluster.ResourceVersion = ""
+	versionedYaml, err := kopscodecs.ToVersionedYamlWithVersion(cluster, v1alpha2.SchemeGroupVersion)
 	if err != nil {
 		return fmt.Errorf("serializing completed cluster spec: %w", err)
 	}
@@ -326,6 +326,11 @@ func (b *PolicyBuilder) BuildAWSPolicy() (*Policy, error) {
 		}
 	}
 
+	// The control plane decrypts the secrets and keys in the state store
+	if e := b.Cluster.Spec.StateStoreEncryption; e != nil && e.AWSKMS != nil {
+		b.KMSKeys = append(b.KMSKeys, e.AWSKMS.KeyID)
+	}
+
 	p, err := b.Role.BuildAWSPolicy(b)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate AWS IAM Policy: %v", err)
@@ -28,6 +28,7 @@ import (
 	"text/template"
 
 	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/ignition"
 	"k8s.io/kops/upup/pkg/fi"
 	"k8s.io/kops/util/pkg/architectures"
 	"k8s.io/kops/util/pkg/mirrors"
@@ -326,6 +327,60 @@ func AWSMultipartMIME(bootScript string, ig *kops.InstanceGroup) (string, error)
 	return userData, nil
 }
 
+const (
+	// ignitionBootstrapScriptPath is where the Ignition user data writes the nodeup (bootstrap) script
+	ignitionBootstrapScriptPath = "/opt/kops/bootstrap.sh"
+	// ignitionBootstrapUnit is the systemd unit that runs the nodeup (bootstrap) script
+	ignitionBootstrapUnit = "kops-bootstrap.service"
+)
+
+var ignitionBootstrapUnitContents = `[Unit]
+Description=Bootstrap the node using kOps nodeup
+Wants=network-online.target
+After=network-online.target
+
+[Service]
+Type=oneshot
+RemainAfterExit=yes
+ExecStart=` + ignitionBootstrapScriptPath + `
+
+[Install]
+WantedBy=multi-user.target
+`
+
+// IgnitionUserData returns an Ignition config that writes the nodeup (bootstrap) script
+// and runs it from a systemd unit, for Flatcar and Fedora CoreOS
+func IgnitionUserData(bootScript string, ig *kops.InstanceGroup) (string, error) {
+	if len(ig.Spec.AdditionalUserData) > 0 {
+		return "", fmt.Errorf("additionalUserData is not supported with the Ignition user data format")
+	}
+
+	config := ignition.NewConfig()
+	if !ig.IsBastion() {
+		config.Storage.Files = append(config.Storage.Files, ignition.File{
+			Node: ignition.Node{
+				Path:      ignitionBootstrapScriptPath,
+				Overwrite: fi.Bool(true),
+			},
+			Contents: ignition.Resource{
+				Source: fi.String(ignition.DataURL([]byte(bootScript))),
+			},
+			Mode: ignition.Mode(0o700),
+		})
+		config.Systemd.Units = append(config.Systemd.Units, ignition.Unit{
+			Name:     ignitionBootstrapUnit,
+			Enabled:  fi.Bool(true),
+			Contents: fi.String(ignitionBootstrapUnitContents),
+		})
+	}
+
+	b, err := config.Marshal()
+	if err != nil {
+		return "", fmt.Errorf("error building ignition config: %v", err)
+	}
+	return string(b), nil
+}
+
 func writeUserDataPart(mimeWriter *multipart.Writer, fileName string, contentType string, content []byte) error {
 	header := textproto.MIMEHeader{}
 
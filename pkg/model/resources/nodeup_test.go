@@ -17,8 +17,13 @@ limitations under the License.
 package resources
 
 import (
+	"encoding/json"
 	"strings"
 	"testing"
+
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/ignition"
+	"k8s.io/kops/upup/pkg/fi"
 )
 
 func Test_NodeUpTabs(t *testing.T) {
@@ -28,3 +33,60 @@ func Test_NodeUpTabs(t *testing.T) {
 		}
 	}
 }
+
+func TestIgnitionUserData(t *testing.T) {
+	ig := &kops.InstanceGroup{
+		Spec: kops.InstanceGroupSpec{
+			Role:           kops.InstanceGroupRoleNode,
+			UserDataFormat: kops.UserDataFormatIgnition,
+		},
+	}
+
+	userData, err := IgnitionUserData("#!/bin/bash\necho bootstrap\n", ig)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var config ignition.Config
+	if err := json.Unmarshal([]byte(userData), &config); err != nil {
+		t.Fatalf("user data is not valid JSON: %v\n%s", err, userData)
+	}
+	if config.Ignition.Version != ignition.Version {
+		t.Errorf("unexpected version %q", config.Ignition.Version)
+	}
+
+	if len(config.Storage.Files) != 1 {
+		t.Fatalf("expected a single file, got %+v", config.Storage.Files)
+	}
+	f := config.Storage.Files[0]
+	if f.Path != ignitionBootstrapScriptPath || fi.IntValue(f.Mode) != 0o700 {
+		t.Errorf("unexpected file %+v", f)
+	}
+	if expected := ignition.DataURL([]byte("#!/bin/bash\necho bootstrap\n")); fi.StringValue(f.Contents.Source) != expected {
+		t.Errorf("unexpected contents %q, expected %q", fi.StringValue(f.Contents.Source), expected)
+	}
+
+	if len(config.Systemd.Units) != 1 {
+		t.Fatalf("expected a single unit, got %+v", config.Systemd.Units)
+	}
+	unit := config.Systemd.Units[0]
+	if unit.Name != ignitionBootstrapUnit || !fi.BoolValue(unit.Enabled) || !strings.Contains(fi.StringValue(unit.Contents), "ExecStart="+ignitionBootstrapScriptPath) {
+		t.Errorf("unexpected unit %+v", unit)
+	}
+}
+
+func TestIgnitionUserDataAdditionalUserData(t *testing.T) {
+	ig := &kops.InstanceGroup{
+		Spec: kops.InstanceGroupSpec{
+			Role:           kops.InstanceGroupRoleNode,
+			UserDataFormat: kops.UserDataFormatIgnition,
+			AdditionalUserData: []kops.UserData{
+				{Name: "extra.sh", Type: "text/x-shellscript", Content: "#!/bin/sh"},
+			},
+		},
+	}
+
+	if _, err := IgnitionUserData("#!/bin/bash", ig); err == nil {
+		t.Errorf("expected an error with additionalUserData")
+	}
+}
@@ -0,0 +1,207 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package ospackages resolves the OS packages that nodeup installs into bundles,
+// which are staged in the file repository so that nodes can install them without
+// contacting the repositories of their distribution.
+package ospackages
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/kops/util/pkg/architectures"
+	"k8s.io/kops/util/pkg/distributions"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// BundlePath is the path of the bundles in the file repository
+	BundlePath = "os-packages"
+	// IndexFile is the name of the index of a bundle
+	IndexFile = "index.yaml"
+	// ManifestFile is the name of the file below BundlePath that lists the hashes of the bundle indexes
+	ManifestFile = "bundles.yaml"
+)
+
+// Manifest lists the bundles in a file repository, so that their indexes can be pinned in the nodeup configuration
+type Manifest struct {
+	// Indexes are the sha256 hashes of the bundle indexes, by the key of the bundle, e.g. "jammy/amd64"
+	Indexes map[string]string `json:"indexes"`
+}
+
+// Bundle is the index of the packages bundled for a distribution and architecture
+type Bundle struct {
+	// Distribution is the id of the distribution, e.g. "jammy" or "rocky9"
+	Distribution string `json:"distribution"`
+	// Architecture is the architecture of the packages
+	Architecture architectures.Architecture `json:"architecture"`
+	// Packages are the bundled packages
+	Packages []*BundledPackage `json:"packages"`
+	// Repositories are the signed metadata of the deb repositories the packages were bundled from, which vouch
+	// for the hashes of the package files; rpm package files carry their own signatures
+	Repositories []*BundledRepository `json:"repositories,omitempty"`
+}
+
+// BundledRepository is the signed metadata of a deb repository, copied into a bundle
+type BundledRepository struct {
+	// URL is the location of the release of the repository, e.g. "https://archive.ubuntu.com/ubuntu/dists/jammy/"
+	URL string `json:"url"`
+	// Release is the InRelease file of the repository, signed by the distribution
+	Release *BundledFile `json:"release"`
+	// Indexes are the Packages indexes of the repository that list the bundled packages
+	Indexes []*BundledFile `json:"indexes"`
+}
+
+// BundledFile is a metadata file of a repository, copied into a bundle
+type BundledFile struct {
+	// Path is the path of the file, relative to the release of the repository
+	Path string `json:"path"`
+	// File is the name of the copy of the file, relative to the bundle
+	File string `json:"file"`
+	// SHA256 is the hash of the file
+	SHA256 string `json:"sha256"`
+}
+
+// BundledPackage is a package file in a bundle
+type BundledPackage struct {
+	// Name is the name of the package
+	Name string `json:"name"`
+	// Version is the version of the package
+	Version string `json:"version"`
+	// MinVersion is the lowest version that satisfies the bundled packages depending on this package.
+	// An installed dependency is only replaced if it is older; if empty, any installed version will do.
+	MinVersion string `json:"minVersion,omitempty"`
+	// Provides are the other names the package was requested by, when it was bundled for a virtual package
+	Provides []string `json:"provides,omitempty"`
+	// File is the name of the package file, relative to the bundle
+	File string `json:"file"`
+	// SHA256 is the hash of the package file
+	SHA256 string `json:"sha256"`
+	// Depends are the names of the bundled packages this package depends on
+	Depends []string `json:"depends,omitempty"`
+}
+
+// BaseURL returns the location of the bundles in the given file repository
+func BaseURL(fileRepository string) string {
+	return strings.TrimSuffix(fileRepository, "/") + "/" + BundlePath
+}
+
+// BundleKey identifies the bundle for a distribution and architecture, e.g. "jammy/amd64"
+func BundleKey(distribution distributions.Distribution, arch architectures.Architecture) string {
+	return bundleKey(distribution.ID(), arch)
+}
+
+func bundleKey(distribution string, arch architectures.Architecture) string {
+	return distribution + "/" + string(arch)
+}
+
+// BundleURL returns the location of the bundle for a distribution and architecture below base
+func BundleURL(base string, distribution distributions.Distribution, arch architectures.Architecture) string {
+	return strings.TrimSuffix(base, "/") + "/" + BundleKey(distribution, arch)
+}
+
+// ParseBundle parses the index of a bundle
+func ParseBundle(data []byte) (*Bundle, error) {
+	bundle := &Bundle{}
+	if err := yaml.Unmarshal(data, bundle); err != nil {
+		return nil, fmt.Errorf("error parsing package bundle: %v", err)
+	}
+	return bundle, nil
+}
+
+// Marshal serializes the index of a bundle
+func (b *Bundle) Marshal() ([]byte, error) {
+	return yaml.Marshal(b)
+}
+
+// Key returns the key of the bundle, as used by BundleKey
+func (b *Bundle) Key() string {
+	return bundleKey(b.Distribution, b.Architecture)
+}
+
+// ParseManifest parses the manifest of the bundles in a file repository
+func ParseManifest(data []byte) (*Manifest, error) {
+	manifest := &Manifest{}
+	if err := yaml.Unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf("error parsing package bundle manifest: %v", err)
+	}
+	if manifest.Indexes == nil {
+		manifest.Indexes = make(map[string]string)
+	}
+	return manifest, nil
+}
+
+// Marshal serializes the manifest of the bundles in a file repository
+func (m *Manifest) Marshal() ([]byte, error) {
+	return yaml.Marshal(m)
+}
+
+// Find returns the bundled package with the given name, or the package that provides it
+func (b *Bundle) Find(name string) *BundledPackage {
+	for _, p := range b.Packages {
+		if p.Name == name {
+			return p
+		}
+	}
+	for _, p := range b.Packages {
+		for _, provided := range p.Provides {
+			if provided == name {
+				return p
+			}
+		}
+	}
+	return nil
+}
+
+// Closure returns the package with the given name followed by the bundled packages it depends on,
+// directly or indirectly, sorted by name
+func (b *Bundle) Closure(name string) ([]*BundledPackage, error) {
+	p := b.Find(name)
+	if p == nil {
+		return nil, fmt.Errorf("package %q is not in the %s/%s package bundle", name, b.Distribution, b.Architecture)
+	}
+
+	seen := map[string]*BundledPackage{p.Name: p}
+	queue := []*BundledPackage{p}
+	for len(queue) > 0 {
+		next := queue[0]
+		queue = queue[1:]
+		for _, dep := range next.Depends {
+			if seen[dep] != nil {
+				continue
+			}
+			d := b.Find(dep)
+			if d == nil {
+				return nil, fmt.Errorf("package %q depends on %q, which is not in the %s/%s package bundle", next.Name, dep, b.Distribution, b.Architecture)
+			}
+			seen[dep] = d
+			queue = append(queue, d)
+		}
+	}
+
+	var deps []*BundledPackage
+	for depName, d := range seen {
+		if depName != p.Name {
+			deps = append(deps, d)
+		}
+	}
+	sort.Slice(deps, func(i, j int) bool {
+		return deps[i].Name < deps[j].Name
+	})
+	return append([]*BundledPackage{p}, deps...), nil
+}
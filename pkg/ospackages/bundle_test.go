@@ -0,0 +1,92 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ospackages
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/kops/util/pkg/architectures"
+	"k8s.io/kops/util/pkg/distributions"
+)
+
+func TestBundleClosure(t *testing.T) {
+	data := []byte(`distribution: jammy
+architecture: amd64
+packages:
+- name: conntrack
+  version: 1:1.4.6-2build2
+  file: conntrack_1.4.6-2build2_amd64.deb
+  sha256: "1111111111111111111111111111111111111111111111111111111111111111"
+  depends:
+  - libnetfilter-conntrack3
+- name: libnetfilter-conntrack3
+  version: 1.0.9-1
+  file: libnetfilter-conntrack3_1.0.9-1_amd64.deb
+  sha256: "2222222222222222222222222222222222222222222222222222222222222222"
+  depends:
+  - libnfnetlink0
+- name: libnfnetlink0
+  version: 1.0.1-3build3
+  file: libnfnetlink0_1.0.1-3build3_amd64.deb
+  sha256: "3333333333333333333333333333333333333333333333333333333333333333"
+  depends:
+  - libnetfilter-conntrack3
+- name: netcat-openbsd
+  version: 1.218-4ubuntu1
+  provides:
+  - netcat
+  file: netcat-openbsd_1.218-4ubuntu1_amd64.deb
+  sha256: "4444444444444444444444444444444444444444444444444444444444444444"
+  depends:
+  - libbsd0
+`)
+	bundle, err := ParseBundle(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	closure, err := bundle.Closure("conntrack")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var names []string
+	for _, p := range closure {
+		names = append(names, p.Name)
+	}
+	if expected := []string{"conntrack", "libnetfilter-conntrack3", "libnfnetlink0"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("unexpected closure, actual=%v, expected=%v", names, expected)
+	}
+
+	if p := bundle.Find("netcat"); p == nil || p.Name != "netcat-openbsd" {
+		t.Errorf("expected netcat to be provided by netcat-openbsd, got %v", p)
+	}
+	if _, err := bundle.Closure("netcat"); err == nil {
+		t.Errorf("expected an error for a missing dependency")
+	}
+	if _, err := bundle.Closure("socat"); err == nil {
+		t.Errorf("expected an error for a missing package")
+	}
+}
+
+func TestBundleURL(t *testing.T) {
+	base := BaseURL("https://example.com/kops/")
+	actual := BundleURL(base, distributions.DistributionRocky9, architectures.ArchitectureArm64)
+	if expected := "https://example.com/kops/os-packages/rocky9/arm64"; actual != expected {
+		t.Errorf("unexpected bundle URL, actual=%q, expected=%q", actual, expected)
+	}
+}
@@ -0,0 +1,252 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ospackages
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// parseDebPackages parses a Packages file of a deb repository, whose files are relative to mirror
+func parseDebPackages(data []byte, mirror string) ([]*repoPackage, error) {
+	var packages []*repoPackage
+
+	fields := make(map[string]string)
+	flush := func() error {
+		if len(fields) == 0 {
+			return nil
+		}
+		defer func() {
+			fields = make(map[string]string)
+		}()
+
+		name := fields["Package"]
+		if name == "" || fields["Filename"] == "" || fields["SHA256"] == "" {
+			return fmt.Errorf("incomplete entry for package %q", name)
+		}
+		p := &repoPackage{
+			name:     name,
+			version:  fields["Version"],
+			url:      strings.TrimSuffix(mirror, "/") + "/" + fields["Filename"],
+			sha256:   fields["SHA256"],
+			provides: debRelations(fields["Provides"]),
+			base:     fields["Essential"] == "yes" || fields["Priority"] == "required",
+		}
+		for _, relation := range []string{fields["Pre-Depends"], fields["Depends"]} {
+			if relation == "" {
+				continue
+			}
+			for _, dep := range strings.Split(relation, ",") {
+				p.requires = append(p.requires, debRequirements(dep))
+			}
+		}
+		packages = append(packages, p)
+		return nil
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if line[0] == ' ' || line[0] == '\t' {
+			// Continuation of a multi-line field, e.g. Description
+			continue
+		}
+		tokens := strings.SplitN(line, ":", 2)
+		if len(tokens) != 2 {
+			return nil, fmt.Errorf("error parsing line %q", line)
+		}
+		fields[tokens[0]] = strings.TrimSpace(tokens[1])
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+
+	return packages, nil
+}
+
+// debRelations returns the package names of a comma-separated list of relations,
+// dropping version constraints and architecture qualifiers, e.g. "libc6 (>= 2.34), perl:any" becomes [libc6 perl]
+func debRelations(s string) []string {
+	var names []string
+	for _, relation := range strings.Split(s, ",") {
+		name := relation
+		if i := strings.IndexAny(name, "(["); i >= 0 {
+			name = name[:i]
+		}
+		name = strings.TrimSpace(name)
+		if i := strings.Index(name, ":"); i >= 0 {
+			name = name[:i]
+		}
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// debRequirements parses the alternatives of a dependency, keeping their version constraints,
+// e.g. "libnsl2 | libnsl-compat:any (>= 1.3)" becomes [libnsl2, libnsl-compat (>= 1.3)]
+func debRequirements(s string) []requirement {
+	var requirements []requirement
+	for _, alternative := range strings.Split(s, "|") {
+		r := requirement{}
+		name := alternative
+		if i := strings.Index(name, "("); i >= 0 {
+			constraint := name[i+1:]
+			if j := strings.Index(constraint, ")"); j >= 0 {
+				constraint = constraint[:j]
+			}
+			constraint = strings.TrimSpace(constraint)
+			op := strings.TrimRightFunc(constraint, func(r rune) bool {
+				return r != '<' && r != '=' && r != '>'
+			})
+			r.version = strings.TrimSpace(strings.TrimPrefix(constraint, op))
+			switch op {
+			case "<":
+				// Deprecated spelling of <=
+				op = "<="
+			case ">":
+				// Deprecated spelling of >=
+				op = ">="
+			}
+			r.op = op
+			name = name[:i]
+		}
+		if i := strings.Index(name, "["); i >= 0 {
+			name = name[:i]
+		}
+		name = strings.TrimSpace(name)
+		if i := strings.Index(name, ":"); i >= 0 {
+			name = name[:i]
+		}
+		if name == "" {
+			continue
+		}
+		r.name = name
+		requirements = append(requirements, r)
+	}
+	return requirements
+}
+
+// compareDebVersions compares two [epoch:]upstream[-revision] strings the way dpkg does,
+// returning -1, 0 or 1 if a is older than, the same as, or newer than b
+func compareDebVersions(a, b string) int {
+	epochA, restA := splitDebEpoch(a)
+	epochB, restB := splitDebEpoch(b)
+	if epochA != epochB {
+		if epochA < epochB {
+			return -1
+		}
+		return 1
+	}
+	upstreamA, revisionA := splitRelease(restA)
+	upstreamB, revisionB := splitRelease(restB)
+	if c := compareDebSegments(upstreamA, upstreamB); c != 0 {
+		return c
+	}
+	return compareDebSegments(revisionA, revisionB)
+}
+
+func splitDebEpoch(v string) (int, string) {
+	if i := strings.Index(v, ":"); i >= 0 {
+		epoch, err := strconv.Atoi(v[:i])
+		if err == nil {
+			return epoch, v[i+1:]
+		}
+	}
+	return 0, v
+}
+
+// debOrder returns the sort weight of a character of a non-digit run: a tilde sorts before
+// the end of the run, which sorts before letters, which sort before other characters
+func debOrder(s string) int {
+	switch {
+	case s == "":
+		return 0
+	case s[0] == '~':
+		return -1
+	case unicode.IsDigit(rune(s[0])):
+		return 0
+	case unicode.IsLetter(rune(s[0])):
+		return int(s[0])
+	default:
+		return int(s[0]) + 256
+	}
+}
+
+// compareDebSegments implements the verrevcmp of dpkg: alternating runs of non-digits and digits
+// are compared, the former by character with debOrder and the latter numerically
+func compareDebSegments(a, b string) int {
+	isDigit := func(s string) bool {
+		return s != "" && unicode.IsDigit(rune(s[0]))
+	}
+
+	for a != "" || b != "" {
+		for (a != "" && !isDigit(a)) || (b != "" && !isDigit(b)) {
+			orderA, orderB := debOrder(a), debOrder(b)
+			if orderA != orderB {
+				if orderA < orderB {
+					return -1
+				}
+				return 1
+			}
+			if a != "" {
+				a = a[1:]
+			}
+			if b != "" {
+				b = b[1:]
+			}
+		}
+
+		a = strings.TrimLeft(a, "0")
+		b = strings.TrimLeft(b, "0")
+		firstDiff := 0
+		for isDigit(a) && isDigit(b) {
+			if firstDiff == 0 {
+				firstDiff = int(a[0]) - int(b[0])
+			}
+			a, b = a[1:], b[1:]
+		}
+		if isDigit(a) {
+			return 1
+		}
+		if isDigit(b) {
+			return -1
+		}
+		if firstDiff != 0 {
+			if firstDiff < 0 {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,270 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ospackages
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"k8s.io/klog/v2"
+	"k8s.io/kops/util/pkg/distributions"
+)
+
+// repoPackage is a package listed in the metadata of a repository
+type repoPackage struct {
+	name    string
+	version string
+	// url is the location of the package file
+	url    string
+	sha256 string
+	// provides are the capabilities of the package besides its name, including its files for rpms
+	provides []string
+	// requires are the dependencies of the package; each one lists alternatives that satisfy it
+	requires [][]requirement
+	// base is true for packages that are installed on every system; they are only bundled
+	// when a dependency requires a version that the system may not have
+	base bool
+	// index is the Packages index listing a deb package, which is bundled to vouch for its hash
+	index *debIndex
+}
+
+// requirement is a dependency on a package, optionally restricted to a range of versions
+type requirement struct {
+	name string
+	// op is the version relation, one of "<<", "<=", "=", ">=" or ">>", or empty if any version satisfies the requirement
+	op      string
+	version string
+}
+
+func (r requirement) String() string {
+	if r.op == "" {
+		return r.name
+	}
+	return fmt.Sprintf("%s (%s %s)", r.name, r.op, r.version)
+}
+
+// packageIndex holds the packages of the repositories of a distribution
+type packageIndex struct {
+	format    string
+	packages  map[string]*repoPackage
+	providers map[string][]*repoPackage
+}
+
+func newPackageIndex(format string) *packageIndex {
+	return &packageIndex{
+		format:   format,
+		packages: make(map[string]*repoPackage),
+	}
+}
+
+// add adds a package; for debs it replaces a package with the same name, for rpms the newest version is kept
+func (x *packageIndex) add(p *repoPackage) {
+	existing := x.packages[p.name]
+	if existing != nil && x.format == formatRpm && compareRPMVersions(existing.version, p.version) >= 0 {
+		return
+	}
+	x.packages[p.name] = p
+	x.providers = nil
+}
+
+// lookup returns the package with the given name, or the first package by name providing it
+func (x *packageIndex) lookup(name string) *repoPackage {
+	if p := x.packages[name]; p != nil {
+		return p
+	}
+
+	if x.providers == nil {
+		x.providers = make(map[string][]*repoPackage)
+		for _, p := range x.packages {
+			for _, provided := range p.provides {
+				x.providers[provided] = append(x.providers[provided], p)
+			}
+		}
+		for _, providers := range x.providers {
+			sort.Slice(providers, func(i, j int) bool {
+				return providers[i].name < providers[j].name
+			})
+		}
+	}
+	if providers := x.providers[name]; len(providers) > 0 {
+		return providers[0]
+	}
+	return nil
+}
+
+// resolve returns the bundle of the named packages and the packages they depend on. Base packages are only
+// bundled when a version is required of them, as the version installed on the system may be too old.
+func (x *packageIndex) resolve(names []string) ([]*BundledPackage, map[string]*repoPackage, error) {
+	selected := make(map[string]*repoPackage)
+	depends := make(map[string]map[string]bool)
+	provides := make(map[string][]string)
+	minVersions := make(map[string]string)
+
+	var queue []*repoPackage
+	for _, name := range names {
+		p := x.lookup(name)
+		if p == nil {
+			return nil, nil, fmt.Errorf("package %q was not found", name)
+		}
+		if p.name != name {
+			provides[p.name] = append(provides[p.name], name)
+		}
+		if selected[p.name] == nil {
+			selected[p.name] = p
+			queue = append(queue, p)
+		}
+	}
+
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		depends[p.name] = make(map[string]bool)
+
+		for _, alternatives := range p.requires {
+			dep, r := x.satisfy(p, alternatives, selected)
+			if dep == nil || dep == p {
+				continue
+			}
+			if dep.base && r.op == "" {
+				// Installed on every system, and any version will do
+				continue
+			}
+			depends[p.name][dep.name] = true
+			if minVersion := x.minVersion(dep, r); minVersion != "" {
+				if existing := minVersions[dep.name]; existing == "" || x.compare(minVersion, existing) > 0 {
+					minVersions[dep.name] = minVersion
+				}
+			}
+			if selected[dep.name] == nil {
+				selected[dep.name] = dep
+				queue = append(queue, dep)
+			}
+		}
+	}
+
+	var bundled []*BundledPackage
+	for name, p := range selected {
+		b := &BundledPackage{
+			Name:       name,
+			Version:    p.version,
+			MinVersion: minVersions[name],
+			Provides:   provides[name],
+			File:       path.Base(p.url),
+			SHA256:     p.sha256,
+		}
+		for dep := range depends[name] {
+			b.Depends = append(b.Depends, dep)
+		}
+		sort.Strings(b.Depends)
+		bundled = append(bundled, b)
+	}
+	sort.Slice(bundled, func(i, j int) bool {
+		return bundled[i].Name < bundled[j].Name
+	})
+	return bundled, selected, nil
+}
+
+// satisfy returns the package that satisfies one of the alternatives of a dependency of p, along with the alternative,
+// preferring packages that are already selected, or installed on every system when any version will do
+func (x *packageIndex) satisfy(p *repoPackage, alternatives []requirement, selected map[string]*repoPackage) (*repoPackage, requirement) {
+	var candidates []*repoPackage
+	var candidateRequirements []requirement
+	for _, alternative := range alternatives {
+		dep := x.lookup(alternative.name)
+		if dep == nil || !x.satisfies(dep, alternative) {
+			continue
+		}
+		if dep == p || selected[dep.name] == dep || (dep.base && alternative.op == "") {
+			return dep, alternative
+		}
+		candidates = append(candidates, dep)
+		candidateRequirements = append(candidateRequirements, alternative)
+	}
+	if len(candidates) == 0 {
+		klog.Warningf("dependency %v of package %q was not found; assuming it is installed", alternatives, p.name)
+		return nil, requirement{}
+	}
+	return candidates[0], candidateRequirements[0]
+}
+
+// satisfies returns true if the version of p satisfies the requirement.
+// Packages that provide the required name are assumed to provide a suitable version.
+func (x *packageIndex) satisfies(p *repoPackage, r requirement) bool {
+	if r.op == "" || p.name != r.name {
+		return true
+	}
+
+	version := p.version
+	if x.format == formatRpm {
+		// A required rpm version without a release matches every release of the version
+		if _, required := splitEpoch(r.version); !strings.Contains(required, "-") {
+			epoch, rest := splitEpoch(version)
+			v, _ := splitRelease(rest)
+			version = epoch + ":" + v
+		}
+	}
+
+	c := x.compare(version, r.version)
+	switch r.op {
+	case "<<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case "=":
+		return c == 0
+	case ">=":
+		return c >= 0
+	case ">>":
+		return c > 0
+	default:
+		klog.Warningf("unknown version relation in %v", r)
+		return true
+	}
+}
+
+// minVersion returns the lowest version of p that satisfies the requirement, or an empty string if any version does.
+// When the lowest version is not known, that is the version in the index.
+func (x *packageIndex) minVersion(p *repoPackage, r requirement) string {
+	switch r.op {
+	case "":
+		return ""
+	case ">=", "=":
+		if p.name == r.name {
+			return r.version
+		}
+	}
+	return p.version
+}
+
+// compare compares two versions of a package in the index
+func (x *packageIndex) compare(a, b string) int {
+	if x.format == formatRpm {
+		return compareRPMVersions(a, b)
+	}
+	return compareDebVersions(a, b)
+}
+
+// CompareVersions compares two versions of a package of the distribution,
+// returning -1, 0 or 1 if a is older than, the same as, or newer than b
+func CompareVersions(d distributions.Distribution, a, b string) int {
+	if d.IsDebianFamily() {
+		return compareDebVersions(a, b)
+	}
+	return compareRPMVersions(a, b)
+}
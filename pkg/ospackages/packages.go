@@ -0,0 +1,247 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ospackages
+
+import (
+	"k8s.io/kops/util/pkg/distributions"
+)
+
+// Component is a nodeup model builder that installs OS packages
+type Component string
+
+const (
+	// ComponentRequired are the packages needed by the kubelet and the container runtime
+	ComponentRequired Component = "required"
+	// ComponentMiscUtils are miscellaneous utilities
+	ComponentMiscUtils Component = "miscutils"
+	// ComponentNTP is the NTP daemon
+	ComponentNTP Component = "ntp"
+	// ComponentLogrotate is logrotate
+	ComponentLogrotate Component = "logrotate"
+	// ComponentUpdateService installs the OS updates automatically
+	ComponentUpdateService Component = "update-service"
+	// ComponentCalico are the packages needed by Calico
+	ComponentCalico Component = "calico"
+	// ComponentNvidia are the NVIDIA container runtime and driver
+	ComponentNvidia Component = "nvidia"
+)
+
+// Components are all the components that install OS packages
+var Components = []Component{
+	ComponentRequired,
+	ComponentMiscUtils,
+	ComponentNTP,
+	ComponentLogrotate,
+	ComponentUpdateService,
+	ComponentCalico,
+	ComponentNvidia,
+}
+
+const (
+	// NvidiaKeyring is the key that signs the NVIDIA container runtime repositories
+	NvidiaKeyring = "https://nvidia.github.io/nvidia-container-runtime/gpgkey"
+	// nvidiaContainerRuntimePackage is the package of the NVIDIA container runtime
+	nvidiaContainerRuntimePackage = "nvidia-container-runtime"
+)
+
+// NvidiaSources are the apt sources of the NVIDIA container runtime; $(ARCH) is replaced by the architecture
+var NvidiaSources = []string{
+	"deb https://nvidia.github.io/nvidia-container-runtime/stable/ubuntu18.04/$(ARCH) /",
+	"deb https://nvidia.github.io/libnvidia-container/stable/ubuntu18.04/$(ARCH) /",
+	"deb https://nvidia.github.io/nvidia-docker/ubuntu18.04/$(ARCH) /",
+}
+
+// Node holds the settings of a node that determine the OS packages nodeup installs on it.
+// The nodeup model builders install the packages it lists, and the bundles are resolved from it,
+// so that a bundle holds every package nodeup installs.
+type Node struct {
+	Distribution distributions.Distribution
+	// KubernetesLT120 is true if the node runs a Kubernetes version older than 1.20
+	KubernetesLT120 bool
+	// OnGCE is true if the node runs on GCE
+	OnGCE bool
+	// ManagedNTP is true if kOps configures NTP on the node
+	ManagedNTP bool
+	// AutomaticUpdates is true if the node installs the OS updates itself, rather than an external process
+	AutomaticUpdates bool
+	// Calico is true if the cluster uses Calico networking
+	Calico bool
+	// NvidiaDriverPackage is the NVIDIA driver package, set if the node installs the NVIDIA container runtime
+	NvidiaDriverPackage string
+}
+
+// Packages returns the OS packages that a component installs on the node
+func (n *Node) Packages(component Component) []string {
+	d := n.Distribution
+	if d == distributions.DistributionContainerOS || d == distributions.DistributionFlatcar {
+		// These distributions do not use a package manager
+		return nil
+	}
+
+	switch component {
+	case ComponentRequired:
+		return n.required()
+
+	case ComponentMiscUtils:
+		return n.miscUtils()
+
+	case ComponentNTP:
+		if !n.ManagedNTP {
+			return nil
+		}
+		if !n.OnGCE && d.IsUbuntu() && d.Version() <= 20.04 {
+			// systemd-timesyncd is used instead
+			return nil
+		}
+		if d.IsDebianFamily() || d.IsRHELFamily() || d.IsSUSEFamily() {
+			return []string{"chrony"}
+		}
+
+	case ComponentLogrotate:
+		return []string{"logrotate"}
+
+	case ComponentUpdateService:
+		if d.IsDebianFamily() && n.AutomaticUpdates {
+			return []string{"unattended-upgrades"}
+		}
+
+	case ComponentCalico:
+		if n.Calico && d.IsUbuntu() {
+			return []string{"wireguard"}
+		}
+
+	case ComponentNvidia:
+		if n.NvidiaDriverPackage != "" && d.IsUbuntu() {
+			return []string{nvidiaContainerRuntimePackage, n.NvidiaDriverPackage}
+		}
+	}
+
+	return nil
+}
+
+// All returns the OS packages that all the components install on the node
+func (n *Node) All() []string {
+	var packages []string
+	for _, component := range Components {
+		packages = append(packages, n.Packages(component)...)
+	}
+	return packages
+}
+
+// required returns the packages needed by the kubelet and the container runtime.
+// The kubelet needs conntrack (kops #5671), ebtables (kops #1711) and ethtool (kops #1830).
+func (n *Node) required() []string {
+	d := n.Distribution
+	switch {
+	case d.IsDebianFamily():
+		// From containerd: https://github.com/containerd/cri/blob/master/contrib/ansible/tasks/bootstrap_ubuntu.yaml
+		return []string{
+			"bridge-utils",
+			"cgroupfs-mount",
+			"conntrack",
+			"ebtables",
+			"ethtool",
+			"iptables",
+			"libapparmor1",
+			"libseccomp2",
+			"libltdl7",
+			"pigz",
+			"socat",
+			"util-linux",
+		}
+
+	case d.IsRHELFamily():
+		// From containerd: https://github.com/containerd/cri/blob/master/contrib/ansible/tasks/bootstrap_centos.yaml
+		packages := []string{
+			"conntrack-tools",
+			"ethtool",
+			"iptables",
+			"libseccomp",
+			"libtool-ltdl",
+			"socat",
+			"util-linux",
+		}
+		switch d {
+		case distributions.DistributionAmazonLinux2023, distributions.DistributionRhel9, distributions.DistributionRocky9:
+			// ebtables and libcgroup are no longer available
+		default:
+			packages = append(packages, "ebtables", "libcgroup")
+		}
+		switch d {
+		case distributions.DistributionAmazonLinux2:
+			// Amazon Linux 2 doesn't have SELinux enabled by default
+		default:
+			packages = append(packages, "container-selinux", "pigz")
+		}
+		return packages
+
+	case d.IsSUSEFamily():
+		// SLES uses AppArmor rather than SELinux, so container-selinux is not needed
+		return []string{
+			"conntrack-tools",
+			"ebtables",
+			"ethtool",
+			"iptables",
+			"libseccomp2",
+			"libltdl7",
+			"socat",
+			"util-linux",
+		}
+	}
+	return nil
+}
+
+// miscUtils returns miscellaneous utilities that have been installed for a long time
+func (n *Node) miscUtils() []string {
+	d := n.Distribution
+
+	var packages []string
+	switch {
+	case d.IsDebianFamily():
+		if n.KubernetesLT120 {
+			packages = append(packages, "curl", "wget", "perl", "apt-transport-https")
+
+			// TODO: Do we really need python-apt?
+			if (d.IsUbuntu() && d.Version() >= 20.10) || (!d.IsUbuntu() && d.Version() >= 11) {
+				// python-apt not available (though python3-apt is)
+			} else {
+				packages = append(packages, "python-apt")
+			}
+		}
+
+	case d.IsRHELFamily():
+		// TODO: These packages have been auto-installed for a long time, and likely we don't need all of them any longer
+		switch d {
+		case distributions.DistributionAmazonLinux2023:
+			// Amazon Linux 2023 ships curl-minimal, which conflicts with curl, and no longer ships python2
+		case distributions.DistributionRhel9, distributions.DistributionRocky9:
+			// RHEL 9 no longer ships python2
+			packages = append(packages, "curl")
+		default:
+			packages = append(packages, "curl", "python2")
+		}
+		packages = append(packages, "wget", "git")
+
+	case d.IsSUSEFamily():
+		packages = append(packages, "curl", "wget", "git")
+	}
+
+	if d.IsUbuntu() && n.KubernetesLT120 {
+		packages = append(packages, "netcat-traditional", "git")
+	}
+	return packages
+}
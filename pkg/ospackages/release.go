@@ -0,0 +1,154 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ospackages
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// parseReleaseHashes returns the sha256 hashes of the files listed by a deb Release or InRelease file,
+// by their path relative to the release. The signature of an InRelease file is not checked.
+func parseReleaseHashes(data []byte) (map[string]string, error) {
+	hashes := make(map[string]string)
+	inSection := false
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		if line == "-----BEGIN PGP SIGNATURE-----" {
+			break
+		}
+		if strings.HasPrefix(line, " ") {
+			if !inSection {
+				continue
+			}
+			fields := strings.Fields(line)
+			if len(fields) != 3 {
+				return nil, fmt.Errorf("error parsing release line %q", line)
+			}
+			hashes[fields[2]] = strings.ToLower(fields[0])
+			continue
+		}
+		inSection = strings.HasPrefix(line, "SHA256:")
+	}
+	if len(hashes) == 0 {
+		return nil, fmt.Errorf("release does not list any SHA256 hashes")
+	}
+	return hashes, nil
+}
+
+// checkReleaseHash checks that the file at path in a release has the hash the release lists for it
+func checkReleaseHash(hashes map[string]string, path string, data []byte) error {
+	expected, found := hashes[path]
+	if !found {
+		return fmt.Errorf("%q is not listed by the release", path)
+	}
+	if actual := sha256Hex(data); actual != expected {
+		return fmt.Errorf("the hash of %q is %s, but the release lists %s", path, actual, expected)
+	}
+	return nil
+}
+
+func sha256Hex(data []byte) string {
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:])
+}
+
+// decompress decompresses a file compressed with gzip or zstd, according to the extension of its name
+func decompress(name string, data []byte) ([]byte, error) {
+	var reader io.Reader
+	switch {
+	case strings.HasSuffix(name, ".gz"):
+		gz, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		reader = gz
+	case strings.HasSuffix(name, ".zst"):
+		zr, err := zstd.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		reader = zr
+	default:
+		return data, nil
+	}
+	return io.ReadAll(reader)
+}
+
+// VerifyRepositories checks that every package file of a deb bundle is vouched for by the signed metadata of the
+// repositories it was bundled from: the hash of the file must be listed by a bundled Packages index, whose own hash
+// is listed by the release file of its repository. read returns the content of a file in the bundle, and
+// verifySignature returns the signed content of a release file once its signature is verified.
+// The Valid-Until field of the releases is not checked, as a bundle is a snapshot of the repositories.
+func (b *Bundle) VerifyRepositories(read func(file *BundledFile) ([]byte, error), verifySignature func(release []byte) ([]byte, error)) error {
+	if len(b.Repositories) == 0 {
+		return fmt.Errorf("the %s/%s package bundle has no signed repository metadata; bundle the packages again", b.Distribution, b.Architecture)
+	}
+
+	verified := make(map[string]bool)
+	for _, repository := range b.Repositories {
+		data, err := read(repository.Release)
+		if err != nil {
+			return err
+		}
+		release, err := verifySignature(data)
+		if err != nil {
+			return fmt.Errorf("error verifying the signature of the release %q: %v", repository.URL, err)
+		}
+		hashes, err := parseReleaseHashes(release)
+		if err != nil {
+			return fmt.Errorf("error parsing the release %q: %v", repository.URL, err)
+		}
+
+		for _, index := range repository.Indexes {
+			data, err := read(index)
+			if err != nil {
+				return err
+			}
+			if err := checkReleaseHash(hashes, index.Path, data); err != nil {
+				return fmt.Errorf("error verifying the index %q of %q: %v", index.Path, repository.URL, err)
+			}
+			data, err = decompress(index.Path, data)
+			if err != nil {
+				return fmt.Errorf("error decompressing the index %q of %q: %v", index.Path, repository.URL, err)
+			}
+			packages, err := parseDebPackages(data, repository.URL)
+			if err != nil {
+				return fmt.Errorf("error parsing the index %q of %q: %v", index.Path, repository.URL, err)
+			}
+			for _, p := range packages {
+				verified[strings.ToLower(p.sha256)] = true
+			}
+		}
+	}
+
+	for _, p := range b.Packages {
+		if !verified[strings.ToLower(p.SHA256)] {
+			return fmt.Errorf("package file %q is not listed by the signed repository metadata of the %s/%s package bundle", p.File, b.Distribution, b.Architecture)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ospackages
+
+import (
+	"fmt"
+	"testing"
+
+	"k8s.io/kops/util/pkg/architectures"
+	"k8s.io/kops/util/pkg/distributions"
+)
+
+func TestParseReleaseHashes(t *testing.T) {
+	release := `Origin: Ubuntu
+Suite: jammy
+MD5Sum:
+ 0123456789abcdef0123456789abcdef 1024 main/binary-amd64/Packages.gz
+SHA256:
+ 1111111111111111111111111111111111111111111111111111111111111111 1024 main/binary-amd64/Packages.gz
+ 2222222222222222222222222222222222222222222222222222222222222222 4096 main/binary-amd64/Packages
+Acquire-By-Hash: yes
+`
+	hashes, err := parseReleaseHashes([]byte(release))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(hashes) != 2 || hashes["main/binary-amd64/Packages.gz"] != "1111111111111111111111111111111111111111111111111111111111111111" {
+		t.Errorf("unexpected hashes %v", hashes)
+	}
+
+	if _, err := parseReleaseHashes([]byte("Origin: Ubuntu\n")); err == nil {
+		t.Errorf("expected an error for a release without hashes")
+	}
+}
+
+func TestVerifyRepositories(t *testing.T) {
+	main := `Package: socat
+Version: 1.7.4.1-3ubuntu4
+Architecture: amd64
+Filename: pool/main/s/socat/socat_1.7.4.1-3ubuntu4_amd64.deb
+SHA256: 1111111111111111111111111111111111111111111111111111111111111111
+`
+	files := map[string][]byte{}
+	addJammyRepositories(t, files, map[string]string{"jammy/main": main})
+	r := &Resolver{fetch: fakeFetch(files)}
+	bundle, sources, err := r.Resolve(distributions.DistributionUbuntu2204, architectures.ArchitectureAmd64, []string{"socat"}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The bundled copies are read from where they were bundled from
+	read := func(file *BundledFile) ([]byte, error) {
+		if data, found := files[sources[file.File]]; found {
+			return data, nil
+		}
+		return nil, fmt.Errorf("not found: %s", file.File)
+	}
+	signed := func(release []byte) ([]byte, error) {
+		return release, nil
+	}
+	if err := bundle.VerifyRepositories(read, signed); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	unsigned := func(release []byte) ([]byte, error) {
+		return nil, fmt.Errorf("BAD signature")
+	}
+	if err := bundle.VerifyRepositories(read, unsigned); err == nil {
+		t.Errorf("expected an error for a release with a bad signature")
+	}
+
+	// A package file must be listed by a verified index
+	bundle.Packages[0].SHA256 = "3333333333333333333333333333333333333333333333333333333333333333"
+	if err := bundle.VerifyRepositories(read, signed); err == nil {
+		t.Errorf("expected an error for a package that is not listed by the repository metadata")
+	}
+	bundle.Packages[0].SHA256 = "1111111111111111111111111111111111111111111111111111111111111111"
+
+	// An index must be listed by the verified release
+	jammy := jammyMirror + "/dists/jammy/"
+	files[jammy+"main/binary-amd64/Packages.gz"] = gzipped(t, main+"\n")
+	if err := bundle.VerifyRepositories(read, signed); err == nil {
+		t.Errorf("expected an error for an index that does not match its release")
+	}
+
+	bundle.Repositories = nil
+	if err := bundle.VerifyRepositories(read, signed); err == nil {
+		t.Errorf("expected an error for a bundle without repository metadata")
+	}
+}
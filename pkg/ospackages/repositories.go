@@ -0,0 +1,144 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ospackages
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/kops/util/pkg/architectures"
+	"k8s.io/kops/util/pkg/distributions"
+)
+
+const (
+	formatDeb = "deb"
+	formatRpm = "rpm"
+)
+
+// repository is a public package repository of a distribution
+type repository struct {
+	// format is the format of the repository metadata, deb or rpm
+	format string
+	// url is the base URL of the repository
+	url string
+	// mirrorList is true if url is a list of mirrors of the repository, rather than the repository itself
+	mirrorList bool
+	// suite is the debian distribution of a deb repository, e.g. "jammy-updates",
+	// or the directory of a flat deb repository, e.g. "/"
+	suite string
+	// components are the components of a deb repository, e.g. "main"; a flat repository has none
+	components []string
+}
+
+// parseAptSource parses a one-line apt source, such as "deb https://example.com/ubuntu jammy main"
+// or "deb https://example.com/$(ARCH) /", in which $(ARCH) is replaced by the architecture
+func parseAptSource(source string, arch architectures.Architecture) (repository, error) {
+	fields := strings.Fields(strings.ReplaceAll(source, "$(ARCH)", string(arch)))
+	if len(fields) < 3 || fields[0] != "deb" {
+		return repository{}, fmt.Errorf("unsupported apt source %q", source)
+	}
+	r := repository{
+		format:     formatDeb,
+		url:        fields[1],
+		suite:      fields[2],
+		components: fields[3:],
+	}
+	if len(r.components) == 0 && !strings.HasSuffix(r.suite, "/") {
+		return repository{}, fmt.Errorf("apt source %q has no components", source)
+	}
+	return r, nil
+}
+
+// repositoriesFor returns the repositories that hold the packages of a distribution.
+// Packages in later deb repositories replace those with the same name in earlier ones.
+func repositoriesFor(d distributions.Distribution, arch architectures.Architecture) ([]repository, error) {
+	var rpmArch string
+	switch arch {
+	case architectures.ArchitectureAmd64:
+		rpmArch = "x86_64"
+	case architectures.ArchitectureArm64:
+		rpmArch = "aarch64"
+	default:
+		return nil, fmt.Errorf("unsupported architecture %q", arch)
+	}
+
+	switch {
+	case d.IsUbuntu():
+		mirror := "https://archive.ubuntu.com/ubuntu"
+		if arch != architectures.ArchitectureAmd64 {
+			mirror = "https://ports.ubuntu.com/ubuntu-ports"
+		}
+		var repositories []repository
+		for _, suffix := range []string{"", "-updates", "-security"} {
+			repositories = append(repositories, repository{
+				format:     formatDeb,
+				url:        mirror,
+				suite:      d.ID() + suffix,
+				components: []string{"main", "restricted", "universe"},
+			})
+		}
+		return repositories, nil
+
+	case d.IsDebianFamily():
+		securitySuite := d.ID() + "-security"
+		if d == distributions.DistributionDebian10 {
+			securitySuite = d.ID() + "/updates"
+		}
+		return []repository{
+			{format: formatDeb, url: "https://deb.debian.org/debian", suite: d.ID(), components: []string{"main"}},
+			{format: formatDeb, url: "https://deb.debian.org/debian", suite: d.ID() + "-updates", components: []string{"main"}},
+			{format: formatDeb, url: "https://deb.debian.org/debian-security", suite: securitySuite, components: []string{"main"}},
+		}, nil
+
+	case d == distributions.DistributionRocky8 || d == distributions.DistributionRocky9:
+		version := strings.TrimPrefix(d.ID(), "rocky")
+		var repositories []repository
+		for _, repo := range []string{"BaseOS", "AppStream"} {
+			repositories = append(repositories, repository{
+				format: formatRpm,
+				url:    "https://dl.rockylinux.org/pub/rocky/" + version + "/" + repo + "/" + rpmArch + "/os",
+			})
+		}
+		return repositories, nil
+
+	case d == distributions.DistributionAmazonLinux2:
+		return []repository{
+			{format: formatRpm, url: "https://amazonlinux-2-repos-us-east-1.s3.dualstack.us-east-1.amazonaws.com/2/core/latest/" + rpmArch + "/mirror.list", mirrorList: true},
+		}, nil
+
+	case d == distributions.DistributionAmazonLinux2023:
+		return []repository{
+			{format: formatRpm, url: "https://cdn.amazonlinux.com/al2023/core/mirrors/latest/" + rpmArch + "/mirror.list", mirrorList: true},
+		}, nil
+
+	case d.IsRHELFamily() || d.IsSUSEFamily():
+		return nil, fmt.Errorf("the repositories of %s require a subscription; package bundles are not supported", d.ID())
+
+	default:
+		return nil, fmt.Errorf("%s does not use OS packages", d.ID())
+	}
+}
+
+// rpmArchitectures returns the package architectures that can be installed on arch
+func rpmArchitectures(arch architectures.Architecture) []string {
+	switch arch {
+	case architectures.ArchitectureArm64:
+		return []string{"aarch64", "noarch"}
+	default:
+		return []string{"x86_64", "noarch"}
+	}
+}
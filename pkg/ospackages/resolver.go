@@ -0,0 +1,272 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ospackages
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/klog/v2"
+	"k8s.io/kops/util/pkg/architectures"
+	"k8s.io/kops/util/pkg/distributions"
+	"k8s.io/kops/util/pkg/vfs"
+)
+
+// Resolver resolves packages and their dependencies using the metadata of the public repositories of a distribution
+type Resolver struct {
+	// fetch reads the file at a URL
+	fetch func(url string) ([]byte, error)
+}
+
+// NewResolver returns a Resolver that reads repository metadata over HTTP
+func NewResolver() *Resolver {
+	return &Resolver{
+		fetch: func(url string) ([]byte, error) {
+			return vfs.Context.ReadFile(url)
+		},
+	}
+}
+
+// Resolve returns the bundle holding the named packages and their dependencies, except those installed on
+// every system, along with the location each bundled file is downloaded from.
+// The packages are looked up in the repositories of the distribution, followed by the additional apt sources.
+func (r *Resolver) Resolve(d distributions.Distribution, arch architectures.Architecture, names []string, aptSources []string) (*Bundle, map[string]string, error) {
+	repositories, err := repositoriesFor(d, arch)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, source := range aptSources {
+		if repositories[0].format != formatDeb {
+			return nil, nil, fmt.Errorf("apt source %q cannot be used with %s", source, d.ID())
+		}
+		repository, err := parseAptSource(source, arch)
+		if err != nil {
+			return nil, nil, err
+		}
+		repositories = append(repositories, repository)
+	}
+
+	index := newPackageIndex(repositories[0].format)
+	for _, repository := range repositories {
+		packages, err := r.load(repository, arch)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, p := range packages {
+			index.add(p)
+		}
+	}
+
+	bundled, selected, err := index.resolve(names)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error resolving packages for %s/%s: %v", d.ID(), arch, err)
+	}
+
+	sources := make(map[string]string)
+	for _, p := range bundled {
+		if existing, found := sources[p.File]; found {
+			return nil, nil, fmt.Errorf("packages from %q and %q have the same file name", existing, selected[p.Name].url)
+		}
+		sources[p.File] = selected[p.Name].url
+	}
+
+	// The metadata of the deb repositories is bundled as well, so that nodes can verify its signature
+	var releases []*debRelease
+	indexes := make(map[*debRelease][]*debIndex)
+	for _, p := range bundled {
+		index := selected[p.Name].index
+		if index == nil {
+			continue
+		}
+		if _, found := indexes[index.release]; !found {
+			releases = append(releases, index.release)
+		}
+		if !containsIndex(indexes[index.release], index) {
+			indexes[index.release] = append(indexes[index.release], index)
+		}
+	}
+	var bundledRepositories []*BundledRepository
+	for i, release := range releases {
+		dir := fmt.Sprintf("repositories/%d/", i)
+		repo := &BundledRepository{
+			URL:     release.url,
+			Release: &BundledFile{Path: releaseFile, File: dir + releaseFile, SHA256: release.sha256},
+		}
+		sources[repo.Release.File] = release.url + releaseFile
+		for _, index := range indexes[release] {
+			file := &BundledFile{Path: index.path, File: dir + index.path, SHA256: index.sha256}
+			repo.Indexes = append(repo.Indexes, file)
+			sources[file.File] = release.url + index.path
+		}
+		bundledRepositories = append(bundledRepositories, repo)
+	}
+
+	bundle := &Bundle{
+		Distribution: d.ID(),
+		Architecture: arch,
+		Packages:     bundled,
+		Repositories: bundledRepositories,
+	}
+	return bundle, sources, nil
+}
+
+// releaseFile is the name of the signed release file of a deb repository
+const releaseFile = "InRelease"
+
+// debRelease is the release file of a deb repository
+type debRelease struct {
+	// url is the location of the directory holding the release file
+	url    string
+	sha256 string
+}
+
+// debIndex is a Packages index of a deb repository
+type debIndex struct {
+	release *debRelease
+	// path is the path of the index, relative to the release
+	path   string
+	sha256 string
+}
+
+func containsIndex(indexes []*debIndex, index *debIndex) bool {
+	for _, i := range indexes {
+		if i == index {
+			return true
+		}
+	}
+	return false
+}
+
+// load reads the packages listed by a repository
+func (r *Resolver) load(repository repository, arch architectures.Architecture) ([]*repoPackage, error) {
+	baseURL := repository.url
+	if repository.mirrorList {
+		data, err := r.fetch(repository.url)
+		if err != nil {
+			return nil, fmt.Errorf("error reading mirror list %q: %v", repository.url, err)
+		}
+		baseURL = ""
+		for _, line := range strings.Split(string(data), "\n") {
+			if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+				baseURL = line
+				break
+			}
+		}
+		if baseURL == "" {
+			return nil, fmt.Errorf("mirror list %q is empty", repository.url)
+		}
+	}
+	baseURL = strings.TrimSuffix(baseURL, "/")
+
+	// The package hashes are only as trustworthy as the metadata listing them
+	if !strings.HasPrefix(baseURL, "https://") {
+		return nil, fmt.Errorf("repository %q is not served over https", baseURL)
+	}
+
+	switch repository.format {
+	case formatDeb:
+		// The release file lists the hashes of the indexes; nodes verify its signature before installing the bundle
+		var releaseURL string
+		var paths []string
+		if len(repository.components) == 0 {
+			// A flat repository has its release and lists its packages in the directory named by the suite
+			releaseURL = baseURL + "/" + strings.TrimPrefix(repository.suite, "/")
+			paths = []string{"Packages"}
+		} else {
+			releaseURL = baseURL + "/dists/" + repository.suite + "/"
+			for _, component := range repository.components {
+				paths = append(paths, component+"/binary-"+string(arch)+"/Packages.gz")
+			}
+		}
+
+		u := releaseURL + releaseFile
+		data, err := r.fetch(u)
+		if err != nil {
+			return nil, fmt.Errorf("error reading %q: %v", u, err)
+		}
+		hashes, err := parseReleaseHashes(data)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing %q: %v", u, err)
+		}
+		release := &debRelease{url: releaseURL, sha256: sha256Hex(data)}
+
+		var packages []*repoPackage
+		for _, path := range paths {
+			u := releaseURL + path
+			data, err := r.fetch(u)
+			if err != nil {
+				return nil, fmt.Errorf("error reading %q: %v", u, err)
+			}
+			if err := checkReleaseHash(hashes, path, data); err != nil {
+				return nil, fmt.Errorf("error verifying %q: %v", u, err)
+			}
+			index := &debIndex{release: release, path: path, sha256: sha256Hex(data)}
+			data, err = decompress(path, data)
+			if err != nil {
+				return nil, fmt.Errorf("error decompressing %q: %v", u, err)
+			}
+			parsed, err := parseDebPackages(data, baseURL)
+			if err != nil {
+				return nil, fmt.Errorf("error parsing %q: %v", u, err)
+			}
+			for _, p := range parsed {
+				p.index = index
+			}
+			klog.V(2).Infof("found %d packages in %q", len(parsed), u)
+			packages = append(packages, parsed...)
+		}
+		return packages, nil
+
+	case formatRpm:
+		u := baseURL + "/repodata/repomd.xml"
+		data, err := r.fetch(u)
+		if err != nil {
+			return nil, fmt.Errorf("error reading %q: %v", u, err)
+		}
+		location, err := primaryLocation(data)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing %q: %v", u, err)
+		}
+		u = baseURL + "/" + location
+		data, err = r.fetchDecompressed(u)
+		if err != nil {
+			return nil, err
+		}
+		packages, err := parseRPMPrimary(data, baseURL, rpmArchitectures(arch))
+		if err != nil {
+			return nil, fmt.Errorf("error parsing %q: %v", u, err)
+		}
+		klog.V(2).Infof("found %d packages in %q", len(packages), u)
+		return packages, nil
+
+	default:
+		return nil, fmt.Errorf("unknown repository format %q", repository.format)
+	}
+}
+
+// fetchDecompressed reads a file compressed with gzip or zstd, according to its extension
+func (r *Resolver) fetchDecompressed(u string) ([]byte, error) {
+	data, err := r.fetch(u)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %q: %v", u, err)
+	}
+	decompressed, err := decompress(u, data)
+	if err != nil {
+		return nil, fmt.Errorf("error decompressing %q: %v", u, err)
+	}
+	return decompressed, nil
+}
@@ -0,0 +1,439 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ospackages
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/kops/util/pkg/architectures"
+	"k8s.io/kops/util/pkg/distributions"
+)
+
+func gzipped(t *testing.T, s string) []byte {
+	var b bytes.Buffer
+	w := gzip.NewWriter(&b)
+	if _, err := w.Write([]byte(s)); err != nil {
+		t.Fatalf("error compressing: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("error compressing: %v", err)
+	}
+	return b.Bytes()
+}
+
+// addRelease adds a clearsigned release to files, listing the hashes of the files at the given paths below releaseURL
+func addRelease(files map[string][]byte, releaseURL string, paths ...string) {
+	var b strings.Builder
+	b.WriteString("-----BEGIN PGP SIGNED MESSAGE-----\nHash: SHA512\n\nOrigin: Ubuntu\nMD5Sum:\n 00000000000000000000000000000000 0 ignored\nSHA256:\n")
+	for _, path := range paths {
+		data := files[releaseURL+path]
+		fmt.Fprintf(&b, " %s %d %s\n", sha256Hex(data), len(data), path)
+	}
+	b.WriteString("-----BEGIN PGP SIGNATURE-----\n\nnot a signature\n-----END PGP SIGNATURE-----\n")
+	files[releaseURL+releaseFile] = []byte(b.String())
+}
+
+const jammyMirror = "https://archive.ubuntu.com/ubuntu"
+
+// addJammyRepositories adds the amd64 indexes of the jammy repositories to files, along with their releases.
+// The indexes list the packages in contents by suite and component, e.g. "jammy-updates/main".
+func addJammyRepositories(t *testing.T, files map[string][]byte, contents map[string]string) {
+	for _, suite := range []string{"jammy", "jammy-updates", "jammy-security"} {
+		releaseURL := jammyMirror + "/dists/" + suite + "/"
+		var paths []string
+		for _, component := range []string{"main", "restricted", "universe"} {
+			path := component + "/binary-amd64/Packages.gz"
+			files[releaseURL+path] = gzipped(t, contents[suite+"/"+component])
+			paths = append(paths, path)
+		}
+		addRelease(files, releaseURL, paths...)
+	}
+}
+
+func fakeFetch(files map[string][]byte) func(string) ([]byte, error) {
+	return func(url string) ([]byte, error) {
+		if data, found := files[url]; found {
+			return data, nil
+		}
+		return nil, fmt.Errorf("not found: %s", url)
+	}
+}
+
+func TestResolveDeb(t *testing.T) {
+	main := `Package: socat
+Version: 1.7.4.1-3ubuntu4
+Architecture: amd64
+Depends: libc6 (>= 2.34), libssl3 (>= 3.0.0~~alpha1), libwrap0 (>= 7.6-4~)
+Priority: optional
+Description: multipurpose relay for bidirectional data transfer
+ socat, like netcat, relays data.
+Filename: pool/main/s/socat/socat_1.7.4.1-3ubuntu4_amd64.deb
+SHA256: 1111111111111111111111111111111111111111111111111111111111111111
+
+Package: libc6
+Version: 2.35-0ubuntu3
+Architecture: amd64
+Priority: required
+Filename: pool/main/g/glibc/libc6_2.35-0ubuntu3_amd64.deb
+SHA256: 2222222222222222222222222222222222222222222222222222222222222222
+
+Package: libssl3
+Version: 3.0.2-0ubuntu1
+Architecture: amd64
+Pre-Depends: libc6 (>= 2.34)
+Filename: pool/main/o/openssl/libssl3_3.0.2-0ubuntu1_amd64.deb
+SHA256: 3333333333333333333333333333333333333333333333333333333333333333
+
+Package: libwrap0
+Version: 7.6.q-31build2
+Architecture: amd64
+Depends: libc6 (>= 2.33), libnsl2 | libnsl-compat:any
+Filename: pool/main/t/tcp-wrappers/libwrap0_7.6.q-31build2_amd64.deb
+SHA256: 4444444444444444444444444444444444444444444444444444444444444444
+
+Package: libnsl2
+Version: 1.3.0-2build2
+Architecture: amd64
+Provides: libnsl-compat
+Filename: pool/main/libn/libnsl/libnsl2_1.3.0-2build2_amd64.deb
+SHA256: 5555555555555555555555555555555555555555555555555555555555555555
+`
+	updates := `Package: libssl3
+Version: 3.0.2-0ubuntu1.6
+Architecture: amd64
+Pre-Depends: libc6 (>= 2.34)
+Filename: pool/main/o/openssl/libssl3_3.0.2-0ubuntu1.6_amd64.deb
+SHA256: 6666666666666666666666666666666666666666666666666666666666666666
+`
+	files := map[string][]byte{}
+	addJammyRepositories(t, files, map[string]string{"jammy/main": main, "jammy-updates/main": updates})
+
+	r := &Resolver{fetch: fakeFetch(files)}
+	bundle, sources, err := r.Resolve(distributions.DistributionUbuntu2204, architectures.ArchitectureAmd64, []string{"socat"}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	jammy := jammyMirror + "/dists/jammy/"
+	jammyUpdates := jammyMirror + "/dists/jammy-updates/"
+	index := "main/binary-amd64/Packages.gz"
+
+	expected := &Bundle{
+		Distribution: "jammy",
+		Architecture: architectures.ArchitectureAmd64,
+		Packages: []*BundledPackage{
+			// libc6 is installed on every system, but the version on the image may be older than required
+			{Name: "libc6", Version: "2.35-0ubuntu3", MinVersion: "2.34", File: "libc6_2.35-0ubuntu3_amd64.deb", SHA256: "2222222222222222222222222222222222222222222222222222222222222222"},
+			{Name: "libnsl2", Version: "1.3.0-2build2", File: "libnsl2_1.3.0-2build2_amd64.deb", SHA256: "5555555555555555555555555555555555555555555555555555555555555555"},
+			{Name: "libssl3", Version: "3.0.2-0ubuntu1.6", MinVersion: "3.0.0~~alpha1", File: "libssl3_3.0.2-0ubuntu1.6_amd64.deb", SHA256: "6666666666666666666666666666666666666666666666666666666666666666", Depends: []string{"libc6"}},
+			{Name: "libwrap0", Version: "7.6.q-31build2", MinVersion: "7.6-4~", File: "libwrap0_7.6.q-31build2_amd64.deb", SHA256: "4444444444444444444444444444444444444444444444444444444444444444", Depends: []string{"libc6", "libnsl2"}},
+			{Name: "socat", Version: "1.7.4.1-3ubuntu4", File: "socat_1.7.4.1-3ubuntu4_amd64.deb", SHA256: "1111111111111111111111111111111111111111111111111111111111111111", Depends: []string{"libc6", "libssl3", "libwrap0"}},
+		},
+		// Only the indexes listing bundled packages are bundled
+		Repositories: []*BundledRepository{
+			{
+				URL:     jammy,
+				Release: &BundledFile{Path: "InRelease", File: "repositories/0/InRelease", SHA256: sha256Hex(files[jammy+"InRelease"])},
+				Indexes: []*BundledFile{{Path: index, File: "repositories/0/" + index, SHA256: sha256Hex(files[jammy+index])}},
+			},
+			{
+				URL:     jammyUpdates,
+				Release: &BundledFile{Path: "InRelease", File: "repositories/1/InRelease", SHA256: sha256Hex(files[jammyUpdates+"InRelease"])},
+				Indexes: []*BundledFile{{Path: index, File: "repositories/1/" + index, SHA256: sha256Hex(files[jammyUpdates+index])}},
+			},
+		},
+	}
+	if !reflect.DeepEqual(bundle, expected) {
+		actual, _ := bundle.Marshal()
+		t.Errorf("unexpected bundle:\n%s", actual)
+	}
+	if sources["libssl3_3.0.2-0ubuntu1.6_amd64.deb"] != jammyMirror+"/pool/main/o/openssl/libssl3_3.0.2-0ubuntu1.6_amd64.deb" {
+		t.Errorf("unexpected sources: %v", sources)
+	}
+	if sources["repositories/1/InRelease"] != jammyUpdates+"InRelease" || sources["repositories/1/"+index] != jammyUpdates+index {
+		t.Errorf("unexpected sources: %v", sources)
+	}
+
+	if _, _, err := r.Resolve(distributions.DistributionUbuntu2204, architectures.ArchitectureAmd64, []string{"missing"}, nil); err == nil {
+		t.Errorf("expected an error resolving a missing package")
+	}
+
+	// An index that does not match the hash in its release is rejected
+	files[jammyUpdates+index] = gzipped(t, main)
+	if _, _, err := r.Resolve(distributions.DistributionUbuntu2204, architectures.ArchitectureAmd64, []string{"socat"}, nil); err == nil {
+		t.Errorf("expected an error for an index that does not match its release")
+	}
+}
+
+func TestResolveRPM(t *testing.T) {
+	repomd := `<?xml version="1.0" encoding="UTF-8"?>
+<repomd xmlns="http://linux.duke.edu/metadata/repo" xmlns:rpm="http://linux.duke.edu/metadata/rpm">
+  <data type="filelists"><location href="repodata/abc-filelists.xml.gz"/></data>
+  <data type="primary"><location href="repodata/def-primary.xml.gz"/></data>
+</repomd>
+`
+	primary := `<?xml version="1.0" encoding="UTF-8"?>
+<metadata xmlns="http://linux.duke.edu/metadata/common" xmlns:rpm="http://linux.duke.edu/metadata/rpm" packages="5">
+<package type="rpm">
+  <name>socat</name><arch>x86_64</arch><version epoch="0" ver="1.7.4.1" rel="5.el9"/>
+  <checksum type="sha256" pkgid="YES">1111111111111111111111111111111111111111111111111111111111111111</checksum>
+  <location href="Packages/s/socat-1.7.4.1-5.el9.x86_64.rpm"/>
+  <format>
+    <rpm:provides><rpm:entry name="socat" flags="EQ" epoch="0" ver="1.7.4.1" rel="5.el9"/></rpm:provides>
+    <rpm:requires>
+      <rpm:entry name="libc.so.6()(64bit)"/>
+      <rpm:entry name="/usr/bin/sh"/>
+      <rpm:entry name="rpmlib(PayloadIsZstd)"/>
+      <rpm:entry name="(foo if bar)"/>
+    </rpm:requires>
+    <file>/usr/bin/socat</file>
+  </format>
+</package>
+<package type="rpm">
+  <name>glibc</name><arch>x86_64</arch><version epoch="0" ver="2.34" rel="28.el9"/>
+  <checksum type="sha256" pkgid="YES">2222222222222222222222222222222222222222222222222222222222222222</checksum>
+  <location href="Packages/g/glibc-2.34-28.el9.x86_64.rpm"/>
+  <format><rpm:provides><rpm:entry name="libc.so.6()(64bit)"/></rpm:provides></format>
+</package>
+<package type="rpm">
+  <name>glibc</name><arch>x86_64</arch><version epoch="0" ver="2.34" rel="40.el9"/>
+  <checksum type="sha256" pkgid="YES">3333333333333333333333333333333333333333333333333333333333333333</checksum>
+  <location href="Packages/g/glibc-2.34-40.el9.x86_64.rpm"/>
+  <format><rpm:provides><rpm:entry name="libc.so.6()(64bit)"/></rpm:provides></format>
+</package>
+<package type="rpm">
+  <name>glibc</name><arch>i686</arch><version epoch="0" ver="2.34" rel="60.el9"/>
+  <checksum type="sha256" pkgid="YES">4444444444444444444444444444444444444444444444444444444444444444</checksum>
+  <location href="Packages/g/glibc-2.34-60.el9.i686.rpm"/>
+  <format><rpm:provides><rpm:entry name="libc.so.6"/></rpm:provides></format>
+</package>
+<package type="rpm">
+  <name>bash</name><arch>x86_64</arch><version epoch="0" ver="5.1.8" rel="4.el9"/>
+  <checksum type="sha256" pkgid="YES">5555555555555555555555555555555555555555555555555555555555555555</checksum>
+  <location href="Packages/b/bash-5.1.8-4.el9.x86_64.rpm"/>
+  <format><file>/usr/bin/sh</file></format>
+</package>
+</metadata>
+`
+	baseOS := "https://dl.rockylinux.org/pub/rocky/9/BaseOS/x86_64/os"
+	appStream := "https://dl.rockylinux.org/pub/rocky/9/AppStream/x86_64/os"
+	files := map[string][]byte{
+		baseOS + "/repodata/repomd.xml":            []byte(repomd),
+		baseOS + "/repodata/def-primary.xml.gz":    gzipped(t, primary),
+		appStream + "/repodata/repomd.xml":         []byte(repomd),
+		appStream + "/repodata/def-primary.xml.gz": gzipped(t, `<metadata packages="0"></metadata>`),
+	}
+
+	r := &Resolver{fetch: fakeFetch(files)}
+	bundle, sources, err := r.Resolve(distributions.DistributionRocky9, architectures.ArchitectureAmd64, []string{"socat"}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := &Bundle{
+		Distribution: "rocky9",
+		Architecture: architectures.ArchitectureAmd64,
+		Packages: []*BundledPackage{
+			{Name: "bash", Version: "5.1.8-4.el9", File: "bash-5.1.8-4.el9.x86_64.rpm", SHA256: "5555555555555555555555555555555555555555555555555555555555555555"},
+			{Name: "glibc", Version: "2.34-40.el9", File: "glibc-2.34-40.el9.x86_64.rpm", SHA256: "3333333333333333333333333333333333333333333333333333333333333333"},
+			{Name: "socat", Version: "1.7.4.1-5.el9", File: "socat-1.7.4.1-5.el9.x86_64.rpm", SHA256: "1111111111111111111111111111111111111111111111111111111111111111", Depends: []string{"bash", "glibc"}},
+		},
+	}
+	if !reflect.DeepEqual(bundle, expected) {
+		actual, _ := bundle.Marshal()
+		t.Errorf("unexpected bundle:\n%s", actual)
+	}
+	if sources["glibc-2.34-40.el9.x86_64.rpm"] != baseOS+"/Packages/g/glibc-2.34-40.el9.x86_64.rpm" {
+		t.Errorf("unexpected sources: %v", sources)
+	}
+}
+
+func TestResolveUnsupported(t *testing.T) {
+	r := &Resolver{fetch: fakeFetch(nil)}
+	for _, d := range []distributions.Distribution{distributions.DistributionRhel9, distributions.DistributionSLES15, distributions.DistributionFlatcar} {
+		if _, _, err := r.Resolve(d, architectures.ArchitectureAmd64, []string{"socat"}, nil); err == nil {
+			t.Errorf("expected an error for %s", d.ID())
+		}
+	}
+}
+
+func TestCompareRPMVersions(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"1.0-1", "1.0-1", 0},
+		{"1.0-1", "1.0-2", -1},
+		{"1.10-1", "1.9-1", 1},
+		{"2.34-40.el9", "2.34-28.el9", 1},
+		{"1.0a-1", "1.0-1", 1},
+		{"1.0~rc1-1", "1.0-1", -1},
+		{"1:1.0-1", "2.0-1", 1},
+		{"1.0.1-1", "1.0a-1", 1},
+		{"005-1", "5-1", 0},
+	}
+	for _, test := range tests {
+		if actual := compareRPMVersions(test.a, test.b); actual != test.expected {
+			t.Errorf("compareRPMVersions(%q, %q) = %d, expected %d", test.a, test.b, actual, test.expected)
+		}
+	}
+}
+
+func TestResolveDebVersions(t *testing.T) {
+	main := `Package: conntrack
+Version: 1:1.4.6-2build2
+Architecture: amd64
+Depends: libc6 (>= 2.34), libmnl0, libnetfilter-conntrack3 (>= 1.0.9) | libnetfilter-conntrack-old
+Filename: pool/main/c/conntrack/conntrack_1.4.6-2build2_amd64.deb
+SHA256: 1111111111111111111111111111111111111111111111111111111111111111
+
+Package: libc6
+Version: 2.35-0ubuntu3
+Architecture: amd64
+Priority: required
+Filename: pool/main/g/glibc/libc6_2.35-0ubuntu3_amd64.deb
+SHA256: 2222222222222222222222222222222222222222222222222222222222222222
+
+Package: libmnl0
+Version: 1.0.4-3build2
+Architecture: amd64
+Priority: required
+Filename: pool/main/libm/libmnl/libmnl0_1.0.4-3build2_amd64.deb
+SHA256: 3333333333333333333333333333333333333333333333333333333333333333
+
+Package: libnetfilter-conntrack3
+Version: 1.0.8-3
+Architecture: amd64
+Filename: pool/main/libn/libnetfilter-conntrack/libnetfilter-conntrack3_1.0.8-3_amd64.deb
+SHA256: 4444444444444444444444444444444444444444444444444444444444444444
+
+Package: libnetfilter-conntrack-old
+Version: 1.0.0-1
+Architecture: amd64
+Filename: pool/main/libn/libnetfilter-conntrack-old/libnetfilter-conntrack-old_1.0.0-1_amd64.deb
+SHA256: 5555555555555555555555555555555555555555555555555555555555555555
+`
+	files := map[string][]byte{}
+	addJammyRepositories(t, files, map[string]string{"jammy/main": main})
+
+	r := &Resolver{fetch: fakeFetch(files)}
+	bundle, _, err := r.Resolve(distributions.DistributionUbuntu2204, architectures.ArchitectureAmd64, []string{"conntrack"}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var names []string
+	for _, p := range bundle.Packages {
+		names = append(names, p.Name)
+	}
+	// libmnl0 is required by the system and any version will do; libnetfilter-conntrack3 is too old, so the alternative is used
+	if expected := []string{"conntrack", "libc6", "libnetfilter-conntrack-old"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("unexpected packages, actual=%v, expected=%v", names, expected)
+	}
+	if libc6 := bundle.Find("libc6"); libc6 == nil || libc6.MinVersion != "2.34" {
+		t.Errorf("expected libc6 to be bundled with minimum version 2.34, got %+v", libc6)
+	}
+}
+
+func TestResolveFlatAptSource(t *testing.T) {
+	flat := `Package: nvidia-container-runtime
+Version: 3.11.0-1
+Architecture: all
+Depends: nvidia-container-toolkit (>= 1.11.0-1)
+Filename: ./nvidia-container-runtime_3.11.0-1_all.deb
+SHA256: 1111111111111111111111111111111111111111111111111111111111111111
+
+Package: nvidia-container-toolkit
+Version: 1.11.0-1
+Architecture: amd64
+Filename: ./nvidia-container-toolkit_1.11.0-1_amd64.deb
+SHA256: 2222222222222222222222222222222222222222222222222222222222222222
+`
+	files := map[string][]byte{}
+	addJammyRepositories(t, files, nil)
+	flatURL := "https://nvidia.github.io/nvidia-container-runtime/stable/ubuntu18.04/amd64/"
+	files[flatURL+"Packages"] = []byte(flat)
+	addRelease(files, flatURL, "Packages")
+
+	r := &Resolver{fetch: fakeFetch(files)}
+	sources := []string{"deb https://nvidia.github.io/nvidia-container-runtime/stable/ubuntu18.04/$(ARCH) /"}
+	bundle, urls, err := r.Resolve(distributions.DistributionUbuntu2204, architectures.ArchitectureAmd64, []string{"nvidia-container-runtime"}, sources)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(bundle.Packages) != 2 {
+		t.Fatalf("expected the runtime and the toolkit to be bundled, got %d packages", len(bundle.Packages))
+	}
+	if u := urls["nvidia-container-toolkit_1.11.0-1_amd64.deb"]; u != "https://nvidia.github.io/nvidia-container-runtime/stable/ubuntu18.04/amd64/./nvidia-container-toolkit_1.11.0-1_amd64.deb" {
+		t.Errorf("unexpected source %q", u)
+	}
+	if len(bundle.Repositories) != 1 || bundle.Repositories[0].URL != flatURL || len(bundle.Repositories[0].Indexes) != 1 || bundle.Repositories[0].Indexes[0].Path != "Packages" {
+		t.Errorf("expected the release and index of the flat repository to be bundled, got %+v", bundle.Repositories)
+	}
+
+	insecure := []string{"deb http://nvidia.github.io/nvidia-container-runtime/stable/ubuntu18.04/$(ARCH) /"}
+	if _, _, err := r.Resolve(distributions.DistributionUbuntu2204, architectures.ArchitectureAmd64, []string{"nvidia-container-runtime"}, insecure); err == nil {
+		t.Errorf("expected an error using an apt source that is not served over https")
+	}
+
+	if _, _, err := r.Resolve(distributions.DistributionRocky9, architectures.ArchitectureAmd64, []string{"socat"}, sources); err == nil {
+		t.Errorf("expected an error using an apt source with an rpm distribution")
+	}
+}
+
+func TestDebRequirements(t *testing.T) {
+	actual := debRequirements(" libnsl2 | libnsl-compat:any (>= 1.3) | libfoo (<< 2) | libbar (> 1)")
+	expected := []requirement{
+		{name: "libnsl2"},
+		{name: "libnsl-compat", op: ">=", version: "1.3"},
+		{name: "libfoo", op: "<<", version: "2"},
+		{name: "libbar", op: ">=", version: "1"},
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("unexpected requirements, actual=%v, expected=%v", actual, expected)
+	}
+}
+
+func TestCompareDebVersions(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"1.0-1", "1.0-1", 0},
+		{"1.0-1", "1.0-2", -1},
+		{"1.10", "1.9", 1},
+		{"2.35-0ubuntu3", "2.34", 1},
+		{"1.0~rc1", "1.0", -1},
+		{"3.0.2-0ubuntu1.6", "3.0.0~~alpha1", 1},
+		{"1.0~~", "1.0~", -1},
+		{"1:1.0", "2.0", 1},
+		{"1.0a", "1.0", 1},
+		{"1.0a", "1.0+", -1},
+		{"7.6.q-31build2", "7.6-4~", 1},
+		{"005", "5", 0},
+	}
+	for _, test := range tests {
+		if actual := compareDebVersions(test.a, test.b); actual != test.expected {
+			t.Errorf("compareDebVersions(%q, %q) = %d, expected %d", test.a, test.b, actual, test.expected)
+		}
+	}
+}
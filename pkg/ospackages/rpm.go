@@ -0,0 +1,264 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ospackages
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// repomd is the subset of repodata/repomd.xml that locates the primary metadata
+type repomd struct {
+	Data []struct {
+		Type     string `xml:"type,attr"`
+		Location struct {
+			Href string `xml:"href,attr"`
+		} `xml:"location"`
+	} `xml:"data"`
+}
+
+// primaryLocation returns the location of the primary metadata listed in repomd.xml
+func primaryLocation(data []byte) (string, error) {
+	md := &repomd{}
+	if err := xml.Unmarshal(data, md); err != nil {
+		return "", fmt.Errorf("error parsing repomd.xml: %v", err)
+	}
+	for _, d := range md.Data {
+		if d.Type == "primary" && d.Location.Href != "" {
+			return d.Location.Href, nil
+		}
+	}
+	return "", fmt.Errorf("repomd.xml does not list the primary metadata")
+}
+
+type rpmEntry struct {
+	Name    string `xml:"name,attr"`
+	Flags   string `xml:"flags,attr"`
+	Epoch   string `xml:"epoch,attr"`
+	Version string `xml:"ver,attr"`
+	Release string `xml:"rel,attr"`
+}
+
+// rpmRelations maps the flags of an rpm dependency to version relations
+var rpmRelations = map[string]string{
+	"LT": "<<",
+	"LE": "<=",
+	"EQ": "=",
+	"GE": ">=",
+	"GT": ">>",
+}
+
+// requirement returns the requirement expressed by a requires entry
+func (e *rpmEntry) requirement() requirement {
+	r := requirement{name: e.Name}
+	op, found := rpmRelations[e.Flags]
+	if !found || e.Version == "" {
+		return r
+	}
+	version := e.Version
+	if e.Release != "" {
+		version += "-" + e.Release
+	}
+	if e.Epoch != "" && e.Epoch != "0" {
+		version = e.Epoch + ":" + version
+	}
+	r.op = op
+	r.version = version
+	return r
+}
+
+// rpmPrimary is the subset of the primary metadata of an rpm repository used to resolve packages
+type rpmPrimary struct {
+	Packages []struct {
+		Name    string `xml:"name"`
+		Arch    string `xml:"arch"`
+		Version struct {
+			Epoch   string `xml:"epoch,attr"`
+			Ver     string `xml:"ver,attr"`
+			Release string `xml:"rel,attr"`
+		} `xml:"version"`
+		Checksum struct {
+			Type  string `xml:"type,attr"`
+			Value string `xml:",chardata"`
+		} `xml:"checksum"`
+		Location struct {
+			Href string `xml:"href,attr"`
+		} `xml:"location"`
+		Format struct {
+			Provides []rpmEntry `xml:"provides>entry"`
+			Requires []rpmEntry `xml:"requires>entry"`
+			Files    []string   `xml:"file"`
+		} `xml:"format"`
+	} `xml:"package"`
+}
+
+// parseRPMPrimary parses the primary metadata of an rpm repository at baseURL, keeping the packages of the given architectures
+func parseRPMPrimary(data []byte, baseURL string, arches []string) ([]*repoPackage, error) {
+	primary := &rpmPrimary{}
+	if err := xml.Unmarshal(data, primary); err != nil {
+		return nil, fmt.Errorf("error parsing primary metadata: %v", err)
+	}
+
+	var packages []*repoPackage
+	for _, rp := range primary.Packages {
+		if !contains(arches, rp.Arch) {
+			continue
+		}
+		if rp.Checksum.Type != "sha256" {
+			return nil, fmt.Errorf("unsupported checksum type %q for package %q", rp.Checksum.Type, rp.Name)
+		}
+
+		version := rp.Version.Ver + "-" + rp.Version.Release
+		if rp.Version.Epoch != "" && rp.Version.Epoch != "0" {
+			version = rp.Version.Epoch + ":" + version
+		}
+		p := &repoPackage{
+			name:    rp.Name,
+			version: version,
+			url:     strings.TrimSuffix(baseURL, "/") + "/" + rp.Location.Href,
+			sha256:  strings.TrimSpace(rp.Checksum.Value),
+		}
+		for _, e := range rp.Format.Provides {
+			p.provides = append(p.provides, e.Name)
+		}
+		p.provides = append(p.provides, rp.Format.Files...)
+		for _, e := range rp.Format.Requires {
+			// rpmlib features are provided by rpm itself, and rich dependencies are conditional
+			if strings.HasPrefix(e.Name, "rpmlib(") || strings.HasPrefix(e.Name, "(") {
+				continue
+			}
+			p.requires = append(p.requires, []requirement{e.requirement()})
+		}
+		packages = append(packages, p)
+	}
+	return packages, nil
+}
+
+// compareRPMVersions compares two [epoch:]version-release strings the way rpm does,
+// returning -1, 0 or 1 if a is older than, the same as, or newer than b
+func compareRPMVersions(a, b string) int {
+	epochA, restA := splitEpoch(a)
+	epochB, restB := splitEpoch(b)
+	if c := compareRPMSegments(epochA, epochB); c != 0 {
+		return c
+	}
+	versionA, releaseA := splitRelease(restA)
+	versionB, releaseB := splitRelease(restB)
+	if c := compareRPMSegments(versionA, versionB); c != 0 {
+		return c
+	}
+	return compareRPMSegments(releaseA, releaseB)
+}
+
+func splitRelease(v string) (string, string) {
+	if i := strings.LastIndex(v, "-"); i >= 0 {
+		return v[:i], v[i+1:]
+	}
+	return v, ""
+}
+
+func splitEpoch(v string) (string, string) {
+	if i := strings.Index(v, ":"); i >= 0 {
+		return v[:i], v[i+1:]
+	}
+	return "0", v
+}
+
+// compareRPMSegments implements rpmvercmp: alternating runs of digits and letters are compared,
+// numerically or lexically, and separators are ignored; a tilde sorts before anything
+func compareRPMSegments(a, b string) int {
+	isSeparator := func(r rune) bool {
+		return !unicode.IsDigit(r) && !unicode.IsLetter(r) && r != '~'
+	}
+
+	for {
+		a = strings.TrimLeftFunc(a, isSeparator)
+		b = strings.TrimLeftFunc(b, isSeparator)
+
+		if strings.HasPrefix(a, "~") || strings.HasPrefix(b, "~") {
+			if !strings.HasPrefix(a, "~") {
+				return 1
+			}
+			if !strings.HasPrefix(b, "~") {
+				return -1
+			}
+			a, b = a[1:], b[1:]
+			continue
+		}
+		if a == "" || b == "" {
+			break
+		}
+
+		numeric := unicode.IsDigit(rune(a[0]))
+		segment := func(s string) (string, string) {
+			i := strings.IndexFunc(s, func(r rune) bool {
+				if numeric {
+					return !unicode.IsDigit(r)
+				}
+				return !unicode.IsLetter(r)
+			})
+			if i < 0 {
+				return s, ""
+			}
+			return s[:i], s[i:]
+		}
+		segA, restA := segment(a)
+		segB, restB := segment(b)
+		if segB == "" {
+			// Numeric segments are newer than alphabetic ones
+			if numeric {
+				return 1
+			}
+			return -1
+		}
+
+		if numeric {
+			segA = strings.TrimLeft(segA, "0")
+			segB = strings.TrimLeft(segB, "0")
+			if len(segA) != len(segB) {
+				if len(segA) > len(segB) {
+					return 1
+				}
+				return -1
+			}
+		}
+		if c := strings.Compare(segA, segB); c != 0 {
+			return c
+		}
+		a, b = restA, restB
+	}
+
+	switch {
+	case a == "" && b == "":
+		return 0
+	case a == "":
+		return -1
+	default:
+		return 1
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
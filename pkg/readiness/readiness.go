@@ -0,0 +1,39 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package readiness
+
+import (
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+)
+
+// DeploymentProblem returns a description of why the Deployment is not ready, or an empty string if it is ready.
+// A Deployment is ready once the latest generation has been observed and all its replicas are updated and available.
+func DeploymentProblem(d *appsv1.Deployment) string {
+	replicas := int32(1)
+	if d.Spec.Replicas != nil {
+		replicas = *d.Spec.Replicas
+	}
+	if d.Status.ObservedGeneration < d.Generation {
+		return fmt.Sprintf("deployment %s/%s has not observed the latest generation", d.Namespace, d.Name)
+	}
+	if d.Status.UpdatedReplicas < replicas || d.Status.AvailableReplicas < replicas {
+		return fmt.Sprintf("deployment %s/%s has %d/%d updated and %d/%d available replicas", d.Namespace, d.Name, d.Status.UpdatedReplicas, replicas, d.Status.AvailableReplicas, replicas)
+	}
+	return ""
+}
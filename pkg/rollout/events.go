@@ -0,0 +1,99 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rollout
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// EventType is the kind of step of a rolling update an Event reports.
+type EventType string
+
+const (
+	EventInstanceGroupStarted   EventType = "InstanceGroupStarted"
+	EventInstanceGroupCompleted EventType = "InstanceGroupCompleted"
+	EventDrainStarted           EventType = "DrainStarted"
+	EventDrainBlocked           EventType = "DrainBlocked"
+	EventInstanceReplaced       EventType = "InstanceReplaced"
+	EventValidationFailed       EventType = "ValidationFailed"
+	EventCompleted              EventType = "Completed"
+	EventFailed                 EventType = "Failed"
+)
+
+// Event reports a step of a rolling update.
+type Event struct {
+	Time          time.Time `json:"time"`
+	Type          EventType `json:"type"`
+	InstanceGroup string    `json:"instanceGroup,omitempty"`
+	Instance      string    `json:"instance,omitempty"`
+	Node          string    `json:"node,omitempty"`
+	Message       string    `json:"message,omitempty"`
+	// BlockingBudgets are the PodDisruptionBudgets that prevented pods from being evicted from the node.
+	BlockingBudgets []BlockingBudget `json:"blockingBudgets,omitempty"`
+}
+
+// BlockingBudget is a PodDisruptionBudget that prevents a pod from being evicted.
+type BlockingBudget struct {
+	Namespace          string `json:"namespace"`
+	Name               string `json:"name"`
+	Pod                string `json:"pod"`
+	DisruptionsAllowed int32  `json:"disruptionsAllowed"`
+}
+
+func (b BlockingBudget) String() string {
+	return fmt.Sprintf("PodDisruptionBudget %s/%s (%d disruptions allowed) protecting pod %s/%s", b.Namespace, b.Name, b.DisruptionsAllowed, b.Namespace, b.Pod)
+}
+
+// EventSink receives the events of a rolling update.
+type EventSink interface {
+	Emit(event *Event)
+}
+
+// JSONEventSink writes events as newline-delimited JSON objects.
+type JSONEventSink struct {
+	mutex sync.Mutex
+	out   io.Writer
+}
+
+var _ EventSink = &JSONEventSink{}
+
+// NewJSONEventSink builds a JSONEventSink writing to out.
+func NewJSONEventSink(out io.Writer) *JSONEventSink {
+	return &JSONEventSink{out: out}
+}
+
+// Emit writes the event; failures are logged, as they should not stop the rolling update.
+func (s *JSONEventSink) Emit(event *Event) {
+	b, err := json.Marshal(event)
+	if err != nil {
+		klog.Warningf("unable to marshal rolling update event: %v", err)
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, err := s.out.Write(append(b, '\n')); err != nil {
+		klog.Warningf("unable to write rolling update event: %v", err)
+	}
+}
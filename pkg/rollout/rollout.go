@@ -0,0 +1,123 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package rollout defines the record of a rolling update that is persisted in the state store.
+package rollout
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strconv"
+	"time"
+)
+
+// Phase is the stage of a rolling update.
+type Phase string
+
+const (
+	PhaseBastions     Phase = "Bastions"
+	PhaseControlPlane Phase = "ControlPlane"
+	PhaseAPIServers   Phase = "APIServers"
+	PhaseNodes        Phase = "Nodes"
+	PhaseCompleted    Phase = "Completed"
+)
+
+// Status is the overall status of a rolling update.
+type Status string
+
+const (
+	StatusInProgress Status = "InProgress"
+	StatusCompleted  Status = "Completed"
+	StatusFailed     Status = "Failed"
+)
+
+// Record is the persisted record of a rolling update.
+type Record struct {
+	// Name identifies the rolling update; it is derived from the start time so that records sort chronologically,
+	// followed by a random suffix so that rolling updates started in the same second have distinct records.
+	Name string `json:"name"`
+	// Status is the overall status of the rolling update.
+	Status Status `json:"status"`
+	// Phase is the stage the rolling update reached.
+	Phase Phase `json:"phase,omitempty"`
+
+	// StartedAt is the time the rolling update was started.
+	StartedAt time.Time `json:"startedAt"`
+	// UpdatedAt is the time the record was last updated.
+	UpdatedAt time.Time `json:"updatedAt"`
+	// Resumed counts the number of times the rolling update was resumed.
+	Resumed int `json:"resumed,omitempty"`
+
+	// Options are the options the rolling update was started with.
+	Options Options `json:"options"`
+
+	// CompletedGroups are the names of the instance groups that have been fully updated.
+	CompletedGroups []string `json:"completedGroups,omitempty"`
+	// ReplacedInstances are the instances that have been replaced.
+	ReplacedInstances []ReplacedInstance `json:"replacedInstances,omitempty"`
+
+	// LastValidationFailure is the most recent cluster validation failure.
+	LastValidationFailure string `json:"lastValidationFailure,omitempty"`
+	// Error is the error that stopped the rolling update, if it failed.
+	Error string `json:"error,omitempty"`
+}
+
+// Options are the options of a rolling update that are needed to resume it.
+type Options struct {
+	Force              bool     `json:"force,omitempty"`
+	CloudOnly          bool     `json:"cloudOnly,omitempty"`
+	InstanceGroups     []string `json:"instanceGroups,omitempty"`
+	InstanceGroupRoles []string `json:"instanceGroupRoles,omitempty"`
+}
+
+// ReplacedInstance records an instance that was replaced.
+type ReplacedInstance struct {
+	InstanceGroup string    `json:"instanceGroup"`
+	ID            string    `json:"id"`
+	Node          string    `json:"node,omitempty"`
+	ReplacedAt    time.Time `json:"replacedAt"`
+}
+
+// NewRecord builds a new in-progress record for a rolling update started at the specified time.
+func NewRecord(now time.Time, options Options) *Record {
+	return &Record{
+		Name:      now.UTC().Format("20060102-150405") + "-" + randomSuffix(now),
+		Status:    StatusInProgress,
+		StartedAt: now,
+		UpdatedAt: now,
+		Options:   options,
+	}
+}
+
+// randomSuffix returns a short random string to make record names unique.
+func randomSuffix(now time.Time) string {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		// Fall back to the sub-second part of the start time
+		return strconv.Itoa(now.Nanosecond())
+	}
+	return hex.EncodeToString(b)
+}
+
+// IsGroupCompleted returns true if the named instance group has been fully updated.
+func (r *Record) IsGroupCompleted(name string) bool {
+	for _, g := range r.CompletedGroups {
+		if g == name {
+			return true
+		}
+	}
+	return false
+}
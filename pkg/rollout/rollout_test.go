@@ -0,0 +1,42 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rollout
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewRecordNames(t *testing.T) {
+	now := time.Date(2022, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	first := NewRecord(now, Options{InstanceGroups: []string{"nodes-a"}})
+	second := NewRecord(now, Options{InstanceGroups: []string{"nodes-b"}})
+	if first.Name == second.Name {
+		t.Errorf("expected rolling updates started in the same second to have distinct names, got %q", first.Name)
+	}
+	for _, record := range []*Record{first, second} {
+		if !strings.HasPrefix(record.Name, "20220301-120000-") {
+			t.Errorf("expected the name %q to start with the start time", record.Name)
+		}
+	}
+
+	if later := NewRecord(now.Add(time.Second), Options{}); later.Name <= first.Name || later.Name <= second.Name {
+		t.Errorf("expected a later rolling update to sort after the earlier ones, got %q", later.Name)
+	}
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stateencryption
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"k8s.io/kops/pkg/apis/kops"
+)
+
+// awsKMSProvider seals data keys using an AWS KMS key
+type awsKMSProvider struct {
+	kms   *kms.KMS
+	keyID string
+}
+
+var _ KeyProvider = &awsKMSProvider{}
+
+func newAWSKMSProvider(spec *kops.StateStoreEncryptionAWSKMSSpec) (*awsKMSProvider, error) {
+	region := spec.Region
+	if region == "" {
+		keyARN, err := arn.Parse(spec.KeyID)
+		if err != nil {
+			return nil, fmt.Errorf("region must be set unless the KMS key ID is an ARN")
+		}
+		region = keyARN.Region
+	}
+
+	config := aws.NewConfig().WithCredentialsChainVerboseErrors(true).WithRegion(region)
+	sess, err := session.NewSession(config)
+	if err != nil {
+		return nil, fmt.Errorf("error building AWS session: %v", err)
+	}
+
+	return &awsKMSProvider{
+		kms:   kms.New(sess, config),
+		keyID: spec.KeyID,
+	}, nil
+}
+
+// Seal implements KeyProvider::Seal
+func (p *awsKMSProvider) Seal(dataKey []byte) ([]byte, error) {
+	response, err := p.kms.Encrypt(&kms.EncryptInput{
+		KeyId:     aws.String(p.keyID),
+		Plaintext: dataKey,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error encrypting with KMS key %q: %v", p.keyID, err)
+	}
+	return response.CiphertextBlob, nil
+}
+
+// Unseal implements KeyProvider::Unseal
+func (p *awsKMSProvider) Unseal(sealedKey []byte) ([]byte, error) {
+	response, err := p.kms.Decrypt(&kms.DecryptInput{
+		KeyId:          aws.String(p.keyID),
+		CiphertextBlob: sealedKey,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error decrypting with KMS key %q: %v", p.keyID, err)
+	}
+	return response.Plaintext, nil
+}
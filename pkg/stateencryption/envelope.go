@@ -0,0 +1,160 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package stateencryption implements client-side envelope encryption of files in the state store.
+// Each file is encrypted with AES-256-GCM using a random data key, which is sealed by a KeyProvider
+// and stored alongside the ciphertext.
+package stateencryption
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+
+	"k8s.io/kops/pkg/apis/kops"
+)
+
+// header marks an encrypted file; files without it are read as plaintext
+var header = []byte("kops-encrypted-v1\n")
+
+// dataKeySize is the size of the AES-256 data keys
+const dataKeySize = 32
+
+// envelope is the serialized form of an encrypted file
+type envelope struct {
+	// Provider identifies the key provider that sealed the data key, so files can be read
+	// (and re-encrypted) after the cluster has moved to a different provider.
+	// The location of key files is not recorded; it is taken from local configuration when reading.
+	Provider kops.StateStoreEncryptionSpec `json:"provider"`
+	// SealedKey is the data key, sealed by the provider
+	SealedKey []byte `json:"sealedKey"`
+	// Nonce is the AES-GCM nonce
+	Nonce []byte `json:"nonce"`
+	// Ciphertext is the encrypted contents of the file
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// IsEncrypted returns true if data was written by Encrypt
+func IsEncrypted(data []byte) bool {
+	return bytes.HasPrefix(data, header)
+}
+
+// Encrypt encrypts data with a new data key sealed by the provider configured in spec.
+// If spec is nil, data is returned unchanged.
+func Encrypt(spec *kops.StateStoreEncryptionSpec, data []byte) ([]byte, error) {
+	if spec == nil {
+		return data, nil
+	}
+
+	provider, err := GetKeyProvider(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	dataKey := make([]byte, dataKeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, fmt.Errorf("error generating data key: %v", err)
+	}
+
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("error generating nonce: %v", err)
+	}
+
+	sealedKey, err := provider.Seal(dataKey)
+	if err != nil {
+		return nil, fmt.Errorf("error sealing data key: %v", err)
+	}
+
+	recorded := *spec
+	if recorded.KeyFile != nil {
+		recorded.KeyFile = &kops.StateStoreEncryptionKeyFileSpec{}
+	}
+
+	e := &envelope{
+		Provider:   recorded,
+		SealedKey:  sealedKey,
+		Nonce:      nonce,
+		Ciphertext: aead.Seal(nil, nonce, data, header),
+	}
+	b, err := json.Marshal(e)
+	if err != nil {
+		return nil, fmt.Errorf("error serializing encrypted file: %v", err)
+	}
+
+	var out bytes.Buffer
+	out.Write(header)
+	out.Write(b)
+	return out.Bytes(), nil
+}
+
+// Decrypt decrypts data written by Encrypt, using the kind of key provider recorded in the file,
+// configured as described by localProvider from local, the encryption configured for the cluster being read.
+// Data that is not encrypted is returned unchanged, so that encryption can be enabled on an existing state store.
+func Decrypt(local *kops.StateStoreEncryptionSpec, data []byte) ([]byte, error) {
+	if !IsEncrypted(data) {
+		return data, nil
+	}
+
+	e := &envelope{}
+	if err := json.Unmarshal(data[len(header):], e); err != nil {
+		return nil, fmt.Errorf("error parsing encrypted file: %v", err)
+	}
+
+	spec, err := localProvider(local, &e.Provider)
+	if err != nil {
+		return nil, err
+	}
+	provider, err := GetKeyProvider(spec)
+	if err != nil {
+		return nil, err
+	}
+	dataKey, err := provider.Unseal(e.SealedKey)
+	if err != nil {
+		return nil, fmt.Errorf("error unsealing data key: %v", err)
+	}
+
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(e.Nonce) != aead.NonceSize() {
+		return nil, fmt.Errorf("encrypted file has invalid nonce")
+	}
+	plaintext, err := aead.Open(nil, e.Nonce, e.Ciphertext, header)
+	if err != nil {
+		return nil, fmt.Errorf("error decrypting file: %v", err)
+	}
+	return plaintext, nil
+}
+
+func newAEAD(dataKey []byte) (cipher.AEAD, error) {
+	if len(dataKey) != dataKeySize {
+		return nil, fmt.Errorf("data key has unexpected length %d", len(dataKey))
+	}
+	block, err := aes.NewCipher(dataKey)
+	if err != nil {
+		return nil, fmt.Errorf("error building cipher: %v", err)
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,224 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stateencryption
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"k8s.io/kops/pkg/apis/kops"
+)
+
+// testIdentity is the age X25519 identity for the private key 0x42 * 32, from the age test vectors
+const testIdentity = "AGE-SECRET-KEY-1GFPYYSJZGFPYYSJZGFPYYSJZGFPYYSJZGFPYYSJZGFPYYSJZGFPQ4EGAEX"
+
+// otherIdentity is the age X25519 identity for the private key 0x01..0x20
+const otherIdentity = "AGE-SECRET-KEY-1QYPQXPQ9QCRSSZG2PVXQ6RS0ZQG3YYC5Z5TPWXQERGD3C8G7RUSQGPQYEE"
+
+func writeKeyFile(t *testing.T, identity string) *kops.StateStoreEncryptionSpec {
+	p := filepath.Join(t.TempDir(), "state.key")
+	contents := "# created: 2022-06-01T00:00:00Z\n# public key: age1...\n" + identity + "\n"
+	if err := os.WriteFile(p, []byte(contents), 0o600); err != nil {
+		t.Fatalf("error writing key file: %v", err)
+	}
+	return &kops.StateStoreEncryptionSpec{
+		KeyFile: &kops.StateStoreEncryptionKeyFileSpec{Path: p},
+	}
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	spec := writeKeyFile(t, testIdentity)
+	plaintext := []byte("apiVersion: kops.k8s.io/v1alpha2\nkind: Keyset\n")
+
+	encrypted, err := Encrypt(spec, plaintext)
+	if err != nil {
+		t.Fatalf("error encrypting: %v", err)
+	}
+	if !IsEncrypted(encrypted) {
+		t.Errorf("expected encrypted data to have the encryption header")
+	}
+	if bytes.Contains(encrypted, []byte("Keyset")) {
+		t.Errorf("encrypted data contains the plaintext")
+	}
+
+	decrypted, err := Decrypt(spec, encrypted)
+	if err != nil {
+		t.Fatalf("error decrypting: %v", err)
+	}
+	if !bytes.Equal(decrypted, plaintext) {
+		t.Errorf("expected %q, got %q", plaintext, decrypted)
+	}
+
+	// Each file has its own data key
+	again, err := Encrypt(spec, plaintext)
+	if err != nil {
+		t.Fatalf("error encrypting: %v", err)
+	}
+	if bytes.Equal(again, encrypted) {
+		t.Errorf("expected encrypting twice to produce different results")
+	}
+
+	tampered := append([]byte{}, encrypted...)
+	tampered[len(tampered)-5] ^= 1
+	if _, err := Decrypt(spec, tampered); err == nil {
+		t.Errorf("expected error decrypting tampered data")
+	}
+}
+
+func TestDecryptPlaintext(t *testing.T) {
+	plaintext := []byte(`{"Data":"c2VjcmV0"}`)
+
+	decrypted, err := Decrypt(nil, plaintext)
+	if err != nil {
+		t.Fatalf("error decrypting plaintext: %v", err)
+	}
+	if !bytes.Equal(decrypted, plaintext) {
+		t.Errorf("expected plaintext to be returned unchanged, got %q", decrypted)
+	}
+
+	encrypted, err := Encrypt(nil, plaintext)
+	if err != nil {
+		t.Fatalf("error encrypting without encryption configured: %v", err)
+	}
+	if !bytes.Equal(encrypted, plaintext) {
+		t.Errorf("expected data to be unchanged without encryption configured, got %q", encrypted)
+	}
+}
+
+func TestDecryptResolvesKeyFileLocally(t *testing.T) {
+	spec := writeKeyFile(t, testIdentity)
+	other := writeKeyFile(t, otherIdentity)
+	plaintext := []byte("secret")
+
+	encrypted, err := Encrypt(spec, plaintext)
+	if err != nil {
+		t.Fatalf("error encrypting: %v", err)
+	}
+	if bytes.Contains(encrypted, []byte(spec.KeyFile.Path)) {
+		t.Errorf("expected the key file path not to be recorded in the encrypted file")
+	}
+
+	if _, err := Decrypt(nil, encrypted); err == nil {
+		t.Errorf("expected error decrypting without a key file configured")
+	}
+	if _, err := Decrypt(other, encrypted); err == nil {
+		t.Errorf("expected error decrypting with a different key file configured")
+	}
+
+	t.Setenv(KeyFileEnv, spec.KeyFile.Path)
+	decrypted, err := Decrypt(other, encrypted)
+	if err != nil {
+		t.Fatalf("error decrypting with %s: %v", KeyFileEnv, err)
+	}
+	if !bytes.Equal(decrypted, plaintext) {
+		t.Errorf("expected %q, got %q", plaintext, decrypted)
+	}
+}
+
+func TestLocalProviderVaultAddress(t *testing.T) {
+	t.Setenv("VAULT_ADDR", "")
+	recorded := &kops.StateStoreEncryptionSpec{
+		VaultTransit: &kops.StateStoreEncryptionVaultTransitSpec{Address: "https://vault.example.com:8200", Key: "kops"},
+	}
+
+	if _, err := localProvider(nil, recorded); err == nil {
+		t.Errorf("expected error using a Vault address that is only recorded in the file")
+	}
+	if _, err := localProvider(recorded, recorded); err != nil {
+		t.Errorf("unexpected error using the configured Vault address: %v", err)
+	}
+	t.Setenv("VAULT_ADDR", "https://vault.example.com:8200")
+	if _, err := localProvider(nil, recorded); err != nil {
+		t.Errorf("unexpected error using the Vault address in VAULT_ADDR: %v", err)
+	}
+}
+
+func TestKeyFileProvider(t *testing.T) {
+	provider, err := GetKeyProvider(writeKeyFile(t, testIdentity))
+	if err != nil {
+		t.Fatalf("error building provider: %v", err)
+	}
+	other, err := GetKeyProvider(writeKeyFile(t, otherIdentity))
+	if err != nil {
+		t.Fatalf("error building provider: %v", err)
+	}
+
+	dataKey := bytes.Repeat([]byte{7}, dataKeySize)
+	sealed, err := provider.Seal(dataKey)
+	if err != nil {
+		t.Fatalf("error sealing: %v", err)
+	}
+	unsealed, err := provider.Unseal(sealed)
+	if err != nil {
+		t.Fatalf("error unsealing: %v", err)
+	}
+	if !bytes.Equal(unsealed, dataKey) {
+		t.Errorf("expected %x, got %x", dataKey, unsealed)
+	}
+	if _, err := other.Unseal(sealed); err == nil {
+		t.Errorf("expected error unsealing with a different key file")
+	}
+}
+
+func TestParseX25519Identity(t *testing.T) {
+	grid := []struct {
+		Input       string
+		ExpectedKey []byte
+		ExpectError bool
+	}{
+		{
+			Input:       testIdentity,
+			ExpectedKey: bytes.Repeat([]byte{0x42}, 32),
+		},
+		{
+			Input:       "# comment\n\n" + otherIdentity + "\n",
+			ExpectedKey: []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20, 21, 22, 23, 24, 25, 26, 27, 28, 29, 30, 31, 32},
+		},
+		{
+			// Bad checksum
+			Input:       "AGE-SECRET-KEY-1GFPYYSJZGFPYYSJZGFPYYSJZGFPYYSJZGFPYYSJZGFPYYSJZGFPQ4EGAEQ",
+			ExpectError: true,
+		},
+		{
+			Input:       "age1zvkyg2lqzraa2lnjvqej32nkuu0ues2s82hzrye869xeexvn73equnujwj",
+			ExpectError: true,
+		},
+		{
+			Input:       "# no identity\n",
+			ExpectError: true,
+		},
+	}
+
+	for _, g := range grid {
+		key, err := parseX25519Identity([]byte(g.Input))
+		if g.ExpectError {
+			if err == nil {
+				t.Errorf("expected error parsing %q", g.Input)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("error parsing %q: %v", g.Input, err)
+			continue
+		}
+		if !bytes.Equal(key, g.ExpectedKey) {
+			t.Errorf("parsing %q: expected %x, got %x", g.Input, g.ExpectedKey, key)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stateencryption
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/curve25519"
+	"golang.org/x/crypto/nacl/box"
+	"k8s.io/kops/pkg/apis/kops"
+)
+
+// ageSecretKeyPrefix is the bech32 human-readable part of an age X25519 identity
+const ageSecretKeyPrefix = "age-secret-key-"
+
+// keyFileProvider seals data keys in NaCl anonymous sealed boxes (X25519 and XSalsa20-Poly1305) to the public key
+// of an X25519 private key read from a file. The key file uses the encoding of an age X25519 identity, so that it
+// can be generated with age-keygen, but the sealed keys are not in the age format and age cannot open them.
+type keyFileProvider struct {
+	publicKey  [32]byte
+	privateKey [32]byte
+}
+
+var _ KeyProvider = &keyFileProvider{}
+
+func newKeyFileProvider(spec *kops.StateStoreEncryptionKeyFileSpec) (*keyFileProvider, error) {
+	b, err := os.ReadFile(spec.Path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading state store encryption key file: %v", err)
+	}
+
+	privateKey, err := parseX25519Identity(b)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing state store encryption key file %q: %v", spec.Path, err)
+	}
+
+	p := &keyFileProvider{}
+	copy(p.privateKey[:], privateKey)
+	publicKey, err := curve25519.X25519(p.privateKey[:], curve25519.Basepoint)
+	if err != nil {
+		return nil, fmt.Errorf("error deriving public key from %q: %v", spec.Path, err)
+	}
+	copy(p.publicKey[:], publicKey)
+	return p, nil
+}
+
+// Seal implements KeyProvider::Seal
+func (p *keyFileProvider) Seal(dataKey []byte) ([]byte, error) {
+	return box.SealAnonymous(nil, dataKey, &p.publicKey, rand.Reader)
+}
+
+// Unseal implements KeyProvider::Unseal
+func (p *keyFileProvider) Unseal(sealedKey []byte) ([]byte, error) {
+	dataKey, ok := box.OpenAnonymous(nil, sealedKey, &p.publicKey, &p.privateKey)
+	if !ok {
+		return nil, fmt.Errorf("data key was not sealed with this key file")
+	}
+	return dataKey, nil
+}
+
+// parseX25519Identity returns the X25519 private key of the first identity in a file of age X25519 identities
+func parseX25519Identity(b []byte) ([]byte, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		hrp, data, err := bech32Decode(line)
+		if err != nil {
+			return nil, err
+		}
+		if hrp != ageSecretKeyPrefix {
+			return nil, fmt.Errorf("expected an age X25519 identity (AGE-SECRET-KEY-1...)")
+		}
+		if len(data) != 32 {
+			return nil, fmt.Errorf("age identity has unexpected length %d", len(data))
+		}
+		return data, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, fmt.Errorf("no age identity found")
+}
+
+const bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+// bech32Decode decodes a bech32 string (BIP 173) into its human-readable part and data
+func bech32Decode(s string) (string, []byte, error) {
+	if strings.ToLower(s) != s && strings.ToUpper(s) != s {
+		return "", nil, fmt.Errorf("invalid bech32 string: mixed case")
+	}
+	s = strings.ToLower(s)
+
+	pos := strings.LastIndex(s, "1")
+	if pos < 1 || pos+7 > len(s) {
+		return "", nil, fmt.Errorf("invalid bech32 string: separator not found")
+	}
+	hrp := s[:pos]
+
+	var values []byte
+	for _, c := range s[pos+1:] {
+		v := strings.IndexRune(bech32Charset, c)
+		if v == -1 {
+			return "", nil, fmt.Errorf("invalid bech32 string: unexpected character %q", c)
+		}
+		values = append(values, byte(v))
+	}
+
+	if bech32Polymod(append(bech32HRPExpand(hrp), values...)) != 1 {
+		return "", nil, fmt.Errorf("invalid bech32 string: bad checksum")
+	}
+
+	data, err := convertBits(values[:len(values)-6], 5, 8)
+	if err != nil {
+		return "", nil, err
+	}
+	return hrp, data, nil
+}
+
+func bech32Polymod(values []byte) uint32 {
+	generator := []uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+	chk := uint32(1)
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := 0; i < 5; i++ {
+			if (top>>uint(i))&1 == 1 {
+				chk ^= generator[i]
+			}
+		}
+	}
+	return chk
+}
+
+func bech32HRPExpand(hrp string) []byte {
+	var values []byte
+	for _, c := range []byte(hrp) {
+		values = append(values, c>>5)
+	}
+	values = append(values, 0)
+	for _, c := range []byte(hrp) {
+		values = append(values, c&31)
+	}
+	return values
+}
+
+// convertBits regroups a slice of fromBits-bit values into toBits-bit values, without padding
+func convertBits(data []byte, fromBits, toBits uint) ([]byte, error) {
+	var out []byte
+	acc := uint32(0)
+	bits := uint(0)
+	maxValue := uint32(1)<<toBits - 1
+	for _, v := range data {
+		acc = acc<<fromBits | uint32(v)
+		bits += fromBits
+		for bits >= toBits {
+			bits -= toBits
+			out = append(out, byte((acc>>bits)&maxValue))
+		}
+	}
+	if bits >= fromBits || (acc<<(toBits-bits))&maxValue != 0 {
+		return nil, fmt.Errorf("invalid bech32 string: invalid padding")
+	}
+	return out, nil
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stateencryption
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	"k8s.io/kops/pkg/apis/kops"
+)
+
+// KeyProvider seals and unseals the data keys used to encrypt files
+type KeyProvider interface {
+	// Seal encrypts a data key
+	Seal(dataKey []byte) ([]byte, error)
+	// Unseal decrypts a data key that was sealed by Seal
+	Unseal(sealedKey []byte) ([]byte, error)
+}
+
+var (
+	providersMutex sync.Mutex
+	// providers caches the key providers by their configuration, so that we authenticate once per process
+	providers = make(map[string]KeyProvider)
+)
+
+// EncryptionFor returns the state store encryption configured for the cluster, or nil if files are stored unencrypted
+func EncryptionFor(cluster *kops.Cluster) *kops.StateStoreEncryptionSpec {
+	if cluster == nil {
+		return nil
+	}
+	return cluster.Spec.StateStoreEncryption
+}
+
+// KeyFileEnv is the environment variable that sets the path of the key file used to read the state store,
+// overriding the path configured for the cluster.
+const KeyFileEnv = "KOPS_STATE_ENCRYPTION_KEY_FILE"
+
+// localProvider returns the configuration of the provider to unseal a data key sealed by the recorded provider.
+// A file in the state store must not be able to direct where we read keys from or send credentials to,
+// so the key file is the one at the path in KeyFileEnv or else in local, and a Vault address is only used
+// if it is the one in local or in VAULT_ADDR.
+func localProvider(local, recorded *kops.StateStoreEncryptionSpec) (*kops.StateStoreEncryptionSpec, error) {
+	switch {
+	case recorded.KeyFile != nil:
+		keyFilePath := os.Getenv(KeyFileEnv)
+		if keyFilePath == "" && local != nil && local.KeyFile != nil {
+			keyFilePath = local.KeyFile.Path
+		}
+		if keyFilePath == "" {
+			return nil, fmt.Errorf("file was encrypted with a key file, but no key file is configured; set %s to its path", KeyFileEnv)
+		}
+		return &kops.StateStoreEncryptionSpec{KeyFile: &kops.StateStoreEncryptionKeyFileSpec{Path: keyFilePath}}, nil
+
+	case recorded.VaultTransit != nil:
+		address := recorded.VaultTransit.Address
+		if (local == nil || local.VaultTransit == nil || local.VaultTransit.Address != address) && os.Getenv("VAULT_ADDR") != address {
+			return nil, fmt.Errorf("file was encrypted with the Vault server at %q, which is not configured; set VAULT_ADDR to it to read the file", address)
+		}
+		return recorded, nil
+
+	default:
+		return recorded, nil
+	}
+}
+
+// GetKeyProvider returns the KeyProvider configured by spec
+func GetKeyProvider(spec *kops.StateStoreEncryptionSpec) (KeyProvider, error) {
+	b, err := json.Marshal(spec)
+	if err != nil {
+		return nil, fmt.Errorf("error serializing key provider configuration: %v", err)
+	}
+	cacheKey := string(b)
+
+	providersMutex.Lock()
+	defer providersMutex.Unlock()
+
+	if provider := providers[cacheKey]; provider != nil {
+		return provider, nil
+	}
+
+	var provider KeyProvider
+	switch {
+	case spec.KeyFile != nil && spec.VaultTransit == nil && spec.AWSKMS == nil:
+		provider, err = newKeyFileProvider(spec.KeyFile)
+	case spec.VaultTransit != nil && spec.KeyFile == nil && spec.AWSKMS == nil:
+		provider, err = newVaultTransitProvider(spec.VaultTransit)
+	case spec.AWSKMS != nil && spec.KeyFile == nil && spec.VaultTransit == nil:
+		provider, err = newAWSKMSProvider(spec.AWSKMS)
+	default:
+		return nil, fmt.Errorf("exactly one of keyFile, vaultTransit or awsKMS must be set")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	providers[cacheKey] = provider
+	return provider, nil
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stateencryption
+
+import (
+	"encoding/base64"
+	"fmt"
+	"path"
+
+	vault "github.com/hashicorp/vault/api"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/util/pkg/vfs"
+)
+
+// defaultTransitMountPath is where the Vault transit secrets engine is mounted by default
+const defaultTransitMountPath = "transit"
+
+// vaultTransitProvider seals data keys using the Vault transit secrets engine
+type vaultTransitProvider struct {
+	client    *vault.Client
+	mountPath string
+	key       string
+}
+
+var _ KeyProvider = &vaultTransitProvider{}
+
+func newVaultTransitProvider(spec *kops.StateStoreEncryptionVaultTransitSpec) (*vaultTransitProvider, error) {
+	client, err := vfs.NewVaultClient(spec.Address)
+	if err != nil {
+		return nil, fmt.Errorf("error building vault client: %v", err)
+	}
+
+	mountPath := spec.MountPath
+	if mountPath == "" {
+		mountPath = defaultTransitMountPath
+	}
+
+	return &vaultTransitProvider{
+		client:    client,
+		mountPath: mountPath,
+		key:       spec.Key,
+	}, nil
+}
+
+// Seal implements KeyProvider::Seal
+func (p *vaultTransitProvider) Seal(dataKey []byte) ([]byte, error) {
+	secret, err := p.client.Logical().Write(path.Join(p.mountPath, "encrypt", p.key), map[string]interface{}{
+		"plaintext": base64.StdEncoding.EncodeToString(dataKey),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error encrypting with vault transit key %q: %v", p.key, err)
+	}
+	if secret == nil || secret.Data == nil {
+		return nil, fmt.Errorf("vault transit key %q returned no ciphertext", p.key)
+	}
+	ciphertext, ok := secret.Data["ciphertext"].(string)
+	if !ok || ciphertext == "" {
+		return nil, fmt.Errorf("vault transit key %q returned no ciphertext", p.key)
+	}
+	return []byte(ciphertext), nil
+}
+
+// Unseal implements KeyProvider::Unseal
+func (p *vaultTransitProvider) Unseal(sealedKey []byte) ([]byte, error) {
+	secret, err := p.client.Logical().Write(path.Join(p.mountPath, "decrypt", p.key), map[string]interface{}{
+		"ciphertext": string(sealedKey),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error decrypting with vault transit key %q: %v", p.key, err)
+	}
+	if secret == nil || secret.Data == nil {
+		return nil, fmt.Errorf("vault transit key %q returned no plaintext", p.key)
+	}
+	plaintext, ok := secret.Data["plaintext"].(string)
+	if !ok {
+		return nil, fmt.Errorf("vault transit key %q returned no plaintext", p.key)
+	}
+	dataKey, err := base64.StdEncoding.DecodeString(plaintext)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding plaintext from vault transit key %q: %v", p.key, err)
+	}
+	return dataKey, nil
+}
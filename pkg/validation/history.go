@@ -0,0 +1,248 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validation
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// ValidationAttempt describes a single attempt at validating the cluster,
+// relative to the attempt before it.
+type ValidationAttempt struct {
+	// Attempt is the 1-based number of the attempt.
+	Attempt int `json:"attempt"`
+	// Timestamp is the time the attempt completed.
+	Timestamp time.Time `json:"timestamp"`
+	// ElapsedSeconds is the time since validation started.
+	ElapsedSeconds float64 `json:"elapsedSeconds"`
+	// Ready is true if the attempt found no failures.
+	Ready bool `json:"ready"`
+	// Error is set if the attempt could not be completed.
+	Error string `json:"error,omitempty"`
+	// FailureCount is the number of failures found by the attempt.
+	FailureCount int `json:"failureCount"`
+	// NewFailures are the failures that were not present in the previous attempt.
+	NewFailures []*ValidationError `json:"newFailures,omitempty"`
+	// ResolvedFailures are the failures of the previous attempt that are no longer present.
+	ResolvedFailures []*ValidationError `json:"resolvedFailures,omitempty"`
+	// NodesAdded are the nodes that were not present in the previous attempt.
+	NodesAdded []string `json:"nodesAdded,omitempty"`
+	// NodesRemoved are the nodes of the previous attempt that are no longer present.
+	NodesRemoved []string `json:"nodesRemoved,omitempty"`
+}
+
+// ValidationHistory records successive validation attempts, so we can report how each component converged.
+type ValidationHistory struct {
+	Start    time.Time
+	Attempts []*ValidationAttempt
+
+	// failures are the failures of the last completed attempt, by component
+	failures map[string]*ValidationError
+	// nodes are the nodes of the last completed attempt
+	nodes map[string]bool
+	// components tracks every component that failed at some point, by component
+	components map[string]*componentHistory
+}
+
+// componentHistory tracks the failures of a single component, identified by the kind and name of its ValidationErrors.
+type componentHistory struct {
+	kind       string
+	name       string
+	message    string
+	resolvedAt time.Time
+}
+
+// NewValidationHistory creates a ValidationHistory for validation that started at the given time.
+func NewValidationHistory(start time.Time) *ValidationHistory {
+	return &ValidationHistory{
+		Start:      start,
+		failures:   make(map[string]*ValidationError),
+		nodes:      make(map[string]bool),
+		components: make(map[string]*componentHistory),
+	}
+}
+
+func componentKey(failure *ValidationError) string {
+	return failure.Kind + "/" + failure.Name
+}
+
+// Record adds the outcome of a validation attempt that completed at the given time.
+// If validation returned an error, the attempt records the error and the previous failures and nodes are kept for comparison.
+func (h *ValidationHistory) Record(now time.Time, result *ValidationCluster, validationErr error) *ValidationAttempt {
+	attempt := &ValidationAttempt{
+		Attempt:        len(h.Attempts) + 1,
+		Timestamp:      now,
+		ElapsedSeconds: now.Sub(h.Start).Seconds(),
+	}
+	h.Attempts = append(h.Attempts, attempt)
+
+	if validationErr != nil {
+		attempt.Error = validationErr.Error()
+		return attempt
+	}
+
+	failures := make(map[string]*ValidationError)
+	for _, failure := range result.Failures {
+		key := componentKey(failure)
+		failures[key] = failure
+		if h.failures[key] == nil {
+			attempt.NewFailures = append(attempt.NewFailures, failure)
+		}
+
+		component := h.components[key]
+		if component == nil {
+			component = &componentHistory{kind: failure.Kind, name: failure.Name}
+			h.components[key] = component
+		}
+		component.message = failure.Message
+		component.resolvedAt = time.Time{}
+	}
+	for key, failure := range h.failures {
+		if failures[key] == nil {
+			attempt.ResolvedFailures = append(attempt.ResolvedFailures, failure)
+			h.components[key].resolvedAt = now
+		}
+	}
+	sortFailures(attempt.ResolvedFailures)
+	h.failures = failures
+
+	nodes := make(map[string]bool)
+	for _, node := range result.Nodes {
+		nodes[node.Name] = true
+		if !h.nodes[node.Name] {
+			attempt.NodesAdded = append(attempt.NodesAdded, node.Name)
+		}
+	}
+	for name := range h.nodes {
+		if !nodes[name] {
+			attempt.NodesRemoved = append(attempt.NodesRemoved, name)
+		}
+	}
+	sort.Strings(attempt.NodesAdded)
+	sort.Strings(attempt.NodesRemoved)
+	h.nodes = nodes
+
+	attempt.FailureCount = len(result.Failures)
+	attempt.Ready = len(result.Failures) == 0
+	return attempt
+}
+
+func sortFailures(failures []*ValidationError) {
+	sort.Slice(failures, func(i, j int) bool {
+		return componentKey(failures[i]) < componentKey(failures[j])
+	})
+}
+
+type junitTestSuite struct {
+	XMLName   xml.Name        `xml:"testsuite"`
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Time      float64         `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      float64       `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit writes a JUnit report with one test case per component that failed validation at some point,
+// timed by how long it took to converge, and a test case for the cluster as a whole.
+func (h *ValidationHistory) WriteJUnit(w io.Writer, clusterName string) error {
+	suite := &junitTestSuite{
+		Name:      "kops validate cluster " + clusterName,
+		Timestamp: h.Start.UTC().Format(time.RFC3339),
+	}
+
+	var end time.Time
+	ready := false
+	if len(h.Attempts) != 0 {
+		last := h.Attempts[len(h.Attempts)-1]
+		end = last.Timestamp
+		ready = last.Ready
+	} else {
+		end = h.Start
+	}
+
+	var keys []string
+	for key := range h.components {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		component := h.components[key]
+		testCase := junitTestCase{
+			Name:      component.name,
+			ClassName: component.kind,
+		}
+		if component.resolvedAt.IsZero() {
+			testCase.Time = end.Sub(h.Start).Seconds()
+			testCase.Failure = &junitFailure{
+				Message: component.message,
+				Text:    component.message,
+			}
+		} else {
+			testCase.Time = component.resolvedAt.Sub(h.Start).Seconds()
+		}
+		suite.TestCases = append(suite.TestCases, testCase)
+	}
+
+	clusterCase := junitTestCase{
+		Name:      clusterName,
+		ClassName: "Cluster",
+		Time:      end.Sub(h.Start).Seconds(),
+	}
+	if !ready {
+		message := fmt.Sprintf("cluster did not validate after %d attempts", len(h.Attempts))
+		clusterCase.Failure = &junitFailure{Message: message, Text: message}
+	}
+	suite.TestCases = append(suite.TestCases, clusterCase)
+
+	suite.Tests = len(suite.TestCases)
+	for _, testCase := range suite.TestCases {
+		if testCase.Failure != nil {
+			suite.Failures++
+		}
+	}
+	suite.Time = end.Sub(h.Start).Seconds()
+
+	b, err := xml.MarshalIndent(suite, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshaling JUnit report: %w", err)
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	if _, err := w.Write(b); err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "\n")
+	return err
+}
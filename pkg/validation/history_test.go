@@ -0,0 +1,94 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validation
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ValidationHistory(t *testing.T) {
+	start := time.Date(2022, 5, 1, 10, 0, 0, 0, time.UTC)
+	h := NewValidationHistory(start)
+
+	podFailure := &ValidationError{Kind: "Pod", Name: "kube-system/coredns", Message: "pod is pending"}
+	nodeFailure := &ValidationError{Kind: "Node", Name: "node-b", Message: "node is not ready"}
+
+	attempt := h.Record(start.Add(10*time.Second), &ValidationCluster{
+		Failures: []*ValidationError{podFailure},
+		Nodes:    []*ValidationNode{{Name: "master-a"}},
+	}, nil)
+	assert.Equal(t, 1, attempt.Attempt)
+	assert.Equal(t, 10.0, attempt.ElapsedSeconds)
+	assert.False(t, attempt.Ready)
+	assert.Equal(t, []*ValidationError{podFailure}, attempt.NewFailures)
+	assert.Equal(t, []string{"master-a"}, attempt.NodesAdded)
+
+	attempt = h.Record(start.Add(20*time.Second), nil, fmt.Errorf("connection refused"))
+	assert.Equal(t, "connection refused", attempt.Error)
+	assert.Empty(t, attempt.NewFailures)
+
+	attempt = h.Record(start.Add(30*time.Second), &ValidationCluster{
+		Failures: []*ValidationError{nodeFailure},
+		Nodes:    []*ValidationNode{{Name: "node-b"}},
+	}, nil)
+	assert.Equal(t, []*ValidationError{nodeFailure}, attempt.NewFailures)
+	assert.Equal(t, []*ValidationError{podFailure}, attempt.ResolvedFailures)
+	assert.Equal(t, []string{"node-b"}, attempt.NodesAdded)
+	assert.Equal(t, []string{"master-a"}, attempt.NodesRemoved)
+	assert.Equal(t, 1, attempt.FailureCount)
+
+	var b bytes.Buffer
+	if err := h.WriteJUnit(&b, "test.k8s.local"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `<?xml version="1.0" encoding="UTF-8"?>
+<testsuite name="kops validate cluster test.k8s.local" tests="3" failures="2" time="30" timestamp="2022-05-01T10:00:00Z">
+  <testcase name="node-b" classname="Node" time="30">
+    <failure message="node is not ready">node is not ready</failure>
+  </testcase>
+  <testcase name="kube-system/coredns" classname="Pod" time="30"></testcase>
+  <testcase name="test.k8s.local" classname="Cluster" time="30">
+    <failure message="cluster did not validate after 3 attempts">cluster did not validate after 3 attempts</failure>
+  </testcase>
+</testsuite>
+`
+	assert.Equal(t, expected, b.String())
+
+	attempt = h.Record(start.Add(45*time.Second), &ValidationCluster{
+		Nodes: []*ValidationNode{{Name: "node-b"}},
+	}, nil)
+	assert.True(t, attempt.Ready)
+	assert.Equal(t, []*ValidationError{nodeFailure}, attempt.ResolvedFailures)
+
+	b.Reset()
+	if err := h.WriteJUnit(&b, "test.k8s.local"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected = `<?xml version="1.0" encoding="UTF-8"?>
+<testsuite name="kops validate cluster test.k8s.local" tests="3" failures="0" time="45" timestamp="2022-05-01T10:00:00Z">
+  <testcase name="node-b" classname="Node" time="45"></testcase>
+  <testcase name="kube-system/coredns" classname="Pod" time="30"></testcase>
+  <testcase name="test.k8s.local" classname="Cluster" time="45"></testcase>
+</testsuite>
+`
+	assert.Equal(t, expected, b.String())
+}
@@ -172,6 +172,8 @@ func (v *clusterValidatorImpl) Validate() (*ValidationCluster, error) {
 		return nil, fmt.Errorf("cannot get pod health for %q: %v", clusterName, err)
 	}
 
+	validation.collectValidationCheckFailures(ctx, v.k8sClient, v.cluster.Spec.ValidationChecks)
+
 	return validation, nil
 }
 
@@ -0,0 +1,105 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/readiness"
+)
+
+// collectValidationCheckFailures evaluates the additional checks from the cluster spec.
+// The failures are not attributed to an instance group, so they are relevant to every instance group being updated.
+func (v *ValidationCluster) collectValidationCheckFailures(ctx context.Context, client kubernetes.Interface, checks []kops.ValidationCheck) {
+	for _, check := range checks {
+		var problem string
+		switch {
+		case check.Deployment != nil:
+			problem = checkDeployment(ctx, client, check.Deployment)
+		case check.HTTPGet != nil:
+			problem = checkHTTPGet(ctx, client, check.HTTPGet)
+		case check.CustomResourceDefinition != nil:
+			problem = checkCustomResourceDefinition(ctx, client, check.CustomResourceDefinition)
+		default:
+			klog.Warningf("ignoring validation check %q with no check type", check.Name)
+		}
+		if problem != "" {
+			v.addError(&ValidationError{
+				Kind:    "ValidationCheck",
+				Name:    check.Name,
+				Message: fmt.Sprintf("validation check %q failed: %s", check.Name, problem),
+			})
+		}
+	}
+}
+
+// checkDeployment returns a description of why the Deployment is not ready, or an empty string if it is ready.
+func checkDeployment(ctx context.Context, client kubernetes.Interface, check *kops.DeploymentValidationCheck) string {
+	d, err := client.AppsV1().Deployments(check.Namespace).Get(ctx, check.Name, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return fmt.Sprintf("deployment %s/%s not found", check.Namespace, check.Name)
+		}
+		return fmt.Sprintf("error getting deployment %s/%s: %v", check.Namespace, check.Name, err)
+	}
+
+	return readiness.DeploymentProblem(d)
+}
+
+// checkHTTPGet returns a description of why the endpoint did not respond successfully, or an empty string if it did.
+// The request goes through the API server's service proxy, so the endpoint does not need to be reachable from where kops runs.
+func checkHTTPGet(ctx context.Context, client kubernetes.Interface, check *kops.HTTPGetValidationCheck) string {
+	path := check.Path
+	if path == "" {
+		path = "/"
+	}
+	if _, err := client.CoreV1().Services(check.Namespace).ProxyGet("", check.Service, check.Port, path, nil).DoRaw(ctx); err != nil {
+		return fmt.Sprintf("GET %s on service %s/%s port %s failed: %v", path, check.Namespace, check.Service, check.Port, err)
+	}
+	return ""
+}
+
+// checkCustomResourceDefinition returns a description of why the CRD is not established, or an empty string if it is.
+// The CRD is read through the discovery REST client, as the clientset has no apiextensions client.
+func checkCustomResourceDefinition(ctx context.Context, client kubernetes.Interface, check *kops.CustomResourceDefinitionValidationCheck) string {
+	data, err := client.Discovery().RESTClient().Get().AbsPath("/apis/apiextensions.k8s.io/v1/customresourcedefinitions", check.Name).Do(ctx).Raw()
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return fmt.Sprintf("CustomResourceDefinition %s not found", check.Name)
+		}
+		return fmt.Sprintf("error getting CustomResourceDefinition %s: %v", check.Name, err)
+	}
+
+	crd := &apiextensionsv1.CustomResourceDefinition{}
+	if err := json.Unmarshal(data, crd); err != nil {
+		return fmt.Sprintf("error parsing CustomResourceDefinition %s: %v", check.Name, err)
+	}
+	for _, condition := range crd.Status.Conditions {
+		if condition.Type == apiextensionsv1.Established && condition.Status == apiextensionsv1.ConditionTrue {
+			return ""
+		}
+	}
+	return fmt.Sprintf("CustomResourceDefinition %s is not established", check.Name)
+}
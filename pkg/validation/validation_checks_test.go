@@ -0,0 +1,170 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	restclient "k8s.io/client-go/rest"
+	restfake "k8s.io/client-go/rest/fake"
+	k8stesting "k8s.io/client-go/testing"
+	kopsapi "k8s.io/kops/pkg/apis/kops"
+)
+
+type fakeResponse struct {
+	err error
+}
+
+func (r *fakeResponse) DoRaw(ctx context.Context) ([]byte, error) {
+	return nil, r.err
+}
+
+func (r *fakeResponse) Stream(ctx context.Context) (io.ReadCloser, error) {
+	return nil, r.err
+}
+
+// crdClient serves CustomResourceDefinitions through the discovery REST client.
+type crdClient struct {
+	*fake.Clientset
+	crds map[string]*apiextensionsv1.CustomResourceDefinition
+}
+
+type crdDiscovery struct {
+	discovery.DiscoveryInterface
+	client *crdClient
+}
+
+func (c *crdClient) Discovery() discovery.DiscoveryInterface {
+	return &crdDiscovery{DiscoveryInterface: c.Clientset.Discovery(), client: c}
+}
+
+func (d *crdDiscovery) RESTClient() restclient.Interface {
+	return &restfake.RESTClient{
+		NegotiatedSerializer: scheme.Codecs.WithoutConversion(),
+		Client: restfake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+			crd := d.client.crds[path.Base(req.URL.Path)]
+			if crd == nil {
+				return &http.Response{StatusCode: http.StatusNotFound, Body: io.NopCloser(strings.NewReader(""))}, nil
+			}
+			data, err := json.Marshal(crd)
+			if err != nil {
+				return nil, err
+			}
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader(data))}, nil
+		}),
+	}
+}
+
+func crd(name string, established apiextensionsv1.ConditionStatus) *apiextensionsv1.CustomResourceDefinition {
+	return &apiextensionsv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: apiextensionsv1.CustomResourceDefinitionStatus{
+			Conditions: []apiextensionsv1.CustomResourceDefinitionCondition{
+				{Type: apiextensionsv1.NamesAccepted, Status: apiextensionsv1.ConditionTrue},
+				{Type: apiextensionsv1.Established, Status: established},
+			},
+		},
+	}
+}
+
+func deployment(name string, replicas, available int32) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "apps", Name: name},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+		Status: appsv1.DeploymentStatus{
+			UpdatedReplicas:   replicas,
+			AvailableReplicas: available,
+		},
+	}
+}
+
+func Test_ValidationChecks(t *testing.T) {
+	client := &crdClient{
+		Clientset: fake.NewSimpleClientset(
+			deployment("ready", 2, 2),
+			deployment("unavailable", 2, 1),
+		),
+		crds: map[string]*apiextensionsv1.CustomResourceDefinition{
+			"widgets.example.com": crd("widgets.example.com", apiextensionsv1.ConditionTrue),
+			"gadgets.example.com": crd("gadgets.example.com", apiextensionsv1.ConditionFalse),
+		},
+	}
+	client.PrependProxyReactor("services", func(action k8stesting.Action) (bool, restclient.ResponseWrapper, error) {
+		proxy := action.(k8stesting.ProxyGetAction)
+		if proxy.GetName() == "healthy" && proxy.GetPath() == "/healthz" {
+			return true, &fakeResponse{}, nil
+		}
+		return true, &fakeResponse{err: fmt.Errorf("the server is currently unable to handle the request")}, nil
+	})
+
+	checks := []kopsapi.ValidationCheck{
+		{Name: "deployment-ready", Deployment: &kopsapi.DeploymentValidationCheck{Namespace: "apps", Name: "ready"}},
+		{Name: "deployment-unavailable", Deployment: &kopsapi.DeploymentValidationCheck{Namespace: "apps", Name: "unavailable"}},
+		{Name: "deployment-missing", Deployment: &kopsapi.DeploymentValidationCheck{Namespace: "apps", Name: "missing"}},
+		{Name: "http-healthy", HTTPGet: &kopsapi.HTTPGetValidationCheck{Namespace: "apps", Service: "healthy", Port: "http", Path: "/healthz"}},
+		{Name: "http-unhealthy", HTTPGet: &kopsapi.HTTPGetValidationCheck{Namespace: "apps", Service: "unhealthy", Port: "http"}},
+		{Name: "crd-established", CustomResourceDefinition: &kopsapi.CustomResourceDefinitionValidationCheck{Name: "widgets.example.com"}},
+		{Name: "crd-not-established", CustomResourceDefinition: &kopsapi.CustomResourceDefinitionValidationCheck{Name: "gadgets.example.com"}},
+		{Name: "crd-missing", CustomResourceDefinition: &kopsapi.CustomResourceDefinitionValidationCheck{Name: "gizmos.example.com"}},
+	}
+
+	v := &ValidationCluster{}
+	v.collectValidationCheckFailures(context.Background(), client, checks)
+
+	assert.Equal(t, []*ValidationError{
+		{
+			Kind:    "ValidationCheck",
+			Name:    "deployment-unavailable",
+			Message: "validation check \"deployment-unavailable\" failed: deployment apps/unavailable has 2/2 updated and 1/2 available replicas",
+		},
+		{
+			Kind:    "ValidationCheck",
+			Name:    "deployment-missing",
+			Message: "validation check \"deployment-missing\" failed: deployment apps/missing not found",
+		},
+		{
+			Kind:    "ValidationCheck",
+			Name:    "http-unhealthy",
+			Message: "validation check \"http-unhealthy\" failed: GET / on service apps/unhealthy port http failed: the server is currently unable to handle the request",
+		},
+		{
+			Kind:    "ValidationCheck",
+			Name:    "crd-not-established",
+			Message: "validation check \"crd-not-established\" failed: CustomResourceDefinition gadgets.example.com is not established",
+		},
+		{
+			Kind:    "ValidationCheck",
+			Name:    "crd-missing",
+			Message: "validation check \"crd-missing\" failed: CustomResourceDefinition gizmos.example.com not found",
+		},
+	}, v.Failures)
+}
@@ -21,6 +21,7 @@ import (
 	"os"
 	"os/exec"
 	"strings"
+	"time"
 
 	"k8s.io/klog/v2"
 )
@@ -30,11 +31,20 @@ func applyChannel(channel string) error {
 	// We don't embed the channels code because we expect this will eventually be part of kubectl
 	klog.Infof("checking channel: %q", channel)
 
-	out, err := execChannels("apply", "channel", channel, "--v=4", "--yes")
+	out, err := execChannels(applyChannelArgs(channel)...)
 	klog.V(4).Infof("apply channel output was: %v", out)
 	return err
 }
 
+// channelsHealthTimeout is how long channels waits for the workloads of an updated addon to become ready,
+// before rolling back to the previously installed manifest
+const channelsHealthTimeout = 5 * time.Minute
+
+// applyChannelArgs returns the arguments to channels that apply the channel
+func applyChannelArgs(channel string) []string {
+	return []string{"apply", "channel", channel, "--v=4", "--yes", "--health-timeout=" + channelsHealthTimeout.String()}
+}
+
 func execChannels(args ...string) (string, error) {
 	channelsPath := "/opt/kops/bin/channels"
 	cmd := exec.Command(channelsPath, args...)
@@ -0,0 +1,60 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package protokube
+
+import (
+	"io"
+	"testing"
+
+	"k8s.io/klog/v2"
+	channelscmd "k8s.io/kops/channels/pkg/cmd"
+)
+
+// TestApplyChannelArgsEnableHealthChecking parses the arguments protokube runs channels with,
+// to check that addon updates are gated on the health of their workloads
+func TestApplyChannelArgsEnableHealthChecking(t *testing.T) {
+	// channels registers the klog flags, such as --v
+	klog.InitFlags(nil)
+
+	args := applyChannelArgs("s3://bucket/cluster.example.com/addons/bootstrap-channel.yaml")
+
+	root := channelscmd.NewCmdRoot(nil, io.Discard)
+	cmd, flags, err := root.Find(args)
+	if err != nil {
+		t.Fatalf("error finding channels command for %v: %v", args, err)
+	}
+	if cmd.CommandPath() != "channels apply channel" {
+		t.Fatalf("unexpected channels command %q for %v", cmd.CommandPath(), args)
+	}
+	if err := cmd.ParseFlags(flags); err != nil {
+		t.Fatalf("error parsing channels flags %v: %v", flags, err)
+	}
+
+	if yes, err := cmd.Flags().GetBool("yes"); err != nil || !yes {
+		t.Errorf("expected --yes to be set, got %v, %v", yes, err)
+	}
+	healthTimeout, err := cmd.Flags().GetDuration("health-timeout")
+	if err != nil {
+		t.Fatalf("error reading --health-timeout: %v", err)
+	}
+	if healthTimeout <= 0 {
+		t.Errorf("expected health checking to be enabled, got --health-timeout=%v", healthTimeout)
+	}
+	if healthTimeout != channelsHealthTimeout {
+		t.Errorf("unexpected health timeout, actual=%v, expected=%v", healthTimeout, channelsHealthTimeout)
+	}
+}
@@ -55,6 +55,7 @@ import (
 	"k8s.io/kops/pkg/model/hetznermodel"
 	"k8s.io/kops/pkg/model/iam"
 	"k8s.io/kops/pkg/model/openstackmodel"
+	"k8s.io/kops/pkg/ospackages"
 	"k8s.io/kops/pkg/templates"
 	"k8s.io/kops/pkg/wellknownports"
 	"k8s.io/kops/upup/models"
@@ -144,10 +145,13 @@ type ApplyClusterCmd struct {
 	ImageAssets []*assets.ImageAsset
 	// FileAssets are the file assets we use (output).
 	FileAssets []*assets.FileAsset
+
+	// TaskReport is the execution timeline of the tasks (output).
+	TaskReport *fi.TaskReport
 }
 
 func (c *ApplyClusterCmd) Run(ctx context.Context) error {
-	if c.TargetName == TargetTerraform {
+	if c.TargetName == TargetTerraform || c.TargetName == TargetTerraformJSON {
 		found := false
 		for _, cp := range TerraformCloudProviders {
 			if c.Cloud.ProviderID() == cp {
@@ -482,7 +486,21 @@ func (c *ApplyClusterCmd) Run(ctx context.Context) error {
 		cloud:            cloud,
 	}
 
-	configBuilder, err := newNodeUpConfigBuilder(cluster, assetBuilder, c.Assets, encryptionConfigSecretHash)
+	var osPackageBundleHashes map[string]string
+	if cluster.Spec.Assets != nil && fi.BoolValue(cluster.Spec.Assets.OSPackageBundle) && !c.GetAssets {
+		// The bundles are written by get assets, so they are only pinned when building the configuration of real nodes
+		base := ospackages.BaseURL(fi.StringValue(cluster.Spec.Assets.FileRepository))
+		manifest, err := assets.ReadOSPackageManifest(base)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return fmt.Errorf("no OS package bundles were found in %q; run kops get assets --copy --bundle-os-packages first", base)
+			}
+			return err
+		}
+		osPackageBundleHashes = manifest.Indexes
+	}
+
+	configBuilder, err := newNodeUpConfigBuilder(cluster, assetBuilder, c.Assets, encryptionConfigSecretHash, osPackageBundleHashes)
 	if err != nil {
 		return err
 	}
@@ -684,7 +702,7 @@ func (c *ApplyClusterCmd) Run(ctx context.Context) error {
 			return fmt.Errorf("direct configuration not supported with CloudProvider:%q", cluster.Spec.GetCloudProvider())
 		}
 
-	case TargetTerraform:
+	case TargetTerraform, TargetTerraformJSON:
 		checkExisting = false
 		outDir := c.OutDir
 		var vfsProvider *vfs.TerraformProvider
@@ -695,7 +713,12 @@ func (c *ApplyClusterCmd) Run(ctx context.Context) error {
 				return err
 			}
 		}
-		tf := terraform.NewTerraformTarget(cloud, project, vfsProvider, outDir, cluster.Spec.Target)
+		var tf *terraform.TerraformTarget
+		if c.TargetName == TargetTerraformJSON {
+			tf = terraform.NewTerraformJSONTarget(cloud, project, vfsProvider, outDir, cluster.Spec.Target)
+		} else {
+			tf = terraform.NewTerraformTarget(cloud, project, vfsProvider, outDir, cluster.Spec.Target)
+		}
 
 		// We include a few "util" variables in the TF output
 		if err := tf.AddOutputVariable("region", terraformWriter.LiteralFromStringValue(cloud.Region())); err != nil {
@@ -769,6 +792,7 @@ func (c *ApplyClusterCmd) Run(ctx context.Context) error {
 	}
 
 	err = context.RunTasks(options)
+	c.TaskReport = context.TaskReport()
 	if err != nil {
 		return fmt.Errorf("error running tasks: %v", err)
 	}
@@ -1123,9 +1147,11 @@ type nodeUpConfigBuilder struct {
 	protokubeAsset             map[architectures.Architecture][]*mirrors.MirroredAsset
 	channelsAsset              map[architectures.Architecture][]*mirrors.MirroredAsset
 	encryptionConfigSecretHash string
+	// osPackageBundleHashes are the hashes of the indexes of the OS package bundles, by distribution and architecture
+	osPackageBundleHashes map[string]string
 }
 
-func newNodeUpConfigBuilder(cluster *kops.Cluster, assetBuilder *assets.AssetBuilder, assets map[architectures.Architecture][]*mirrors.MirroredAsset, encryptionConfigSecretHash string) (model.NodeUpConfigBuilder, error) {
+func newNodeUpConfigBuilder(cluster *kops.Cluster, assetBuilder *assets.AssetBuilder, assets map[architectures.Architecture][]*mirrors.MirroredAsset, encryptionConfigSecretHash string, osPackageBundleHashes map[string]string) (model.NodeUpConfigBuilder, error) {
 	configBase, err := vfs.Context.BuildVfsPath(cluster.Spec.ConfigBase)
 	if err != nil {
 		return nil, fmt.Errorf("error parsing config base %q: %v", cluster.Spec.ConfigBase, err)
@@ -1266,6 +1292,7 @@ func newNodeUpConfigBuilder(cluster *kops.Cluster, assetBuilder *assets.AssetBui
 		protokubeAsset:             protokubeAsset,
 		channelsAsset:              channelsAsset,
 		encryptionConfigSecretHash: encryptionConfigSecretHash,
+		osPackageBundleHashes:      osPackageBundleHashes,
 	}
 
 	return &configBuilder, nil
@@ -1434,6 +1461,11 @@ func (n *nodeUpConfigBuilder) BuildConfig(ig *kops.InstanceGroup, apiserverAddit
 		config.Packages = ig.Spec.Packages
 	}
 
+	if cluster.Spec.Assets != nil && fi.BoolValue(cluster.Spec.Assets.OSPackageBundle) {
+		config.OSPackageBundle = ospackages.BaseURL(fi.StringValue(cluster.Spec.Assets.FileRepository))
+		config.OSPackageBundleHashes = n.osPackageBundleHashes
+	}
+
 	return config, bootConfig, nil
 }
 
@@ -337,6 +337,87 @@ terraform {
 	doRenderTests(t, "RenderTerraform", cases)
 }
 
+func TestAutoscalingGroupTerraformJSONRender(t *testing.T) {
+	cases := []*renderTest{
+		{
+			Resource: &AutoscalingGroup{
+				Name:           fi.String("test"),
+				Granularity:    fi.String("5min"),
+				LaunchTemplate: &LaunchTemplate{Name: fi.String("test_lc")},
+				MaxSize:        fi.Int64(10),
+				Metrics:        []string{"test"},
+				MinSize:        fi.Int64(1),
+				Subnets: []*Subnet{
+					{
+						Name: fi.String("test-sg"),
+						ID:   fi.String("sg-1111"),
+					},
+				},
+				Tags: map[string]string{
+					"test":    "tag",
+					"cluster": "test",
+				},
+			},
+			Expected: `{
+  "provider": {
+    "aws": [
+      {
+        "region": "eu-west-2"
+      }
+    ]
+  },
+  "resource": {
+    "aws_autoscaling_group": {
+      "test": {
+        "enabled_metrics": [
+          "test"
+        ],
+        "launch_template": {
+          "id": "${aws_launch_template.test_lc.id}",
+          "version": "${aws_launch_template.test_lc.latest_version}"
+        },
+        "max_size": 10,
+        "metrics_granularity": "5min",
+        "min_size": 1,
+        "name": "test",
+        "tag": [
+          {
+            "key": "cluster",
+            "propagate_at_launch": true,
+            "value": "test"
+          },
+          {
+            "key": "test",
+            "propagate_at_launch": true,
+            "value": "tag"
+          }
+        ],
+        "vpc_zone_identifier": [
+          "${aws_subnet.test-sg.id}"
+        ]
+      }
+    }
+  },
+  "terraform": {
+    "required_providers": {
+      "aws": {
+        "configuration_aliases": [
+          "aws.files"
+        ],
+        "source": "hashicorp/aws",
+        "version": ">= 4.0.0"
+      }
+    },
+    "required_version": ">= 0.15.0"
+  }
+}
+`,
+		},
+	}
+
+	doRenderTests(t, "RenderTerraformJSON", cases)
+}
+
 func TestAutoscalingGroupCloudformationRender(t *testing.T) {
 	cases := []*renderTest{
 		{
@@ -40,6 +40,7 @@ func doRenderTests(t *testing.T, method string, cases []*renderTest) {
 	for i, c := range cases {
 		var filename string
 		var target interface{}
+		renderMethod := method
 
 		cloud := awsup.BuildMockAWSCloud("eu-west-2", "abc")
 
@@ -47,6 +48,10 @@ func doRenderTests(t *testing.T, method string, cases []*renderTest) {
 		case "RenderTerraform":
 			target = terraform.NewTerraformTarget(cloud, "test", nil, outdir, nil)
 			filename = "kubernetes.tf"
+		case "RenderTerraformJSON":
+			target = terraform.NewTerraformJSONTarget(cloud, "test", nil, outdir, nil)
+			filename = "main.tf.json"
+			renderMethod = "RenderTerraform"
 		case "RenderCloudformation":
 			target = cloudformation.NewCloudformationTarget(cloud, "test", outdir)
 			filename = "kubernetes.json"
@@ -63,7 +68,7 @@ func doRenderTests(t *testing.T, method string, cases []*renderTest) {
 
 		err := func() error {
 			// @step: invoke the rendering method of the target
-			resp := reflect.ValueOf(c.Resource).MethodByName(method).Call(inputs)
+			resp := reflect.ValueOf(c.Resource).MethodByName(renderMethod).Call(inputs)
 			if err := resp[0].Interface(); err != nil {
 				return err.(error)
 			}
@@ -0,0 +1,102 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awsup
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"k8s.io/kops/pkg/bootstrap"
+)
+
+// AWSUserVerifierOptions configures the verification of users by their AWS identity.
+type AWSUserVerifierOptions struct {
+	// Principals are the ARNs of the IAM users and roles that are permitted.
+	Principals []string `json:"principals"`
+	// Region is the AWS region of the cluster.
+	Region string `json:"region"`
+}
+
+type awsUserVerifier struct {
+	verifier   *awsVerifier
+	principals []string
+}
+
+var _ bootstrap.Verifier = &awsUserVerifier{}
+
+// NewAWSUserVerifier returns a verifier for requests signed with the AWS credentials of a permitted IAM user or role.
+func NewAWSUserVerifier(opt *AWSUserVerifierOptions) (bootstrap.Verifier, error) {
+	verifier, err := newAWSVerifier(&AWSVerifierOptions{Region: opt.Region})
+	if err != nil {
+		return nil, err
+	}
+	return &awsUserVerifier{
+		verifier:   verifier,
+		principals: opt.Principals,
+	}, nil
+}
+
+func (a *awsUserVerifier) VerifyToken(ctx context.Context, token string, body []byte, useInstanceIDForNodeName bool) (*bootstrap.VerifyResult, error) {
+	callerARN, err := a.verifier.verifyCallerIdentity(token, body)
+	if err != nil {
+		return nil, err
+	}
+
+	if !matchesPrincipal(callerARN, a.principals) {
+		return nil, fmt.Errorf("arn %q is not a permitted principal", callerARN)
+	}
+
+	return &bootstrap.VerifyResult{
+		User: callerARN,
+	}, nil
+}
+
+// matchesPrincipal returns whether the caller ARN returned by GetCallerIdentity is one of the IAM principals.
+// A caller that has assumed a role matches the ARN of the role.
+func matchesPrincipal(callerARN string, principals []string) bool {
+	caller, err := arn.Parse(callerARN)
+	if err != nil {
+		return false
+	}
+
+	for _, p := range principals {
+		principal, err := arn.Parse(p)
+		if err != nil || principal.Partition != caller.Partition || principal.AccountID != caller.AccountID || principal.Service != "iam" {
+			continue
+		}
+
+		switch {
+		case caller.Service == "iam" && strings.HasPrefix(caller.Resource, "user/"):
+			// The caller ARN of a user includes its path, as does the principal
+			if principal.Resource == caller.Resource {
+				return true
+			}
+		case caller.Service == "sts" && strings.HasPrefix(caller.Resource, "assumed-role/"):
+			// The caller ARN is assumed-role/<name>/<session>, without the path of the role
+			parts := strings.Split(caller.Resource, "/")
+			if len(parts) != 3 || !strings.HasPrefix(principal.Resource, "role/") {
+				continue
+			}
+			if principal.Resource[strings.LastIndex(principal.Resource, "/")+1:] == parts[1] {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,52 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awsup
+
+import (
+	"testing"
+)
+
+func TestMatchesPrincipal(t *testing.T) {
+	principals := []string{
+		"arn:aws:iam::123456789012:user/alice",
+		"arn:aws:iam::123456789012:user/team/bob",
+		"arn:aws:iam::123456789012:role/path/to/admin",
+	}
+
+	grid := []struct {
+		caller   string
+		expected bool
+	}{
+		{caller: "arn:aws:iam::123456789012:user/alice", expected: true},
+		{caller: "arn:aws:iam::123456789012:user/team/bob", expected: true},
+		{caller: "arn:aws:iam::123456789012:user/bob", expected: false},
+		{caller: "arn:aws:iam::123456789012:user/mallory", expected: false},
+		{caller: "arn:aws:sts::123456789012:assumed-role/admin/session", expected: true},
+		{caller: "arn:aws:sts::123456789012:assumed-role/nodes/i-0123456789abcdef0", expected: false},
+		{caller: "arn:aws:sts::210987654321:assumed-role/admin/session", expected: false},
+		{caller: "arn:aws-cn:sts::123456789012:assumed-role/admin/session", expected: false},
+		{caller: "arn:aws:sts::123456789012:federated-user/alice", expected: false},
+		{caller: "not-an-arn", expected: false},
+	}
+	for _, g := range grid {
+		t.Run(g.caller, func(t *testing.T) {
+			if actual := matchesPrincipal(g.caller, principals); actual != g.expected {
+				t.Errorf("expected %v, got %v", g.expected, actual)
+			}
+		})
+	}
+}
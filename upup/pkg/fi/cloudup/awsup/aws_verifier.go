@@ -60,6 +60,10 @@ type awsVerifier struct {
 var _ bootstrap.Verifier = &awsVerifier{}
 
 func NewAWSVerifier(opt *AWSVerifierOptions) (bootstrap.Verifier, error) {
+	return newAWSVerifier(opt)
+}
+
+func newAWSVerifier(opt *AWSVerifierOptions) (*awsVerifier, error) {
 	config := aws.NewConfig().
 		WithCredentialsChainVerboseErrors(true).
 		WithRegion(opt.Region).
@@ -121,67 +125,11 @@ type ResponseMetadata struct {
 }
 
 func (a awsVerifier) VerifyToken(ctx context.Context, token string, body []byte, useInstanceIDForNodeName bool) (*bootstrap.VerifyResult, error) {
-	if !strings.HasPrefix(token, AWSAuthenticationTokenPrefix) {
-		return nil, fmt.Errorf("incorrect authorization type")
-	}
-	token = strings.TrimPrefix(token, AWSAuthenticationTokenPrefix)
-
-	// We rely on the client and server using the same version of the same STS library.
-	stsRequest, _ := a.sts.GetCallerIdentityRequest(nil)
-	err := stsRequest.Sign()
-	if err != nil {
-		return nil, fmt.Errorf("creating identity request: %v", err)
-	}
-
-	stsRequest.HTTPRequest.Header = nil
-	tokenBytes, err := base64.StdEncoding.DecodeString(token)
-	if err != nil {
-		return nil, fmt.Errorf("decoding authorization token: %v", err)
-	}
-	err = json.Unmarshal(tokenBytes, &stsRequest.HTTPRequest.Header)
-	if err != nil {
-		return nil, fmt.Errorf("unmarshalling authorization token: %v", err)
-	}
-
-	// Verify the token has signed the body content.
-	sha := sha256.Sum256(body)
-	if stsRequest.HTTPRequest.Header.Get("X-Kops-Request-SHA") != base64.RawStdEncoding.EncodeToString(sha[:]) {
-		return nil, fmt.Errorf("incorrect SHA")
-	}
-
-	requestBytes, _ := io.ReadAll(stsRequest.Body)
-	_, _ = stsRequest.Body.Seek(0, io.SeekStart)
-	if stsRequest.HTTPRequest.Header.Get("Content-Length") != strconv.Itoa(len(requestBytes)) {
-		return nil, fmt.Errorf("incorrect content-length")
-	}
-
-	response, err := a.client.Do(stsRequest.HTTPRequest)
-	if err != nil {
-		return nil, fmt.Errorf("sending STS request: %v", err)
-	}
-	if response != nil {
-		defer response.Body.Close()
-	}
-
-	responseBody, err := io.ReadAll(response.Body)
+	arn, err := a.verifyCallerIdentity(token, body)
 	if err != nil {
-		return nil, fmt.Errorf("reading STS response: %v", err)
-	}
-	if response.StatusCode != 200 {
-		return nil, fmt.Errorf("received status code %d from STS: %s", response.StatusCode, string(responseBody))
-	}
-
-	callerIdentity := GetCallerIdentityResponse{}
-	err = xml.NewDecoder(bytes.NewReader(responseBody)).Decode(&callerIdentity)
-	if err != nil {
-		return nil, fmt.Errorf("decoding STS response: %v", err)
-	}
-
-	if callerIdentity.GetCallerIdentityResult[0].Account != a.accountId {
-		return nil, fmt.Errorf("incorrect account %s", callerIdentity.GetCallerIdentityResult[0].Account)
+		return nil, err
 	}
 
-	arn := callerIdentity.GetCallerIdentityResult[0].Arn
 	parts := strings.Split(arn, ":")
 	if len(parts) != 6 {
 		return nil, fmt.Errorf("arn %q contains unexpected number of colons", arn)
@@ -251,3 +199,69 @@ func (a awsVerifier) VerifyToken(ctx context.Context, token string, body []byte,
 
 	return result, nil
 }
+
+// verifyCallerIdentity verifies that the token is a signed STS GetCallerIdentity request for the body,
+// made by a principal in our account, and returns the ARN of the caller.
+func (a awsVerifier) verifyCallerIdentity(token string, body []byte) (string, error) {
+	if !strings.HasPrefix(token, AWSAuthenticationTokenPrefix) {
+		return "", fmt.Errorf("incorrect authorization type")
+	}
+	token = strings.TrimPrefix(token, AWSAuthenticationTokenPrefix)
+
+	// We rely on the client and server using the same version of the same STS library.
+	stsRequest, _ := a.sts.GetCallerIdentityRequest(nil)
+	err := stsRequest.Sign()
+	if err != nil {
+		return "", fmt.Errorf("creating identity request: %v", err)
+	}
+
+	stsRequest.HTTPRequest.Header = nil
+	tokenBytes, err := base64.StdEncoding.DecodeString(token)
+	if err != nil {
+		return "", fmt.Errorf("decoding authorization token: %v", err)
+	}
+	err = json.Unmarshal(tokenBytes, &stsRequest.HTTPRequest.Header)
+	if err != nil {
+		return "", fmt.Errorf("unmarshalling authorization token: %v", err)
+	}
+
+	// Verify the token has signed the body content.
+	sha := sha256.Sum256(body)
+	if stsRequest.HTTPRequest.Header.Get("X-Kops-Request-SHA") != base64.RawStdEncoding.EncodeToString(sha[:]) {
+		return "", fmt.Errorf("incorrect SHA")
+	}
+
+	requestBytes, _ := io.ReadAll(stsRequest.Body)
+	_, _ = stsRequest.Body.Seek(0, io.SeekStart)
+	if stsRequest.HTTPRequest.Header.Get("Content-Length") != strconv.Itoa(len(requestBytes)) {
+		return "", fmt.Errorf("incorrect content-length")
+	}
+
+	response, err := a.client.Do(stsRequest.HTTPRequest)
+	if err != nil {
+		return "", fmt.Errorf("sending STS request: %v", err)
+	}
+	if response != nil {
+		defer response.Body.Close()
+	}
+
+	responseBody, err := io.ReadAll(response.Body)
+	if err != nil {
+		return "", fmt.Errorf("reading STS response: %v", err)
+	}
+	if response.StatusCode != 200 {
+		return "", fmt.Errorf("received status code %d from STS: %s", response.StatusCode, string(responseBody))
+	}
+
+	callerIdentity := GetCallerIdentityResponse{}
+	err = xml.NewDecoder(bytes.NewReader(responseBody)).Decode(&callerIdentity)
+	if err != nil {
+		return "", fmt.Errorf("decoding STS response: %v", err)
+	}
+
+	if callerIdentity.GetCallerIdentityResult[0].Account != a.accountId {
+		return "", fmt.Errorf("incorrect account %s", callerIdentity.GetCallerIdentityResult[0].Account)
+	}
+
+	return callerIdentity.GetCallerIdentityResult[0].Arn, nil
+}
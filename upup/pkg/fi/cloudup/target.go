@@ -20,5 +20,6 @@ const (
 	TargetDirect         = "direct"
 	TargetDryRun         = "dryrun"
 	TargetTerraform      = "terraform"
+	TargetTerraformJSON  = "terraform-json"
 	TargetCloudformation = "cloudformation"
 )
@@ -38,11 +38,13 @@ import (
 	"strconv"
 	"strings"
 	"text/template"
+	"time"
 
 	"github.com/Masterminds/sprig/v3"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/klog/v2"
 	"k8s.io/kops/pkg/flagbuilder"
@@ -53,6 +55,7 @@ import (
 	apiModel "k8s.io/kops/pkg/apis/kops/model"
 	"k8s.io/kops/pkg/apis/kops/util"
 	"k8s.io/kops/pkg/apis/nodeup"
+	"k8s.io/kops/pkg/bootstrap/oidc"
 	"k8s.io/kops/pkg/dns"
 	"k8s.io/kops/pkg/featureflag"
 	"k8s.io/kops/pkg/kubemanifest"
@@ -647,6 +650,13 @@ func (tf *TemplateFunctions) KopsControllerConfig() (string, error) {
 		default:
 			return "", fmt.Errorf("unsupported cloud provider %s", cluster.Spec.GetCloudProvider())
 		}
+
+		if cluster.Spec.KopsController != nil && cluster.Spec.KopsController.AdminCredentials != nil {
+			config.Server.AdminCredentials = buildAdminCredentialsOptions(cluster.Spec.KopsController.AdminCredentials, tf.Region)
+		}
+		if cluster.Spec.KopsController != nil && cluster.Spec.KopsController.RateLimit != nil {
+			config.Server.RateLimit = buildRateLimitOptions(cluster.Spec.KopsController.RateLimit)
+		}
 	}
 
 	if cluster.Spec.IsKopsControllerIPAM() {
@@ -668,6 +678,46 @@ func (tf *TemplateFunctions) KopsControllerConfig() (string, error) {
 	return string(b), nil
 }
 
+// buildAdminCredentialsOptions builds the kops-controller configuration for issuing admin credentials.
+func buildAdminCredentialsOptions(spec *kops.KopsControllerAdminCredentialsConfig, region string) *kopscontrollerconfig.AdminCredentialsOptions {
+	opt := &kopscontrollerconfig.AdminCredentialsOptions{
+		MaxValidity: metav1.Duration{Duration: time.Hour},
+	}
+	if spec.MaxValidity != nil {
+		opt.MaxValidity = *spec.MaxValidity
+	}
+	if len(spec.AWSPrincipals) != 0 {
+		opt.AWS = &awsup.AWSUserVerifierOptions{
+			Principals: spec.AWSPrincipals,
+			Region:     region,
+		}
+	}
+	if spec.OIDC != nil {
+		opt.OIDC = &oidc.VerifierOptions{
+			IssuerURL:     spec.OIDC.IssuerURL,
+			ClientID:      spec.OIDC.ClientID,
+			UsernameClaim: fi.StringValue(spec.OIDC.UsernameClaim),
+			AllowedUsers:  spec.OIDC.AllowedUsers,
+		}
+	}
+	return opt
+}
+
+// buildRateLimitOptions builds the kops-controller configuration for limiting credential requests.
+func buildRateLimitOptions(spec *kops.KopsControllerRateLimitConfig) *kopscontrollerconfig.RateLimitOptions {
+	opt := &kopscontrollerconfig.RateLimitOptions{
+		Requests: kopscontrollerconfig.DefaultRateLimitRequests,
+		Period:   metav1.Duration{Duration: kopscontrollerconfig.DefaultRateLimitPeriod},
+	}
+	if spec.Requests != nil {
+		opt.Requests = int(*spec.Requests)
+	}
+	if spec.Period != nil {
+		opt.Period = *spec.Period
+	}
+	return opt
+}
+
 // KopsControllerArgv returns the args to kops-controller
 func (tf *TemplateFunctions) KopsControllerArgv() ([]string, error) {
 	var argv []string
@@ -677,6 +727,10 @@ func (tf *TemplateFunctions) KopsControllerArgv() ([]string, error) {
 
 	argv = append(argv, "--conf=/etc/kubernetes/kops-controller/config/config.yaml")
 
+	if c := tf.Cluster.Spec.KopsController; c != nil && c.MetricsPort != nil {
+		argv = append(argv, fmt.Sprintf("--metrics-addr=:%d", *c.MetricsPort))
+	}
+
 	return argv, nil
 }
 
@@ -39,6 +39,8 @@ type TerraformTarget struct {
 	// extra config to add to the provider block
 	clusterSpecTarget *kops.TargetSpec
 	filesProvider     *vfs.TerraformProvider
+	// json selects Terraform's JSON syntax (main.tf.json) instead of HCL2 (kubernetes.tf)
+	json bool
 }
 
 func NewTerraformTarget(cloud fi.Cloud, project string, filesProvider *vfs.TerraformProvider, outDir string, clusterSpecTarget *kops.TargetSpec) *TerraformTarget {
@@ -54,6 +56,13 @@ func NewTerraformTarget(cloud fi.Cloud, project string, filesProvider *vfs.Terra
 	return &target
 }
 
+// NewTerraformJSONTarget returns a TerraformTarget that writes the configuration in Terraform's JSON syntax.
+func NewTerraformJSONTarget(cloud fi.Cloud, project string, filesProvider *vfs.TerraformProvider, outDir string, clusterSpecTarget *kops.TargetSpec) *TerraformTarget {
+	target := NewTerraformTarget(cloud, project, filesProvider, outDir, clusterSpecTarget)
+	target.json = true
+	return target
+}
+
 var _ fi.Target = &TerraformTarget{}
 
 func (t *TerraformTarget) AddFileResource(resourceType string, resourceName string, key string, r fi.Resource, base64 bool) (*terraformWriter.Literal, error) {
@@ -92,8 +101,14 @@ func tfGetFilesProviderExtraConfig(c *kops.TargetSpec) map[string]string {
 }
 
 func (t *TerraformTarget) Finish(taskMap map[string]fi.Task) error {
-	if err := t.finishHCL2(); err != nil {
-		return err
+	if t.json {
+		if err := t.finishJSON(); err != nil {
+			return err
+		}
+	} else {
+		if err := t.finishHCL2(); err != nil {
+			return err
+		}
 	}
 
 	for relativePath, contents := range t.Files {
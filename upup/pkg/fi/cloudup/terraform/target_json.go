@@ -0,0 +1,234 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package terraform
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/gocty"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/featureflag"
+	"k8s.io/kops/upup/pkg/fi/cloudup/terraformWriter"
+)
+
+// finishJSON writes the configuration in Terraform's JSON syntax.
+// It contains the same resources, locals, outputs and providers as the HCL2 output,
+// with references written as "${...}" interpolations.
+func (t *TerraformTarget) finishJSON() error {
+	root := make(map[string]interface{})
+
+	outputs, err := t.GetOutputs()
+	if err != nil {
+		return err
+	}
+	if locals, outputBlocks := jsonLocalsOutputs(outputs); len(outputBlocks) != 0 {
+		root["locals"] = locals
+		root["output"] = outputBlocks
+	}
+
+	providerName := string(t.Cloud.ProviderID())
+	if t.Cloud.ProviderID() == kops.CloudProviderGCE {
+		providerName = "google"
+	}
+	provider := map[string]interface{}{
+		"region": t.Cloud.Region(),
+	}
+	if t.Cloud.ProviderID() == kops.CloudProviderGCE {
+		provider["project"] = t.Project
+	}
+	for k, v := range tfGetProviderExtraConfig(t.clusterSpecTarget) {
+		provider[k] = v
+	}
+	providers := map[string][]interface{}{
+		providerName: {provider},
+	}
+	if t.filesProvider != nil {
+		filesProvider := map[string]interface{}{
+			"alias": "files",
+		}
+		for k, v := range t.filesProvider.Arguments {
+			filesProvider[k] = v
+		}
+		for k, v := range tfGetFilesProviderExtraConfig(t.clusterSpecTarget) {
+			filesProvider[k] = v
+		}
+		providers[t.filesProvider.Name] = append(providers[t.filesProvider.Name], filesProvider)
+	}
+	root["provider"] = providers
+
+	resourcesByType, err := t.GetResourcesByType()
+	if err != nil {
+		return err
+	}
+	resourceBlocks := make(map[string]map[string]interface{})
+	for resourceType, resources := range resourcesByType {
+		for resourceName, item := range resources {
+			resType, err := gocty.ImpliedType(item)
+			if err != nil {
+				return err
+			}
+			resVal, err := gocty.ToCtyValue(item, resType)
+			if err != nil {
+				return err
+			}
+			if resVal.IsNull() {
+				continue
+			}
+			v, err := jsonValue(resVal)
+			if err != nil {
+				return fmt.Errorf("error converting %s.%s: %w", resourceType, resourceName, err)
+			}
+			if resourceBlocks[resourceType] == nil {
+				resourceBlocks[resourceType] = make(map[string]interface{})
+			}
+			resourceBlocks[resourceType][resourceName] = v
+		}
+	}
+	root["resource"] = resourceBlocks
+
+	requiredProviders := make(map[string]interface{})
+	if t.Cloud.ProviderID() == kops.CloudProviderGCE {
+		requiredProviders["google"] = map[string]interface{}{
+			"source":  "hashicorp/google",
+			"version": ">= 2.19.0",
+		}
+	} else if t.Cloud.ProviderID() == kops.CloudProviderAWS {
+		requiredProviders["aws"] = map[string]interface{}{
+			"source":  "hashicorp/aws",
+			"version": ">= 4.0.0",
+			// In JSON syntax, the aliases are written as strings holding the provider references
+			"configuration_aliases": []string{"aws.files"},
+		}
+		if featureflag.Spotinst.Enabled() {
+			requiredProviders["spotinst"] = map[string]interface{}{
+				"source":  "spotinst/spotinst",
+				"version": ">= 1.33.0",
+			}
+		}
+	}
+	root["terraform"] = map[string]interface{}{
+		"required_version":   ">= 0.15.0",
+		"required_providers": requiredProviders,
+	}
+
+	var b bytes.Buffer
+	encoder := json.NewEncoder(&b)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(root); err != nil {
+		return fmt.Errorf("error marshaling terraform JSON: %w", err)
+	}
+	t.Files["main.tf.json"] = b.Bytes()
+	return nil
+}
+
+// jsonLocalsOutputs returns the locals and the output blocks for all output variables
+func jsonLocalsOutputs(outputs map[string]terraformWriter.OutputValue) (map[string]interface{}, map[string]interface{}) {
+	locals := make(map[string]interface{})
+	outputBlocks := make(map[string]interface{})
+	for tfName, v := range outputs {
+		var value interface{}
+		if v.Value != nil {
+			value = v.Value.Value
+		} else {
+			values := make([]string, 0, len(v.ValueArray))
+			for _, literal := range v.ValueArray {
+				values = append(values, literal.Value)
+			}
+			value = values
+		}
+		locals[tfName] = value
+		outputBlocks[tfName] = map[string]interface{}{
+			"value": value,
+		}
+	}
+	return locals, outputBlocks
+}
+
+// asLiteral returns the Literal if the value is a terraformWriter.Literal
+func asLiteral(value cty.Value) (*terraformWriter.Literal, bool) {
+	refLiteral := reflect.New(reflect.TypeOf(terraformWriter.Literal{}))
+	err := gocty.FromCtyValue(value, refLiteral.Interface())
+	literal, ok := refLiteral.Interface().(*terraformWriter.Literal)
+	return literal, err == nil && ok
+}
+
+// jsonValue converts a value into its representation in Terraform's JSON syntax.
+// Nested blocks are written as objects, or as lists of objects if repeated.
+// Null values, empty lists and empty maps are returned as nil, so that they can be omitted.
+func jsonValue(value cty.Value) (interface{}, error) {
+	if value.IsNull() {
+		return nil, nil
+	}
+
+	if literal, ok := asLiteral(value); ok {
+		return literal.Value, nil
+	}
+
+	switch {
+	case value.Type().IsListType() || value.Type().IsSetType() || value.Type().IsTupleType():
+		if value.LengthInt() == 0 {
+			return nil, nil
+		}
+		var values []interface{}
+		for it := value.ElementIterator(); it.Next(); {
+			_, v := it.Element()
+			jv, err := jsonValue(v)
+			if err != nil {
+				return nil, err
+			}
+			if jv != nil {
+				values = append(values, jv)
+			}
+		}
+		return values, nil
+
+	case value.Type().IsObjectType() || value.Type().IsMapType():
+		values := make(map[string]interface{})
+		for it := value.ElementIterator(); it.Next(); {
+			k, v := it.Element()
+			jv, err := jsonValue(v)
+			if err != nil {
+				return nil, err
+			}
+			if jv != nil {
+				values[k.AsString()] = jv
+			}
+		}
+		// Like in HCL2, empty maps are omitted but empty objects are written as empty blocks
+		if len(values) == 0 && value.Type().IsMapType() {
+			return nil, nil
+		}
+		return values, nil
+
+	case value.Type() == cty.String:
+		return value.AsString(), nil
+
+	case value.Type() == cty.Bool:
+		return value.True(), nil
+
+	case value.Type() == cty.Number:
+		return json.Number(value.AsBigFloat().Text('f', -1)), nil
+
+	default:
+		return nil, fmt.Errorf("unhandled value type %s", value.Type().FriendlyName())
+	}
+}
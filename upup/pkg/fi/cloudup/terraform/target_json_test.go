@@ -0,0 +1,113 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package terraform
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/gocty"
+	"k8s.io/kops/upup/pkg/fi/cloudup/terraformWriter"
+)
+
+func TestJSONValue(t *testing.T) {
+	literalValue := func(literal *terraformWriter.Literal) cty.Value {
+		ty, err := gocty.ImpliedType(literal)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		v, err := gocty.ToCtyValue(literal, ty)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return v
+	}
+
+	cases := []struct {
+		name     string
+		value    cty.Value
+		expected string
+	}{
+		{
+			name:     "null",
+			value:    cty.NullVal(cty.String),
+			expected: `null`,
+		},
+		{
+			name:     "empty list",
+			value:    cty.ListValEmpty(cty.String),
+			expected: `null`,
+		},
+		{
+			name:     "empty map",
+			value:    cty.MapValEmpty(cty.String),
+			expected: `null`,
+		},
+		{
+			name:     "number",
+			value:    cty.NumberIntVal(10),
+			expected: `10`,
+		},
+		{
+			name:     "map",
+			value:    cty.MapVal(map[string]cty.Value{"key1": cty.StringVal("value1"), "key2": cty.StringVal("value2")}),
+			expected: `{"key1":"value1","key2":"value2"}`,
+		},
+		{
+			name:     "literal reference",
+			value:    literalValue(terraformWriter.LiteralProperty("aws_vpc", "foo", "id")),
+			expected: `"${aws_vpc.foo.id}"`,
+		},
+		{
+			name:     "literal function",
+			value:    literalValue(terraformWriter.LiteralFunctionExpression("file", []string{`"${path.module}/foo"`})),
+			expected: `"${file(\"${path.module}/foo\")}"`,
+		},
+		{
+			name: "object with null attribute",
+			value: cty.ObjectVal(map[string]cty.Value{
+				"key1": cty.StringVal("value1"),
+				"key2": cty.NullVal(cty.Bool),
+			}),
+			expected: `{"key1":"value1"}`,
+		},
+		{
+			name: "list of objects",
+			value: cty.ListVal([]cty.Value{
+				cty.ObjectVal(map[string]cty.Value{"key": cty.StringVal("value1")}),
+				cty.ObjectVal(map[string]cty.Value{"key": cty.StringVal("value2")}),
+			}),
+			expected: `[{"key":"value1"},{"key":"value2"}]`,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			v, err := jsonValue(tc.value)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			actual, err := json.Marshal(v)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(actual) != tc.expected {
+				t.Errorf("expected: '%s', got: '%s'", tc.expected, string(actual))
+			}
+		})
+	}
+}
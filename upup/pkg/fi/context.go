@@ -44,6 +44,8 @@ type Context struct {
 
 	tasks map[string]Task
 
+	taskReport *TaskReport
+
 	warnings []*Warning
 }
 
@@ -79,13 +81,20 @@ func (c *Context) AllTasks() map[string]Task {
 }
 
 func (c *Context) RunTasks(options RunTasksOptions) error {
+	c.taskReport = &TaskReport{}
 	e := &executor{
 		context: c,
 		options: options,
+		report:  c.taskReport,
 	}
 	return e.RunTasks(c.tasks)
 }
 
+// TaskReport returns the execution timeline of the most recent call to RunTasks, or nil if RunTasks has not been called.
+func (c *Context) TaskReport() *TaskReport {
+	return c.taskReport
+}
+
 func (c *Context) Close() {
 	klog.V(2).Infof("deleting temp dir: %q", c.Tmpdir)
 	if c.Tmpdir != "" {
@@ -414,6 +414,24 @@ func (t *DryRunTarget) Changes() (map[string]Task, map[string]Task) {
 	return creates, updates
 }
 
+// ChangedTasks returns the sorted keys in taskMap of the tasks which are going to be created or updated
+func (t *DryRunTarget) ChangedTasks(taskMap map[string]Task) []string {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	var keys []string
+	for k, task := range taskMap {
+		for _, r := range t.changes {
+			if r.e == task {
+				keys = append(keys, k)
+				break
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 // HasChanges returns true iff any changes would have been made
 func (t *DryRunTarget) HasChanges() bool {
 	return len(t.changes)+len(t.deletions) != 0
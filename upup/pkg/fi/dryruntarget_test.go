@@ -95,3 +95,21 @@ func Test_DryrunTarget_PrintReport(t *testing.T) {
 	err = target.PrintReport(tasks, &out)
 	assert.NoError(t, err, "target.PrintReport()")
 }
+
+func Test_DryrunTarget_ChangedTasks(t *testing.T) {
+	target := NewDryRunTarget(nil, &bytes.Buffer{})
+
+	unchanged := &testTask{Name: String("unchanged")}
+	created := &testTask{Name: String("created")}
+	updated := &testTask{Name: String("updated")}
+	tasks := map[string]Task{
+		"testTask/unchanged": unchanged,
+		"testTask/created":   created,
+		"testTask/updated":   updated,
+	}
+
+	assert.NoError(t, target.Render((*testTask)(nil), created, &testTask{}), "target.Render()")
+	assert.NoError(t, target.Render(&testTask{Name: String("updated")}, updated, &testTask{}), "target.Render()")
+
+	assert.Equal(t, []string{"testTask/created", "testTask/updated"}, target.ChangedTasks(tasks))
+}
@@ -29,6 +29,8 @@ type executor struct {
 	context *Context
 
 	options RunTasksOptions
+
+	report *TaskReport
 }
 
 type taskState struct {
@@ -38,16 +40,20 @@ type taskState struct {
 	deadline     time.Time
 	lastError    error
 	dependencies []*taskState
+	timing       *TaskTiming
 }
 
 type RunTasksOptions struct {
 	MaxTaskDuration         time.Duration
 	WaitAfterAllTasksFailed time.Duration
+	// MaxConcurrency limits the number of tasks that are run in parallel; zero means no limit.
+	MaxConcurrency int
 }
 
 func (o *RunTasksOptions) InitDefaults() {
 	o.MaxTaskDuration = 10 * time.Minute
 	o.WaitAfterAllTasksFailed = 10 * time.Second
+	o.MaxConcurrency = 0
 }
 
 // RunTasks executes all the tasks, considering their dependencies
@@ -69,8 +75,14 @@ func (e *executor) RunTasks(taskMap map[string]Task) error {
 		ts := &taskState{
 			key:  k,
 			task: task,
+			timing: &TaskTiming{
+				Key: k,
+			},
 		}
 		taskStates[k] = ts
+		if e.report != nil {
+			e.report.add(ts.timing)
+		}
 	}
 
 	for k, ts := range taskStates {
@@ -122,6 +134,7 @@ func (e *executor) RunTasks(taskMap map[string]Task) error {
 		var errors []error
 		for i, err := range taskErrors {
 			ts := tasks[i]
+			ts.timing.recordAttempt(err)
 			if err != nil {
 				//  print warning message and continue like the task succeeded
 				if _, ok := err.(*ExistsAndWarnIfChangesError); ok {
@@ -171,24 +184,46 @@ func (e *executor) RunTasks(taskMap map[string]Task) error {
 	return nil
 }
 
+// forkJoin runs the tasks in parallel, using at most options.MaxConcurrency workers,
+// and returns the error from each task in the same order as the input.
 func (e *executor) forkJoin(tasks []*taskState) []error {
 	if len(tasks) == 0 {
 		return nil
 	}
 
+	workers := len(tasks)
+	if e.options.MaxConcurrency > 0 && e.options.MaxConcurrency < workers {
+		workers = e.options.MaxConcurrency
+	}
+
+	queue := make(chan int, len(tasks))
+	for i := range tasks {
+		queue <- i
+	}
+	close(queue)
+
 	var wg sync.WaitGroup
 	results := make([]error, len(tasks))
-	for i := 0; i < len(tasks); i++ {
+	for w := 0; w < workers; w++ {
 		wg.Add(1)
-		go func(ts *taskState, index int) {
-			results[index] = fmt.Errorf("function panic")
+		go func() {
 			defer wg.Done()
-			klog.V(2).Infof("Executing task %q: %v\n", ts.key, ts.task)
-			results[index] = ts.task.Run(e.context)
-		}(tasks[i], i)
+			for index := range queue {
+				e.runTask(tasks[index], &results[index])
+			}
+		}()
 	}
 
 	wg.Wait()
 
 	return results
 }
+
+// runTask runs a single task, recording its timing and storing the result in *result.
+func (e *executor) runTask(ts *taskState, result *error) {
+	*result = fmt.Errorf("function panic")
+	ts.timing.start()
+	defer ts.timing.end()
+	klog.V(2).Infof("Executing task %q: %v\n", ts.key, ts.task)
+	*result = ts.task.Run(e.context)
+}
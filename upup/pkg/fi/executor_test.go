@@ -0,0 +1,175 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+type concurrencyTracker struct {
+	mutex   sync.Mutex
+	running int
+	max     int
+}
+
+type countingTask struct {
+	Name *string
+
+	tracker  *concurrencyTracker
+	failures int
+}
+
+var _ Task = &countingTask{}
+
+func (t *countingTask) Run(_ *Context) error {
+	t.tracker.mutex.Lock()
+	t.tracker.running++
+	if t.tracker.running > t.tracker.max {
+		t.tracker.max = t.tracker.running
+	}
+	t.tracker.mutex.Unlock()
+
+	time.Sleep(10 * time.Millisecond)
+
+	t.tracker.mutex.Lock()
+	t.tracker.running--
+	t.tracker.mutex.Unlock()
+
+	if t.failures > 0 {
+		t.failures--
+		return fmt.Errorf("task %s failed", *t.Name)
+	}
+	return nil
+}
+
+func Test_Executor_MaxConcurrency(t *testing.T) {
+	grid := []struct {
+		MaxConcurrency int
+		Expected       int
+	}{
+		{MaxConcurrency: 0, Expected: 8},
+		{MaxConcurrency: 1, Expected: 1},
+		{MaxConcurrency: 3, Expected: 3},
+	}
+	for _, g := range grid {
+		t.Run(fmt.Sprintf("%d", g.MaxConcurrency), func(t *testing.T) {
+			tracker := &concurrencyTracker{}
+			tasks := make(map[string]Task)
+			for i := 0; i < 8; i++ {
+				name := fmt.Sprintf("task-%d", i)
+				tasks[name] = &countingTask{Name: String(name), tracker: tracker}
+			}
+
+			c := &Context{tasks: tasks}
+			options := RunTasksOptions{}
+			options.InitDefaults()
+			options.MaxConcurrency = g.MaxConcurrency
+			if err := c.RunTasks(options); err != nil {
+				t.Fatalf("unexpected error running tasks: %v", err)
+			}
+
+			if tracker.max != g.Expected {
+				t.Errorf("unexpected maximum concurrency: expected %d, got %d", g.Expected, tracker.max)
+			}
+		})
+	}
+}
+
+func Test_Executor_TaskReport(t *testing.T) {
+	tracker := &concurrencyTracker{}
+	tasks := map[string]Task{
+		"ok":    &countingTask{Name: String("ok"), tracker: tracker},
+		"flaky": &countingTask{Name: String("flaky"), tracker: tracker, failures: 1},
+	}
+
+	c := &Context{tasks: tasks}
+	options := RunTasksOptions{}
+	options.InitDefaults()
+	options.WaitAfterAllTasksFailed = time.Millisecond
+	if err := c.RunTasks(options); err != nil {
+		t.Fatalf("unexpected error running tasks: %v", err)
+	}
+
+	report := c.TaskReport()
+	if report == nil {
+		t.Fatalf("expected task report")
+	}
+
+	attempts := make(map[string]int)
+	for _, timing := range report.Tasks() {
+		if !timing.Done {
+			t.Errorf("task %q not marked as done", timing.Key)
+		}
+		if timing.LastError != "" {
+			t.Errorf("task %q has unexpected last error %q", timing.Key, timing.LastError)
+		}
+		if timing.Start == nil || timing.End == nil || timing.End.Before(*timing.Start) {
+			t.Errorf("task %q has invalid timeline %v - %v", timing.Key, timing.Start, timing.End)
+		}
+		attempts[timing.Key] = timing.Attempts
+	}
+	if attempts["ok"] != 1 || attempts["flaky"] != 2 {
+		t.Errorf("unexpected attempts: %v", attempts)
+	}
+
+	var buf bytes.Buffer
+	if err := report.WriteJSON(&buf); err != nil {
+		t.Fatalf("error writing report: %v", err)
+	}
+	var decoded []TaskTiming
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("error parsing report %q: %v", buf.String(), err)
+	}
+	if len(decoded) != 2 {
+		t.Errorf("expected 2 tasks in report, got %d", len(decoded))
+	}
+	for _, timing := range decoded {
+		if timing.DurationSeconds <= 0 {
+			t.Errorf("task %q has unexpected duration %v", timing.Key, timing.DurationSeconds)
+		}
+	}
+}
+
+func Test_TaskReport_NotRun(t *testing.T) {
+	report := &TaskReport{}
+	report.add(&TaskTiming{Key: "blocked"})
+
+	var buf bytes.Buffer
+	if err := report.WriteJSON(&buf); err != nil {
+		t.Fatalf("error writing report: %v", err)
+	}
+	var decoded []map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("error parsing report %q: %v", buf.String(), err)
+	}
+	if len(decoded) != 1 {
+		t.Fatalf("expected 1 task in report, got %d", len(decoded))
+	}
+	for _, field := range []string{"start", "end"} {
+		if _, found := decoded[0][field]; found {
+			t.Errorf("expected %q to be omitted for a task that never ran, got %s", field, buf.String())
+		}
+	}
+	if decoded[0]["durationSeconds"] != 0.0 {
+		t.Errorf("unexpected duration for a task that never ran: %s", buf.String())
+	}
+}
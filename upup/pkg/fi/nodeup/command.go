@@ -49,6 +49,7 @@ import (
 	"k8s.io/kops/upup/pkg/fi/cloudup/gce/gcediscovery"
 	"k8s.io/kops/upup/pkg/fi/cloudup/gce/tpm/gcetpmsigner"
 	"k8s.io/kops/upup/pkg/fi/nodeup/cloudinit"
+	"k8s.io/kops/upup/pkg/fi/nodeup/ignition"
 	"k8s.io/kops/upup/pkg/fi/nodeup/local"
 	"k8s.io/kops/upup/pkg/fi/nodeup/nodetasks"
 	"k8s.io/kops/upup/pkg/fi/secrets"
@@ -75,56 +76,165 @@ type NodeUpCommand struct {
 	cluster        *api.Cluster
 }
 
+// nodeupTasks is the task graph built for the node, along with the state needed to run it
+type nodeupTasks struct {
+	taskMap      map[string]fi.Task
+	modelContext *model.NodeupModelContext
+	nodeupConfig *nodeup.Config
+	cloud        fi.Cloud
+	keyStore     fi.Keystore
+	secretStore  fi.SecretStore
+	configBase   vfs.Path
+}
+
 // Run is responsible for perform the nodeup process
 func (c *NodeUpCommand) Run(out io.Writer) error {
 	ctx := context.Background()
 
+	bootConfig, region, err := c.loadBootConfig(ctx)
+	if err != nil {
+		return err
+	}
+	if err := seedRNG(ctx, bootConfig, region); err != nil {
+		return err
+	}
+
+	tasks, err := c.loadNodeConfig(ctx, bootConfig, region)
+	if err != nil {
+		return err
+	}
+	if err := loadKernelModules(tasks.modelContext); err != nil {
+		return err
+	}
+	if err := c.buildTaskMap(tasks); err != nil {
+		return err
+	}
+	taskMap := tasks.taskMap
+	modelContext := tasks.modelContext
+	cloud := tasks.cloud
+
+	var target fi.Target
+	checkExisting := true
+
+	switch c.Target {
+	case "direct":
+		target = &local.LocalTarget{
+			CacheDir:   c.CacheDir,
+			Cloud:      cloud,
+			InstanceID: modelContext.InstanceID,
+			Cluster:    c.cluster,
+		}
+	case "dryrun":
+		assetBuilder := assets.NewAssetBuilder(c.cluster, false)
+		target = fi.NewDryRunTarget(assetBuilder, out)
+	case "cloudinit":
+		checkExisting = false
+		target = cloudinit.NewCloudInitTarget(out)
+	case "ignition":
+		if err := ignition.CheckTasks(taskMap); err != nil {
+			return err
+		}
+		checkExisting = false
+		target = ignition.NewIgnitionTarget(out)
+	default:
+		return fmt.Errorf("unsupported target type %q", c.Target)
+	}
+
+	context, err := fi.NewContext(target, c.cluster, cloud, tasks.keyStore, tasks.secretStore, tasks.configBase, checkExisting, taskMap)
+	if err != nil {
+		klog.Exitf("error building context: %v", err)
+	}
+	defer context.Close()
+
+	var options fi.RunTasksOptions
+	options.InitDefaults()
+
+	err = context.RunTasks(options)
+	if err != nil {
+		klog.Exitf("error running tasks: %v", err)
+	}
+
+	err = target.Finish(taskMap)
+	if err != nil {
+		klog.Exitf("error closing target: %v", err)
+	}
+
+	if tasks.nodeupConfig.EnableLifecycleHook {
+		if modelContext.CloudProvider == api.CloudProviderAWS {
+			err := completeWarmingLifecycleAction(cloud.(awsup.AWSCloud), modelContext)
+			if err != nil {
+				return fmt.Errorf("failed to complete lifecylce action: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// buildTasks loads the configuration of the node and builds its task graph, without changing the node
+func (c *NodeUpCommand) buildTasks(ctx context.Context) (*nodeupTasks, error) {
+	bootConfig, region, err := c.loadBootConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tasks, err := c.loadNodeConfig(ctx, bootConfig, region)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.buildTaskMap(tasks); err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// loadBootConfig loads the boot configuration of the node and determines its region
+func (c *NodeUpCommand) loadBootConfig(ctx context.Context) (*nodeup.BootConfig, string, error) {
 	var bootConfig nodeup.BootConfig
 	if c.ConfigLocation != "" {
 		b, err := vfs.Context.ReadFile(c.ConfigLocation)
 		if err != nil {
-			return fmt.Errorf("error loading configuration %q: %v", c.ConfigLocation, err)
+			return nil, "", fmt.Errorf("error loading configuration %q: %v", c.ConfigLocation, err)
 		}
 
 		err = utils.YamlUnmarshal(b, &bootConfig)
 		if err != nil {
-			return fmt.Errorf("error parsing configuration %q: %v", c.ConfigLocation, err)
+			return nil, "", fmt.Errorf("error parsing configuration %q: %v", c.ConfigLocation, err)
 		}
 	} else {
-		return fmt.Errorf("ConfigLocation is required")
+		return nil, "", fmt.Errorf("ConfigLocation is required")
 	}
 
 	if c.CacheDir == "" {
-		return fmt.Errorf("CacheDir is required")
+		return nil, "", fmt.Errorf("CacheDir is required")
 	}
 
 	region, err := getRegion(ctx, &bootConfig)
 	if err != nil {
-		return err
-	}
-	if err = seedRNG(ctx, &bootConfig, region); err != nil {
-		return err
+		return nil, "", err
 	}
+	return &bootConfig, region, nil
+}
 
+// loadNodeConfig loads the cluster and nodeup configuration of the node, and builds its model context
+func (c *NodeUpCommand) loadNodeConfig(ctx context.Context, bootConfig *nodeup.BootConfig, region string) (*nodeupTasks, error) {
 	var configBase vfs.Path
 
 	// If we're using a config server instead of vfs, nodeConfig will hold our configuration
 	var nodeConfig *nodeup.NodeConfig
 
 	if bootConfig.ConfigServer != nil {
-		response, err := getNodeConfigFromServer(ctx, &bootConfig, region)
+		response, err := getNodeConfigFromServer(ctx, bootConfig, region)
 		if err != nil {
-			return fmt.Errorf("failed to get node config from server: %w", err)
+			return nil, fmt.Errorf("failed to get node config from server: %w", err)
 		}
 		nodeConfig = response.NodeConfig
 	} else if fi.StringValue(bootConfig.ConfigBase) != "" {
 		var err error
 		configBase, err = vfs.Context.BuildVfsPath(*bootConfig.ConfigBase)
 		if err != nil {
-			return fmt.Errorf("cannot parse ConfigBase %q: %v", *bootConfig.ConfigBase, err)
+			return nil, fmt.Errorf("cannot parse ConfigBase %q: %v", *bootConfig.ConfigBase, err)
 		}
 	} else {
-		return fmt.Errorf("ConfigBase or ConfigServer is required")
+		return nil, fmt.Errorf("ConfigBase or ConfigServer is required")
 	}
 
 	{
@@ -139,18 +249,18 @@ func (c *NodeUpCommand) Run(out io.Writer) error {
 
 			b, err = p.ReadFile()
 			if err != nil {
-				return fmt.Errorf("error loading Cluster %q: %v", p, err)
+				return nil, fmt.Errorf("error loading Cluster %q: %v", p, err)
 			}
 			clusterDescription = fmt.Sprintf("%q", p)
 		}
 
 		o, _, err := kopscodecs.Decode(b, nil)
 		if err != nil {
-			return fmt.Errorf("error parsing Cluster %s: %v", clusterDescription, err)
+			return nil, fmt.Errorf("error parsing Cluster %s: %v", clusterDescription, err)
 		}
 		var ok bool
 		if c.cluster, ok = o.(*api.Cluster); !ok {
-			return fmt.Errorf("unexpected object type for Cluster %s: %T", clusterDescription, o)
+			return nil, fmt.Errorf("unexpected object type for Cluster %s: %T", clusterDescription, o)
 		}
 	}
 
@@ -158,7 +268,7 @@ func (c *NodeUpCommand) Run(out io.Writer) error {
 	var nodeupConfigHash [32]byte
 	if nodeConfig != nil {
 		if err := utils.YamlUnmarshal([]byte(nodeConfig.NodeupConfig), &nodeupConfig); err != nil {
-			return fmt.Errorf("error parsing BootConfig config response: %v", err)
+			return nil, fmt.Errorf("error parsing BootConfig config response: %v", err)
 		}
 		nodeupConfigHash = sha256.Sum256([]byte(nodeConfig.NodeupConfig))
 		nodeupConfig.CAs[fi.CertificateIDCA] = bootConfig.ConfigServer.CACertificates
@@ -167,19 +277,19 @@ func (c *NodeUpCommand) Run(out io.Writer) error {
 
 		b, err := nodeupConfigLocation.ReadFile()
 		if err != nil {
-			return fmt.Errorf("error loading NodeupConfig %q: %v", nodeupConfigLocation, err)
+			return nil, fmt.Errorf("error loading NodeupConfig %q: %v", nodeupConfigLocation, err)
 		}
 
 		if err = utils.YamlUnmarshal(b, &nodeupConfig); err != nil {
-			return fmt.Errorf("error parsing NodeupConfig %q: %v", nodeupConfigLocation, err)
+			return nil, fmt.Errorf("error parsing NodeupConfig %q: %v", nodeupConfigLocation, err)
 		}
 		nodeupConfigHash = sha256.Sum256(b)
 	} else {
-		return fmt.Errorf("no instance group defined in nodeup config")
+		return nil, fmt.Errorf("no instance group defined in nodeup config")
 	}
 
 	if want, got := bootConfig.NodeupConfigHash, base64.StdEncoding.EncodeToString(nodeupConfigHash[:]); got != want {
-		return fmt.Errorf("nodeup config hash mismatch (was %q, expected %q)", got, want)
+		return nil, fmt.Errorf("nodeup config hash mismatch (was %q, expected %q)", got, want)
 	}
 
 	cloudProvider := api.CloudProviderID(bootConfig.CloudProvider)
@@ -187,19 +297,19 @@ func (c *NodeUpCommand) Run(out io.Writer) error {
 		cloudProvider = c.cluster.Spec.GetCloudProvider()
 	}
 
-	err = evaluateSpec(c, &nodeupConfig, cloudProvider)
+	err := evaluateSpec(c, &nodeupConfig, cloudProvider)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	architecture, err := architectures.FindArchitecture()
 	if err != nil {
-		return fmt.Errorf("error determining OS architecture: %v", err)
+		return nil, fmt.Errorf("error determining OS architecture: %v", err)
 	}
 
 	distribution, err := distributions.FindDistribution("/")
 	if err != nil {
-		return fmt.Errorf("error determining OS distribution: %v", err)
+		return nil, fmt.Errorf("error determining OS distribution: %v", err)
 	}
 
 	configAssets := nodeupConfig.Assets[architecture]
@@ -207,7 +317,7 @@ func (c *NodeUpCommand) Run(out io.Writer) error {
 	for _, asset := range configAssets {
 		err := assetStore.Add(asset)
 		if err != nil {
-			return fmt.Errorf("error adding asset %q: %v", asset, err)
+			return nil, fmt.Errorf("error adding asset %q: %v", asset, err)
 		}
 	}
 
@@ -216,7 +326,7 @@ func (c *NodeUpCommand) Run(out io.Writer) error {
 	if cloudProvider == api.CloudProviderAWS {
 		awsCloud, err := awsup.NewAWSCloud(region, nil)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		cloud = awsCloud
 	}
@@ -229,7 +339,7 @@ func (c *NodeUpCommand) Run(out io.Writer) error {
 		Cluster:       c.cluster,
 		ConfigBase:    configBase,
 		Distribution:  distribution,
-		BootConfig:    &bootConfig,
+		BootConfig:    bootConfig,
 		NodeupConfig:  &nodeupConfig,
 	}
 
@@ -241,13 +351,13 @@ func (c *NodeUpCommand) Run(out io.Writer) error {
 		klog.Infof("Building SecretStore at %q", c.cluster.Spec.SecretStore)
 		p, err := vfs.Context.BuildVfsPath(c.cluster.Spec.SecretStore)
 		if err != nil {
-			return fmt.Errorf("error building secret store path: %v", err)
+			return nil, fmt.Errorf("error building secret store path: %v", err)
 		}
 
 		secretStore = secrets.NewVFSSecretStore(c.cluster, p)
 		modelContext.SecretStore = secretStore
 	} else {
-		return fmt.Errorf("SecretStore not set")
+		return nil, fmt.Errorf("SecretStore not set")
 	}
 
 	if nodeConfig != nil {
@@ -256,34 +366,34 @@ func (c *NodeUpCommand) Run(out io.Writer) error {
 		klog.Infof("Building KeyStore at %q", c.cluster.Spec.KeyStore)
 		p, err := vfs.Context.BuildVfsPath(c.cluster.Spec.KeyStore)
 		if err != nil {
-			return fmt.Errorf("error building key store path: %v", err)
+			return nil, fmt.Errorf("error building key store path: %v", err)
 		}
 
 		modelContext.KeyStore = fi.NewVFSCAStore(c.cluster, p)
 		keyStore = modelContext.KeyStore
 	} else {
-		return fmt.Errorf("KeyStore not set")
+		return nil, fmt.Errorf("KeyStore not set")
 	}
 
 	if err := modelContext.Init(); err != nil {
-		return err
+		return nil, err
 	}
 
 	if cloudProvider == api.CloudProviderAWS {
 		instanceIDBytes, err := vfs.Context.ReadFile("metadata://aws/meta-data/instance-id")
 		if err != nil {
-			return fmt.Errorf("error reading instance-id from AWS metadata: %v", err)
+			return nil, fmt.Errorf("error reading instance-id from AWS metadata: %v", err)
 		}
 		modelContext.InstanceID = string(instanceIDBytes)
 
 		modelContext.ConfigurationMode, err = getAWSConfigurationMode(modelContext)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		modelContext.MachineType, err = getMachineType()
 		if err != nil {
-			return fmt.Errorf("failed to get machine type: %w", err)
+			return nil, fmt.Errorf("failed to get machine type: %w", err)
 		}
 
 		// If Nvidia is enabled in the cluster, check if this instance has support for it.
@@ -293,7 +403,7 @@ func (c *NodeUpCommand) Run(out io.Writer) error {
 			// Get the instance type's detailed information.
 			instanceType, err := awsup.GetMachineTypeInfo(awsCloud, modelContext.MachineType)
 			if err != nil {
-				return err
+				return nil, err
 			}
 
 			if instanceType.GPU {
@@ -309,9 +419,21 @@ func (c *NodeUpCommand) Run(out io.Writer) error {
 		}
 	}
 
-	if err := loadKernelModules(modelContext); err != nil {
-		return err
-	}
+	return &nodeupTasks{
+		modelContext: modelContext,
+		nodeupConfig: &nodeupConfig,
+		cloud:        cloud,
+		keyStore:     keyStore,
+		secretStore:  secretStore,
+		configBase:   configBase,
+	}, nil
+}
+
+// buildTaskMap builds the task graph of the node
+func (c *NodeUpCommand) buildTaskMap(tasks *nodeupTasks) error {
+	modelContext := tasks.modelContext
+	nodeupConfig := tasks.nodeupConfig
+	architecture := modelContext.Architecture
 
 	loader := &Loader{}
 	loader.Builders = append(loader.Builders, &dns.GossipBuilder{NodeupModelContext: modelContext})
@@ -364,54 +486,13 @@ func (c *NodeUpCommand) Run(out io.Writer) error {
 	}
 	// Protokube load image task is in ProtokubeBuilder
 
-	var target fi.Target
-	checkExisting := true
-
-	switch c.Target {
-	case "direct":
-		target = &local.LocalTarget{
-			CacheDir:   c.CacheDir,
-			Cloud:      cloud,
-			InstanceID: modelContext.InstanceID,
-			Cluster:    c.cluster,
+	if nodeupConfig.OSPackageBundle != "" {
+		if err := useOSPackageBundle(taskMap, nodeupConfig.OSPackageBundle, nodeupConfig.OSPackageBundleHashes, modelContext.Distribution, architecture); err != nil {
+			return err
 		}
-	case "dryrun":
-		assetBuilder := assets.NewAssetBuilder(c.cluster, false)
-		target = fi.NewDryRunTarget(assetBuilder, out)
-	case "cloudinit":
-		checkExisting = false
-		target = cloudinit.NewCloudInitTarget(out)
-	default:
-		return fmt.Errorf("unsupported target type %q", c.Target)
-	}
-
-	context, err := fi.NewContext(target, c.cluster, cloud, keyStore, secretStore, configBase, checkExisting, taskMap)
-	if err != nil {
-		klog.Exitf("error building context: %v", err)
-	}
-	defer context.Close()
-
-	var options fi.RunTasksOptions
-	options.InitDefaults()
-
-	err = context.RunTasks(options)
-	if err != nil {
-		klog.Exitf("error running tasks: %v", err)
-	}
-
-	err = target.Finish(taskMap)
-	if err != nil {
-		klog.Exitf("error closing target: %v", err)
 	}
 
-	if nodeupConfig.EnableLifecycleHook {
-		if cloudProvider == api.CloudProviderAWS {
-			err := completeWarmingLifecycleAction(cloud.(awsup.AWSCloud), modelContext)
-			if err != nil {
-				return fmt.Errorf("failed to complete lifecylce action: %w", err)
-			}
-		}
-	}
+	tasks.taskMap = taskMap
 	return nil
 }
 
@@ -0,0 +1,249 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ignition
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+
+	"k8s.io/kops/pkg/ignition"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/util/pkg/hashing"
+)
+
+// IgnitionTarget renders the tasks as an Ignition v3 config, for Flatcar and Fedora CoreOS
+type IgnitionTarget struct {
+	Config *ignition.Config
+	out    io.Writer
+}
+
+func NewIgnitionTarget(out io.Writer) *IgnitionTarget {
+	t := &IgnitionTarget{
+		Config: ignition.NewConfig(),
+		out:    out,
+	}
+	return t
+}
+
+var _ fi.Target = &IgnitionTarget{}
+
+func (t *IgnitionTarget) ProcessDeletions() bool {
+	// We don't expect any, but it would be our job to process them
+	return true
+}
+
+// WriteFile adds a file; contents with a remote source are downloaded by Ignition, others are inlined
+func (t *IgnitionTarget) WriteFile(destPath string, contents fi.Resource, fileMode os.FileMode, owner, group string) error {
+	f := ignition.File{
+		Node: node(destPath, owner, group),
+		Mode: ignition.Mode(fileMode),
+	}
+
+	var p *fi.Source
+	if hs, ok := contents.(fi.HasSource); ok {
+		p = hs.GetSource()
+	}
+
+	if p != nil {
+		if p.URL == "" || p.Parent != nil {
+			return fmt.Errorf("file %q is extracted from an archive, which is not supported with Ignition", destPath)
+		}
+		f.Contents.Source = fi.String(p.URL)
+		if p.Hash != nil && p.Hash.Algorithm == hashing.HashAlgorithmSHA256 {
+			f.Contents.Verification.Hash = fi.String(string(p.Hash.Algorithm) + "-" + p.Hash.Hex())
+		}
+	} else {
+		d, err := fi.ResourceAsBytes(contents)
+		if err != nil {
+			return err
+		}
+
+		// Not a strict limit, just a sanity check
+		if len(d) > 256*1024 {
+			return fmt.Errorf("resource is very large (failed sanity-check): %v", contents)
+		}
+
+		f.Contents.Source = fi.String(ignition.DataURL(d))
+	}
+
+	t.Config.Storage.Files = append(t.Config.Storage.Files, f)
+	return nil
+}
+
+// AddDirectory adds a directory; Ignition creates missing parent directories with mode 0755
+func (t *IgnitionTarget) AddDirectory(destPath string, dirMode os.FileMode, owner, group string) {
+	for _, d := range t.Config.Storage.Directories {
+		if d.Path == destPath {
+			return
+		}
+	}
+	t.Config.Storage.Directories = append(t.Config.Storage.Directories, ignition.Directory{
+		Node: node(destPath, owner, group),
+		Mode: ignition.Mode(dirMode),
+	})
+}
+
+// AddLink adds a symbolic link
+func (t *IgnitionTarget) AddLink(destPath string, target string) {
+	t.Config.Storage.Links = append(t.Config.Storage.Links, ignition.Link{
+		Node:   ignition.Node{Path: destPath},
+		Target: target,
+	})
+}
+
+// AddUnit adds a systemd unit, which Ignition writes under /etc/systemd/system;
+// a unit without contents only enables or disables a unit shipped with the OS
+func (t *IgnitionTarget) AddUnit(name string, contents *string, enabled bool) {
+	t.Config.Systemd.Units = append(t.Config.Systemd.Units, ignition.Unit{
+		Name:     name,
+		Contents: contents,
+		Enabled:  fi.Bool(enabled),
+	})
+}
+
+// AddMount adds a systemd mount unit, which mounts source on mountpoint at boot
+func (t *IgnitionTarget) AddMount(source, mountpoint, fsType string, options []string) {
+	var b strings.Builder
+	b.WriteString("[Unit]\n")
+	b.WriteString("Before=local-fs.target\n")
+	b.WriteString("\n")
+	b.WriteString("[Mount]\n")
+	fmt.Fprintf(&b, "What=%s\n", source)
+	fmt.Fprintf(&b, "Where=%s\n", mountpoint)
+	if fsType != "" {
+		fmt.Fprintf(&b, "Type=%s\n", fsType)
+	}
+	if len(options) != 0 {
+		fmt.Fprintf(&b, "Options=%s\n", strings.Join(options, ","))
+	}
+	b.WriteString("\n")
+	b.WriteString("[Install]\n")
+	b.WriteString("WantedBy=local-fs.target\n")
+
+	t.AddUnit(mountUnitName(mountpoint), fi.String(b.String()), true)
+}
+
+// AddUser adds a user account; uid, home and shell are optional
+func (t *IgnitionTarget) AddUser(name string, uid int, home, shell string) {
+	user := ignition.PasswdUser{Name: name}
+	if uid != 0 {
+		user.UID = fi.Int(uid)
+	}
+	if home != "" {
+		user.HomeDir = fi.String(home)
+	}
+	if shell != "" {
+		user.Shell = fi.String(shell)
+	}
+	t.Config.Passwd.Users = append(t.Config.Passwd.Users, user)
+}
+
+// AddGroup adds a group
+func (t *IgnitionTarget) AddGroup(name string, gid *int, system bool) {
+	group := ignition.PasswdGroup{Name: name, Gid: gid}
+	if system {
+		group.System = fi.Bool(true)
+	}
+	t.Config.Passwd.Groups = append(t.Config.Passwd.Groups, group)
+}
+
+func (t *IgnitionTarget) Finish(taskMap map[string]fi.Task) error {
+	d, err := t.Config.Marshal()
+	if err != nil {
+		return fmt.Errorf("error serializing ignition config: %v", err)
+	}
+
+	_, err = t.out.Write(d)
+	if err != nil {
+		return fmt.Errorf("error writing ignition config to output: %v", err)
+	}
+	return nil
+}
+
+// CheckTasks returns an error listing the tasks that cannot be rendered as an Ignition config,
+// so that nodeup fails before rendering any of them.
+// Tasks without any Render method, such as the ones that only build resources for other tasks, are always supported.
+func CheckTasks(taskMap map[string]fi.Task) error {
+	var unsupported []string
+	for key, task := range taskMap {
+		if rendersTo(task, "RenderIgnition") {
+			continue
+		}
+		if rendersTo(task, "Render") {
+			unsupported = append(unsupported, key)
+		}
+	}
+	if len(unsupported) != 0 {
+		sort.Strings(unsupported)
+		return fmt.Errorf("tasks not supported with the Ignition target: %s", strings.Join(unsupported, ", "))
+	}
+	return nil
+}
+
+// rendersTo returns true if the task has a method whose name starts with prefix
+func rendersTo(task fi.Task, prefix string) bool {
+	taskType := reflect.TypeOf(task)
+	for i := 0; i < taskType.NumMethod(); i++ {
+		if strings.HasPrefix(taskType.Method(i).Name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// mountUnitName returns the name of the mount unit for a mountpoint, escaped as systemd-escape --path does
+func mountUnitName(mountpoint string) string {
+	p := strings.Trim(path.Clean(mountpoint), "/")
+	if p == "" {
+		return "-.mount"
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(p); i++ {
+		c := p[i]
+		switch {
+		case c == '/':
+			b.WriteByte('-')
+		case c == '.' && i == 0:
+			fmt.Fprintf(&b, "\\x%02x", c)
+		case c == '_' || c == '.' || (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z'):
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "\\x%02x", c)
+		}
+	}
+	return b.String() + ".mount"
+}
+
+func node(destPath string, owner, group string) ignition.Node {
+	n := ignition.Node{
+		Path:      destPath,
+		Overwrite: fi.Bool(true),
+	}
+	if owner != "" {
+		n.User = &ignition.NodeUser{Name: owner}
+	}
+	if group != "" {
+		n.Group = &ignition.NodeGroup{Name: group}
+	}
+	return n
+}
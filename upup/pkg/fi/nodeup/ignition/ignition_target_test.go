@@ -0,0 +1,146 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ignition
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/util/pkg/hashing"
+)
+
+type sourceResource struct {
+	source *fi.Source
+}
+
+func (r *sourceResource) Open() (io.Reader, error) {
+	return strings.NewReader(""), nil
+}
+
+func (r *sourceResource) GetSource() *fi.Source {
+	return r.source
+}
+
+func TestIgnitionTarget(t *testing.T) {
+	var out bytes.Buffer
+	target := NewIgnitionTarget(&out)
+
+	if err := target.WriteFile("/etc/sysconfig/kubelet", fi.NewStringResource("DAEMON_ARGS=\n"), 0o644, "", ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	remote := &sourceResource{source: &fi.Source{
+		URL:  "https://example.com/kubelet",
+		Hash: hashing.MustFromString("833723369ad345a88dd85d61b1e77336d56e61b864557ded71b92b6e34158e6a"),
+	}}
+	if err := target.WriteFile("/usr/local/bin/kubelet", remote, 0o755, "root", "root"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	extracted := &sourceResource{source: &fi.Source{
+		Parent:             &fi.Source{URL: "https://example.com/cni.tgz"},
+		ExtractFromArchive: "bridge",
+	}}
+	if err := target.WriteFile("/opt/cni/bin/bridge", extracted, 0o755, "", ""); err == nil {
+		t.Errorf("expected an error writing a file extracted from an archive")
+	}
+	target.AddDirectory("/var/lib/kubelet", 0o755, "", "")
+	target.AddDirectory("/var/lib/kubelet", 0o755, "", "")
+	target.AddLink("/usr/bin/kubectl", "/usr/local/bin/kubectl")
+	target.AddUnit("kubelet.service", fi.String("[Service]\nExecStart=/usr/local/bin/kubelet\n"), true)
+	target.AddUnit("containerd.service", nil, true)
+	target.AddMount("/var/lib/kubelet", "/var/lib/kubelet", "none", []string{"bind", "exec"})
+	target.AddUser("etcd", 10001, "/var/lib/etcd", "/sbin/nologin")
+	target.AddGroup("etcd", nil, true)
+
+	if err := target.Finish(nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := `{"ignition":{"version":"3.3.0"},` +
+		`"passwd":{"users":[{"name":"etcd","uid":10001,"homeDir":"/var/lib/etcd","shell":"/sbin/nologin"}],"groups":[{"name":"etcd","system":true}]},` +
+		`"storage":{"directories":[{"path":"/var/lib/kubelet","overwrite":true,"mode":493}],` +
+		`"files":[{"path":"/etc/sysconfig/kubelet","overwrite":true,"contents":{"source":"data:;base64,REFFTU9OX0FSR1M9Cg==","verification":{}},"mode":420},` +
+		`{"path":"/usr/local/bin/kubelet","overwrite":true,"user":{"name":"root"},"group":{"name":"root"},"contents":{"source":"https://example.com/kubelet","verification":{"hash":"sha256-833723369ad345a88dd85d61b1e77336d56e61b864557ded71b92b6e34158e6a"}},"mode":493}],` +
+		`"links":[{"path":"/usr/bin/kubectl","target":"/usr/local/bin/kubectl"}]},` +
+		`"systemd":{"units":[{"name":"kubelet.service","enabled":true,"contents":"[Service]\nExecStart=/usr/local/bin/kubelet\n"},` +
+		`{"name":"containerd.service","enabled":true},` +
+		`{"name":"var-lib-kubelet.mount","enabled":true,"contents":"[Unit]\nBefore=local-fs.target\n\n[Mount]\nWhat=/var/lib/kubelet\nWhere=/var/lib/kubelet\nType=none\nOptions=bind,exec\n\n[Install]\nWantedBy=local-fs.target\n"}]}}`
+	if out.String() != expected {
+		t.Errorf("unexpected config\nexpected: %s\nactual:   %s", expected, out.String())
+	}
+}
+
+func TestMountUnitName(t *testing.T) {
+	grid := map[string]string{
+		"/":                           "-.mount",
+		"/var/lib/kubelet":            "var-lib-kubelet.mount",
+		"/opt/kops/bin/":              "opt-kops-bin.mount",
+		"/home/kubernetes/flexvolume": "home-kubernetes-flexvolume.mount",
+		"/mnt/my-disk":                "mnt-my\\x2ddisk.mount",
+	}
+	for mountpoint, expected := range grid {
+		if actual := mountUnitName(mountpoint); actual != expected {
+			t.Errorf("unexpected unit name for %q: expected %q, got %q", mountpoint, expected, actual)
+		}
+	}
+}
+
+type unsupportedTask struct{}
+
+func (t *unsupportedTask) Run(c *fi.Context) error {
+	return nil
+}
+
+func (_ *unsupportedTask) RenderLocal(t fi.Target, a, e, changes *unsupportedTask) error {
+	return nil
+}
+
+type resourceTask struct{}
+
+func (t *resourceTask) Run(c *fi.Context) error {
+	return nil
+}
+
+type supportedTask struct{}
+
+func (t *supportedTask) Run(c *fi.Context) error {
+	return nil
+}
+
+func (_ *supportedTask) RenderIgnition(t *IgnitionTarget, a, e, changes *supportedTask) error {
+	return nil
+}
+
+func TestCheckTasks(t *testing.T) {
+	if err := CheckTasks(map[string]fi.Task{"supported": &supportedTask{}, "resource": &resourceTask{}}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	err := CheckTasks(map[string]fi.Task{
+		"supported": &supportedTask{},
+		"b":         &unsupportedTask{},
+		"a":         &unsupportedTask{},
+	})
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	if expected := "tasks not supported with the Ignition target: a, b"; err.Error() != expected {
+		t.Errorf("unexpected error %q, expected %q", err.Error(), expected)
+	}
+}
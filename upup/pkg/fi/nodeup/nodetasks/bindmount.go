@@ -25,6 +25,7 @@ import (
 	"k8s.io/klog/v2"
 	"k8s.io/kops/upup/pkg/fi"
 	"k8s.io/kops/upup/pkg/fi/nodeup/cloudinit"
+	"k8s.io/kops/upup/pkg/fi/nodeup/ignition"
 	"k8s.io/kops/upup/pkg/fi/nodeup/local"
 )
 
@@ -253,3 +254,22 @@ func (e *BindMount) execute(t Executor) error {
 func (_ *BindMount) RenderCloudInit(t *cloudinit.CloudInitTarget, a, e, changes *BindMount) error {
 	return fmt.Errorf("BindMount::RenderCloudInit not implemented")
 }
+
+// RenderIgnition implements fi.Task::Render functionality for an Ignition target
+func (_ *BindMount) RenderIgnition(t *ignition.IgnitionTarget, a, e, changes *BindMount) error {
+	options := []string{"bind"}
+	if e.Recursive {
+		options = []string{"rbind"}
+	}
+	for _, option := range e.Options {
+		switch option {
+		case "ro", "rshared", "exec", "noexec", "suid", "nosuid", "dev", "nodev":
+			options = append(options, option)
+		default:
+			return fmt.Errorf("unknown option: %q", option)
+		}
+	}
+
+	t.AddMount(e.Source, e.Mountpoint, "none", options)
+	return nil
+}
@@ -28,6 +28,7 @@ import (
 	"k8s.io/klog/v2"
 	"k8s.io/kops/upup/pkg/fi"
 	"k8s.io/kops/upup/pkg/fi/nodeup/cloudinit"
+	"k8s.io/kops/upup/pkg/fi/nodeup/ignition"
 	"k8s.io/kops/upup/pkg/fi/nodeup/local"
 )
 
@@ -312,3 +313,28 @@ func (_ *File) RenderCloudInit(t *cloudinit.CloudInitTarget, a, e, changes *File
 
 	return nil
 }
+
+// RenderIgnition implements fi.Task::Render functionality for an Ignition target
+func (_ *File) RenderIgnition(t *ignition.IgnitionTarget, a, e, changes *File) error {
+	dirMode := os.FileMode(0o755)
+	fileMode, err := fi.ParseFileMode(fi.StringValue(e.Mode), 0o644)
+	if err != nil {
+		return fmt.Errorf("invalid file mode for %s: %q", e.Path, *e.Mode)
+	}
+
+	// OnChangeExecute is not rendered: Ignition writes files before systemd starts,
+	// so units, drop-ins and sysctls are read from them on first boot.
+
+	switch e.Type {
+	case FileType_Symlink:
+		t.AddLink(e.Path, fi.StringValue(e.Symlink))
+	case FileType_Directory:
+		t.AddDirectory(e.Path, dirMode, fi.StringValue(e.Owner), fi.StringValue(e.Group))
+	case FileType_File:
+		return t.WriteFile(e.Path, e.Contents, fileMode, fi.StringValue(e.Owner), fi.StringValue(e.Group))
+	default:
+		return fmt.Errorf("File type=%q not valid/supported", e.Type)
+	}
+
+	return nil
+}
@@ -25,6 +25,7 @@ import (
 	"k8s.io/klog/v2"
 	"k8s.io/kops/upup/pkg/fi"
 	"k8s.io/kops/upup/pkg/fi/nodeup/cloudinit"
+	"k8s.io/kops/upup/pkg/fi/nodeup/ignition"
 	"k8s.io/kops/upup/pkg/fi/nodeup/local"
 )
 
@@ -129,3 +130,9 @@ func (_ *GroupTask) RenderCloudInit(t *cloudinit.CloudInitTarget, a, e, changes
 
 	return nil
 }
+
+// RenderIgnition implements fi.Task::Render functionality for an Ignition target
+func (_ *GroupTask) RenderIgnition(t *ignition.IgnitionTarget, a, e, changes *GroupTask) error {
+	t.AddGroup(e.Name, e.GID, e.System)
+	return nil
+}
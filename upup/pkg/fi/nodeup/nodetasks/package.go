@@ -21,12 +21,14 @@ import (
 	"os"
 	"os/exec"
 	"path"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"sync"
 
 	"k8s.io/klog/v2"
 	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/ospackages"
 	"k8s.io/kops/upup/pkg/fi"
 	"k8s.io/kops/upup/pkg/fi/nodeup/cloudinit"
 	"k8s.io/kops/upup/pkg/fi/nodeup/local"
@@ -37,7 +39,10 @@ import (
 type Package struct {
 	Name string
 
-	Version      *string `json:"version,omitempty"`
+	Version *string `json:"version,omitempty"`
+	// MinVersion is, for a dependency installed from a file, the lowest version that satisfies the package
+	// depending on it; an installed dependency is only replaced if it is older.
+	MinVersion   *string `json:"minVersion,omitempty"`
 	Source       *string `json:"source,omitempty"`
 	Hash         *string `json:"hash,omitempty"`
 	PreventStart *bool   `json:"preventStart,omitempty"`
@@ -73,7 +78,8 @@ func (e *Package) GetDependencies(tasks map[string]fi.Task) []fi.Task {
 		}
 	}
 
-	// If this package is a bare deb, install it after OS managed packages
+	// If this package is a bare deb, install it after OS managed packages,
+	// and once the repository metadata of the bundle it may come from is verified
 	if !e.isOSPackage() {
 		for _, v := range tasks {
 			if vp, ok := v.(*Package); ok {
@@ -81,6 +87,9 @@ func (e *Package) GetDependencies(tasks map[string]fi.Task) []fi.Task {
 					deps = append(deps, v)
 				}
 			}
+			if _, ok := v.(*PackageBundle); ok {
+				deps = append(deps, v)
+			}
 		}
 	}
 
@@ -134,15 +143,15 @@ func (e *Package) Find(c *fi.Context) (*Package, error) {
 		return nil, fmt.Errorf("unknown or unsupported distro: %v", err)
 	}
 
-	if d.IsDebianFamily() {
+	switch d.PackageManager() {
+	case distributions.PackageManagerApt:
 		return e.findDpkg(c)
+	case distributions.PackageManagerYum, distributions.PackageManagerDnf, distributions.PackageManagerZypper:
+		// yum, dnf and zypper all record installed packages in the rpm database
+		return e.findRpm(c)
+	default:
+		return nil, fmt.Errorf("unsupported package system")
 	}
-
-	if d.IsRHELFamily() {
-		return e.findYum(c)
-	}
-
-	return nil, fmt.Errorf("unsupported package system")
 }
 
 func (e *Package) findDpkg(c *fi.Context) (*Package, error) {
@@ -212,7 +221,7 @@ func (e *Package) findDpkg(c *fi.Context) (*Package, error) {
 	}, nil
 }
 
-func (e *Package) findYum(c *fi.Context) (*Package, error) {
+func (e *Package) findRpm(c *fi.Context) (*Package, error) {
 	args := []string{"/usr/bin/rpm", "-q", e.Name, "--queryformat", "%{NAME} %{VERSION}"}
 	human := strings.Join(args, " ")
 
@@ -299,17 +308,27 @@ func (_ *Package) RenderLocal(t *local.LocalTarget, a, e, changes *Package) erro
 			var ext string
 			if d.IsDebianFamily() {
 				ext = ".deb"
-			} else if d.IsRHELFamily() {
+			} else if d.IsRHELFamily() || d.IsSUSEFamily() {
 				ext = ".rpm"
 			} else {
 				return fmt.Errorf("unsupported package system")
 			}
 
 			// Download all the debs/rpms.
-			pkgs = make([]string, 1+len(e.Deps))
-			for i, pkg := range append([]*Package{e}, e.Deps...) {
+			for _, pkg := range append([]*Package{e}, e.Deps...) {
+				if pkg != e {
+					// Don't replace packages that came with the image, unless they are too old
+					installed, err := isInstalled(d, pkg.Name, fi.StringValue(pkg.MinVersion))
+					if err != nil {
+						return err
+					}
+					if installed {
+						klog.V(2).Infof("dependency %q of package %q is already installed at a suitable version", pkg.Name, e.Name)
+						continue
+					}
+				}
 				local := path.Join(localPackageDir, pkg.Name+ext)
-				pkgs[i] = local
+				pkgs = append(pkgs, local)
 				var hash *hashing.Hash
 				if fi.StringValue(pkg.Hash) != "" {
 					parsed, err := hashing.FromString(fi.StringValue(pkg.Hash))
@@ -324,24 +343,25 @@ func (_ *Package) RenderLocal(t *local.LocalTarget, a, e, changes *Package) erro
 				}
 			}
 		} else {
-			pkgs = append(pkgs, e.Name)
+			pkgs = append(pkgs, packageSpec(d.PackageManager(), e.Name, fi.StringValue(e.Version)))
 		}
 
-		var args []string
+		if e.Source != nil && d.IsRHELFamily() {
+			// Local rpms are checked against the keys of the repositories, which may not have been imported yet
+			if err := importRepositoryKeys(); err != nil {
+				return err
+			}
+		}
+
+		args, err := installCommand(d.PackageManager(), e.Source != nil)
+		if err != nil {
+			return err
+		}
+		args = append(args, pkgs...)
 		env := os.Environ()
 		if d.IsDebianFamily() {
-			args = []string{"apt-get", "install", "--yes", "--no-install-recommends"}
 			env = append(env, "DEBIAN_FRONTEND=noninteractive")
-		} else if d.IsRHELFamily() {
-			if d == distributions.DistributionRhel8 || d == distributions.DistributionRocky8 {
-				args = []string{"/usr/bin/dnf", "install", "-y", "--setopt=install_weak_deps=False"}
-			} else {
-				args = []string{"/usr/bin/yum", "install", "-y"}
-			}
-		} else {
-			return fmt.Errorf("unsupported package system")
 		}
-		args = append(args, pkgs...)
 
 		klog.Infof("running command %s", args)
 		cmd := exec.Command(args[0], args[1:]...)
@@ -362,9 +382,9 @@ func (_ *Package) RenderLocal(t *local.LocalTarget, a, e, changes *Package) erro
 				}
 
 				changes.Healthy = nil
-			} else if d.IsRHELFamily() {
-				// Not set on TagOSFamilyRHEL, we can't currently reach here anyway...
-				return fmt.Errorf("package repair not supported on RHEL/CentOS")
+			} else if d.IsRHELFamily() || d.IsSUSEFamily() {
+				// Not set by findRpm, we can't currently reach here anyway...
+				return fmt.Errorf("package repair not supported on rpm-based distributions")
 			} else {
 				return fmt.Errorf("unsupported package system")
 			}
@@ -378,6 +398,165 @@ func (_ *Package) RenderLocal(t *local.LocalTarget, a, e, changes *Package) erro
 	return nil
 }
 
+// isInstalled returns true if the package with the given name is installed, at minVersion or newer if it is set
+func isInstalled(d distributions.Distribution, name string, minVersion string) (bool, error) {
+	var args []string
+	if d.IsDebianFamily() {
+		args = []string{"dpkg-query", "-f", "${db:Status-Abbrev}${Version}\\n", "-W", name}
+	} else if d.IsRHELFamily() || d.IsSUSEFamily() {
+		args = []string{"/usr/bin/rpm", "-q", name, "--queryformat", "%{EPOCH}:%{VERSION}-%{RELEASE}\\n"}
+	} else {
+		return false, fmt.Errorf("unsupported package system")
+	}
+
+	output, err := exec.Command(args[0], args[1:]...).CombinedOutput()
+	if err != nil {
+		if _, ok := err.(*exec.ExitError); ok {
+			// Both dpkg-query and rpm exit with an error for packages they don't know
+			return false, nil
+		}
+		return false, fmt.Errorf("error checking whether package %q is installed: %v: %s", name, err, string(output))
+	}
+	return installedAtVersion(d, string(output), minVersion), nil
+}
+
+// installedAtVersion returns true if the output of the query in isInstalled lists an installed package
+// at minVersion or newer; rpm lists one line for each installed architecture
+func installedAtVersion(d distributions.Distribution, output string, minVersion string) bool {
+	for _, line := range strings.Split(output, "\n") {
+		var version string
+		if d.IsDebianFamily() {
+			if !strings.HasPrefix(line, "ii") {
+				continue
+			}
+			version = strings.TrimSpace(line[2:])
+		} else {
+			// rpm prints (none) for packages without an epoch
+			version = strings.TrimPrefix(strings.TrimSpace(line), "(none):")
+			if version == "" {
+				continue
+			}
+		}
+		if minVersion == "" || ospackages.CompareVersions(d, version, minVersion) >= 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// installCommand returns the command that installs packages with the given package manager;
+// local is true when the packages are downloaded files rather than names from the package repositories
+func installCommand(packageManager distributions.PackageManager, local bool) ([]string, error) {
+	switch packageManager {
+	case distributions.PackageManagerApt:
+		return []string{"apt-get", "install", "--yes", "--no-install-recommends"}, nil
+	case distributions.PackageManagerYum:
+		args := []string{"/usr/bin/yum", "install", "-y"}
+		if local {
+			// The downloaded files include their dependencies, so there is no need to contact the repositories,
+			// and their signatures are checked like those of packages from the repositories
+			args = append(args, "--disablerepo=*", "--setopt=localpkg_gpgcheck=1")
+		}
+		return args, nil
+	case distributions.PackageManagerDnf:
+		args := []string{"/usr/bin/dnf", "install", "-y", "--setopt=install_weak_deps=False"}
+		if local {
+			args = append(args, "--disablerepo=*", "--setopt=localpkg_gpgcheck=1")
+		}
+		return args, nil
+	case distributions.PackageManagerZypper:
+		// zypper checks the signatures of local rpms unless told otherwise
+		args := []string{"/usr/bin/zypper", "--non-interactive"}
+		if local {
+			args = append(args, "--no-refresh")
+		}
+		args = append(args, "install", "--no-recommends")
+		return args, nil
+	default:
+		return nil, fmt.Errorf("unsupported package system")
+	}
+}
+
+// yumRepositoryFiles is the pattern of the files configuring the repositories of yum and dnf
+const yumRepositoryFiles = "/etc/yum.repos.d/*.repo"
+
+// importRepositoryKeys imports the local keys that the repositories of yum and dnf check packages with into the rpm
+// database, since yum and dnf only import them when installing from the repositories
+func importRepositoryKeys() error {
+	files, err := filepath.Glob(yumRepositoryFiles)
+	if err != nil {
+		return err
+	}
+	var keys []string
+	seen := make(map[string]bool)
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("error reading %q: %v", file, err)
+		}
+		for _, key := range repositoryKeys(string(data)) {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	if len(keys) == 0 {
+		return fmt.Errorf("no repository keys found in %s to verify local packages with", yumRepositoryFiles)
+	}
+
+	args := append([]string{"/usr/bin/rpm", "--import"}, keys...)
+	klog.Infof("running command %s", args)
+	output, err := exec.Command(args[0], args[1:]...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("error importing repository keys: %v: %s", err, string(output))
+	}
+	return nil
+}
+
+// repositoryKeys returns the local key files named by the gpgkey options of a yum repository file.
+// Keys that are downloaded or named using variables are skipped, since rpm cannot import them.
+func repositoryKeys(repoFile string) []string {
+	var keys []string
+	inKeys := false
+	for _, line := range strings.Split(repoFile, "\n") {
+		var values string
+		if strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t") {
+			// A continuation of the previous option
+			if !inKeys {
+				continue
+			}
+			values = line
+		} else {
+			tokens := strings.SplitN(line, "=", 2)
+			inKeys = len(tokens) == 2 && strings.TrimSpace(tokens[0]) == "gpgkey"
+			if !inKeys {
+				continue
+			}
+			values = tokens[1]
+		}
+		for _, value := range strings.FieldsFunc(values, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' }) {
+			if strings.HasPrefix(value, "file://") && !strings.Contains(value, "$") {
+				keys = append(keys, strings.TrimPrefix(value, "file://"))
+			}
+		}
+	}
+	return keys
+}
+
+// packageSpec returns the argument that selects a package from the package repositories, pinned to version if it is set
+func packageSpec(packageManager distributions.PackageManager, name string, version string) string {
+	if version == "" {
+		return name
+	}
+	switch packageManager {
+	case distributions.PackageManagerYum, distributions.PackageManagerDnf:
+		return name + "-" + version
+	default:
+		return name + "=" + version
+	}
+}
+
 func (_ *Package) RenderCloudInit(t *cloudinit.CloudInitTarget, a, e, changes *Package) error {
 	packageName := e.Name
 	if e.Source != nil {
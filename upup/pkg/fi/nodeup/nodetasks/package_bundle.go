@@ -0,0 +1,154 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodetasks
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"k8s.io/klog/v2"
+	"k8s.io/kops/pkg/ospackages"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/nodeup/local"
+	"k8s.io/kops/util/pkg/vfs"
+)
+
+// aptKeyrings are the patterns of the binary keyrings holding the keys apt trusts to sign repositories
+var aptKeyrings = []string{
+	"/etc/apt/trusted.gpg",
+	"/etc/apt/trusted.gpg.d/*.gpg",
+	"/usr/share/keyrings/*-archive-keyring.gpg",
+}
+
+// PackageBundle verifies the signed metadata of the deb repositories an OS package bundle was resolved from,
+// using the keys apt trusts, before any package is installed from the bundle
+type PackageBundle struct {
+	Name string
+	// Source is the location of the bundle
+	Source string
+	// Bundle is the index of the bundle
+	Bundle *ospackages.Bundle
+}
+
+var _ fi.HasDependencies = &PackageBundle{}
+
+// GetDependencies returns the tasks adding apt sources, which may add the keys of the bundled repositories
+func (e *PackageBundle) GetDependencies(tasks map[string]fi.Task) []fi.Task {
+	var deps []fi.Task
+	for _, v := range tasks {
+		if _, ok := v.(*AptSource); ok {
+			deps = append(deps, v)
+		}
+	}
+	return deps
+}
+
+var _ fi.HasName = &PackageBundle{}
+
+func (e *PackageBundle) GetName() *string {
+	return &e.Name
+}
+
+func (e *PackageBundle) String() string {
+	return e.Name
+}
+
+func (e *PackageBundle) Find(c *fi.Context) (*PackageBundle, error) {
+	return nil, nil
+}
+
+func (e *PackageBundle) Run(c *fi.Context) error {
+	return fi.DefaultDeltaRunMethod(e, c)
+}
+
+func (_ *PackageBundle) CheckChanges(a, e, changes *PackageBundle) error {
+	return nil
+}
+
+func (_ *PackageBundle) RenderLocal(t *local.LocalTarget, a, e, changes *PackageBundle) error {
+	keyrings, err := findAptKeyrings()
+	if err != nil {
+		return err
+	}
+
+	read := func(file *ospackages.BundledFile) ([]byte, error) {
+		u := e.Source + "/" + file.File
+		data, err := vfs.Context.ReadFile(u)
+		if err != nil {
+			return nil, fmt.Errorf("error reading %q: %v", u, err)
+		}
+		return data, nil
+	}
+	verify := func(release []byte) ([]byte, error) {
+		return gpgvVerify(keyrings, release)
+	}
+
+	klog.Infof("verifying the repository metadata of the OS package bundle %q", e.Source)
+	return e.Bundle.VerifyRepositories(read, verify)
+}
+
+// findAptKeyrings returns the keyrings holding the keys apt trusts
+func findAptKeyrings() ([]string, error) {
+	var keyrings []string
+	for _, pattern := range aptKeyrings {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, err
+		}
+		keyrings = append(keyrings, matches...)
+	}
+	if len(keyrings) == 0 {
+		return nil, fmt.Errorf("no apt keyrings found in %v", aptKeyrings)
+	}
+	return keyrings, nil
+}
+
+// gpgvVerify verifies the signature of a clearsigned file with the keys in the given keyrings,
+// returning the signed content
+func gpgvVerify(keyrings []string, signed []byte) ([]byte, error) {
+	tmpDir, err := os.MkdirTemp("", "package-bundle")
+	if err != nil {
+		return nil, fmt.Errorf("error creating temp dir: %v", err)
+	}
+	defer func() {
+		if err := os.RemoveAll(tmpDir); err != nil {
+			klog.Warningf("error deleting temp dir %q: %v", tmpDir, err)
+		}
+	}()
+
+	in := filepath.Join(tmpDir, "InRelease")
+	out := filepath.Join(tmpDir, "Release")
+	if err := os.WriteFile(in, signed, 0o600); err != nil {
+		return nil, fmt.Errorf("error writing %q: %v", in, err)
+	}
+
+	args := []string{"gpgv"}
+	for _, keyring := range keyrings {
+		args = append(args, "--keyring", keyring)
+	}
+	args = append(args, "--output", out, in)
+
+	klog.V(2).Infof("running command %s", args)
+	cmd := exec.Command(args[0], args[1:]...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("error verifying signature: %v: %s", err, string(output))
+	}
+	return os.ReadFile(out)
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodetasks
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/kops/util/pkg/distributions"
+)
+
+func TestInstallCommand(t *testing.T) {
+	tests := []struct {
+		distribution distributions.Distribution
+		local        bool
+		expected     []string
+	}{
+		{
+			distribution: distributions.DistributionUbuntu2204,
+			expected:     []string{"apt-get", "install", "--yes", "--no-install-recommends"},
+		},
+		{
+			distribution: distributions.DistributionAmazonLinux2,
+			expected:     []string{"/usr/bin/yum", "install", "-y"},
+		},
+		{
+			distribution: distributions.DistributionAmazonLinux2023,
+			expected:     []string{"/usr/bin/dnf", "install", "-y", "--setopt=install_weak_deps=False"},
+		},
+		{
+			distribution: distributions.DistributionRocky9,
+			local:        true,
+			expected:     []string{"/usr/bin/dnf", "install", "-y", "--setopt=install_weak_deps=False", "--disablerepo=*", "--setopt=localpkg_gpgcheck=1"},
+		},
+		{
+			distribution: distributions.DistributionAmazonLinux2,
+			local:        true,
+			expected:     []string{"/usr/bin/yum", "install", "-y", "--disablerepo=*", "--setopt=localpkg_gpgcheck=1"},
+		},
+		{
+			distribution: distributions.DistributionSLES15,
+			expected:     []string{"/usr/bin/zypper", "--non-interactive", "install", "--no-recommends"},
+		},
+		{
+			distribution: distributions.DistributionSLES15,
+			local:        true,
+			expected:     []string{"/usr/bin/zypper", "--non-interactive", "--no-refresh", "install", "--no-recommends"},
+		},
+	}
+
+	for _, test := range tests {
+		actual, err := installCommand(test.distribution.PackageManager(), test.local)
+		if err != nil {
+			t.Errorf("unexpected error for %v: %v", test.distribution, err)
+			continue
+		}
+		if !reflect.DeepEqual(actual, test.expected) {
+			t.Errorf("unexpected command for %v, actual=%v, expected=%v", test.distribution, actual, test.expected)
+		}
+	}
+
+	if _, err := installCommand(distributions.DistributionFlatcar.PackageManager(), false); err == nil {
+		t.Errorf("expected an error for a distribution without a package manager")
+	}
+}
+
+func TestRepositoryKeys(t *testing.T) {
+	repoFile := `[baseos]
+name=Rocky Linux $releasever - BaseOS
+mirrorlist=https://mirrors.rockylinux.org/mirrorlist?arch=$basearch&repo=BaseOS-$releasever
+gpgcheck=1
+enabled=1
+gpgkey=file:///etc/pki/rpm-gpg/RPM-GPG-KEY-Rocky-9
+
+[extras]
+name=Extras
+gpgkey = file:///etc/pki/rpm-gpg/RPM-GPG-KEY-extras,https://example.com/RPM-GPG-KEY
+	file:///etc/pki/rpm-gpg/RPM-GPG-KEY-extras-$releasever
+	file:///etc/pki/rpm-gpg/RPM-GPG-KEY-legacy
+enabled=0
+`
+	expected := []string{
+		"/etc/pki/rpm-gpg/RPM-GPG-KEY-Rocky-9",
+		"/etc/pki/rpm-gpg/RPM-GPG-KEY-extras",
+		"/etc/pki/rpm-gpg/RPM-GPG-KEY-legacy",
+	}
+	if actual := repositoryKeys(repoFile); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("unexpected keys, actual=%v, expected=%v", actual, expected)
+	}
+}
+
+func TestPackageSpec(t *testing.T) {
+	tests := []struct {
+		packageManager distributions.PackageManager
+		version        string
+		expected       string
+	}{
+		{packageManager: distributions.PackageManagerApt, version: "", expected: "containerd"},
+		{packageManager: distributions.PackageManagerApt, version: "1.6.8-1", expected: "containerd=1.6.8-1"},
+		{packageManager: distributions.PackageManagerYum, version: "1.6.8", expected: "containerd-1.6.8"},
+		{packageManager: distributions.PackageManagerDnf, version: "1.6.8", expected: "containerd-1.6.8"},
+		{packageManager: distributions.PackageManagerZypper, version: "1.6.8", expected: "containerd=1.6.8"},
+	}
+
+	for _, test := range tests {
+		actual := packageSpec(test.packageManager, "containerd", test.version)
+		if actual != test.expected {
+			t.Errorf("unexpected package spec for %s, actual=%q, expected=%q", test.packageManager, actual, test.expected)
+		}
+	}
+}
+
+func TestInstalledAtVersion(t *testing.T) {
+	tests := []struct {
+		distribution distributions.Distribution
+		output       string
+		minVersion   string
+		expected     bool
+	}{
+		{distribution: distributions.DistributionUbuntu2204, output: "ii 2.35-0ubuntu3\n", expected: true},
+		{distribution: distributions.DistributionUbuntu2204, output: "ii 2.35-0ubuntu3\n", minVersion: "2.34", expected: true},
+		{distribution: distributions.DistributionUbuntu2204, output: "ii 2.31-0ubuntu9\n", minVersion: "2.34", expected: false},
+		{distribution: distributions.DistributionUbuntu2204, output: "rc 2.35-0ubuntu3\n", expected: false},
+		{distribution: distributions.DistributionUbuntu2204, output: "", expected: false},
+		{distribution: distributions.DistributionRocky9, output: "(none):2.34-60.el9\n", minVersion: "2.34", expected: true},
+		{distribution: distributions.DistributionRocky9, output: "(none):2.28-211.el8\n", minVersion: "2.34", expected: false},
+		{distribution: distributions.DistributionRocky9, output: "(none):2.28-211.el8\n(none):2.34-60.el9\n", minVersion: "2.34", expected: true},
+		{distribution: distributions.DistributionRocky9, output: "1:1.0-1\n", minVersion: "2.0", expected: true},
+	}
+
+	for _, test := range tests {
+		actual := installedAtVersion(test.distribution, test.output, test.minVersion)
+		if actual != test.expected {
+			t.Errorf("unexpected result for %v %q with minimum version %q, actual=%v, expected=%v", test.distribution, test.output, test.minVersion, actual, test.expected)
+		}
+	}
+}
@@ -27,6 +27,7 @@ import (
 	"k8s.io/klog/v2"
 	"k8s.io/kops/upup/pkg/fi"
 	"k8s.io/kops/upup/pkg/fi/nodeup/cloudinit"
+	"k8s.io/kops/upup/pkg/fi/nodeup/ignition"
 	"k8s.io/kops/upup/pkg/fi/nodeup/local"
 	"k8s.io/kops/upup/pkg/fi/nodeup/nodetasks/dnstasks"
 	"k8s.io/kops/util/pkg/distributions"
@@ -150,7 +151,7 @@ func (e *Service) systemdSystemPath() (string, error) {
 
 	if d.IsDebianFamily() {
 		return debianSystemdSystemPath, nil
-	} else if d.IsRHELFamily() {
+	} else if d.IsRHELFamily() || d.IsSUSEFamily() {
 		return centosSystemdSystemPath, nil
 	} else if d == distributions.DistributionFlatcar {
 		return flatcarSystemdSystemPath, nil
@@ -397,6 +398,14 @@ func (_ *Service) RenderCloudInit(t *cloudinit.CloudInitTarget, a, e, changes *S
 	return nil
 }
 
+// RenderIgnition implements fi.Task::Render functionality for an Ignition target
+func (_ *Service) RenderIgnition(t *ignition.IgnitionTarget, a, e, changes *Service) error {
+	// Ignition writes units under /etc/systemd/system, and systemd starts the enabled units on first boot
+	enabled := fi.BoolValue(e.ManageState) && fi.BoolValue(e.Enabled)
+	t.AddUnit(e.Name, e.Definition, enabled)
+	return nil
+}
+
 var _ fi.HasName = &Service{}
 
 func (f *Service) GetName() *string {
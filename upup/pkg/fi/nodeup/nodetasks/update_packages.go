@@ -25,6 +25,7 @@ import (
 	"k8s.io/klog/v2"
 	"k8s.io/kops/upup/pkg/fi"
 	"k8s.io/kops/upup/pkg/fi/nodeup/cloudinit"
+	"k8s.io/kops/upup/pkg/fi/nodeup/ignition"
 	"k8s.io/kops/upup/pkg/fi/nodeup/local"
 	"k8s.io/kops/util/pkg/distributions"
 )
@@ -76,18 +77,23 @@ func (_ *UpdatePackages) RenderLocal(t *local.LocalTarget, a, e, changes *Update
 		return fmt.Errorf("unknown or unsupported distro: %v", err)
 	}
 	var args []string
-	if d.IsDebianFamily() {
+	switch d.PackageManager() {
+	case distributions.PackageManagerApt:
 		args = []string{"apt-get", "update"}
-	} else if d.IsRHELFamily() {
+	case distributions.PackageManagerYum:
 		// Probably not technically needed
 		args = []string{"/usr/bin/yum", "check-update"}
-	} else {
+	case distributions.PackageManagerDnf:
+		args = []string{"/usr/bin/dnf", "check-update"}
+	case distributions.PackageManagerZypper:
+		args = []string{"/usr/bin/zypper", "--non-interactive", "refresh"}
+	default:
 		return fmt.Errorf("unsupported package system")
 	}
 	klog.Infof("running command %s", args)
 	cmd := exec.Command(args[0], args[1:]...)
 	output, err := cmd.CombinedOutput()
-	// 'yum check-update' exits with 100 if it finds updates; treat it like a success
+	// 'yum check-update' and 'dnf check-update' exit with 100 if they find updates; treat it like a success
 	if exitCode := cmd.ProcessState.Sys().(syscall.WaitStatus).ExitStatus(); err != nil && exitCode != 100 {
 		return fmt.Errorf("error update packages: %v: %s", err, string(output))
 	}
@@ -99,3 +105,9 @@ func (_ *UpdatePackages) RenderCloudInit(t *cloudinit.CloudInitTarget, a, e, cha
 	t.Config.PackageUpdate = true
 	return nil
 }
+
+// RenderIgnition implements fi.Task::Render functionality for an Ignition target
+func (_ *UpdatePackages) RenderIgnition(t *ignition.IgnitionTarget, a, e, changes *UpdatePackages) error {
+	// Flatcar and Fedora CoreOS are updated as a whole image, there are no packages to update
+	return nil
+}
@@ -25,6 +25,7 @@ import (
 	"k8s.io/klog/v2"
 	"k8s.io/kops/upup/pkg/fi"
 	"k8s.io/kops/upup/pkg/fi/nodeup/cloudinit"
+	"k8s.io/kops/upup/pkg/fi/nodeup/ignition"
 	"k8s.io/kops/upup/pkg/fi/nodeup/local"
 )
 
@@ -138,3 +139,9 @@ func (_ *UserTask) RenderCloudInit(t *cloudinit.CloudInitTarget, a, e, changes *
 
 	return nil
 }
+
+// RenderIgnition implements fi.Task::Render functionality for an Ignition target
+func (_ *UserTask) RenderIgnition(t *ignition.IgnitionTarget, a, e, changes *UserTask) error {
+	t.AddUser(e.Name, e.UID, e.Home, e.Shell)
+	return nil
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeup
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"k8s.io/klog/v2"
+	"k8s.io/kops/pkg/ospackages"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/nodeup/nodetasks"
+	"k8s.io/kops/util/pkg/architectures"
+	"k8s.io/kops/util/pkg/distributions"
+	"k8s.io/kops/util/pkg/hashing"
+	"k8s.io/kops/util/pkg/vfs"
+)
+
+// useOSPackageBundle installs the packages from the OS package bundle for this distribution and architecture.
+// The index of the bundle must match the hash recorded for it by update cluster.
+func useOSPackageBundle(taskMap map[string]fi.Task, base string, hashes map[string]string, distribution distributions.Distribution, architecture architectures.Architecture) error {
+	bundleURL := ospackages.BundleURL(base, distribution, architecture)
+	data, err := vfs.Context.ReadFile(bundleURL + "/" + ospackages.IndexFile)
+	if err != nil {
+		return fmt.Errorf("error reading OS package bundle %q: %v", bundleURL, err)
+	}
+	bundle, err := parseOSPackageBundle(data, hashes[ospackages.BundleKey(distribution, architecture)])
+	if err != nil {
+		return fmt.Errorf("error reading OS package bundle %q: %v", bundleURL, err)
+	}
+	return applyOSPackageBundle(taskMap, bundle, bundleURL, distribution)
+}
+
+// parseOSPackageBundle parses the index of a bundle, once it is verified against the expected sha256 hash
+func parseOSPackageBundle(data []byte, expectedHash string) (*ospackages.Bundle, error) {
+	if expectedHash == "" {
+		return nil, fmt.Errorf("no hash is recorded for the bundle; run kops update cluster once the bundle is copied")
+	}
+	expected, err := hashing.HashAlgorithmSHA256.FromString(expectedHash)
+	if err != nil {
+		return nil, err
+	}
+	actual, err := hashing.HashAlgorithmSHA256.Hash(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if !actual.Equal(expected) {
+		return nil, fmt.Errorf("the hash of the index was %s, expected %s", actual.Hex(), expected.Hex())
+	}
+	return ospackages.ParseBundle(data)
+}
+
+// applyOSPackageBundle points the package tasks at the files in the bundle, along with the files of their dependencies,
+// and drops the task that updates the package lists, so that the repositories of the distribution are never contacted.
+// Debs are only installed once the signed repository metadata in the bundle is verified, while rpms are checked
+// against their own signatures when they are installed.
+func applyOSPackageBundle(taskMap map[string]fi.Task, bundle *ospackages.Bundle, bundleURL string, distribution distributions.Distribution) error {
+	delete(taskMap, "UpdatePackages")
+	if distribution.IsDebianFamily() {
+		taskMap["PackageBundle"] = &nodetasks.PackageBundle{
+			Name:   "PackageBundle",
+			Source: bundleURL,
+			Bundle: bundle,
+		}
+	}
+
+	var keys []string
+	for key := range taskMap {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		p, ok := taskMap[key].(*nodetasks.Package)
+		if !ok || p.Source != nil {
+			continue
+		}
+
+		closure, err := bundle.Closure(p.Name)
+		if err != nil {
+			return err
+		}
+		klog.V(2).Infof("installing package %q from the OS package bundle, with %d dependencies", p.Name, len(closure)-1)
+
+		p.Source = fi.String(bundleURL + "/" + closure[0].File)
+		p.Hash = fi.String(closure[0].SHA256)
+		p.Deps = nil
+		for _, dep := range closure[1:] {
+			d := &nodetasks.Package{
+				Name:   dep.Name,
+				Source: fi.String(bundleURL + "/" + dep.File),
+				Hash:   fi.String(dep.SHA256),
+			}
+			if dep.MinVersion != "" {
+				d.MinVersion = fi.String(dep.MinVersion)
+			}
+			p.Deps = append(p.Deps, d)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeup
+
+import (
+	"bytes"
+	"testing"
+
+	"k8s.io/kops/pkg/ospackages"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/nodeup/nodetasks"
+	"k8s.io/kops/util/pkg/distributions"
+	"k8s.io/kops/util/pkg/hashing"
+)
+
+func TestApplyOSPackageBundle(t *testing.T) {
+	bundle := &ospackages.Bundle{
+		Distribution: "jammy",
+		Architecture: "amd64",
+		Packages: []*ospackages.BundledPackage{
+			{Name: "conntrack", File: "conntrack.deb", SHA256: "1111", Depends: []string{"libnetfilter-conntrack3"}},
+			{Name: "libnetfilter-conntrack3", MinVersion: "1.0.9", File: "libnetfilter-conntrack3.deb", SHA256: "2222"},
+			{Name: "socat", File: "socat.deb", SHA256: "3333"},
+		},
+	}
+	bundleURL := "https://example.com/os-packages/jammy/amd64"
+
+	taskMap := map[string]fi.Task{
+		"Package/conntrack": &nodetasks.Package{Name: "conntrack"},
+		"Package/socat":     &nodetasks.Package{Name: "socat"},
+		"UpdatePackages":    nodetasks.NewUpdatePackages(),
+	}
+	if err := applyOSPackageBundle(taskMap, bundle, bundleURL, distributions.DistributionUbuntu2204); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, found := taskMap["UpdatePackages"]; found {
+		t.Errorf("expected the UpdatePackages task to be removed")
+	}
+	if verify, ok := taskMap["PackageBundle"].(*nodetasks.PackageBundle); !ok || verify.Source != bundleURL || verify.Bundle != bundle {
+		t.Errorf("expected a task verifying the repository metadata of the bundle, got %v", taskMap["PackageBundle"])
+	}
+	if deps := taskMap["Package/conntrack"].(*nodetasks.Package).GetDependencies(taskMap); !containsTask(deps, taskMap["PackageBundle"]) {
+		t.Errorf("expected the packages from the bundle to depend on the verification of its repository metadata")
+	}
+
+	conntrack := taskMap["Package/conntrack"].(*nodetasks.Package)
+	if fi.StringValue(conntrack.Source) != bundleURL+"/conntrack.deb" || fi.StringValue(conntrack.Hash) != "1111" {
+		t.Errorf("unexpected source %q and hash %q", fi.StringValue(conntrack.Source), fi.StringValue(conntrack.Hash))
+	}
+	if len(conntrack.Deps) != 1 || conntrack.Deps[0].Name != "libnetfilter-conntrack3" || fi.StringValue(conntrack.Deps[0].Source) != bundleURL+"/libnetfilter-conntrack3.deb" {
+		t.Errorf("unexpected dependencies %v", conntrack.Deps)
+	} else if fi.StringValue(conntrack.Deps[0].MinVersion) != "1.0.9" {
+		t.Errorf("unexpected minimum version %q", fi.StringValue(conntrack.Deps[0].MinVersion))
+	}
+	if socat := taskMap["Package/socat"].(*nodetasks.Package); len(socat.Deps) != 0 || fi.StringValue(socat.Hash) != "3333" {
+		t.Errorf("unexpected socat task %v", socat)
+	}
+
+	taskMap["Package/nvidia-driver-470"] = &nodetasks.Package{Name: "nvidia-driver-470"}
+	if err := applyOSPackageBundle(taskMap, bundle, bundleURL, distributions.DistributionUbuntu2204); err == nil {
+		t.Errorf("expected an error for a package missing from the bundle")
+	}
+}
+
+func TestApplyOSPackageBundleRPM(t *testing.T) {
+	bundle := &ospackages.Bundle{
+		Distribution: "rocky9",
+		Architecture: "amd64",
+		Packages: []*ospackages.BundledPackage{
+			{Name: "socat", File: "socat.rpm", SHA256: "3333"},
+		},
+	}
+	taskMap := map[string]fi.Task{
+		"Package/socat": &nodetasks.Package{Name: "socat"},
+	}
+	if err := applyOSPackageBundle(taskMap, bundle, "https://example.com/os-packages/rocky9/amd64", distributions.DistributionRocky9); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// rpms carry their own signatures, which are checked when they are installed
+	if _, found := taskMap["PackageBundle"]; found {
+		t.Errorf("expected no task verifying repository metadata for an rpm bundle")
+	}
+}
+
+func containsTask(tasks []fi.Task, task fi.Task) bool {
+	for _, t := range tasks {
+		if t == task {
+			return true
+		}
+	}
+	return false
+}
+
+func TestParseOSPackageBundle(t *testing.T) {
+	bundle := &ospackages.Bundle{
+		Distribution: "jammy",
+		Architecture: "amd64",
+		Packages: []*ospackages.BundledPackage{
+			{Name: "socat", File: "socat.deb", SHA256: "3333"},
+		},
+	}
+	data, err := bundle.Marshal()
+	if err != nil {
+		t.Fatalf("error serializing bundle: %v", err)
+	}
+	hash, err := hashing.HashAlgorithmSHA256.Hash(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("error hashing bundle: %v", err)
+	}
+
+	parsed, err := parseOSPackageBundle(data, hash.Hex())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p := parsed.Find("socat"); p == nil || p.SHA256 != "3333" {
+		t.Errorf("unexpected bundle %v", parsed)
+	}
+
+	if _, err := parseOSPackageBundle(data, ""); err == nil {
+		t.Errorf("expected an error for a bundle without a recorded hash")
+	}
+	tampered := bytes.Replace(data, []byte("3333"), []byte("4444"), 1)
+	if _, err := parseOSPackageBundle(tampered, hash.Hex()); err == nil {
+		t.Errorf("expected an error for a bundle that does not match its hash")
+	}
+}
@@ -0,0 +1,372 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeup
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/klog/v2"
+	"k8s.io/kops/pkg/assets"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/nodeup/local"
+	"k8s.io/kops/upup/pkg/fi/nodeup/nodetasks"
+)
+
+const (
+	// NodeConditionConfigDrift is the Node condition reporting whether the node has drifted from its configuration
+	NodeConditionConfigDrift v1.NodeConditionType = "KopsConfigDrift"
+
+	// reconcileMaxTaskDuration bounds the retries of a task that fails during a reconcile
+	reconcileMaxTaskDuration = time.Minute
+
+	// maxDriftedTasksInMessage is the number of drifted tasks named in the condition and event messages
+	maxDriftedTasksInMessage = 20
+)
+
+// ReconcileCommand periodically compares the node with its configuration,
+// reporting drift as the KopsConfigDrift condition of the Node
+type ReconcileCommand struct {
+	NodeUpCommand
+
+	// Interval is the time between two reconciles
+	Interval time.Duration
+	// Apply re-applies the drifted tasks that can be changed without disrupting the node
+	Apply bool
+
+	client   kubernetes.Interface
+	nodeName string
+}
+
+// Run reconciles the node every Interval, until the context is cancelled
+func (c *ReconcileCommand) Run(ctx context.Context, out io.Writer) error {
+	for {
+		if err := c.reconcile(ctx, out); err != nil {
+			klog.Warningf("error reconciling node configuration (will retry in %s): %v", c.Interval, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(c.Interval):
+		}
+	}
+}
+
+func (c *ReconcileCommand) reconcile(ctx context.Context, out io.Writer) error {
+	tasks, err := c.buildTasks(ctx)
+	if err != nil {
+		// The client is only known once the tasks have been built once
+		if c.client != nil {
+			if reportErr := reportDrift(ctx, c.client, c.nodeName, nil, nil, err, time.Now()); reportErr != nil {
+				klog.Warningf("error reporting drift of node %q: %v", c.nodeName, reportErr)
+			}
+		}
+		return err
+	}
+
+	if c.client == nil {
+		nodeName, err := tasks.modelContext.NodeName()
+		if err != nil {
+			return err
+		}
+		config, err := clientcmd.BuildConfigFromFlags("", tasks.modelContext.KubeletKubeConfig())
+		if err != nil {
+			return fmt.Errorf("error loading kubeconfig %q: %v", tasks.modelContext.KubeletKubeConfig(), err)
+		}
+		client, err := kubernetes.NewForConfig(config)
+		if err != nil {
+			return fmt.Errorf("error building kubernetes client: %v", err)
+		}
+		c.client = client
+		c.nodeName = nodeName
+	}
+
+	drifted, err := c.findDrift(tasks, out)
+	if err != nil {
+		if reportErr := reportDrift(ctx, c.client, c.nodeName, nil, nil, err, time.Now()); reportErr != nil {
+			klog.Warningf("error reporting drift of node %q: %v", c.nodeName, reportErr)
+		}
+		return err
+	}
+	if len(drifted) != 0 {
+		klog.Infof("node configuration has drifted: %s", strings.Join(drifted, ", "))
+	}
+
+	var reapplied []string
+	if c.Apply && len(drifted) != 0 {
+		reapplied, err = c.reapply(tasks, drifted)
+		if err != nil {
+			klog.Warningf("error re-applying drifted tasks: %v", err)
+			reapplied = nil
+		}
+		if len(reapplied) != 0 {
+			klog.Infof("re-applied drifted tasks: %s", strings.Join(reapplied, ", "))
+			drifted = without(drifted, reapplied)
+		}
+	}
+
+	return reportDrift(ctx, c.client, c.nodeName, drifted, reapplied, nil, time.Now())
+}
+
+// findDrift runs the observable tasks against a dry-run target, returning the keys of the tasks that would change
+func (c *ReconcileCommand) findDrift(tasks *nodeupTasks, out io.Writer) ([]string, error) {
+	taskMap := reconcileTaskMap(tasks.taskMap)
+
+	target := fi.NewDryRunTarget(assets.NewAssetBuilder(c.cluster, false), out)
+	if err := runReconcileTasks(c, tasks, target, taskMap); err != nil {
+		return nil, err
+	}
+	return target.ChangedTasks(taskMap), nil
+}
+
+// reapply runs the drifted tasks that can safely be re-applied, returning their keys
+func (c *ReconcileCommand) reapply(tasks *nodeupTasks, drifted []string) ([]string, error) {
+	taskMap := reapplicableTasks(tasks.taskMap, drifted)
+	if len(taskMap) == 0 {
+		return nil, nil
+	}
+
+	target := &local.LocalTarget{
+		CacheDir:   c.CacheDir,
+		Cloud:      tasks.cloud,
+		InstanceID: tasks.modelContext.InstanceID,
+		Cluster:    c.cluster,
+	}
+	if err := runReconcileTasks(c, tasks, target, taskMap); err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	for k := range taskMap {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+func runReconcileTasks(c *ReconcileCommand, tasks *nodeupTasks, target fi.Target, taskMap map[string]fi.Task) error {
+	context, err := fi.NewContext(target, c.cluster, tasks.cloud, tasks.keyStore, tasks.secretStore, tasks.configBase, true, taskMap)
+	if err != nil {
+		return fmt.Errorf("error building context: %v", err)
+	}
+	defer context.Close()
+
+	var options fi.RunTasksOptions
+	options.InitDefaults()
+	options.MaxTaskDuration = reconcileMaxTaskDuration
+
+	if err := context.RunTasks(options); err != nil {
+		return fmt.Errorf("error running tasks: %v", err)
+	}
+	return nil
+}
+
+// isObservable returns true for the tasks whose Find reflects the state of the node,
+// and which have no side effects when run against a dry-run target
+func isObservable(task fi.Task) bool {
+	switch task.(type) {
+	case *nodetasks.File, *nodetasks.Service, *nodetasks.UserTask, *nodetasks.GroupTask,
+		*nodetasks.Package, *nodetasks.Archive, *nodetasks.BindMount:
+		return true
+	default:
+		return false
+	}
+}
+
+// isReapplicable returns true for the tasks that can be re-applied without restarting anything on the node
+func isReapplicable(task fi.Task) bool {
+	switch task := task.(type) {
+	case *nodetasks.File:
+		return len(task.OnChangeExecute) == 0
+	case *nodetasks.UserTask, *nodetasks.GroupTask:
+		return true
+	default:
+		return false
+	}
+}
+
+// reconcileTaskMap returns the observable tasks whose dependencies are all observable.
+// Files holding certificates issued during the run, for example, are not observable.
+func reconcileTaskMap(taskMap map[string]fi.Task) map[string]fi.Task {
+	tasks := make(map[string]fi.Task)
+	for k, task := range taskMap {
+		if isObservable(task) {
+			tasks[k] = task
+		}
+	}
+
+	for {
+		included := make(map[fi.Task]bool)
+		for _, task := range tasks {
+			included[task] = true
+		}
+
+		removed := false
+		for k, task := range tasks {
+			for _, dep := range fi.FindDependencies(tasks, task) {
+				if !included[dep] {
+					klog.V(4).Infof("not reconciling %q, as it depends on %T", k, dep)
+					delete(tasks, k)
+					removed = true
+					break
+				}
+			}
+		}
+		if !removed {
+			return tasks
+		}
+	}
+}
+
+// reapplicableTasks returns the drifted tasks that can be re-applied
+func reapplicableTasks(taskMap map[string]fi.Task, drifted []string) map[string]fi.Task {
+	tasks := make(map[string]fi.Task)
+	for _, k := range drifted {
+		if task := taskMap[k]; task != nil && isReapplicable(task) {
+			tasks[k] = task
+		}
+	}
+	return tasks
+}
+
+// reportDrift sets the KopsConfigDrift condition of the node, recording an event when new drift is found
+// or when drifted tasks were re-applied. A non-nil reconcileErr sets the condition to Unknown.
+func reportDrift(ctx context.Context, client kubernetes.Interface, nodeName string, drifted, reapplied []string, reconcileErr error, now time.Time) error {
+	condition := v1.NodeCondition{
+		Type:              NodeConditionConfigDrift,
+		LastHeartbeatTime: metav1.NewTime(now),
+	}
+	switch {
+	case reconcileErr != nil:
+		condition.Status = v1.ConditionUnknown
+		condition.Reason = "ReconcileFailed"
+		condition.Message = fmt.Sprintf("error comparing the node with its configuration: %v", reconcileErr)
+	case len(drifted) != 0:
+		condition.Status = v1.ConditionTrue
+		condition.Reason = "ConfigDrifted"
+		condition.Message = driftMessage(drifted)
+	default:
+		condition.Status = v1.ConditionFalse
+		condition.Reason = "NoConfigDrift"
+		condition.Message = "node matches its configuration"
+	}
+
+	node, err := client.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("error getting node %q: %v", nodeName, err)
+	}
+
+	var previous *v1.NodeCondition
+	for i := range node.Status.Conditions {
+		if node.Status.Conditions[i].Type == NodeConditionConfigDrift {
+			previous = &node.Status.Conditions[i]
+		}
+	}
+	condition.LastTransitionTime = metav1.NewTime(now)
+	if previous != nil && previous.Status == condition.Status {
+		condition.LastTransitionTime = previous.LastTransitionTime
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"status": map[string]interface{}{
+			"conditions": []v1.NodeCondition{condition},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("error building patch: %v", err)
+	}
+	if _, err := client.CoreV1().Nodes().PatchStatus(ctx, nodeName, patch); err != nil {
+		return fmt.Errorf("error updating status of node %q: %v", nodeName, err)
+	}
+
+	if condition.Status == v1.ConditionTrue && (previous == nil || previous.Status != v1.ConditionTrue || previous.Message != condition.Message) {
+		if err := recordNodeEvent(ctx, client, nodeName, v1.EventTypeWarning, string(NodeConditionConfigDrift), condition.Message, now); err != nil {
+			return err
+		}
+	}
+	if len(reapplied) != 0 {
+		message := fmt.Sprintf("re-applied %s", strings.Join(truncate(reapplied), ", "))
+		if err := recordNodeEvent(ctx, client, nodeName, v1.EventTypeNormal, "KopsConfigDriftCorrected", message, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func recordNodeEvent(ctx context.Context, client kubernetes.Interface, nodeName, eventType, reason, message string, now time.Time) error {
+	event := &v1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s.%s.%x", nodeName, strings.ToLower(reason), now.UnixNano()),
+			Namespace: metav1.NamespaceDefault,
+		},
+		InvolvedObject: v1.ObjectReference{
+			Kind: "Node",
+			Name: nodeName,
+			// The kubelet uses the node name as the UID of the Node in events
+			UID: types.UID(nodeName),
+		},
+		Reason:         reason,
+		Message:        message,
+		Type:           eventType,
+		Source:         v1.EventSource{Component: "nodeup", Host: nodeName},
+		FirstTimestamp: metav1.NewTime(now),
+		LastTimestamp:  metav1.NewTime(now),
+		Count:          1,
+	}
+	if _, err := client.CoreV1().Events(metav1.NamespaceDefault).Create(ctx, event, metav1.CreateOptions{}); err != nil {
+		return fmt.Errorf("error recording event for node %q: %v", nodeName, err)
+	}
+	return nil
+}
+
+func driftMessage(drifted []string) string {
+	return fmt.Sprintf("%d tasks differ from the node configuration: %s", len(drifted), strings.Join(truncate(drifted), ", "))
+}
+
+// truncate limits the list of tasks to maxDriftedTasksInMessage
+func truncate(keys []string) []string {
+	if len(keys) <= maxDriftedTasksInMessage {
+		return keys
+	}
+	truncated := append([]string{}, keys[:maxDriftedTasksInMessage]...)
+	return append(truncated, fmt.Sprintf("and %d more", len(keys)-maxDriftedTasksInMessage))
+}
+
+func without(keys, remove []string) []string {
+	removed := make(map[string]bool)
+	for _, k := range remove {
+		removed[k] = true
+	}
+	var remaining []string
+	for _, k := range keys {
+		if !removed[k] {
+			remaining = append(remaining, k)
+		}
+	}
+	return remaining
+}
@@ -0,0 +1,226 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeup
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/nodeup/nodetasks"
+)
+
+func keys(taskMap map[string]fi.Task) []string {
+	var keys []string
+	for k := range taskMap {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func TestReconcileTaskMap(t *testing.T) {
+	issueCert := &nodetasks.IssueCert{Name: "kubelet"}
+	taskMap := map[string]fi.Task{
+		"IssueCert/kubelet": issueCert,
+		"File//etc/kubernetes/kubelet.crt": &nodetasks.File{
+			Path:     "/etc/kubernetes/kubelet.crt",
+			Contents: &fi.TaskDependentResource{Task: issueCert},
+			Type:     nodetasks.FileType_File,
+		},
+		"File//etc/sysconfig/kubelet": &nodetasks.File{
+			Path:     "/etc/sysconfig/kubelet",
+			Contents: fi.NewStringResource("DAEMON_ARGS="),
+			Type:     nodetasks.FileType_File,
+		},
+		"Package/conntrack":        &nodetasks.Package{Name: "conntrack"},
+		"Service/kubelet.service":  &nodetasks.Service{Name: "kubelet.service"},
+		"UpdatePackages/packages":  &nodetasks.UpdatePackages{},
+		"LoadImageTask/protokube":  &nodetasks.LoadImageTask{Name: "protokube"},
+		"UserTask/etcd":            &nodetasks.UserTask{Name: "etcd"},
+		"GroupTask/kube-apiserver": &nodetasks.GroupTask{Name: "kube-apiserver"},
+	}
+
+	actual := keys(reconcileTaskMap(taskMap))
+	expected := []string{
+		"File//etc/sysconfig/kubelet",
+		"GroupTask/kube-apiserver",
+		"Package/conntrack",
+		"Service/kubelet.service",
+		"UserTask/etcd",
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("unexpected tasks: expected %v, got %v", expected, actual)
+	}
+}
+
+func TestReapplicableTasks(t *testing.T) {
+	taskMap := map[string]fi.Task{
+		"File//etc/sysconfig/kubelet": &nodetasks.File{Path: "/etc/sysconfig/kubelet", Type: nodetasks.FileType_File},
+		"File//etc/sysctl.d/99-k8s-general.conf": &nodetasks.File{
+			Path:            "/etc/sysctl.d/99-k8s-general.conf",
+			Type:            nodetasks.FileType_File,
+			OnChangeExecute: [][]string{{"sysctl", "--system"}},
+		},
+		"Service/kubelet.service": &nodetasks.Service{Name: "kubelet.service"},
+		"UserTask/etcd":           &nodetasks.UserTask{Name: "etcd"},
+		"GroupTask/etcd":          &nodetasks.GroupTask{Name: "etcd"},
+	}
+	drifted := []string{
+		"File//etc/sysconfig/kubelet",
+		"File//etc/sysctl.d/99-k8s-general.conf",
+		"Service/kubelet.service",
+		"UserTask/etcd",
+	}
+
+	actual := keys(reapplicableTasks(taskMap, drifted))
+	expected := []string{
+		"File//etc/sysconfig/kubelet",
+		"UserTask/etcd",
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("unexpected tasks: expected %v, got %v", expected, actual)
+	}
+}
+
+func TestReportDrift(t *testing.T) {
+	ctx := context.Background()
+	client := fake.NewSimpleClientset(&v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}})
+	start := time.Date(2022, 6, 1, 10, 0, 0, 0, time.UTC)
+
+	grid := []struct {
+		Description string
+		Drifted     []string
+		Reapplied   []string
+		Error       error
+
+		ExpectedStatus     v1.ConditionStatus
+		ExpectedMessage    string
+		ExpectedTransition bool
+		ExpectedEvents     []string
+	}{
+		{
+			Description:        "no drift",
+			ExpectedStatus:     v1.ConditionFalse,
+			ExpectedMessage:    "node matches its configuration",
+			ExpectedTransition: true,
+		},
+		{
+			Description:        "drift",
+			Drifted:            []string{"File//etc/sysconfig/kubelet", "Service/kubelet.service"},
+			ExpectedStatus:     v1.ConditionTrue,
+			ExpectedMessage:    "2 tasks differ from the node configuration: File//etc/sysconfig/kubelet, Service/kubelet.service",
+			ExpectedTransition: true,
+			ExpectedEvents:     []string{"KopsConfigDrift"},
+		},
+		{
+			Description:     "same drift",
+			Drifted:         []string{"File//etc/sysconfig/kubelet", "Service/kubelet.service"},
+			ExpectedStatus:  v1.ConditionTrue,
+			ExpectedMessage: "2 tasks differ from the node configuration: File//etc/sysconfig/kubelet, Service/kubelet.service",
+		},
+		{
+			Description:     "drift partially corrected",
+			Drifted:         []string{"Service/kubelet.service"},
+			Reapplied:       []string{"File//etc/sysconfig/kubelet"},
+			ExpectedStatus:  v1.ConditionTrue,
+			ExpectedMessage: "1 tasks differ from the node configuration: Service/kubelet.service",
+			ExpectedEvents:  []string{"KopsConfigDrift", "KopsConfigDriftCorrected"},
+		},
+		{
+			Description:        "reconcile failed",
+			Error:              errors.New("nodeup config hash mismatch"),
+			ExpectedStatus:     v1.ConditionUnknown,
+			ExpectedMessage:    "error comparing the node with its configuration: nodeup config hash mismatch",
+			ExpectedTransition: true,
+		},
+	}
+
+	var lastTransition metav1.Time
+	for i, g := range grid {
+		t.Run(g.Description, func(t *testing.T) {
+			now := start.Add(time.Duration(i) * time.Hour)
+			events, err := client.CoreV1().Events(metav1.NamespaceDefault).List(ctx, metav1.ListOptions{})
+			if err != nil {
+				t.Fatalf("error listing events: %v", err)
+			}
+			eventCount := len(events.Items)
+
+			if err := reportDrift(ctx, client, "node-1", g.Drifted, g.Reapplied, g.Error, now); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			node, err := client.CoreV1().Nodes().Get(ctx, "node-1", metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("error getting node: %v", err)
+			}
+			if len(node.Status.Conditions) != 1 {
+				t.Fatalf("expected a single condition, got %v", node.Status.Conditions)
+			}
+			condition := node.Status.Conditions[0]
+			if condition.Type != NodeConditionConfigDrift || condition.Status != g.ExpectedStatus || condition.Message != g.ExpectedMessage {
+				t.Errorf("unexpected condition %+v", condition)
+			}
+			if !condition.LastHeartbeatTime.Time.Equal(now) {
+				t.Errorf("expected heartbeat at %v, got %v", now, condition.LastHeartbeatTime)
+			}
+			if g.ExpectedTransition {
+				lastTransition = metav1.NewTime(now)
+			}
+			if !condition.LastTransitionTime.Time.Equal(lastTransition.Time) {
+				t.Errorf("expected transition at %v, got %v", lastTransition, condition.LastTransitionTime)
+			}
+
+			events, err = client.CoreV1().Events(metav1.NamespaceDefault).List(ctx, metav1.ListOptions{})
+			if err != nil {
+				t.Fatalf("error listing events: %v", err)
+			}
+			var reasons []string
+			for _, event := range events.Items[eventCount:] {
+				if event.InvolvedObject.Kind != "Node" || event.InvolvedObject.Name != "node-1" {
+					t.Errorf("unexpected event object %+v", event.InvolvedObject)
+				}
+				reasons = append(reasons, event.Reason)
+			}
+			sort.Strings(reasons)
+			if !reflect.DeepEqual(reasons, g.ExpectedEvents) {
+				t.Errorf("unexpected events: expected %v, got %v", g.ExpectedEvents, reasons)
+			}
+		})
+	}
+}
+
+func TestDriftMessageTruncated(t *testing.T) {
+	var drifted []string
+	for i := 0; i < maxDriftedTasksInMessage+5; i++ {
+		drifted = append(drifted, "File//etc/x")
+	}
+	truncated := truncate(drifted)
+	if len(truncated) != maxDriftedTasksInMessage+1 || truncated[maxDriftedTasksInMessage] != "and 5 more" {
+		t.Errorf("unexpected truncation: %v", truncated)
+	}
+	if len(drifted) != maxDriftedTasksInMessage+5 {
+		t.Errorf("truncate modified its argument")
+	}
+}
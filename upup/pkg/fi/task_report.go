@@ -0,0 +1,113 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fi
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"sync"
+	"time"
+)
+
+// TaskReport records the execution timeline of the tasks run by the executor.
+type TaskReport struct {
+	mutex sync.Mutex
+	tasks []*TaskTiming
+}
+
+// TaskTiming is the timeline of a single task.
+// Start and End are omitted for tasks that never ran, for example because a dependency failed.
+type TaskTiming struct {
+	// Key is the name of the task in the task map.
+	Key string `json:"key"`
+	// Start is the time the first attempt to run the task started.
+	Start *time.Time `json:"start,omitempty"`
+	// End is the time the last attempt to run the task finished.
+	End *time.Time `json:"end,omitempty"`
+	// Duration is the time spent running the task, summed across all attempts.
+	Duration time.Duration `json:"-"`
+	// DurationSeconds is Duration in seconds.
+	DurationSeconds float64 `json:"durationSeconds"`
+	// Attempts is the number of times the task was run.
+	Attempts int `json:"attempts"`
+	// Done is true if the task completed successfully.
+	Done bool `json:"done"`
+	// LastError is the error returned by the last unsuccessful attempt, if the task did not complete.
+	LastError string `json:"lastError,omitempty"`
+
+	attemptStart time.Time
+}
+
+func (t *TaskTiming) start() {
+	t.attemptStart = time.Now()
+	if t.Start == nil {
+		start := t.attemptStart
+		t.Start = &start
+	}
+}
+
+func (t *TaskTiming) end() {
+	end := time.Now()
+	t.End = &end
+	t.Duration += end.Sub(t.attemptStart)
+	t.DurationSeconds = t.Duration.Seconds()
+}
+
+func (t *TaskTiming) recordAttempt(err error) {
+	t.Attempts++
+	if err == nil {
+		t.Done = true
+		t.LastError = ""
+		return
+	}
+	if _, ok := err.(*ExistsAndWarnIfChangesError); ok {
+		t.Done = true
+		t.LastError = ""
+		return
+	}
+	t.LastError = err.Error()
+}
+
+func (r *TaskReport) add(t *TaskTiming) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.tasks = append(r.tasks, t)
+}
+
+// Tasks returns the task timings, sorted with the longest-running tasks first.
+func (r *TaskReport) Tasks() []*TaskTiming {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	tasks := make([]*TaskTiming, len(r.tasks))
+	copy(tasks, r.tasks)
+	sort.SliceStable(tasks, func(i, j int) bool {
+		if tasks[i].Duration != tasks[j].Duration {
+			return tasks[i].Duration > tasks[j].Duration
+		}
+		return tasks[i].Key < tasks[j].Key
+	})
+	return tasks
+}
+
+// WriteJSON writes the report as JSON to out.
+func (r *TaskReport) WriteJSON(out io.Writer) error {
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r.Tasks())
+}
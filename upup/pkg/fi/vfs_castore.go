@@ -33,6 +33,7 @@ import (
 	"k8s.io/kops/pkg/kopscodecs"
 	"k8s.io/kops/pkg/pki"
 	"k8s.io/kops/pkg/sshcredentials"
+	"k8s.io/kops/pkg/stateencryption"
 	"k8s.io/kops/util/pkg/vfs"
 )
 
@@ -110,6 +111,11 @@ func (c *VFSCAStore) loadKeyset(p vfs.Path) (*Keyset, error) {
 		return nil, fmt.Errorf("unable to read bundle %q: %v", p, err)
 	}
 
+	data, err = stateencryption.Decrypt(stateencryption.EncryptionFor(c.cluster), data)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decrypt bundle %q: %v", p, err)
+	}
+
 	o, legacyFormat, err := c.parseKeysetYaml(data)
 	if err != nil {
 		return nil, fmt.Errorf("error parsing bundle %q: %v", p, err)
@@ -187,6 +193,11 @@ func writeKeysetBundle(cluster *kops.Cluster, p vfs.Path, name string, keyset *K
 		return err
 	}
 
+	objectData, err = stateencryption.Encrypt(stateencryption.EncryptionFor(cluster), objectData)
+	if err != nil {
+		return fmt.Errorf("error encrypting keyset %q: %v", name, err)
+	}
+
 	acl, err := acls.GetACL(p, cluster)
 	if err != nil {
 		return err
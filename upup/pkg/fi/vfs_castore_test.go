@@ -17,14 +17,18 @@ limitations under the License.
 package fi
 
 import (
+	"crypto/x509/pkix"
 	"math/big"
 	"math/rand"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
+	"k8s.io/kops/pkg/apis/kops"
 	"k8s.io/kops/pkg/pki"
+	"k8s.io/kops/pkg/stateencryption"
 	"k8s.io/kops/util/pkg/vfs"
 )
 
@@ -155,6 +159,73 @@ spec:
 	}
 }
 
+func TestVFSCAStoreEncryption(t *testing.T) {
+	vfs.Context.ResetMemfsContext(true)
+
+	basePath, err := vfs.Context.BuildVfsPath("memfs://tests")
+	if err != nil {
+		t.Fatalf("error building vfspath: %v", err)
+	}
+
+	keyFile := filepath.Join(t.TempDir(), "state.key")
+	if err := os.WriteFile(keyFile, []byte("AGE-SECRET-KEY-1GFPYYSJZGFPYYSJZGFPYYSJZGFPYYSJZGFPYYSJZGFPYYSJZGFPQ4EGAEX\n"), 0o600); err != nil {
+		t.Fatalf("error writing key file: %v", err)
+	}
+
+	cluster := &kops.Cluster{}
+	cluster.Spec.StateStoreEncryption = &kops.StateStoreEncryptionSpec{
+		KeyFile: &kops.StateStoreEncryptionKeyFileSpec{Path: keyFile},
+	}
+
+	cert, privateKey, _, err := pki.IssueCert(&pki.IssueCertRequest{
+		Type:    "ca",
+		Subject: pkix.Name{CommonName: "kubernetes-ca"},
+	}, nil)
+	if err != nil {
+		t.Fatalf("error issuing certificate: %v", err)
+	}
+	keyset, err := NewKeyset(cert, privateKey)
+	if err != nil {
+		t.Fatalf("error building keyset: %v", err)
+	}
+
+	if err := NewVFSCAStore(cluster, basePath).StoreKeyset("kubernetes-ca", keyset); err != nil {
+		t.Fatalf("error from StoreKeyset: %v", err)
+	}
+
+	data, err := basePath.Join("private", "kubernetes-ca", "keyset.yaml").ReadFile()
+	if err != nil {
+		t.Fatalf("error reading keyset: %v", err)
+	}
+	if !stateencryption.IsEncrypted(data) {
+		t.Fatalf("expected keyset to be encrypted, was %q", data)
+	}
+
+	// The key file is taken from the cluster configuration, not from the encrypted file
+	if unconfigured, err := NewVFSCAStore(nil, basePath).FindKeyset("kubernetes-ca"); err == nil && unconfigured != nil {
+		t.Fatalf("expected keyset not to be readable without the key file configured")
+	}
+	loaded, err := NewVFSCAStore(cluster, basePath).FindKeyset("kubernetes-ca")
+	if err != nil {
+		t.Fatalf("error reading keyset: %v", err)
+	}
+	if loaded == nil {
+		t.Fatalf("keyset was not found")
+	}
+
+	expected, err := privateKey.AsString()
+	if err != nil {
+		t.Fatalf("error serializing private key: %v", err)
+	}
+	actual, err := loaded.Primary.PrivateKey.AsString()
+	if err != nil {
+		t.Fatalf("error serializing private key: %v", err)
+	}
+	if actual != expected {
+		t.Errorf("unexpected round-tripped private key data: %q", actual)
+	}
+}
+
 func TestVFSCAStoreRoundTripWithVault(t *testing.T) {
 	token := os.Getenv("VAULT_DEV_ROOT_TOKEN_ID")
 	if token == "" {
@@ -26,6 +26,9 @@ type Distribution struct {
 	// packageFormat is the packaging format used by this distro; either deb or rpm, or "" for immutable OSes
 	packageFormat string
 
+	// packageManager is the tool used to install packages on this distro, or "" for immutable OSes
+	packageManager PackageManager
+
 	// project is the entity that produces the distribution e.g. "debian" or "ubuntu" or "rhel" or "centos"
 	project string
 
@@ -36,22 +39,69 @@ type Distribution struct {
 	version float32
 }
 
+// PackageManager is the tool used to query and install OS packages
+type PackageManager string
+
+const (
+	PackageManagerApt    PackageManager = "apt"
+	PackageManagerYum    PackageManager = "yum"
+	PackageManagerDnf    PackageManager = "dnf"
+	PackageManagerZypper PackageManager = "zypper"
+)
+
 var (
-	DistributionDebian10     = Distribution{packageFormat: "deb", project: "debian", id: "buster", version: 10}
-	DistributionDebian11     = Distribution{packageFormat: "deb", project: "debian", id: "bullseye", version: 11}
-	DistributionUbuntu1804   = Distribution{packageFormat: "deb", project: "ubuntu", id: "bionic", version: 18.04}
-	DistributionUbuntu2004   = Distribution{packageFormat: "deb", project: "ubuntu", id: "focal", version: 20.04}
-	DistributionUbuntu2010   = Distribution{packageFormat: "deb", project: "ubuntu", id: "groovy", version: 20.10}
-	DistributionUbuntu2104   = Distribution{packageFormat: "deb", project: "ubuntu", id: "hirsute", version: 21.04}
-	DistributionUbuntu2110   = Distribution{packageFormat: "deb", project: "ubuntu", id: "impish", version: 21.10}
-	DistributionUbuntu2204   = Distribution{packageFormat: "deb", project: "ubuntu", id: "jammy", version: 22.04}
-	DistributionAmazonLinux2 = Distribution{packageFormat: "rpm", project: "amazonlinux2", id: "amazonlinux2", version: 0}
-	DistributionRhel8        = Distribution{packageFormat: "rpm", project: "rhel", id: "rhel8", version: 8}
-	DistributionRocky8       = Distribution{packageFormat: "rpm", project: "rocky", id: "rocky8", version: 8}
-	DistributionFlatcar      = Distribution{packageFormat: "", project: "flatcar", id: "flatcar", version: 0}
-	DistributionContainerOS  = Distribution{packageFormat: "", project: "containeros", id: "containeros", version: 0}
+	DistributionDebian10        = Distribution{packageFormat: "deb", packageManager: PackageManagerApt, project: "debian", id: "buster", version: 10}
+	DistributionDebian11        = Distribution{packageFormat: "deb", packageManager: PackageManagerApt, project: "debian", id: "bullseye", version: 11}
+	DistributionDebian12        = Distribution{packageFormat: "deb", packageManager: PackageManagerApt, project: "debian", id: "bookworm", version: 12}
+	DistributionUbuntu1804      = Distribution{packageFormat: "deb", packageManager: PackageManagerApt, project: "ubuntu", id: "bionic", version: 18.04}
+	DistributionUbuntu2004      = Distribution{packageFormat: "deb", packageManager: PackageManagerApt, project: "ubuntu", id: "focal", version: 20.04}
+	DistributionUbuntu2010      = Distribution{packageFormat: "deb", packageManager: PackageManagerApt, project: "ubuntu", id: "groovy", version: 20.10}
+	DistributionUbuntu2104      = Distribution{packageFormat: "deb", packageManager: PackageManagerApt, project: "ubuntu", id: "hirsute", version: 21.04}
+	DistributionUbuntu2110      = Distribution{packageFormat: "deb", packageManager: PackageManagerApt, project: "ubuntu", id: "impish", version: 21.10}
+	DistributionUbuntu2204      = Distribution{packageFormat: "deb", packageManager: PackageManagerApt, project: "ubuntu", id: "jammy", version: 22.04}
+	DistributionAmazonLinux2    = Distribution{packageFormat: "rpm", packageManager: PackageManagerYum, project: "amazonlinux2", id: "amazonlinux2", version: 0}
+	DistributionAmazonLinux2023 = Distribution{packageFormat: "rpm", packageManager: PackageManagerDnf, project: "amazonlinux2023", id: "amazonlinux2023", version: 0}
+	DistributionRhel8           = Distribution{packageFormat: "rpm", packageManager: PackageManagerDnf, project: "rhel", id: "rhel8", version: 8}
+	DistributionRhel9           = Distribution{packageFormat: "rpm", packageManager: PackageManagerDnf, project: "rhel", id: "rhel9", version: 9}
+	DistributionRocky8          = Distribution{packageFormat: "rpm", packageManager: PackageManagerDnf, project: "rocky", id: "rocky8", version: 8}
+	DistributionRocky9          = Distribution{packageFormat: "rpm", packageManager: PackageManagerDnf, project: "rocky", id: "rocky9", version: 9}
+	DistributionSLES15          = Distribution{packageFormat: "rpm", packageManager: PackageManagerZypper, project: "sles", id: "sles15", version: 15}
+	DistributionFlatcar         = Distribution{packageFormat: "", project: "flatcar", id: "flatcar", version: 0}
+	DistributionContainerOS     = Distribution{packageFormat: "", project: "containeros", id: "containeros", version: 0}
 )
 
+// all holds the distributions that can be identified, for lookup by id
+var all = []Distribution{
+	DistributionDebian10,
+	DistributionDebian11,
+	DistributionDebian12,
+	DistributionUbuntu1804,
+	DistributionUbuntu2004,
+	DistributionUbuntu2010,
+	DistributionUbuntu2104,
+	DistributionUbuntu2110,
+	DistributionUbuntu2204,
+	DistributionAmazonLinux2,
+	DistributionAmazonLinux2023,
+	DistributionRhel8,
+	DistributionRhel9,
+	DistributionRocky8,
+	DistributionRocky9,
+	DistributionSLES15,
+	DistributionFlatcar,
+	DistributionContainerOS,
+}
+
+// FindDistributionByID returns the distribution with the given id, e.g. "jammy" or "rocky9"
+func FindDistributionByID(id string) (Distribution, error) {
+	for _, d := range all {
+		if d.id == id {
+			return d, nil
+		}
+	}
+	return Distribution{}, fmt.Errorf("unknown distribution %q", id)
+}
+
 // IsDebianFamily returns true if this distribution uses deb packages and generally follows debian package names
 func (d *Distribution) IsDebianFamily() bool {
 	return d.packageFormat == "deb"
@@ -64,7 +114,17 @@ func (d *Distribution) IsUbuntu() bool {
 
 // IsRHELFamily returns true if this distribution uses rpm packages and generally follows rhel package names
 func (d *Distribution) IsRHELFamily() bool {
-	return d.packageFormat == "rpm"
+	return d.packageFormat == "rpm" && !d.IsSUSEFamily()
+}
+
+// IsSUSEFamily returns true if this distribution uses rpm packages and generally follows suse package names
+func (d *Distribution) IsSUSEFamily() bool {
+	return d.project == "sles"
+}
+
+// PackageManager returns the tool used to install packages, or "" if packages cannot be installed
+func (d *Distribution) PackageManager() PackageManager {
+	return d.packageManager
 }
 
 // IsSystemd returns true if this distribution uses systemd
@@ -81,7 +141,7 @@ func (d *Distribution) DefaultUsers() ([]string, error) {
 		return []string{"ubuntu", "root"}, nil
 	case "centos":
 		return []string{"centos"}, nil
-	case "rhel", "amazonlinux2":
+	case "rhel", "amazonlinux2", "amazonlinux2023", "sles":
 		return []string{"ec2-user"}, nil
 	case "rocky":
 		return []string{"rocky"}, nil
@@ -105,6 +165,11 @@ func (d *Distribution) HasLoopbackEtcResolvConf() bool {
 	return false
 }
 
+// ID returns the name of the distribution version e.g. "jammy" or "rocky9"
+func (d *Distribution) ID() string {
+	return d.id
+}
+
 // Version returns the (project scoped) numeric version
 func (d *Distribution) Version() float32 {
 	return d.version
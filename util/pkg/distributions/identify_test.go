@@ -34,6 +34,11 @@ func TestFindDistribution(t *testing.T) {
 			err:      nil,
 			expected: DistributionAmazonLinux2,
 		},
+		{
+			rootfs:   "amazonlinux2023",
+			err:      nil,
+			expected: DistributionAmazonLinux2023,
+		},
 		{
 			rootfs:   "centos7",
 			err:      fmt.Errorf("unsupported distro: centos-7"),
@@ -74,6 +79,11 @@ func TestFindDistribution(t *testing.T) {
 			err:      nil,
 			expected: DistributionDebian11,
 		},
+		{
+			rootfs:   "debian12",
+			err:      nil,
+			expected: DistributionDebian12,
+		},
 		{
 			rootfs:   "flatcar",
 			err:      nil,
@@ -89,11 +99,26 @@ func TestFindDistribution(t *testing.T) {
 			err:      nil,
 			expected: DistributionRhel8,
 		},
+		{
+			rootfs:   "rhel9",
+			err:      nil,
+			expected: DistributionRhel9,
+		},
 		{
 			rootfs:   "rocky8",
 			err:      nil,
 			expected: DistributionRocky8,
 		},
+		{
+			rootfs:   "rocky9",
+			err:      nil,
+			expected: DistributionRocky9,
+		},
+		{
+			rootfs:   "sles15",
+			err:      nil,
+			expected: DistributionSLES15,
+		},
 		{
 			rootfs:   "ubuntu1604",
 			err:      fmt.Errorf("unsupported distro: ubuntu-16.04"),
@@ -147,3 +172,20 @@ func TestFindDistribution(t *testing.T) {
 		}
 	}
 }
+
+func TestFindDistributionByID(t *testing.T) {
+	for _, d := range all {
+		actual, err := FindDistributionByID(d.ID())
+		if err != nil {
+			t.Errorf("unexpected error for %q: %v", d.ID(), err)
+			continue
+		}
+		if actual != d {
+			t.Errorf("unexpected distribution for %q, actual=%v, expected=%v", d.ID(), actual, d)
+		}
+	}
+
+	if _, err := FindDistributionByID("centos7"); err == nil {
+		t.Errorf("expected an error for an unknown distribution")
+	}
+}
@@ -24,6 +24,7 @@ import (
 	"path"
 	"sync"
 	"syscall"
+	"time"
 
 	"k8s.io/klog/v2"
 	"k8s.io/kops/pkg/try"
@@ -35,8 +36,9 @@ type FSPath struct {
 }
 
 var (
-	_ Path    = &FSPath{}
-	_ HasHash = &FSPath{}
+	_ Path                = &FSPath{}
+	_ HasHash             = &FSPath{}
+	_ HasConditionalWrite = &FSPath{}
 )
 
 func NewFSPath(location string) *FSPath {
@@ -113,6 +115,64 @@ func (p *FSPath) CreateFile(data io.ReadSeeker, acl ACL) error {
 	return p.WriteFile(data, acl)
 }
 
+// ReadFileWithVersion implements HasConditionalWrite::ReadFileWithVersion
+func (p *FSPath) ReadFileWithVersion() ([]byte, string, error) {
+	data, err := p.ReadFile()
+	if err != nil {
+		return nil, "", err
+	}
+	return data, contentVersion(data), nil
+}
+
+// WriteFileIfVersion implements HasConditionalWrite::WriteFileIfVersion
+// Writers are serialized by exclusively creating a lock file next to the file,
+// so that the comparison and write are atomic across processes.
+func (p *FSPath) WriteFileIfVersion(data io.ReadSeeker, acl ACL, version string) (string, error) {
+	lockPath := p.location + ".lock"
+	lock, err := os.OpenFile(lockPath, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		if os.IsNotExist(err) {
+			// The directory doesn't exist, so neither does the file
+			return "", ErrVersionMismatch
+		}
+		if os.IsExist(err) {
+			return "", lockedError(p.location, lockPath)
+		}
+		return "", fmt.Errorf("error locking %s: %v", p.location, err)
+	}
+	defer func() {
+		try.CloseFile(lock)
+		if err := os.Remove(lockPath); err != nil {
+			klog.Warningf("unable to remove lock file %q: %v", lockPath, err)
+		}
+	}()
+
+	current, err := p.ReadFile()
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", ErrVersionMismatch
+		}
+		return "", err
+	}
+	if contentVersion(current) != version {
+		return "", ErrVersionMismatch
+	}
+
+	return writeFileWithContentVersion(p, data, acl)
+}
+
+// lockedError describes a lock file held by another writer. A writer that crashes leaves its lock file behind,
+// so the error includes its age to tell a stale lock from a concurrent write.
+func lockedError(location string, lockPath string) error {
+	info, err := os.Stat(lockPath)
+	if err != nil {
+		// The other writer has just finished
+		return fmt.Errorf("%s is being written by another process, try again", location)
+	}
+	age := time.Since(info.ModTime()).Round(time.Second)
+	return fmt.Errorf("%s is being written by another process: lock file %q was created %v ago; if no other kops process is writing it, for example because one was interrupted, remove the lock file and try again", location, lockPath, age)
+}
+
 // ReadFile implements Path::ReadFile
 func (p *FSPath) ReadFile() ([]byte, error) {
 	file, err := os.ReadFile(p.location)
@@ -26,6 +26,7 @@ import (
 	"net/http"
 	"os"
 	"path"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -47,9 +48,10 @@ type GSPath struct {
 }
 
 var (
-	_ Path          = &GSPath{}
-	_ TerraformPath = &GSPath{}
-	_ HasHash       = &GSPath{}
+	_ Path                = &GSPath{}
+	_ TerraformPath       = &GSPath{}
+	_ HasHash             = &GSPath{}
+	_ HasConditionalWrite = &GSPath{}
 )
 
 // gcsReadBackoff is the backoff strategy for GCS read retries
@@ -254,6 +256,101 @@ func (p *GSPath) ReadFile() ([]byte, error) {
 	}
 }
 
+// ReadFileWithVersion implements HasConditionalWrite::ReadFileWithVersion, using the object generation as the version
+func (p *GSPath) ReadFileWithVersion() ([]byte, string, error) {
+	var data []byte
+	var generation string
+	done, err := RetryWithBackoff(gcsReadBackoff, func() (bool, error) {
+		var err error
+		data, generation, err = p.readWithGeneration()
+		if err != nil {
+			if os.IsNotExist(err) {
+				// Not recoverable
+				return true, err
+			}
+			return false, err
+		}
+		if generation == "" {
+			// Not recoverable
+			return true, fmt.Errorf("no generation returned from reading %s", p)
+		}
+		// Success!
+		return true, nil
+	})
+	if err != nil {
+		return nil, "", err
+	} else if done {
+		return data, generation, nil
+	} else {
+		// Shouldn't happen - we always return a non-nil error with false
+		return nil, "", wait.ErrWaitTimeout
+	}
+}
+
+// readWithGeneration reads the object and its generation, without retrying
+func (p *GSPath) readWithGeneration() ([]byte, string, error) {
+	klog.V(4).Infof("Reading file %q", p)
+
+	response, err := p.client.Objects.Get(p.bucket, p.key).Download()
+	if err != nil {
+		if isGCSNotFound(err) {
+			return nil, "", os.ErrNotExist
+		}
+		return nil, "", fmt.Errorf("error reading %s: %v", p, err)
+	}
+	if response == nil {
+		return nil, "", fmt.Errorf("no response returned from reading %s", p)
+	}
+	defer response.Body.Close()
+
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, "", fmt.Errorf("error reading %s: %v", p, err)
+	}
+	return data, response.Header.Get("X-Goog-Generation"), nil
+}
+
+// WriteFileIfVersion implements HasConditionalWrite::WriteFileIfVersion, writing with an ifGenerationMatch precondition
+func (p *GSPath) WriteFileIfVersion(data io.ReadSeeker, acl ACL, version string) (string, error) {
+	generation, err := strconv.ParseInt(version, 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("invalid generation %q for %s: %v", version, p, err)
+	}
+
+	md5Hash, err := hashing.HashAlgorithmMD5.Hash(data)
+	if err != nil {
+		return "", err
+	}
+
+	obj := &storage.Object{
+		Name:    p.key,
+		Md5Hash: base64.StdEncoding.EncodeToString(md5Hash.HashValue),
+	}
+	if acl != nil {
+		gsACL, ok := acl.(*GSAcl)
+		if !ok {
+			return "", fmt.Errorf("write to %s with ACL of unexpected type %T", p, acl)
+		}
+		obj.Acl = gsACL.Acl
+	}
+
+	klog.V(4).Infof("Writing file %q if it matches generation %d", p, generation)
+
+	if _, err := data.Seek(0, 0); err != nil {
+		return "", fmt.Errorf("error seeking to start of data stream for write to %s: %v", p, err)
+	}
+
+	// We don't retry conditional writes; a retry after a lost response would fail the precondition
+	written, err := p.client.Objects.Insert(p.bucket, obj).IfGenerationMatch(generation).Media(data).Do()
+	if err != nil {
+		if apiErr, ok := err.(*googleapi.Error); ok && apiErr.Code == http.StatusPreconditionFailed {
+			return "", ErrVersionMismatch
+		}
+		return "", fmt.Errorf("error writing %s: %v", p, err)
+	}
+	return strconv.FormatInt(written.Generation, 10), nil
+}
+
 // WriteTo implements io.WriterTo::WriteTo
 func (p *GSPath) WriteTo(out io.Writer) (int64, error) {
 	klog.V(4).Infof("Reading file %q", p)
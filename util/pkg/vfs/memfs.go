@@ -38,8 +38,9 @@ type MemFSPath struct {
 }
 
 var (
-	_ Path          = &MemFSPath{}
-	_ TerraformPath = &MemFSPath{}
+	_ Path                = &MemFSPath{}
+	_ TerraformPath       = &MemFSPath{}
+	_ HasConditionalWrite = &MemFSPath{}
 )
 
 type MemFSContext struct {
@@ -132,6 +133,28 @@ func (p *MemFSPath) ReadFile() ([]byte, error) {
 	return p.contents, nil
 }
 
+// ReadFileWithVersion implements HasConditionalWrite::ReadFileWithVersion
+func (p *MemFSPath) ReadFileWithVersion() ([]byte, string, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.contents == nil {
+		return nil, "", os.ErrNotExist
+	}
+	return p.contents, contentVersion(p.contents), nil
+}
+
+// WriteFileIfVersion implements HasConditionalWrite::WriteFileIfVersion
+func (p *MemFSPath) WriteFileIfVersion(r io.ReadSeeker, acl ACL, version string) (string, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.contents == nil || contentVersion(p.contents) != version {
+		return "", ErrVersionMismatch
+	}
+	return writeFileWithContentVersion(p, r, acl)
+}
+
 // WriteTo implements io.WriterTo
 func (p *MemFSPath) WriteTo(out io.Writer) (int64, error) {
 	if p.contents == nil {
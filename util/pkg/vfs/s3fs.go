@@ -50,9 +50,10 @@ type S3Path struct {
 }
 
 var (
-	_ Path          = &S3Path{}
-	_ TerraformPath = &S3Path{}
-	_ HasHash       = &S3Path{}
+	_ Path                = &S3Path{}
+	_ TerraformPath       = &S3Path{}
+	_ HasHash             = &S3Path{}
+	_ HasConditionalWrite = &S3Path{}
 )
 
 // S3Acl is an ACL implementation for objects on S3
@@ -319,6 +320,69 @@ func (p *S3Path) ReadFile() ([]byte, error) {
 	return b.Bytes(), nil
 }
 
+// ReadFileWithVersion implements HasConditionalWrite::ReadFileWithVersion, using the ETag as the version
+func (p *S3Path) ReadFileWithVersion() ([]byte, string, error) {
+	client, err := p.client()
+	if err != nil {
+		return nil, "", err
+	}
+
+	klog.V(4).Infof("Reading file %q", p)
+
+	request := &s3.GetObjectInput{}
+	request.Bucket = aws.String(p.bucket)
+	request.Key = aws.String(p.key)
+
+	response, err := client.GetObject(request)
+	if err != nil {
+		if AWSErrorCode(err) == "NoSuchKey" {
+			return nil, "", os.ErrNotExist
+		}
+		return nil, "", fmt.Errorf("error fetching %s: %v", p, err)
+	}
+	defer response.Body.Close()
+
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, "", fmt.Errorf("error reading %s: %v", p, err)
+	}
+	return data, aws.StringValue(response.ETag), nil
+}
+
+// WriteFileIfVersion implements HasConditionalWrite::WriteFileIfVersion, sending the version as an If-Match precondition
+func (p *S3Path) WriteFileIfVersion(data io.ReadSeeker, aclObj ACL, version string) (string, error) {
+	client, err := p.client()
+	if err != nil {
+		return "", err
+	}
+
+	klog.V(4).Infof("Writing file %q if it matches ETag %s", p, version)
+
+	request := &s3.PutObjectInput{}
+	request.Body = data
+	request.Bucket = aws.String(p.bucket)
+	request.Key = aws.String(p.key)
+	request.ServerSideEncryption, _, _ = p.getServerSideEncryption()
+
+	request.ACL, err = p.getRequestACL(aclObj)
+	if err != nil {
+		return "", err
+	}
+
+	// The SDK doesn't model conditional writes, so we set the header on the request directly
+	req, response := client.PutObjectRequest(request)
+	req.HTTPRequest.Header.Set("If-Match", version)
+	if err := req.Send(); err != nil {
+		switch AWSErrorCode(err) {
+		case "PreconditionFailed", "ConditionalRequestConflict", "NoSuchKey":
+			return "", ErrVersionMismatch
+		}
+		return "", fmt.Errorf("error writing %s: %v", p, err)
+	}
+
+	return aws.StringValue(response.ETag), nil
+}
+
 // WriteTo implements io.WriterTo
 func (p *S3Path) WriteTo(out io.Writer) (int64, error) {
 	client, err := p.client()
@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
 
 	"k8s.io/klog/v2"
@@ -32,6 +33,16 @@ import (
 	vault "github.com/hashicorp/vault/api"
 )
 
+// NewVaultClient builds a client for the Vault server at address (for example https://vault.example.com:8200).
+// It authenticates with the VAULT_TOKEN environment variable if set, otherwise with AWS IAM.
+func NewVaultClient(address string) (*vault.Client, error) {
+	u, err := url.Parse(address)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid vault address: %q", address)
+	}
+	return newVaultClient(u.Scheme+"://", u.Hostname(), u.Port())
+}
+
 func newVaultClient(scheme string, host string, port string) (*vault.Client, error) {
 	addr := scheme + host
 	if port != "" {
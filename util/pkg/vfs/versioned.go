@@ -0,0 +1,108 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vfs
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// ErrVersionMismatch is returned by conditional writes when the file has been modified or removed since the expected version was read.
+var ErrVersionMismatch = errors.New("file has been modified since it was read")
+
+// HasConditionalWrite is implemented by paths that can use the preconditions of their backing store
+// to replace a file only if it has not been modified since it was read.
+type HasConditionalWrite interface {
+	// ReadFileWithVersion returns the contents of the file, along with an opaque token identifying the version that was read.
+	ReadFileWithVersion() ([]byte, string, error)
+
+	// WriteFileIfVersion replaces the contents of the file if its current version matches the given version,
+	// returning the version that was written.  It returns ErrVersionMismatch if the version does not match.
+	WriteFileIfVersion(data io.ReadSeeker, acl ACL, version string) (string, error)
+}
+
+// ReadFileWithVersion returns the contents of the file along with a token identifying the version that was read.
+// Paths that don't implement HasConditionalWrite use a hash of the contents as the version.
+func ReadFileWithVersion(p Path) ([]byte, string, error) {
+	if versioned, ok := p.(HasConditionalWrite); ok {
+		return versioned.ReadFileWithVersion()
+	}
+
+	data, err := p.ReadFile()
+	if err != nil {
+		return nil, "", err
+	}
+	return data, contentVersion(data), nil
+}
+
+// To compare and write paths that don't implement HasConditionalWrite we take a process-wide lock;
+// this doesn't protect against writes from other processes.
+var writeFileIfVersionLock sync.Mutex
+
+// WriteFileIfVersion replaces the contents of the file if it has not been modified since the given version was read,
+// returning the version that was written, or ErrVersionMismatch.
+func WriteFileIfVersion(p Path, data io.ReadSeeker, acl ACL, version string) (string, error) {
+	if version == "" {
+		return "", fmt.Errorf("version is required for conditional write to %s", p)
+	}
+
+	if versioned, ok := p.(HasConditionalWrite); ok {
+		return versioned.WriteFileIfVersion(data, acl, version)
+	}
+
+	writeFileIfVersionLock.Lock()
+	defer writeFileIfVersionLock.Unlock()
+
+	current, err := p.ReadFile()
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", ErrVersionMismatch
+		}
+		return "", err
+	}
+	if contentVersion(current) != version {
+		return "", ErrVersionMismatch
+	}
+
+	return writeFileWithContentVersion(p, data, acl)
+}
+
+// writeFileWithContentVersion writes the file, returning the hash of the contents as the version.
+func writeFileWithContentVersion(p Path, data io.ReadSeeker, acl ACL) (string, error) {
+	b, err := io.ReadAll(data)
+	if err != nil {
+		return "", fmt.Errorf("error reading data: %v", err)
+	}
+	if _, err := data.Seek(0, 0); err != nil {
+		return "", fmt.Errorf("error seeking to start of data stream for write to %s: %v", p, err)
+	}
+	if err := p.WriteFile(data, acl); err != nil {
+		return "", err
+	}
+	return contentVersion(b), nil
+}
+
+// contentVersion is the version of files in stores without native object versions.
+func contentVersion(data []byte) string {
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vfs
+
+import (
+	"bytes"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteFileIfVersion(t *testing.T) {
+	tests := []struct {
+		name string
+		path Path
+	}{
+		{
+			name: "fs",
+			path: NewFSPath(path.Join(t.TempDir(), "SubDir", "config")),
+		},
+		{
+			name: "memfs",
+			path: NewMemFSPath(NewMemFSContext(), "cluster/config"),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p := test.path
+
+			if _, err := WriteFileIfVersion(p, bytes.NewReader([]byte("data")), nil, "missing"); err != ErrVersionMismatch {
+				t.Fatalf("expected ErrVersionMismatch writing missing file, got: %v", err)
+			}
+
+			if err := p.CreateFile(bytes.NewReader([]byte("first")), nil); err != nil {
+				t.Fatalf("error creating file: %v", err)
+			}
+
+			data, version, err := ReadFileWithVersion(p)
+			if err != nil {
+				t.Fatalf("error reading file: %v", err)
+			}
+			if string(data) != "first" {
+				t.Errorf("unexpected contents %q", data)
+			}
+
+			newVersion, err := WriteFileIfVersion(p, bytes.NewReader([]byte("second")), nil, version)
+			if err != nil {
+				t.Fatalf("error writing file: %v", err)
+			}
+			if newVersion == version {
+				t.Errorf("expected version to change after write, was %q", version)
+			}
+
+			// A writer holding the version we started with must not overwrite the second write
+			if _, err := WriteFileIfVersion(p, bytes.NewReader([]byte("third")), nil, version); err != ErrVersionMismatch {
+				t.Errorf("expected ErrVersionMismatch writing stale version, got: %v", err)
+			}
+
+			data, version, err = ReadFileWithVersion(p)
+			if err != nil {
+				t.Fatalf("error reading file: %v", err)
+			}
+			if string(data) != "second" {
+				t.Errorf("unexpected contents %q", data)
+			}
+			if version != newVersion {
+				t.Errorf("expected version %q, got %q", newVersion, version)
+			}
+
+			if fsPath, ok := p.(*FSPath); ok {
+				if _, err := os.Stat(fsPath.location + ".lock"); !os.IsNotExist(err) {
+					t.Errorf("expected lock file to be removed, got: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestWriteFileIfVersionStaleLock(t *testing.T) {
+	p := NewFSPath(path.Join(t.TempDir(), "config"))
+	if err := p.CreateFile(bytes.NewReader([]byte("first")), nil); err != nil {
+		t.Fatalf("error creating file: %v", err)
+	}
+	_, version, err := ReadFileWithVersion(p)
+	if err != nil {
+		t.Fatalf("error reading file: %v", err)
+	}
+
+	// A lock file left behind by a writer that was interrupted an hour ago
+	lockPath := p.location + ".lock"
+	if err := os.WriteFile(lockPath, nil, 0o600); err != nil {
+		t.Fatalf("error creating lock file: %v", err)
+	}
+	created := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(lockPath, created, created); err != nil {
+		t.Fatalf("error setting lock file time: %v", err)
+	}
+
+	_, err = WriteFileIfVersion(p, bytes.NewReader([]byte("second")), nil, version)
+	if err == nil {
+		t.Fatalf("expected an error writing a locked file")
+	}
+	if !strings.Contains(err.Error(), lockPath) || !strings.Contains(err.Error(), "1h0m0s ago") {
+		t.Errorf("expected the error to report the lock file and its age, got: %v", err)
+	}
+
+	if err := os.Remove(lockPath); err != nil {
+		t.Fatalf("error removing lock file: %v", err)
+	}
+	if _, err := WriteFileIfVersion(p, bytes.NewReader([]byte("second")), nil, version); err != nil {
+		t.Errorf("unexpected error writing once the lock file is removed: %v", err)
+	}
+}